@@ -18,59 +18,63 @@ var DefaultsFS embed.FS
 
 var defaultSeedAgents = []string{"chatter", "coder"}
 
+// defaultSeedTrees are the DefaultsFS directories copied into a generated
+// workspace under the same name.
+var defaultSeedTrees = []string{"tools", "models", "embedders", "feeds", "knowledge", "templates", "prompts"}
+
 func SetBootstrapHook() {
 	workspace.SetBootstrapHook(func(store *workspace.BootstrapStore) error {
-		existingWorkspace, err := workspaceRootExists(store.Root())
-		if err != nil {
-			return err
-		}
-		emptyWorkspace, err := workspace.IsEmptyWorkspaceAt(context.Background(), afs.New(), store.Root())
-		if err != nil {
-			return err
-		}
-		if err := ensureWorkspaceDirs(store.Root()); err != nil {
+		return bootstrapWorkspace(store.Root())
+	})
+}
+
+// bootstrapWorkspace creates the workspace directories and, for a new or
+// empty workspace, seeds the bundled defaults together with their manifest.
+func bootstrapWorkspace(root string) error {
+	existingWorkspace, err := workspaceRootExists(root)
+	if err != nil {
+		return err
+	}
+	emptyWorkspace, err := workspace.IsEmptyWorkspaceAt(context.Background(), afs.New(), root)
+	if err != nil {
+		return err
+	}
+	if err := ensureWorkspaceDirs(root); err != nil {
+		return err
+	}
+	generatedWorkspace := !existingWorkspace || emptyWorkspace
+	if generatedWorkspace {
+		if err := seedFileIfMissing(root, "config.yaml", "defaults/config.yaml"); err != nil {
 			return err
 		}
-		generatedWorkspace := !existingWorkspace || emptyWorkspace
-		if generatedWorkspace {
-			if err := seedFileIfMissing(store.Root(), "config.yaml", "defaults/config.yaml"); err != nil {
+		for _, tree := range defaultSeedTrees {
+			if err := seedTreeIfMissing(root, "defaults/"+tree, tree); err != nil {
 				return err
 			}
-			for _, seed := range []struct {
-				src  string
-				dest string
-			}{
-				{src: "defaults/tools", dest: "tools"},
-				{src: "defaults/models", dest: "models"},
-				{src: "defaults/embedders", dest: "embedders"},
-				{src: "defaults/feeds", dest: "feeds"},
-				{src: "defaults/knowledge", dest: "knowledge"},
-				{src: "defaults/templates", dest: "templates"},
-				{src: "defaults/prompts", dest: "prompts"},
-			} {
-				if err := seedTreeIfMissing(store.Root(), seed.src, seed.dest); err != nil {
-					return err
-				}
-			}
-			for _, agent := range defaultSeedAgents {
-				src := filepath.ToSlash(filepath.Join("defaults", "agents", agent))
-				dest := filepath.ToSlash(filepath.Join("agents", agent))
-				if err := seedTreeIfMissing(store.Root(), src, dest); err != nil {
-					return err
-				}
-			}
-			if err := configureGeneratedWorkspaceModels(store.Root(), os.Getenv); err != nil {
+		}
+		for _, agent := range defaultSeedAgents {
+			src := filepath.ToSlash(filepath.Join("defaults", "agents", agent))
+			dest := filepath.ToSlash(filepath.Join("agents", agent))
+			if err := seedTreeIfMissing(root, src, dest); err != nil {
 				return err
 			}
 		}
-		if err := ensureInternalMCPConfig(filepath.Join(store.Root(), "config.yaml")); err != nil {
+		if err := configureGeneratedWorkspaceModels(root, os.Getenv); err != nil {
 			return err
 		}
-		if err := ensureDefaultWorkspaceConfig(filepath.Join(store.Root(), "config.yaml")); err != nil {
-			return err
+		if _, err := os.Stat(filepath.Join(root, ManifestFile)); os.IsNotExist(err) {
+			if err := writeSeedManifest(root, defaultSeedTrees); err != nil {
+				return err
+			}
 		}
-		return nil
-	})
+	}
+	if err := ensureInternalMCPConfig(filepath.Join(root, "config.yaml")); err != nil {
+		return err
+	}
+	if err := ensureDefaultWorkspaceConfig(filepath.Join(root, "config.yaml")); err != nil {
+		return err
+	}
+	return nil
 }
 
 type generatedWorkspaceModel struct {
@@ -100,11 +104,15 @@ func selectGeneratedWorkspaceModel(getenv func(string) string) string {
 // configured. It runs only while seeding a new/empty workspace, so an existing
 // workspace's explicit model choices are never rewritten.
 func configureGeneratedWorkspaceModels(root string, getenv func(string) string) error {
+	return configureWorkspaceModels(root, defaultSeedAgents, getenv)
+}
+
+func configureWorkspaceModels(root string, agents []string, getenv func(string) string) error {
 	model := selectGeneratedWorkspaceModel(getenv)
 	if err := rewriteGeneratedModelRefs(filepath.Join(root, "config.yaml"), model, true); err != nil {
 		return err
 	}
-	for _, agent := range defaultSeedAgents {
+	for _, agent := range agents {
 		path := filepath.Join(root, "agents", agent, agent+".yaml")
 		if err := rewriteGeneratedModelRefs(path, model, false); err != nil {
 			return err
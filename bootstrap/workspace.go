@@ -0,0 +1,391 @@
+package bootstrap
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	iofs "io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile records the checksum of every default file installed by
+// InitWorkspace or UpgradeWorkspace, relative to the workspace root. Upgrade
+// uses it to tell files the user edited apart from files that are simply
+// older copies of a default.
+const ManifestFile = ".defaults-manifest.json"
+
+// defaultsHistoryJSON lists the checksums of every bundled default release,
+// so UpgradeWorkspace can recognize untouched older copies in workspaces that
+// have no manifest entry for a file. Append to it before changing a default;
+// TestDefaultsHistoryCoversBundledDefaults fails until it is updated.
+//
+//go:embed defaults_history.json
+var defaultsHistoryJSON []byte
+
+// WorkspaceTemplate is a named selection of bundled defaults that
+// InitWorkspace seeds into an empty workspace.
+type WorkspaceTemplate struct {
+	Name        string
+	Description string
+	Trees       []string
+	Agents      []string
+}
+
+var workspaceTemplates = []*WorkspaceTemplate{
+	{
+		Name:        "default",
+		Description: "chatter and coder agents with every bundled model, embedder, tool bundle, feed, template, knowledge file and prompt",
+		Trees:       defaultSeedTrees,
+		Agents:      defaultSeedAgents,
+	},
+	{
+		Name:        "minimal",
+		Description: "chatter agent with bundled models, embedders, tool bundles and templates",
+		Trees:       []string{"tools", "models", "embedders", "templates"},
+		Agents:      []string{"chatter"},
+	},
+}
+
+// upgradeTrees are the default directories UpgradeWorkspace merges into an
+// existing workspace. config.yaml and agents are owned by the user once a
+// workspace is generated and are never rewritten.
+var upgradeTrees = []string{"tools", "models", "embedders", "feeds", "knowledge", "templates", "prompts"}
+
+// WorkspaceTemplates returns the templates accepted by InitWorkspace.
+func WorkspaceTemplates() []*WorkspaceTemplate {
+	return workspaceTemplates
+}
+
+// LookupWorkspaceTemplate returns the named template or nil.
+func LookupWorkspaceTemplate(name string) *WorkspaceTemplate {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, candidate := range workspaceTemplates {
+		if candidate.Name == name {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// InitWorkspace seeds root from the named template. It refuses to touch a
+// workspace that already has a config.yaml; use UpgradeWorkspace for those.
+func InitWorkspace(root, template string, getenv func(string) string) error {
+	root = strings.TrimSpace(root)
+	if root == "" {
+		return fmt.Errorf("workspace root is required")
+	}
+	tpl := LookupWorkspaceTemplate(template)
+	if tpl == nil {
+		return fmt.Errorf("unknown workspace template %q (available: %s)", template, strings.Join(workspaceTemplateNames(), ", "))
+	}
+	if _, err := os.Stat(filepath.Join(root, "config.yaml")); err == nil {
+		return fmt.Errorf("workspace %s is already initialized; use upgrade to merge new defaults", root)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if err := ensureWorkspaceDirs(root); err != nil {
+		return err
+	}
+	if err := seedFileIfMissing(root, "config.yaml", "defaults/config.yaml"); err != nil {
+		return err
+	}
+	for _, tree := range tpl.Trees {
+		if err := seedTreeIfMissing(root, "defaults/"+tree, tree); err != nil {
+			return err
+		}
+	}
+	for _, agent := range tpl.Agents {
+		if err := seedTreeIfMissing(root, "defaults/agents/"+agent, "agents/"+agent); err != nil {
+			return err
+		}
+	}
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+	if err := configureWorkspaceModels(root, tpl.Agents, getenv); err != nil {
+		return err
+	}
+	if err := ensureInternalMCPConfig(filepath.Join(root, "config.yaml")); err != nil {
+		return err
+	}
+	if err := ensureDefaultWorkspaceConfig(filepath.Join(root, "config.yaml")); err != nil {
+		return err
+	}
+	return writeSeedManifest(root, tpl.Trees)
+}
+
+// writeSeedManifest records the bundled checksum of every upgradable default
+// under trees, the files a freshly seeded workspace starts from.
+func writeSeedManifest(root string, trees []string) error {
+	manifest := map[string]string{}
+	for _, tree := range trees {
+		if !isUpgradeTree(tree) {
+			continue
+		}
+		if err := walkDefaults(tree, func(rel string, data []byte) error {
+			manifest[rel] = checksum(data)
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return writeManifest(root, manifest)
+}
+
+// DiffStatus classifies a bundled default against the workspace copy.
+type DiffStatus string
+
+const (
+	DiffUnchanged DiffStatus = "unchanged"
+	DiffModified  DiffStatus = "modified"
+	DiffMissing   DiffStatus = "missing"
+)
+
+// FileDiff compares one bundled default with its workspace counterpart.
+type FileDiff struct {
+	Path    string     `json:"path"`
+	Status  DiffStatus `json:"status"`
+	Default []byte     `json:"-"`
+	Local   []byte     `json:"-"`
+}
+
+// DiffWorkspace compares every file in DefaultsFS with the same path under
+// root. YAML files are compared by value so reformatting alone is not drift.
+// Files that exist only in the workspace are not reported.
+func DiffWorkspace(root string) ([]*FileDiff, error) {
+	var result []*FileDiff
+	err := walkDefaults("", func(rel string, data []byte) error {
+		diff := &FileDiff{Path: rel, Default: data}
+		local, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+		switch {
+		case os.IsNotExist(err):
+			diff.Status = DiffMissing
+		case err != nil:
+			return err
+		case sameDefaultContent(rel, data, local):
+			diff.Status = DiffUnchanged
+			diff.Local = local
+		default:
+			diff.Status = DiffModified
+			diff.Local = local
+		}
+		result = append(result, diff)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Path < result[j].Path })
+	return result, nil
+}
+
+// UpgradeAction describes what UpgradeWorkspace did (or would do) to a file.
+type UpgradeAction string
+
+const (
+	UpgradeAdded   UpgradeAction = "added"
+	UpgradeUpdated UpgradeAction = "updated"
+	UpgradeKept    UpgradeAction = "kept"
+	UpgradeSkipped UpgradeAction = "skipped"
+)
+
+// UpgradeChange is one file UpgradeWorkspace acted on. Files already equal to
+// the bundled default are not reported.
+type UpgradeChange struct {
+	Path   string        `json:"path"`
+	Action UpgradeAction `json:"action"`
+	Reason string        `json:"reason,omitempty"`
+}
+
+// UpgradeWorkspace merges new and changed bundled defaults (models,
+// embedders, tool and template bundles, feeds, knowledge and prompts) into
+// root without clobbering local edits:
+//   - a default missing locally is added, unless the manifest shows it was
+//     installed before and the user deleted it;
+//   - a file whose checksum still matches the manifest is replaced with the
+//     new default, as is a file without a manifest entry that matches an
+//     earlier bundled release;
+//   - any other differing file is kept as a local edit.
+//
+// With dryRun nothing is written.
+func UpgradeWorkspace(root string, dryRun bool) ([]*UpgradeChange, error) {
+	if _, err := os.Stat(root); err != nil {
+		return nil, fmt.Errorf("workspace %s: %w", root, err)
+	}
+	manifest, err := readManifest(root)
+	if err != nil {
+		return nil, err
+	}
+	history, err := readDefaultsHistory()
+	if err != nil {
+		return nil, err
+	}
+	var changes []*UpgradeChange
+	for _, tree := range upgradeTrees {
+		err := walkDefaults(tree, func(rel string, data []byte) error {
+			target := filepath.Join(root, filepath.FromSlash(rel))
+			installed, tracked := manifest[rel]
+			local, err := os.ReadFile(target)
+			switch {
+			case os.IsNotExist(err):
+				if tracked {
+					changes = append(changes, &UpgradeChange{Path: rel, Action: UpgradeSkipped, Reason: "deleted locally"})
+					return nil
+				}
+				changes = append(changes, &UpgradeChange{Path: rel, Action: UpgradeAdded})
+			case err != nil:
+				return err
+			case bytes.Equal(local, data) || sameDefaultContent(rel, data, local):
+				manifest[rel] = checksum(local)
+				return nil
+			case tracked && installed == checksum(local),
+				!tracked && history.contains(rel, checksum(local)):
+				changes = append(changes, &UpgradeChange{Path: rel, Action: UpgradeUpdated})
+			default:
+				changes = append(changes, &UpgradeChange{Path: rel, Action: UpgradeKept, Reason: "modified locally"})
+				return nil
+			}
+			manifest[rel] = checksum(data)
+			if dryRun {
+				return nil
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			return os.WriteFile(target, data, 0o644)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if dryRun {
+		return changes, nil
+	}
+	if err := ensureInternalMCPConfig(filepath.Join(root, "config.yaml")); err != nil {
+		return nil, err
+	}
+	if err := ensureDefaultWorkspaceConfig(filepath.Join(root, "config.yaml")); err != nil {
+		return nil, err
+	}
+	return changes, writeManifest(root, manifest)
+}
+
+// walkDefaults calls fn for every file under defaults/<tree> (all defaults
+// when tree is empty) with its workspace-relative slash path.
+func walkDefaults(tree string, fn func(rel string, data []byte) error) error {
+	start := path.Join("defaults", tree)
+	return iofs.WalkDir(DefaultsFS, start, func(name string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		data, err := iofs.ReadFile(DefaultsFS, name)
+		if err != nil {
+			return err
+		}
+		return fn(strings.TrimPrefix(name, "defaults/"), data)
+	})
+}
+
+func sameDefaultContent(rel string, expected, actual []byte) bool {
+	if bytes.Equal(expected, actual) {
+		return true
+	}
+	switch strings.ToLower(path.Ext(rel)) {
+	case ".yaml", ".yml":
+	default:
+		return false
+	}
+	var left, right interface{}
+	if yaml.Unmarshal(expected, &left) != nil || yaml.Unmarshal(actual, &right) != nil {
+		return false
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+func isUpgradeTree(tree string) bool {
+	for _, candidate := range upgradeTrees {
+		if candidate == tree {
+			return true
+		}
+	}
+	return false
+}
+
+func workspaceTemplateNames() []string {
+	names := make([]string, 0, len(workspaceTemplates))
+	for _, tpl := range workspaceTemplates {
+		names = append(names, tpl.Name)
+	}
+	return names
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// defaultsHistory maps a workspace-relative default path to the checksums of
+// its bundled releases.
+type defaultsHistory struct {
+	Files map[string][]string `json:"files"`
+}
+
+func readDefaultsHistory() (*defaultsHistory, error) {
+	history := &defaultsHistory{}
+	if err := json.Unmarshal(defaultsHistoryJSON, history); err != nil {
+		return nil, fmt.Errorf("parse defaults history: %w", err)
+	}
+	return history, nil
+}
+
+func (h *defaultsHistory) contains(rel, sum string) bool {
+	for _, candidate := range h.Files[rel] {
+		if candidate == sum {
+			return true
+		}
+	}
+	return false
+}
+
+type defaultsManifest struct {
+	Files map[string]string `json:"files"`
+}
+
+func readManifest(root string) (map[string]string, error) {
+	data, err := os.ReadFile(filepath.Join(root, ManifestFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+	manifest := &defaultsManifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", ManifestFile, err)
+	}
+	if manifest.Files == nil {
+		manifest.Files = map[string]string{}
+	}
+	return manifest.Files, nil
+}
+
+func writeManifest(root string, files map[string]string) error {
+	data, err := json.MarshalIndent(&defaultsManifest{Files: files}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(root, ManifestFile), append(data, '\n'), 0o644)
+}
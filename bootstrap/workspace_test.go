@@ -0,0 +1,270 @@
+package bootstrap
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateDefaultsHistory = flag.Bool("update", false, "append the bundled default checksums to defaults_history.json")
+
+func TestInitWorkspaceSeedsTemplate(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "ws")
+	if err := InitWorkspace(root, "minimal", func(string) string { return "" }); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"config.yaml", "agents/chatter/chatter.yaml", "models/openai_gpt-5_4.yaml", "tools/bundles/system_exec.yaml", ManifestFile} {
+		if _, err := os.Stat(filepath.Join(root, path)); err != nil {
+			t.Fatalf("expected %s: %v", path, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "agents", "coder")); !os.IsNotExist(err) {
+		t.Fatalf("minimal template should not seed coder, stat err = %v", err)
+	}
+	if err := InitWorkspace(root, "default", nil); err == nil {
+		t.Fatalf("expected init of an existing workspace to fail")
+	}
+	if err := InitWorkspace(filepath.Join(t.TempDir(), "other"), "bogus", nil); err == nil {
+		t.Fatalf("expected unknown template to fail")
+	}
+}
+
+func TestDiffWorkspaceReportsDrift(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "ws")
+	if err := InitWorkspace(root, "default", func(string) string { return "" }); err != nil {
+		t.Fatal(err)
+	}
+	model := filepath.Join(root, "models", "openai_gpt-5_4.yaml")
+	if err := os.WriteFile(model, []byte("id: openai_gpt-5.4\noptions:\n  provider: openai\n  model: gpt-5.4-custom\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(root, "feeds", "plan.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	diffs, err := DiffWorkspace(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses := map[string]DiffStatus{}
+	for _, diff := range diffs {
+		statuses[diff.Path] = diff.Status
+	}
+	expect := map[string]DiffStatus{
+		"models/openai_gpt-5_4.yaml":    DiffModified,
+		"feeds/plan.yaml":               DiffMissing,
+		"embedders/openai_text.yaml":    DiffUnchanged,
+		"agents/coder/prompt/user.tmpl": DiffUnchanged,
+	}
+	for path, want := range expect {
+		if statuses[path] != want {
+			t.Fatalf("%s status = %q, want %q", path, statuses[path], want)
+		}
+	}
+}
+
+func TestUpgradeWorkspaceKeepsLocalEdits(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "ws")
+	if err := InitWorkspace(root, "default", func(string) string { return "" }); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := readManifest(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An untouched copy of an older default is replaced by the new default.
+	stale := []byte("id: openai_gpt-5.5\noptions:\n  provider: openai\n  model: gpt-5.5-preview\n")
+	writeWorkspaceFile(t, root, "models/openai_gpt-5_5.yaml", stale)
+	manifest["models/openai_gpt-5_5.yaml"] = checksum(stale)
+	// A file the user edited since installation is kept.
+	writeWorkspaceFile(t, root, "models/openai_gpt-5_mini.yaml", []byte("id: openai_gpt-5-mini\noptions:\n  provider: openai\n  model: mine\n"))
+	// A default the user deleted stays deleted.
+	if err := os.Remove(filepath.Join(root, "feeds", "plan.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	// A default the workspace never had (new in this release) is added.
+	if err := os.Remove(filepath.Join(root, "embedders", "openai_text.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	delete(manifest, "embedders/openai_text.yaml")
+	if err := writeManifest(root, manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	dryRun, err := UpgradeWorkspace(root, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dryRun) != 4 {
+		t.Fatalf("dry run changes = %d, want 4: %+v", len(dryRun), dryRun)
+	}
+	if _, err := os.Stat(filepath.Join(root, "embedders", "openai_text.yaml")); !os.IsNotExist(err) {
+		t.Fatalf("dry run must not write files")
+	}
+
+	changes, err := UpgradeWorkspace(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions := map[string]UpgradeAction{}
+	for _, change := range changes {
+		actions[change.Path] = change.Action
+	}
+	expect := map[string]UpgradeAction{
+		"models/openai_gpt-5_5.yaml":    UpgradeUpdated,
+		"models/openai_gpt-5_mini.yaml": UpgradeKept,
+		"feeds/plan.yaml":               UpgradeSkipped,
+		"embedders/openai_text.yaml":    UpgradeAdded,
+	}
+	for path, want := range expect {
+		if actions[path] != want {
+			t.Fatalf("%s action = %q, want %q (all: %+v)", path, actions[path], want, actions)
+		}
+	}
+	updated, err := DefaultsFS.ReadFile("defaults/models/openai_gpt-5_5.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readWorkspaceFile(t, root, "models/openai_gpt-5_5.yaml"); got != string(updated) {
+		t.Fatalf("expected updated default, got %q", got)
+	}
+	if got := readWorkspaceFile(t, root, "models/openai_gpt-5_mini.yaml"); got != "id: openai_gpt-5-mini\noptions:\n  provider: openai\n  model: mine\n" {
+		t.Fatalf("local edit was overwritten: %q", got)
+	}
+
+	again, err := UpgradeWorkspace(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, change := range again {
+		if change.Action == UpgradeAdded || change.Action == UpgradeUpdated {
+			t.Fatalf("second upgrade should be a no-op, got %+v", change)
+		}
+	}
+}
+
+func TestUpgradeWorkspaceSeededByHook(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "ws")
+	if err := bootstrapWorkspace(root); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := readManifest(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest) == 0 {
+		t.Fatalf("expected the hook to write %s", ManifestFile)
+	}
+
+	// A default installed by the hook and changed upstream since is updated.
+	stale := []byte("id: openai_gpt-5.5\noptions:\n  provider: openai\n  model: gpt-5.5-preview\n")
+	writeWorkspaceFile(t, root, "models/openai_gpt-5_5.yaml", stale)
+	manifest["models/openai_gpt-5_5.yaml"] = checksum(stale)
+	if err := writeManifest(root, manifest); err != nil {
+		t.Fatal(err)
+	}
+	changes, err := UpgradeWorkspace(root, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Path != "models/openai_gpt-5_5.yaml" || changes[0].Action != UpgradeUpdated {
+		t.Fatalf("changes = %+v, want the stale default updated", changes)
+	}
+}
+
+func TestUpgradeWorkspaceWithoutManifestUsesDefaultsHistory(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "ws")
+	if err := bootstrapWorkspace(root); err != nil {
+		t.Fatal(err)
+	}
+	// Workspaces seeded before the hook wrote a manifest have none.
+	if err := os.Remove(filepath.Join(root, ManifestFile)); err != nil {
+		t.Fatal(err)
+	}
+	previous := []byte("id: openai_gpt-5.5\noptions:\n  provider: openai\n  model: gpt-5.5-preview\n")
+	writeWorkspaceFile(t, root, "models/openai_gpt-5_5.yaml", previous)
+	writeWorkspaceFile(t, root, "models/openai_gpt-5_mini.yaml", []byte("id: openai_gpt-5-mini\noptions:\n  provider: openai\n  model: mine\n"))
+
+	history, err := readDefaultsHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	history.Files["models/openai_gpt-5_5.yaml"] = append(history.Files["models/openai_gpt-5_5.yaml"], checksum(previous))
+	saved := defaultsHistoryJSON
+	defer func() { defaultsHistoryJSON = saved }()
+	if defaultsHistoryJSON, err = json.Marshal(history); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := UpgradeWorkspace(root, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions := map[string]UpgradeAction{}
+	for _, change := range changes {
+		actions[change.Path] = change.Action
+	}
+	if actions["models/openai_gpt-5_5.yaml"] != UpgradeUpdated {
+		t.Fatalf("earlier bundled release should be updated, got %+v", changes)
+	}
+	if actions["models/openai_gpt-5_mini.yaml"] != UpgradeKept {
+		t.Fatalf("local edit should be kept, got %+v", changes)
+	}
+}
+
+// TestDefaultsHistoryCoversBundledDefaults keeps defaults_history.json in step
+// with the bundled defaults; run with -update after changing a default.
+func TestDefaultsHistoryCoversBundledDefaults(t *testing.T) {
+	history, err := readDefaultsHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history.Files == nil {
+		history.Files = map[string][]string{}
+	}
+	var missing []string
+	for _, tree := range upgradeTrees {
+		err := walkDefaults(tree, func(rel string, data []byte) error {
+			if sum := checksum(data); !history.contains(rel, sum) {
+				missing = append(missing, rel)
+				history.Files[rel] = append(history.Files[rel], sum)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+	if !*updateDefaultsHistory {
+		t.Fatalf("defaults_history.json lacks the current checksum of %v; run go test ./bootstrap -run DefaultsHistory -update", missing)
+	}
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("defaults_history.json", append(data, '\n'), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeWorkspaceFile(t *testing.T, root, rel string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(root, filepath.FromSlash(rel)), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readWorkspaceFile(t *testing.T, root, rel string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
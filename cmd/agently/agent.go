@@ -0,0 +1,666 @@
+package agently
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	agentlyrt "github.com/viant/agently/runtime"
+	"gopkg.in/yaml.v3"
+)
+
+// AgentCmd groups workspace agent subcommands. They read the workspace
+// directly and do not need a running server.
+type AgentCmd struct {
+	List     *AgentListCmd     `command:"list" description:"List workspace agents and external A2A agents with their profile"`
+	Show     *AgentShowCmd     `command:"show" description:"Show an agent's resolved config, prompts and tool bundles"`
+	Scaffold *AgentScaffoldCmd `command:"scaffold" description:"Create a new agent directory with prompt templates"`
+	Lint     *AgentLintCmd     `command:"lint" description:"Check agents for missing models, unknown bundles and broken template URIs"`
+}
+
+type AgentListCmd struct {
+	workspaceRootOption
+	Long bool `short:"l" long:"long" description:"also print responsibilities and scope"`
+	JSON bool `long:"json" description:"print agents as JSON"`
+}
+
+type AgentShowCmd struct {
+	workspaceRootOption
+	AgentID   string `short:"a" long:"agent-id" description:"agent id" required:"true"`
+	NoPrompts bool   `long:"no-prompts" description:"omit prompt template contents"`
+	JSON      bool   `long:"json" description:"print the resolved agent as JSON"`
+}
+
+type AgentScaffoldCmd struct {
+	workspaceRootOption
+	AgentID     string   `short:"a" long:"agent-id" description:"new agent id (letters, digits, '-' and '_')" required:"true"`
+	Name        string   `long:"name" description:"display name (defaults to the id)"`
+	Description string   `long:"description" description:"one-line description used in the agent profile"`
+	Model       string   `short:"m" long:"model" description:"model id for modelRef (defaults to the workspace default model)"`
+	Bundles     []string `long:"bundle" description:"tool bundle to enable (repeatable)" default:"resources" default:"system/exec" default:"system/os"`
+}
+
+type AgentLintCmd struct {
+	workspaceRootOption
+	AgentIDs []string `short:"a" long:"agent-id" description:"agent to lint (repeatable, default all)"`
+	Strict   bool     `long:"strict" description:"treat warnings as errors"`
+	JSON     bool     `long:"json" description:"print issues as JSON"`
+}
+
+// agentSpec is the subset of an agent definition the CLI reports on.
+type agentSpec struct {
+	ID          string `yaml:"id"`
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	ModelRef    string `yaml:"modelRef"`
+	Persona     *struct {
+		Summary string `yaml:"summary"`
+	} `yaml:"persona"`
+	Profile *struct {
+		Publish          bool     `yaml:"publish"`
+		Name             string   `yaml:"name"`
+		Description      string   `yaml:"description"`
+		Tags             []string `yaml:"tags"`
+		Rank             int      `yaml:"rank"`
+		Responsibilities []string `yaml:"responsibilities"`
+		InScope          []string `yaml:"inScope"`
+		OutOfScope       []string `yaml:"outOfScope"`
+	} `yaml:"profile"`
+	Prompt       *agentPromptRef `yaml:"prompt"`
+	SystemPrompt *agentPromptRef `yaml:"systemPrompt"`
+	Tool         struct {
+		Bundles []string `yaml:"bundles"`
+		Items   []struct {
+			Name string `yaml:"name"`
+		} `yaml:"items"`
+	} `yaml:"tool"`
+	Template struct {
+		Bundles []string `yaml:"bundles"`
+	} `yaml:"template"`
+}
+
+type agentPromptRef struct {
+	URI    string `yaml:"uri"`
+	Engine string `yaml:"engine"`
+	Text   string `yaml:"text"`
+}
+
+// agentRow is one `agent list` entry.
+type agentRow struct {
+	ID               string   `json:"id"`
+	Name             string   `json:"name"`
+	Source           string   `json:"source"`
+	Rank             int      `json:"rank"`
+	Published        bool     `json:"published"`
+	Model            string   `json:"model,omitempty"`
+	Description      string   `json:"description,omitempty"`
+	Tags             []string `json:"tags,omitempty"`
+	Responsibilities []string `json:"responsibilities,omitempty"`
+	InScope          []string `json:"inScope,omitempty"`
+	OutOfScope       []string `json:"outOfScope,omitempty"`
+	Endpoint         string   `json:"endpoint,omitempty"`
+	Error            string   `json:"error,omitempty"`
+}
+
+// agentDetail is the resolved view printed by `agent show`.
+type agentDetail struct {
+	*agentRow
+	Path            string               `json:"path"`
+	ModelSource     string               `json:"modelSource,omitempty"`
+	Prompts         []*agentPromptDetail `json:"prompts,omitempty"`
+	ToolBundles     []*agentBundleDetail `json:"toolBundles,omitempty"`
+	ToolItems       []string             `json:"toolItems,omitempty"`
+	TemplateBundles []*agentBundleDetail `json:"templateBundles,omitempty"`
+}
+
+type agentPromptDetail struct {
+	Kind    string `json:"kind"`
+	URI     string `json:"uri,omitempty"`
+	Engine  string `json:"engine,omitempty"`
+	Content string `json:"content,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type agentBundleDetail struct {
+	ID      string   `json:"id"`
+	Title   string   `json:"title,omitempty"`
+	Entries []string `json:"entries,omitempty"`
+	Defined bool     `json:"defined"`
+}
+
+func (c *AgentListCmd) Execute(_ []string) error {
+	root := c.root()
+	rows := listWorkspaceAgents(root)
+	if c.JSON {
+		if rows == nil {
+			rows = []*agentRow{}
+		}
+		return printJSON(rows)
+	}
+	if len(rows) == 0 {
+		fmt.Printf("no agents found in %s\n", root)
+		return nil
+	}
+	printAgentTable(os.Stdout, rows, c.Long)
+	return nil
+}
+
+func (c *AgentShowCmd) Execute(_ []string) error {
+	detail, err := showWorkspaceAgent(c.root(), strings.TrimSpace(c.AgentID), !c.NoPrompts)
+	if err != nil {
+		return err
+	}
+	if c.JSON {
+		return printJSON(detail)
+	}
+	printAgentDetail(os.Stdout, detail)
+	return nil
+}
+
+func (c *AgentScaffoldCmd) Execute(_ []string) error {
+	root := c.root()
+	path, err := scaffoldAgent(root, agentScaffold{
+		ID:          strings.TrimSpace(c.AgentID),
+		Name:        strings.TrimSpace(c.Name),
+		Description: strings.TrimSpace(c.Description),
+		Model:       strings.TrimSpace(c.Model),
+		Bundles:     splitPatterns(c.Bundles),
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("created agent %s in %s\n", strings.TrimSpace(c.AgentID), filepath.Dir(path))
+	return nil
+}
+
+func (c *AgentLintCmd) Execute(_ []string) error {
+	root := c.root()
+	issues, err := lintAgents(context.Background(), root, splitPatterns(c.AgentIDs))
+	if err != nil {
+		return err
+	}
+	if c.JSON {
+		if issues == nil {
+			issues = []*workspaceIssue{}
+		}
+		if err := printJSON(issues); err != nil {
+			return err
+		}
+	} else {
+		for _, issue := range issues {
+			fmt.Println(issue.String())
+		}
+	}
+	errorCount := countIssues(issues, issueError)
+	warningCount := countIssues(issues, issueWarning)
+	failing := errorCount
+	if c.Strict {
+		failing += warningCount
+	}
+	if failing > 0 {
+		return fmt.Errorf("agent lint: %d error(s), %d warning(s)", errorCount, warningCount)
+	}
+	if !c.JSON {
+		fmt.Printf("agents OK (%d warning(s))\n", warningCount)
+	}
+	return nil
+}
+
+// listWorkspaceAgents returns internal agents discovered the same way the
+// runtime does, followed by enabled external A2A agents, ordered by rank.
+func listWorkspaceAgents(root string) []*agentRow {
+	var rows []*agentRow
+	for _, id := range agentlyrt.DiscoverAgentIDs(root) {
+		spec, path, err := loadAgentSpec(root, id)
+		if err != nil {
+			row := &agentRow{ID: id, Name: id, Source: "internal", Error: err.Error()}
+			if path == "" {
+				row.Error = "no agent definition"
+			}
+			rows = append(rows, row)
+			continue
+		}
+		rows = append(rows, spec.row(id))
+	}
+	for _, spec := range agentlyrt.LoadExternalA2ASpecs(root) {
+		row := &agentRow{
+			ID:          strings.TrimSpace(spec.ID),
+			Name:        strings.TrimSpace(spec.Directory.Name),
+			Source:      "external",
+			Rank:        spec.Directory.Priority,
+			Published:   true,
+			Description: strings.TrimSpace(spec.Directory.Description),
+			Tags:        append([]string(nil), spec.Directory.Tags...),
+			Endpoint:    strings.TrimSpace(spec.JSONRPCURL),
+		}
+		if row.Name == "" {
+			row.Name = row.ID
+		}
+		rows = append(rows, row)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Rank != rows[j].Rank {
+			return rows[i].Rank > rows[j].Rank
+		}
+		return rows[i].ID < rows[j].ID
+	})
+	return rows
+}
+
+func (s *agentSpec) row(fallbackID string) *agentRow {
+	row := &agentRow{ID: strings.TrimSpace(s.ID), Name: strings.TrimSpace(s.Name), Source: "internal", Model: strings.TrimSpace(s.ModelRef), Description: strings.TrimSpace(s.Description)}
+	if row.ID == "" {
+		row.ID = fallbackID
+	}
+	if profile := s.Profile; profile != nil {
+		if name := strings.TrimSpace(profile.Name); name != "" {
+			row.Name = name
+		}
+		if description := strings.TrimSpace(profile.Description); description != "" {
+			row.Description = description
+		}
+		row.Rank = profile.Rank
+		row.Published = profile.Publish
+		row.Tags = profile.Tags
+		row.Responsibilities = profile.Responsibilities
+		row.InScope = profile.InScope
+		row.OutOfScope = profile.OutOfScope
+	}
+	if row.Description == "" && s.Persona != nil {
+		row.Description = strings.TrimSpace(s.Persona.Summary)
+	}
+	if row.Name == "" {
+		row.Name = row.ID
+	}
+	return row
+}
+
+// agentSpecPath returns the definition file for id: agents/<id>/<id>.yaml
+// or agents/<id>.yaml.
+func agentSpecPath(root, id string) string {
+	for _, candidate := range []string{
+		filepath.Join(root, "agents", id, id+".yaml"),
+		filepath.Join(root, "agents", id+".yaml"),
+	} {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+	return ""
+}
+
+func loadAgentSpec(root, id string) (*agentSpec, string, error) {
+	path := agentSpecPath(root, id)
+	if path == "" {
+		return nil, "", fmt.Errorf("agent %q not found in %s", id, filepath.Join(root, "agents"))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, path, err
+	}
+	spec := &agentSpec{}
+	if err := yaml.Unmarshal(data, spec); err != nil {
+		return nil, path, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return spec, path, nil
+}
+
+func showWorkspaceAgent(root, id string, withPrompts bool) (*agentDetail, error) {
+	if id == "" {
+		return nil, fmt.Errorf("agent id is required")
+	}
+	spec, path, err := loadAgentSpec(root, id)
+	if err != nil {
+		return nil, err
+	}
+	detail := &agentDetail{agentRow: spec.row(id), Path: path}
+	if detail.Model != "" {
+		detail.ModelSource = "agent"
+	} else if model := workspaceDefault(root, "model"); model != "" {
+		detail.Model = model
+		detail.ModelSource = "workspace default"
+	}
+	dir := filepath.Dir(path)
+	for _, prompt := range []struct {
+		kind string
+		ref  *agentPromptRef
+	}{{kind: "system", ref: spec.SystemPrompt}, {kind: "user", ref: spec.Prompt}} {
+		if prompt.ref == nil {
+			continue
+		}
+		item := &agentPromptDetail{Kind: prompt.kind, URI: strings.TrimSpace(prompt.ref.URI), Engine: strings.TrimSpace(prompt.ref.Engine)}
+		switch {
+		case item.URI == "":
+			item.Content = prompt.ref.Text
+		case strings.Contains(item.URI, "://"):
+		default:
+			data, err := os.ReadFile(resolveAgentURI(dir, item.URI))
+			if err != nil {
+				item.Error = err.Error()
+			} else {
+				item.Content = string(data)
+			}
+		}
+		if !withPrompts {
+			item.Content = ""
+		}
+		detail.Prompts = append(detail.Prompts, item)
+	}
+	toolBundles := loadBundleIndex(filepath.Join(root, "tools", "bundles"), "match")
+	for _, id := range spec.Tool.Bundles {
+		detail.ToolBundles = append(detail.ToolBundles, toolBundles.resolve(id))
+	}
+	for _, item := range spec.Tool.Items {
+		if name := strings.TrimSpace(item.Name); name != "" {
+			detail.ToolItems = append(detail.ToolItems, name)
+		}
+	}
+	templateBundles := loadBundleIndex(filepath.Join(root, "templates", "bundles"), "templates")
+	for _, id := range spec.Template.Bundles {
+		detail.TemplateBundles = append(detail.TemplateBundles, templateBundles.resolve(id))
+	}
+	return detail, nil
+}
+
+func resolveAgentURI(dir, uri string) string {
+	uri = strings.TrimPrefix(uri, "file://")
+	if filepath.IsAbs(uri) {
+		return uri
+	}
+	return filepath.Join(dir, filepath.FromSlash(uri))
+}
+
+// workspaceDefault returns default.<key> from the workspace config.yaml.
+func workspaceDefault(root, key string) string {
+	data, err := os.ReadFile(filepath.Join(root, "config.yaml"))
+	if err != nil {
+		return ""
+	}
+	var config struct {
+		Default map[string]interface{} `yaml:"default"`
+	}
+	if yaml.Unmarshal(data, &config) != nil {
+		return ""
+	}
+	value, _ := config.Default[key].(string)
+	return strings.TrimSpace(value)
+}
+
+type bundleIndex map[string]*agentBundleDetail
+
+// loadBundleIndex reads bundle files in dir keyed by id. entriesKey names
+// the list summarised as entries: tool bundles list `match` names, template
+// bundles list `templates`.
+func loadBundleIndex(dir, entriesKey string) bundleIndex {
+	index := bundleIndex{}
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	for _, match := range matches {
+		data, err := os.ReadFile(match)
+		if err != nil {
+			continue
+		}
+		var raw map[string]interface{}
+		if yaml.Unmarshal(data, &raw) != nil {
+			continue
+		}
+		id, _ := raw["id"].(string)
+		if id = strings.TrimSpace(id); id == "" {
+			id = strings.TrimSuffix(filepath.Base(match), ".yaml")
+		}
+		bundle := &agentBundleDetail{ID: id, Defined: true}
+		bundle.Title, _ = raw["title"].(string)
+		entries, _ := raw[entriesKey].([]interface{})
+		for _, entry := range entries {
+			switch actual := entry.(type) {
+			case string:
+				bundle.Entries = append(bundle.Entries, actual)
+			case map[string]interface{}:
+				if name, ok := actual["name"].(string); ok {
+					bundle.Entries = append(bundle.Entries, name)
+				}
+			}
+		}
+		index[id] = bundle
+	}
+	return index
+}
+
+func (b bundleIndex) resolve(id string) *agentBundleDetail {
+	id = strings.TrimSpace(id)
+	if bundle, ok := b[id]; ok {
+		return bundle
+	}
+	return &agentBundleDetail{ID: id}
+}
+
+func printAgentTable(out io.Writer, rows []*agentRow, long bool) {
+	w := tabwriter.NewWriter(out, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSOURCE\tRANK\tMODEL\tTAGS\tDESCRIPTION")
+	for _, row := range rows {
+		model := row.Model
+		if model == "" {
+			model = "-"
+		}
+		description := row.Description
+		if row.Error != "" {
+			description = "error: " + row.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n", row.ID, row.Source, row.Rank, model, strings.Join(row.Tags, ","), truncateCell(description, 60))
+	}
+	w.Flush()
+	if !long {
+		return
+	}
+	for _, row := range rows {
+		if len(row.Responsibilities)+len(row.InScope)+len(row.OutOfScope) == 0 {
+			continue
+		}
+		fmt.Fprintf(out, "\n%s\n", row.ID)
+		printAgentList(out, "responsibilities", row.Responsibilities)
+		printAgentList(out, "in scope", row.InScope)
+		printAgentList(out, "out of scope", row.OutOfScope)
+	}
+}
+
+func printAgentList(out io.Writer, label string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "  %s:\n", label)
+	for _, item := range items {
+		fmt.Fprintf(out, "    - %s\n", item)
+	}
+}
+
+func printAgentDetail(out io.Writer, detail *agentDetail) {
+	fmt.Fprintf(out, "ID          : %s\n", detail.ID)
+	fmt.Fprintf(out, "Name        : %s\n", detail.Name)
+	fmt.Fprintf(out, "Path        : %s\n", detail.Path)
+	model := detail.Model
+	if detail.ModelSource == "workspace default" {
+		model += " (workspace default)"
+	}
+	fmt.Fprintf(out, "Model       : %s\n", model)
+	fmt.Fprintf(out, "Rank        : %d\n", detail.Rank)
+	fmt.Fprintf(out, "Published   : %t\n", detail.Published)
+	if len(detail.Tags) > 0 {
+		fmt.Fprintf(out, "Tags        : %s\n", strings.Join(detail.Tags, ", "))
+	}
+	if detail.Description != "" {
+		fmt.Fprintf(out, "Description : %s\n", detail.Description)
+	}
+	printAgentList(out, "responsibilities", detail.Responsibilities)
+	printAgentList(out, "in scope", detail.InScope)
+	printAgentList(out, "out of scope", detail.OutOfScope)
+	printBundles(out, "tool bundles", detail.ToolBundles)
+	printAgentList(out, "tool items", detail.ToolItems)
+	printBundles(out, "template bundles", detail.TemplateBundles)
+	for _, prompt := range detail.Prompts {
+		source := prompt.URI
+		if source == "" {
+			source = "inline"
+		}
+		fmt.Fprintf(out, "\n--- %s prompt (%s)\n", prompt.Kind, source)
+		switch {
+		case prompt.Error != "":
+			fmt.Fprintf(out, "error: %s\n", prompt.Error)
+		case prompt.Content != "":
+			fmt.Fprintln(out, strings.TrimRight(prompt.Content, "\n"))
+		}
+	}
+}
+
+func printBundles(out io.Writer, label string, bundles []*agentBundleDetail) {
+	if len(bundles) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "  %s:\n", label)
+	for _, bundle := range bundles {
+		switch {
+		case !bundle.Defined:
+			fmt.Fprintf(out, "    - %s (not defined in workspace)\n", bundle.ID)
+		case len(bundle.Entries) > 0:
+			fmt.Fprintf(out, "    - %s: %s\n", bundle.ID, strings.Join(bundle.Entries, ", "))
+		default:
+			fmt.Fprintf(out, "    - %s\n", bundle.ID)
+		}
+	}
+}
+
+var agentIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+type agentScaffold struct {
+	ID          string
+	Name        string
+	Description string
+	Model       string
+	Bundles     []string
+}
+
+// scaffoldAgent writes agents/<id>/<id>.yaml with prompt/system.tmpl and
+// prompt/user.tmpl in the layout of the seeded coder agent. It never
+// overwrites an existing agent.
+func scaffoldAgent(root string, options agentScaffold) (string, error) {
+	if !agentIDPattern.MatchString(options.ID) {
+		return "", fmt.Errorf("invalid agent id %q: use letters, digits, '-' and '_'", options.ID)
+	}
+	if existing := agentSpecPath(root, options.ID); existing != "" {
+		return "", fmt.Errorf("agent %q already exists at %s", options.ID, existing)
+	}
+	dir := filepath.Join(root, "agents", options.ID)
+	if _, err := os.Stat(dir); err == nil {
+		return "", fmt.Errorf("agent directory %s already exists", dir)
+	}
+	if options.Name == "" {
+		options.Name = options.ID
+	}
+	if options.Description == "" {
+		options.Description = options.Name + " agent"
+	}
+	if options.Model == "" {
+		options.Model = workspaceDefault(root, "model")
+	}
+	spec := map[string]interface{}{
+		"id":          options.ID,
+		"name":        options.Name,
+		"description": options.Description,
+		"temperature": 0.2,
+		"persona": map[string]interface{}{
+			"role":    "assistant",
+			"actor":   options.Name,
+			"summary": options.Description,
+		},
+		"profile": map[string]interface{}{
+			"publish":          false,
+			"name":             options.Name,
+			"description":      options.Description,
+			"tags":             []string{},
+			"rank":             10,
+			"responsibilities": []string{},
+		},
+		"prompt":       map[string]interface{}{"uri": "prompt/user.tmpl"},
+		"systemPrompt": map[string]interface{}{"uri": "prompt/system.tmpl", "engine": "go"},
+	}
+	if options.Model != "" {
+		spec["modelRef"] = options.Model
+	}
+	if len(options.Bundles) > 0 {
+		spec["tool"] = map[string]interface{}{"bundles": options.Bundles}
+	}
+	data, err := yaml.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{name: options.ID + ".yaml", data: data},
+		{name: filepath.Join("prompt", "system.tmpl"), data: []byte(fmt.Sprintf(scaffoldSystemPrompt, options.Name))},
+		{name: filepath.Join("prompt", "user.tmpl"), data: []byte(scaffoldUserPrompt)},
+	}
+	for _, file := range files {
+		target := filepath.Join(dir, file.name)
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(target, file.data, 0o644); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(dir, options.ID+".yaml"), nil
+}
+
+const scaffoldSystemPrompt = `You are %s, an agent operating inside this workspace.
+
+- Keep going until the user's request is fully resolved.
+- Use the tools available to you to verify facts; do not guess or invent results.
+- Ask for missing critical input instead of assuming it.
+`
+
+const scaffoldUserPrompt = `{{- if .Context }}
+Context:
+{{ .ContextJSON }}
+{{- end }}
+
+Task:
+{{ .Task.Prompt }}
+`
+
+// lintAgents reports workspace validation issues that concern agent
+// definitions, optionally restricted to ids.
+func lintAgents(ctx context.Context, root string, ids []string) ([]*workspaceIssue, error) {
+	paths := map[string]bool{}
+	selected := ids
+	if len(selected) == 0 {
+		selected = agentlyrt.DiscoverAgentIDs(root)
+	}
+	for _, id := range selected {
+		path := agentSpecPath(root, id)
+		if path == "" {
+			if len(ids) > 0 {
+				return nil, fmt.Errorf("agent %q not found in %s", id, filepath.Join(root, "agents"))
+			}
+			continue
+		}
+		rel, _ := filepath.Rel(root, path)
+		paths[filepath.ToSlash(rel)] = true
+	}
+	issues, err := validateWorkspace(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	var result []*workspaceIssue
+	for _, issue := range issues {
+		if paths[issue.Path] {
+			result = append(result, issue)
+		}
+	}
+	return result, nil
+}
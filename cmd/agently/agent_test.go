@@ -0,0 +1,110 @@
+package agently
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func agentTestWorkspace(t *testing.T) string {
+	return writeAttachmentTree(t, map[string]string{
+		"config.yaml":                  "default:\n  model: base\n",
+		"models/base.yaml":             "id: base\noptions:\n  provider: openai\n  model: gpt\n",
+		"tools/bundles/system_os.yaml": "id: system/os\ntitle: OS\nmatch:\n  - name: \"system/os:*\"\n",
+		"templates/bundles/out.yaml":   "id: out\ntemplates:\n  - dashboard\n",
+		"agents/writer/writer.yaml": "id: writer\nname: Writer\n" +
+			"profile:\n  publish: true\n  rank: 50\n  tags: [docs]\n  responsibilities:\n    - write docs\n" +
+			"prompt:\n  uri: prompt/user.tmpl\n" +
+			"tool:\n  bundles:\n    - system/os\n    - custom\n" +
+			"template:\n  bundles:\n    - out\n",
+		"agents/writer/prompt/user.tmpl": "Task: {{ .Task.Prompt }}\n",
+		"agents/helper.yaml":             "id: helper\nmodelRef: base\nprofile:\n  rank: 80\n",
+	})
+}
+
+func TestListWorkspaceAgents(t *testing.T) {
+	rows := listWorkspaceAgents(agentTestWorkspace(t))
+	require.Len(t, rows, 2)
+	assert.Equal(t, "helper", rows[0].ID)
+	assert.Equal(t, 80, rows[0].Rank)
+	assert.Equal(t, "writer", rows[1].ID)
+	assert.Equal(t, []string{"docs"}, rows[1].Tags)
+	assert.Equal(t, []string{"write docs"}, rows[1].Responsibilities)
+	assert.True(t, rows[1].Published)
+
+	var buf bytes.Buffer
+	printAgentTable(&buf, rows, true)
+	assert.Contains(t, buf.String(), "helper")
+	assert.Contains(t, buf.String(), "    - write docs")
+}
+
+func TestShowWorkspaceAgent(t *testing.T) {
+	root := agentTestWorkspace(t)
+	detail, err := showWorkspaceAgent(root, "writer", true)
+	require.NoError(t, err)
+	assert.Equal(t, "base", detail.Model)
+	assert.Equal(t, "workspace default", detail.ModelSource)
+	require.Len(t, detail.Prompts, 1)
+	assert.Equal(t, "Task: {{ .Task.Prompt }}\n", detail.Prompts[0].Content)
+	require.Len(t, detail.ToolBundles, 2)
+	assert.Equal(t, []string{"system/os:*"}, detail.ToolBundles[0].Entries)
+	assert.False(t, detail.ToolBundles[1].Defined)
+	require.Len(t, detail.TemplateBundles, 1)
+	assert.Equal(t, []string{"dashboard"}, detail.TemplateBundles[0].Entries)
+
+	_, err = showWorkspaceAgent(root, "missing", true)
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestScaffoldAgent_LintsClean(t *testing.T) {
+	root := agentTestWorkspace(t)
+	path, err := scaffoldAgent(root, agentScaffold{ID: "reviewer", Bundles: []string{"system/os"}})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "agents", "reviewer", "reviewer.yaml"), path)
+	for _, name := range []string{"prompt/system.tmpl", "prompt/user.tmpl"} {
+		_, err := os.Stat(filepath.Join(root, "agents", "reviewer", filepath.FromSlash(name)))
+		assert.NoError(t, err, name)
+	}
+	detail, err := showWorkspaceAgent(root, "reviewer", false)
+	require.NoError(t, err)
+	assert.Equal(t, "base", detail.Model)
+	assert.Equal(t, "agent", detail.ModelSource)
+
+	issues, err := lintAgents(context.Background(), root, []string{"reviewer"})
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+
+	_, err = scaffoldAgent(root, agentScaffold{ID: "reviewer"})
+	assert.ErrorContains(t, err, "already exists")
+	_, err = scaffoldAgent(root, agentScaffold{ID: "../escape"})
+	assert.ErrorContains(t, err, "invalid agent id")
+}
+
+func TestLintAgents(t *testing.T) {
+	root := agentTestWorkspace(t)
+	require.NoError(t, os.WriteFile(filepath.Join(root, "agents", "broken.yaml"), []byte("id: broken\nmodelRef: gone\nsystemPrompt:\n  uri: prompt/missing.tmpl\nknowledge:\n  - url: docs/\n"), 0o644))
+
+	issues, err := lintAgents(context.Background(), root, nil)
+	require.NoError(t, err)
+	var lines []string
+	for _, issue := range issues {
+		lines = append(lines, issue.String())
+	}
+	assert.Equal(t, []string{
+		`agents/broken.yaml:2: error: unknown model "gone"`,
+		`agents/broken.yaml:4: error: systemPrompt.uri "prompt/missing.tmpl" not found`,
+		`agents/broken.yaml:6: error: knowledge url "docs/" not found`,
+		`agents/writer/writer.yaml:14: warning: tool bundle "custom" is not defined in tools/bundles`,
+	}, lines)
+
+	issues, err = lintAgents(context.Background(), root, []string{"helper"})
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+	_, err = lintAgents(context.Background(), root, []string{"nobody"})
+	assert.ErrorContains(t, err, "not found")
+}
@@ -0,0 +1,470 @@
+package agently
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/viant/agently-core/sdk"
+)
+
+// ApprovalsCmd groups the queued tool approval subcommands. Bundle rules with
+// `approval.mode: queue` park tool calls here until someone decides them.
+type ApprovalsCmd struct {
+	List    *ApprovalsListCmd    `command:"list" description:"List queued tool approvals"`
+	Show    *ApprovalsShowCmd    `command:"show" description:"Show a queued approval with its tool input and editable fields"`
+	Approve *ApprovalsApproveCmd `command:"approve" description:"Approve a queued tool call, optionally editing fields"`
+	Deny    *ApprovalsDenyCmd    `command:"deny" description:"Deny a queued tool call"`
+}
+
+type approvalIDArg struct {
+	ID string `positional-arg-name:"id" description:"approval ID"`
+}
+
+// ApprovalsListCmd lists queued approvals.
+type ApprovalsListCmd struct {
+	apiClientOptions
+	ConvID      string `short:"c" long:"conv" description:"only include approvals of this conversation"`
+	Tool        string `short:"t" long:"tool" description:"only include approvals of this tool; accepts glob patterns such as system/exec*"`
+	RequestedBy string `long:"requested-by" description:"only include approvals requested by this user id"`
+	Status      string `long:"status" description:"approval status to list" default:"pending"`
+	Limit       int    `long:"limit" description:"maximum number of approvals to list" default:"50"`
+	JSON        bool   `long:"json" description:"Print result as JSON instead of a table"`
+}
+
+// ApprovalsShowCmd prints one queued approval.
+type ApprovalsShowCmd struct {
+	apiClientOptions
+	Args approvalIDArg `positional-args:"yes" required:"yes"`
+	JSON bool          `long:"json" description:"Print result as JSON"`
+}
+
+// ApprovalsApproveCmd approves a queued approval.
+type ApprovalsApproveCmd struct {
+	apiClientOptions
+	Args        approvalIDArg `positional-args:"yes" required:"yes"`
+	Set         []string      `long:"set" description:"edit a field before approving: name=id for radio lists, name=id1,id2 for checkbox lists (repeatable)"`
+	Interactive bool          `short:"i" long:"interactive" description:"edit fields with the terminal checkbox and radio editors"`
+	JSON        bool          `long:"json" description:"Print result as JSON"`
+}
+
+// ApprovalsDenyCmd rejects a queued approval.
+type ApprovalsDenyCmd struct {
+	apiClientOptions
+	Args   approvalIDArg `positional-args:"yes" required:"yes"`
+	Reason string        `long:"reason" description:"reason recorded with the decision"`
+	JSON   bool          `long:"json" description:"Print result as JSON"`
+}
+
+// approvalRow is the CLI view of a queued approval. SDK values are decoded
+// through JSON; arguments and metadata may arrive as JSON strings.
+type approvalRow struct {
+	ID             string      `json:"id"`
+	UserID         string      `json:"userId,omitempty"`
+	ConversationID string      `json:"conversationId,omitempty"`
+	TurnID         string      `json:"turnId,omitempty"`
+	ToolName       string      `json:"toolName,omitempty"`
+	Title          string      `json:"title,omitempty"`
+	Status         string      `json:"status,omitempty"`
+	Arguments      interface{} `json:"arguments,omitempty"`
+	Metadata       interface{} `json:"metadata,omitempty"`
+	CreatedAt      *time.Time  `json:"createdAt,omitempty"`
+}
+
+// approvalDetail is the parsed form printed by `approvals show`.
+type approvalDetail struct {
+	*approvalRow
+	Approval *cliApprovalMeta `json:"approval,omitempty"`
+}
+
+func (c *ApprovalsListCmd) Execute(_ []string) error {
+	ctx := context.Background()
+	client, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+	limit := c.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	rows, err := listApprovals(ctx, client, strings.TrimSpace(c.ConvID), strings.TrimSpace(c.Status))
+	if err != nil {
+		return err
+	}
+	rows = filterApprovalRows(rows, strings.TrimSpace(c.Tool), strings.TrimSpace(c.RequestedBy))
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+	if c.JSON {
+		if rows == nil {
+			rows = []*approvalRow{}
+		}
+		return printJSON(rows)
+	}
+	if len(rows) == 0 {
+		fmt.Println("no approvals found")
+		return nil
+	}
+	printApprovalTable(os.Stdout, rows)
+	return nil
+}
+
+func (c *ApprovalsShowCmd) Execute(_ []string) error {
+	ctx := context.Background()
+	client, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+	row, err := findApproval(ctx, client, c.Args.ID)
+	if err != nil {
+		return err
+	}
+	detail := newApprovalDetail(row)
+	if c.JSON {
+		return printJSON(detail)
+	}
+	printApprovalDetail(os.Stdout, detail)
+	return nil
+}
+
+func (c *ApprovalsApproveCmd) Execute(_ []string) error {
+	ctx := context.Background()
+	client, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+	row, err := findApproval(ctx, client, c.Args.ID)
+	if err != nil {
+		return err
+	}
+	detail := newApprovalDetail(row)
+	editedFields, err := parseApprovalEdits(detail.Approval, c.Set)
+	if err != nil {
+		return err
+	}
+	if c.Interactive {
+		if !stdinIsTTY() {
+			return fmt.Errorf("--interactive needs a terminal; use --set instead")
+		}
+		printApprovalDetail(os.Stdout, detail)
+		edited, cancelled, err := promptApprovalEdits(ctx, os.Stdout, bufio.NewReader(os.Stdin), detail.Approval, editedFields)
+		if err != nil {
+			return err
+		}
+		if cancelled {
+			fmt.Println("aborted")
+			return nil
+		}
+		editedFields = edited
+	}
+	if err := decideApproval(ctx, client, row.ID, "approve", "", editedFields); err != nil {
+		return err
+	}
+	if c.JSON {
+		return printJSON(map[string]interface{}{"id": row.ID, "action": "approve", "editedFields": editedFields})
+	}
+	fmt.Printf("approved %s (%s)\n", row.ID, row.ToolName)
+	return nil
+}
+
+func (c *ApprovalsDenyCmd) Execute(_ []string) error {
+	ctx := context.Background()
+	client, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+	row, err := findApproval(ctx, client, c.Args.ID)
+	if err != nil {
+		return err
+	}
+	reason := strings.TrimSpace(c.Reason)
+	if err := decideApproval(ctx, client, row.ID, "reject", reason, nil); err != nil {
+		return err
+	}
+	if c.JSON {
+		return printJSON(map[string]interface{}{"id": row.ID, "action": "reject", "reason": reason})
+	}
+	fmt.Printf("denied %s (%s)\n", row.ID, row.ToolName)
+	return nil
+}
+
+func listApprovals(ctx context.Context, client *sdk.HTTPClient, conversationID, status string) ([]*approvalRow, error) {
+	result, err := client.ListPendingToolApprovals(ctx, &sdk.ListPendingToolApprovalsInput{
+		ConversationID: conversationID,
+		Status:         status,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list approvals: %w", err)
+	}
+	return decodeApprovalRows(result)
+}
+
+func findApproval(ctx context.Context, client *sdk.HTTPClient, id string) (*approvalRow, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, fmt.Errorf("approval id is required")
+	}
+	rows, err := listApprovals(ctx, client, "", "pending")
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if row.ID == id {
+			return row, nil
+		}
+	}
+	return nil, fmt.Errorf("approval %q not found or already decided", id)
+}
+
+func decideApproval(ctx context.Context, client *sdk.HTTPClient, id, action, reason string, editedFields map[string]interface{}) error {
+	input := &sdk.DecideToolApprovalInput{ID: id, Action: action, Reason: reason}
+	if len(editedFields) > 0 {
+		input.EditedFields = editedFields
+	}
+	if _, err := client.DecideToolApproval(ctx, input); err != nil {
+		return fmt.Errorf("%s approval %q: %w", action, id, err)
+	}
+	return nil
+}
+
+func decodeApprovalRows(value interface{}) ([]*approvalRow, error) {
+	var rows []*approvalRow
+	if err := reencodeJSON(value, &rows); err != nil {
+		return nil, fmt.Errorf("decode approvals: %w", err)
+	}
+	out := rows[:0]
+	for _, row := range rows {
+		if row == nil || strings.TrimSpace(row.ID) == "" {
+			continue
+		}
+		row.Arguments = decodeJSONString(row.Arguments)
+		row.Metadata = decodeJSONString(row.Metadata)
+		out = append(out, row)
+	}
+	return out, nil
+}
+
+// decodeJSONString parses values the server stores as JSON text.
+func decodeJSONString(value interface{}) interface{} {
+	text, ok := value.(string)
+	if !ok || strings.TrimSpace(text) == "" {
+		return value
+	}
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(text), &decoded); err != nil {
+		return value
+	}
+	return decoded
+}
+
+func filterApprovalRows(rows []*approvalRow, tool, requestedBy string) []*approvalRow {
+	if tool == "" && requestedBy == "" {
+		return rows
+	}
+	out := make([]*approvalRow, 0, len(rows))
+	for _, row := range rows {
+		if tool != "" && !matchToolName(tool, row.ToolName) {
+			continue
+		}
+		if requestedBy != "" && !strings.EqualFold(strings.TrimSpace(row.UserID), requestedBy) {
+			continue
+		}
+		out = append(out, row)
+	}
+	return out
+}
+
+// matchToolName compares tool names ignoring the ':' and '/' separator
+// variants ("system/exec:execute" and "system/exec/execute"). A '*' in the
+// pattern matches any run of characters, as in bundle match rules.
+func matchToolName(pattern, name string) bool {
+	pattern = strings.ReplaceAll(strings.TrimSpace(pattern), ":", "/")
+	name = strings.ReplaceAll(strings.TrimSpace(name), ":", "/")
+	if !strings.Contains(pattern, "*") {
+		return strings.EqualFold(pattern, name)
+	}
+	expr := strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+	matched, err := regexp.MatchString("(?i)^"+expr+"$", name)
+	return err == nil && matched
+}
+
+func newApprovalDetail(row *approvalRow) *approvalDetail {
+	detail := &approvalDetail{approvalRow: row}
+	metadata, _ := row.Metadata.(map[string]interface{})
+	if raw, ok := metadata["approval"]; ok {
+		meta := &cliApprovalMeta{}
+		if err := reencodeJSON(raw, meta); err == nil {
+			detail.Approval = meta
+		}
+	}
+	return detail
+}
+
+// parseApprovalEdits turns --set name=value flags into editedFields,
+// checking names and option ids against the approval editors.
+func parseApprovalEdits(meta *cliApprovalMeta, sets []string) (map[string]interface{}, error) {
+	if len(sets) == 0 {
+		return nil, nil
+	}
+	editors := map[string]*cliApprovalEditor{}
+	var names []string
+	if meta != nil {
+		for _, editor := range meta.Editors {
+			if editor != nil && strings.TrimSpace(editor.Name) != "" {
+				editors[editor.Name] = editor
+				names = append(names, editor.Name)
+			}
+		}
+	}
+	edited := map[string]interface{}{}
+	for _, set := range sets {
+		name, value, ok := strings.Cut(set, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --set %q (expected name=value)", set)
+		}
+		editor := editors[name]
+		if editor == nil {
+			if len(names) == 0 {
+				return nil, fmt.Errorf("approval has no editable fields")
+			}
+			return nil, fmt.Errorf("unknown editable field %q (available: %s)", name, strings.Join(names, ", "))
+		}
+		var ids []string
+		for _, id := range strings.Split(value, ",") {
+			if id = strings.TrimSpace(id); id == "" {
+				continue
+			}
+			if !editorHasOption(editor, id) {
+				return nil, fmt.Errorf("field %q has no option %q", name, id)
+			}
+			ids = append(ids, id)
+		}
+		switch strings.ToLower(strings.TrimSpace(editor.Kind)) {
+		case "radio_list":
+			if len(ids) != 1 {
+				return nil, fmt.Errorf("field %q takes exactly one option", name)
+			}
+			edited[name] = ids[0]
+		default:
+			if ids == nil {
+				ids = []string{}
+			}
+			edited[name] = ids
+		}
+	}
+	return edited, nil
+}
+
+func editorHasOption(editor *cliApprovalEditor, id string) bool {
+	for _, option := range editor.Options {
+		if option != nil && option.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// promptApprovalEdits runs the terminal editors used for inline approvals.
+// Fields already set with --set are skipped.
+func promptApprovalEdits(ctx context.Context, w io.Writer, reader *bufio.Reader, meta *cliApprovalMeta, edited map[string]interface{}) (map[string]interface{}, bool, error) {
+	if edited == nil {
+		edited = map[string]interface{}{}
+	}
+	if meta == nil {
+		return edited, false, nil
+	}
+	for _, editor := range meta.Editors {
+		if editor == nil || strings.TrimSpace(editor.Name) == "" {
+			continue
+		}
+		if _, ok := edited[editor.Name]; ok {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(editor.Kind)) {
+		case "checkbox_list":
+			selected, cancel, err := awaitCheckboxEditor(ctx, w, reader, editor)
+			if err != nil || cancel {
+				return nil, true, err
+			}
+			edited[editor.Name] = selected
+		case "radio_list":
+			selected, cancel, err := awaitRadioEditor(ctx, w, reader, editor)
+			if err != nil || cancel {
+				return nil, true, err
+			}
+			edited[editor.Name] = selected
+		}
+	}
+	return edited, false, nil
+}
+
+func printApprovalTable(out io.Writer, rows []*approvalRow) {
+	w := tabwriter.NewWriter(out, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTOOL\tSTATUS\tUSER\tCONVERSATION\tCREATED\tTITLE")
+	for _, row := range rows {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", row.ID, row.ToolName, row.Status, row.UserID, row.ConversationID,
+			formatConversationTime(row.CreatedAt), truncateCell(row.Title, 50))
+	}
+	_ = w.Flush()
+}
+
+func printApprovalDetail(out io.Writer, detail *approvalDetail) {
+	fmt.Fprintf(out, "Approval:     %s\n", detail.ID)
+	if title := firstNonEmpty(detail.Title, approvalMetaTitle(detail.Approval)); title != "" {
+		fmt.Fprintf(out, "Title:        %s\n", title)
+	}
+	fmt.Fprintf(out, "Tool:         %s\n", detail.ToolName)
+	fmt.Fprintf(out, "Status:       %s\n", detail.Status)
+	fmt.Fprintf(out, "Requested by: %s\n", firstNonEmpty(detail.UserID, "-"))
+	fmt.Fprintf(out, "Conversation: %s\n", firstNonEmpty(detail.ConversationID, "-"))
+	fmt.Fprintf(out, "Created:      %s\n", formatConversationTime(detail.CreatedAt))
+	if detail.Approval != nil && strings.TrimSpace(detail.Approval.Message) != "" {
+		fmt.Fprintf(out, "\n%s\n", strings.TrimSpace(detail.Approval.Message))
+	}
+	fmt.Fprintln(out, "\nInput:")
+	data, err := json.MarshalIndent(detail.Arguments, "  ", "  ")
+	if err != nil || detail.Arguments == nil {
+		data = []byte("{}")
+	}
+	fmt.Fprintf(out, "  %s\n", data)
+	if detail.Approval == nil || len(detail.Approval.Editors) == 0 {
+		return
+	}
+	fmt.Fprintln(out, "\nEditable fields:")
+	for _, editor := range detail.Approval.Editors {
+		if editor == nil {
+			continue
+		}
+		fmt.Fprintf(out, "  %s (%s): %s\n", editor.Name, editor.Kind, firstNonEmpty(editor.Label, editor.Name))
+		for _, option := range editor.Options {
+			if option == nil {
+				continue
+			}
+			mark := " "
+			if option.Selected {
+				mark = "x"
+			}
+			fmt.Fprintf(out, "    [%s] %s", mark, option.ID)
+			if option.Label != "" && option.Label != option.ID {
+				fmt.Fprintf(out, "  %s", option.Label)
+			}
+			fmt.Fprintln(out)
+		}
+	}
+	fmt.Fprintf(out, "\nApprove with edits: agently approvals approve %s --set <field>=<id>[,<id>...]\n", detail.ID)
+}
+
+func approvalMetaTitle(meta *cliApprovalMeta) string {
+	if meta == nil {
+		return ""
+	}
+	return meta.Title
+}
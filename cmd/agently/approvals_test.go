@@ -0,0 +1,113 @@
+package agently
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const queuedEnvApprovalMetadata = `{"approval":{"type":"tool_approval","title":"OS Env Access","toolName":"system/os/getEnv",` +
+	`"message":"The agent wants access to environment variables.","editors":[` +
+	`{"name":"names","kind":"checkbox_list","label":"Environment variables","options":[` +
+	`{"id":"HOME","label":"HOME","selected":true},{"id":"SHELL","label":"SHELL","selected":true},{"id":"PATH","label":"PATH","selected":true}]},` +
+	`{"name":"target","kind":"radio_list","options":[{"id":"prod","label":"Production"},{"id":"dev","label":"Development","selected":true}]}]}}`
+
+func queuedApprovalRows(t *testing.T) []*approvalRow {
+	rows, err := decodeApprovalRows([]map[string]interface{}{
+		{"id": "ap-1", "userId": "alice", "conversationId": "conv-1", "toolName": "system/os:getEnv", "status": "pending",
+			"arguments": `{"names":["HOME","SHELL","PATH"]}`, "metadata": queuedEnvApprovalMetadata},
+		{"id": "ap-2", "userId": "bob", "conversationId": "conv-2", "toolName": "system/exec:execute", "status": "pending",
+			"arguments": map[string]interface{}{"commands": []string{"rm -rf /tmp/cache"}}},
+		{"id": "", "toolName": "ignored"},
+	})
+	require.NoError(t, err)
+	return rows
+}
+
+func TestDecodeApprovalRows(t *testing.T) {
+	rows := queuedApprovalRows(t)
+	require.Len(t, rows, 2)
+	assert.Equal(t, map[string]interface{}{"names": []interface{}{"HOME", "SHELL", "PATH"}}, rows[0].Arguments)
+
+	detail := newApprovalDetail(rows[0])
+	require.NotNil(t, detail.Approval)
+	assert.Equal(t, "OS Env Access", detail.Approval.Title)
+	require.Len(t, detail.Approval.Editors, 2)
+
+	var buf bytes.Buffer
+	printApprovalDetail(&buf, detail)
+	out := buf.String()
+	assert.Contains(t, out, "Tool:         system/os:getEnv")
+	assert.Contains(t, out, `"SHELL"`)
+	assert.Contains(t, out, "names (checkbox_list): Environment variables")
+	assert.Contains(t, out, "[x] HOME")
+	assert.Contains(t, out, "[ ] prod  Production")
+}
+
+func TestFilterApprovalRows(t *testing.T) {
+	rows := queuedApprovalRows(t)
+	testCases := []struct {
+		name        string
+		tool        string
+		requestedBy string
+		expect      []string
+	}{
+		{name: "no filters", expect: []string{"ap-1", "ap-2"}},
+		{name: "exact tool with slash separator", tool: "system/exec/execute", expect: []string{"ap-2"}},
+		{name: "tool glob", tool: "system/os*", expect: []string{"ap-1"}},
+		{name: "user", requestedBy: "BOB", expect: []string{"ap-2"}},
+		{name: "no match", tool: "system/exec*", requestedBy: "alice", expect: []string{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ids := []string{}
+			for _, row := range filterApprovalRows(rows, tc.tool, tc.requestedBy) {
+				ids = append(ids, row.ID)
+			}
+			assert.Equal(t, tc.expect, ids)
+		})
+	}
+}
+
+func TestParseApprovalEdits(t *testing.T) {
+	meta := newApprovalDetail(queuedApprovalRows(t)[0]).Approval
+
+	edited, err := parseApprovalEdits(meta, []string{"names=HOME,PATH", "target=prod"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"names": []string{"HOME", "PATH"}, "target": "prod"}, edited)
+
+	edited, err = parseApprovalEdits(meta, []string{"names="})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"names": []string{}}, edited)
+
+	_, err = parseApprovalEdits(meta, []string{"names=HOME,USER"})
+	assert.EqualError(t, err, `field "names" has no option "USER"`)
+	_, err = parseApprovalEdits(meta, []string{"target=prod,dev"})
+	assert.EqualError(t, err, `field "target" takes exactly one option`)
+	_, err = parseApprovalEdits(meta, []string{"command=ls"})
+	assert.EqualError(t, err, `unknown editable field "command" (available: names, target)`)
+	_, err = parseApprovalEdits(nil, []string{"names=HOME"})
+	assert.EqualError(t, err, "approval has no editable fields")
+	_, err = parseApprovalEdits(meta, []string{"names"})
+	assert.ErrorContains(t, err, "expected name=value")
+}
+
+func TestPromptApprovalEdits(t *testing.T) {
+	meta := newApprovalDetail(queuedApprovalRows(t)[0]).Approval
+	var out bytes.Buffer
+
+	edited, cancelled, err := promptApprovalEdits(context.Background(), &out, bufio.NewReader(strings.NewReader("1,3\n")), meta, map[string]interface{}{"target": "prod"})
+	require.NoError(t, err)
+	assert.False(t, cancelled)
+	assert.Equal(t, map[string]interface{}{"names": []string{"HOME", "PATH"}, "target": "prod"}, edited)
+	assert.NotContains(t, out.String(), "Production", "fields set with --set are not prompted")
+
+	_, cancelled, err = promptApprovalEdits(context.Background(), &out, bufio.NewReader(strings.NewReader("cancel\n")), meta, nil)
+	require.NoError(t, err)
+	assert.True(t, cancelled)
+}
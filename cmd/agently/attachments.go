@@ -0,0 +1,431 @@
+package agently
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/viant/agently-core/protocol/binding"
+)
+
+const (
+	defaultAttachMaxFileBytes  = 10 << 20
+	defaultAttachMaxTotalBytes = 25 << 20
+	stdinAttachmentName        = "stdin"
+)
+
+// attachmentOptions controls how --attach values are expanded and bounded.
+type attachmentOptions struct {
+	// Include and Exclude are glob patterns matched against the base name
+	// and the slash-separated relative path of files found by directory or
+	// glob expansion. Explicitly named files are never filtered.
+	Include       []string
+	Exclude       []string
+	MaxFileBytes  int64
+	MaxTotalBytes int64
+	Stdin         io.Reader
+}
+
+func defaultAttachmentOptions() *attachmentOptions {
+	return &attachmentOptions{
+		MaxFileBytes:  defaultAttachMaxFileBytes,
+		MaxTotalBytes: defaultAttachMaxTotalBytes,
+		Stdin:         os.Stdin,
+	}
+}
+
+// attachmentOptions builds attachment options from the query flags.
+func (c *ChatCmd) attachmentOptions() (*attachmentOptions, error) {
+	options := defaultAttachmentOptions()
+	options.Include = splitPatterns(c.AttachInclude)
+	options.Exclude = splitPatterns(c.AttachExclude)
+	if value := strings.TrimSpace(c.AttachMaxFile); value != "" {
+		size, err := parseByteSize(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --attach-max-file: %w", err)
+		}
+		options.MaxFileBytes = size
+	}
+	if value := strings.TrimSpace(c.AttachMaxTotal); value != "" {
+		size, err := parseByteSize(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --attach-max-total: %w", err)
+		}
+		options.MaxTotalBytes = size
+	}
+	return options, nil
+}
+
+// loadAttachments expands every value into files and reads them. A value may
+// be a file, a directory (walked recursively, hidden directories skipped), a
+// glob (`**` matches any number of directories) or `-` for stdin.
+func loadAttachments(values []string, options *attachmentOptions) ([]*binding.Attachment, error) {
+	if options == nil {
+		options = defaultAttachmentOptions()
+	}
+	var out []*binding.Attachment
+	var total int64
+	seen := map[string]bool{}
+	usedStdin := false
+	add := func(attachment *binding.Attachment) error {
+		total += int64(len(attachment.Data))
+		if options.MaxTotalBytes > 0 && total > options.MaxTotalBytes {
+			return fmt.Errorf("attachments exceed the total size limit of %s at %q (raise --attach-max-total)", formatByteSize(options.MaxTotalBytes), attachment.Name)
+		}
+		out = append(out, attachment)
+		return nil
+	}
+	for _, item := range values {
+		value := strings.TrimSpace(item)
+		if value == "" {
+			return nil, fmt.Errorf("attachment path is required")
+		}
+		if value == "-" {
+			if usedStdin {
+				return nil, fmt.Errorf("stdin can only be attached once")
+			}
+			usedStdin = true
+			attachment, err := readStdinAttachment(options)
+			if err != nil {
+				return nil, err
+			}
+			if err := add(attachment); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		files, err := expandAttachmentPath(value, options)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if seen[file.path] {
+				continue
+			}
+			seen[file.path] = true
+			attachment, err := readFileAttachment(file, options)
+			if err != nil {
+				return nil, err
+			}
+			if err := add(attachment); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return out, nil
+}
+
+type attachmentFile struct {
+	path string
+	name string
+}
+
+func expandAttachmentPath(value string, options *attachmentOptions) ([]attachmentFile, error) {
+	if hasGlobMeta(value) {
+		files, err := expandAttachmentGlob(value, options)
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no files match attachment pattern %q", value)
+		}
+		return files, nil
+	}
+	info, err := os.Stat(value)
+	if err != nil {
+		return nil, fmt.Errorf("read attachment %q: %w", value, err)
+	}
+	if !info.IsDir() {
+		return []attachmentFile{{path: value, name: filepath.Base(value)}}, nil
+	}
+	files, err := walkAttachmentDir(value, "", options)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("attachment directory %q has no matching files", value)
+	}
+	return files, nil
+}
+
+// walkAttachmentDir collects regular files under root whose path relative to
+// root matches pattern (any file when empty) and the include/exclude filters.
+// Attachment names keep the directory structure, prefixed with root's name.
+func walkAttachmentDir(root, pattern string, options *attachmentOptions) ([]attachmentFile, error) {
+	var files []attachmentFile
+	prefix := filepath.Base(filepath.Clean(root))
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != root && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if pattern != "" && !matchAttachmentPattern(pattern, rel) {
+			return nil
+		}
+		if !options.accepts(rel) {
+			return nil
+		}
+		name := rel
+		if prefix != "." {
+			name = prefix + "/" + rel
+		}
+		files = append(files, attachmentFile{path: path, name: name})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk attachment directory %q: %w", root, err)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+	return files, nil
+}
+
+func expandAttachmentGlob(pattern string, options *attachmentOptions) ([]attachmentFile, error) {
+	if strings.Contains(pattern, "**") {
+		// Walk from the deepest directory without glob characters and match
+		// the remaining segments, where every `**` spans any depth.
+		segments := strings.Split(filepath.ToSlash(pattern), "/")
+		index := 0
+		for index < len(segments) && !hasGlobMeta(segments[index]) {
+			index++
+		}
+		root := strings.Join(segments[:index], "/")
+		switch {
+		case root == "" && index > 0:
+			root = "/"
+		case root == "":
+			root = "."
+		}
+		return walkAttachmentDir(filepath.FromSlash(root), strings.Join(segments[index:], "/"), options)
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attachment pattern %q: %w", pattern, err)
+	}
+	sort.Strings(matches)
+	var files []attachmentFile
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if !options.accepts(filepath.ToSlash(match)) {
+			continue
+		}
+		files = append(files, attachmentFile{path: match, name: filepath.ToSlash(filepath.Clean(match))})
+	}
+	return files, nil
+}
+
+// matchAttachmentPattern matches a glob remainder against a relative path.
+// Patterns without a separator match the base name at any depth; otherwise
+// each segment must match, with `**` matching zero or more directories.
+func matchAttachmentPattern(pattern, rel string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := filepath.Match(pattern, pathBase(rel))
+		return ok
+	}
+	return matchAttachmentSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+func matchAttachmentSegments(pattern, rel []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(rel); i++ {
+				if matchAttachmentSegments(pattern[1:], rel[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(rel) == 0 {
+			return false
+		}
+		if ok, _ := filepath.Match(pattern[0], rel[0]); !ok {
+			return false
+		}
+		pattern, rel = pattern[1:], rel[1:]
+	}
+	return len(rel) == 0
+}
+
+func (o *attachmentOptions) accepts(rel string) bool {
+	matches := func(patterns []string) bool {
+		for _, pattern := range patterns {
+			if ok, _ := filepath.Match(pattern, pathBase(rel)); ok {
+				return true
+			}
+			if ok, _ := filepath.Match(pattern, rel); ok {
+				return true
+			}
+		}
+		return false
+	}
+	if len(o.Include) > 0 && !matches(o.Include) {
+		return false
+	}
+	return !matches(o.Exclude)
+}
+
+func readFileAttachment(file attachmentFile, options *attachmentOptions) (*binding.Attachment, error) {
+	info, err := os.Stat(file.path)
+	if err != nil {
+		return nil, fmt.Errorf("read attachment %q: %w", file.path, err)
+	}
+	if options.MaxFileBytes > 0 && info.Size() > options.MaxFileBytes {
+		return nil, fmt.Errorf("attachment %q is %s, above the per-file limit of %s (raise --attach-max-file)", file.path, formatByteSize(info.Size()), formatByteSize(options.MaxFileBytes))
+	}
+	data, err := os.ReadFile(file.path)
+	if err != nil {
+		return nil, fmt.Errorf("read attachment %q: %w", file.path, err)
+	}
+	return &binding.Attachment{
+		Name: file.name,
+		Mime: detectAttachmentMime(file.path, data),
+		Data: data,
+	}, nil
+}
+
+func readStdinAttachment(options *attachmentOptions) (*binding.Attachment, error) {
+	if options.Stdin == nil {
+		return nil, fmt.Errorf("stdin is not available for attachments")
+	}
+	reader := options.Stdin
+	if options.MaxFileBytes > 0 {
+		reader = io.LimitReader(reader, options.MaxFileBytes+1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("read attachment from stdin: %w", err)
+	}
+	if options.MaxFileBytes > 0 && int64(len(data)) > options.MaxFileBytes {
+		return nil, fmt.Errorf("attachment from stdin is above the per-file limit of %s (raise --attach-max-file)", formatByteSize(options.MaxFileBytes))
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("attachment from stdin is empty")
+	}
+	return &binding.Attachment{
+		Name: stdinAttachmentName,
+		Mime: detectAttachmentMime("", data),
+		Data: data,
+	}, nil
+}
+
+// detectAttachmentMime prefers the extension's registered type and falls back
+// to content sniffing, so extensionless files such as Makefile still attach.
+func detectAttachmentMime(path string, data []byte) string {
+	if ext := filepath.Ext(path); ext != "" {
+		if mimeType := mime.TypeByExtension(ext); strings.TrimSpace(mimeType) != "" {
+			return mimeType
+		}
+	}
+	return http.DetectContentType(data)
+}
+
+func (o *attachmentOptions) maxTotal() int64 {
+	if o == nil {
+		return defaultAttachMaxTotalBytes
+	}
+	return o.MaxTotalBytes
+}
+
+func attachmentBytes(attachments []*binding.Attachment) int64 {
+	var total int64
+	for _, item := range attachments {
+		if item != nil {
+			total += int64(len(item.Data))
+		}
+	}
+	return total
+}
+
+func containsStdinAttachment(values []string) bool {
+	for _, value := range values {
+		if strings.TrimSpace(value) == "-" {
+			return true
+		}
+	}
+	return false
+}
+
+func hasGlobMeta(value string) bool {
+	return strings.ContainsAny(value, "*?[")
+}
+
+func pathBase(rel string) string {
+	if index := strings.LastIndex(rel, "/"); index != -1 {
+		return rel[index+1:]
+	}
+	return rel
+}
+
+func splitPatterns(values []string) []string {
+	var out []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				out = append(out, item)
+			}
+		}
+	}
+	return out
+}
+
+// parseByteSize accepts a plain byte count or a number with a B, KB/KiB,
+// MB/MiB or GB/GiB suffix. Both decimal and binary suffixes are treated as
+// powers of 1024.
+func parseByteSize(raw string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(raw))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{
+		{"KIB", 1 << 10}, {"MIB", 1 << 20}, {"GIB", 1 << 30},
+		{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30},
+		{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30},
+		{"B", 1},
+	} {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.size
+			break
+		}
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("%q is not a size (e.g. 512KB, 10MB)", raw)
+	}
+	return int64(number * float64(multiplier)), nil
+}
+
+func formatByteSize(size int64) string {
+	switch {
+	case size >= 1<<30:
+		return strconv.FormatFloat(float64(size)/(1<<30), 'f', 1, 64) + "GiB"
+	case size >= 1<<20:
+		return strconv.FormatFloat(float64(size)/(1<<20), 'f', 1, 64) + "MiB"
+	case size >= 1<<10:
+		return strconv.FormatFloat(float64(size)/(1<<10), 'f', 1, 64) + "KiB"
+	}
+	return strconv.FormatInt(size, 10) + "B"
+}
@@ -0,0 +1,165 @@
+package agently
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeAttachmentTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return root
+}
+
+func attachmentNames(t *testing.T, values []string, options *attachmentOptions) []string {
+	t.Helper()
+	attachments, err := loadAttachments(values, options)
+	require.NoError(t, err)
+	var names []string
+	for _, item := range attachments {
+		names = append(names, item.Name)
+	}
+	return names
+}
+
+func TestLoadAttachments_Directory(t *testing.T) {
+	root := writeAttachmentTree(t, map[string]string{
+		"src/main.go":        "package main",
+		"src/main_test.go":   "package main",
+		"src/pkg/util.go":    "package pkg",
+		"src/.git/config":    "[core]",
+		"src/README.md":      "# readme",
+		"src/pkg/Makefile":   "build:\n\tgo build ./...\n",
+		"other/ignored.go":   "package other",
+		"src/pkg/data.proto": "syntax = \"proto3\";",
+	})
+	dir := filepath.Join(root, "src")
+
+	names := attachmentNames(t, []string{dir}, defaultAttachmentOptions())
+	assert.Equal(t, []string{"src/README.md", "src/main.go", "src/main_test.go", "src/pkg/Makefile", "src/pkg/data.proto", "src/pkg/util.go"}, names)
+
+	options := defaultAttachmentOptions()
+	options.Include = splitPatterns([]string{"*.go,Makefile"})
+	options.Exclude = []string{"*_test.go"}
+	names = attachmentNames(t, []string{dir}, options)
+	assert.Equal(t, []string{"src/main.go", "src/pkg/Makefile", "src/pkg/util.go"}, names)
+}
+
+func TestLoadAttachments_Globs(t *testing.T) {
+	root := writeAttachmentTree(t, map[string]string{
+		"deploy/app.yaml":         "a: 1",
+		"deploy/prod/db.yaml":     "b: 2",
+		"deploy/prod/notes.txt":   "n",
+		"deploy/.hidden/sec.yaml": "s: 3",
+	})
+	names := attachmentNames(t, []string{filepath.Join(root, "deploy", "**", "*.yaml")}, defaultAttachmentOptions())
+	assert.Equal(t, []string{"deploy/app.yaml", "deploy/prod/db.yaml"}, names)
+
+	attachments, err := loadAttachments([]string{filepath.Join(root, "deploy", "*.yaml")}, defaultAttachmentOptions())
+	require.NoError(t, err)
+	require.Len(t, attachments, 1)
+	assert.True(t, strings.HasSuffix(attachments[0].Name, "deploy/app.yaml"))
+
+	// a file named explicitly and again through a glob is attached once
+	explicit := filepath.Join(root, "deploy", "app.yaml")
+	attachments, err = loadAttachments([]string{explicit, filepath.Join(root, "deploy", "*.yaml")}, defaultAttachmentOptions())
+	require.NoError(t, err)
+	assert.Len(t, attachments, 1)
+
+	_, err = loadAttachments([]string{filepath.Join(root, "deploy", "*.json")}, defaultAttachmentOptions())
+	assert.ErrorContains(t, err, "no files match")
+
+	nested := writeAttachmentTree(t, map[string]string{
+		"svc/api/config/app.yaml":         "a: 1",
+		"svc/api/internal/config/db.yaml": "b: 2",
+		"svc/web/config/ui.yaml":          "c: 3",
+		"svc/web/assets/logo.yaml":        "d: 4",
+	})
+	names = attachmentNames(t, []string{filepath.Join(nested, "svc", "**", "config", "**", "*.yaml")}, defaultAttachmentOptions())
+	assert.Equal(t, []string{"svc/api/config/app.yaml", "svc/api/internal/config/db.yaml", "svc/web/config/ui.yaml"}, names)
+
+	names = attachmentNames(t, []string{filepath.Join(nested, "svc", "*", "**", "ui.yaml")}, defaultAttachmentOptions())
+	assert.Equal(t, []string{"svc/web/config/ui.yaml"}, names)
+}
+
+func TestLoadAttachments_Stdin(t *testing.T) {
+	options := defaultAttachmentOptions()
+	options.Stdin = strings.NewReader("diff --git a/x b/x\n")
+	attachments, err := loadAttachments([]string{"-"}, options)
+	require.NoError(t, err)
+	require.Len(t, attachments, 1)
+	assert.Equal(t, stdinAttachmentName, attachments[0].Name)
+	assert.Equal(t, "text/plain; charset=utf-8", attachments[0].Mime)
+	assert.Equal(t, "diff --git a/x b/x\n", string(attachments[0].Data))
+
+	options.Stdin = strings.NewReader("x")
+	_, err = loadAttachments([]string{"-", "-"}, options)
+	assert.ErrorContains(t, err, "only be attached once")
+}
+
+func TestLoadAttachments_Limits(t *testing.T) {
+	root := writeAttachmentTree(t, map[string]string{
+		"a.txt": strings.Repeat("a", 600),
+		"b.txt": strings.Repeat("b", 600),
+	})
+
+	options := defaultAttachmentOptions()
+	options.MaxFileBytes = 512
+	_, err := loadAttachments([]string{filepath.Join(root, "a.txt")}, options)
+	assert.ErrorContains(t, err, "above the per-file limit of 512B")
+
+	options = defaultAttachmentOptions()
+	options.MaxTotalBytes = 1000
+	_, err = loadAttachments([]string{root}, options)
+	assert.ErrorContains(t, err, "total size limit")
+
+	options.Stdin = strings.NewReader(strings.Repeat("x", 2000))
+	options.MaxFileBytes = 1024
+	_, err = loadAttachments([]string{"-"}, options)
+	assert.ErrorContains(t, err, "per-file limit of 1.0KiB")
+}
+
+func TestDetectAttachmentMime(t *testing.T) {
+	assert.Equal(t, "text/plain; charset=utf-8", detectAttachmentMime("Makefile", []byte("build:\n\tgo build\n")))
+	assert.Equal(t, "image/png", detectAttachmentMime("logo", []byte("\x89PNG\r\n\x1a\n0000")))
+	assert.Equal(t, "application/json", detectAttachmentMime("data.json", []byte("{}")))
+}
+
+func TestParseByteSize(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected int64
+	}{
+		{input: "512", expected: 512},
+		{input: "512B", expected: 512},
+		{input: "4kb", expected: 4 << 10},
+		{input: "10MB", expected: 10 << 20},
+		{input: "1.5MiB", expected: 3 << 19},
+		{input: "2G", expected: 2 << 30},
+	}
+	for _, testCase := range testCases {
+		actual, err := parseByteSize(testCase.input)
+		require.NoError(t, err, testCase.input)
+		assert.Equal(t, testCase.expected, actual, testCase.input)
+	}
+	_, err := parseByteSize("lots")
+	assert.Error(t, err)
+
+	options, err := (&ChatCmd{AttachMaxFile: "1MB", AttachInclude: []string{"*.go, *.md"}}).attachmentOptions()
+	require.NoError(t, err)
+	assert.EqualValues(t, 1<<20, options.MaxFileBytes)
+	assert.EqualValues(t, defaultAttachMaxTotalBytes, options.MaxTotalBytes)
+	assert.Equal(t, []string{"*.go", "*.md"}, options.Include)
+	_, err = (&ChatCmd{AttachMaxTotal: "big"}).attachmentOptions()
+	assert.ErrorContains(t, err, "--attach-max-total")
+}
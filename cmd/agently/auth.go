@@ -0,0 +1,232 @@
+package agently
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/viant/agently-core/sdk"
+)
+
+// AuthCmd groups the credential cache subcommands. Client commands cache the
+// session and bearer token of every server they authenticate against, so
+// repeated invocations skip the login flow until the credentials expire.
+type AuthCmd struct {
+	Login  *AuthLoginCmd  `command:"login" description:"Log in to a server and cache the session"`
+	Logout *AuthLogoutCmd `command:"logout" description:"Forget cached credentials for a server"`
+	Status *AuthStatusCmd `command:"status" description:"List cached credentials"`
+	Token  *AuthTokenCmd  `command:"token" description:"Print the cached bearer token or session for a server"`
+}
+
+// AuthLoginCmd runs the login flow and caches the result, replacing any
+// cached credentials of the server.
+type AuthLoginCmd struct {
+	apiClientOptions
+}
+
+// AuthLogoutCmd drops cached credentials.
+type AuthLogoutCmd struct {
+	apiClientOptions
+	All bool `long:"all" description:"forget the credentials of every server"`
+}
+
+// AuthStatusCmd lists the cache.
+type AuthStatusCmd struct {
+	JSON bool `long:"json" description:"Print result as JSON instead of a table"`
+}
+
+// AuthTokenCmd prints a cached credential for scripts.
+type AuthTokenCmd struct {
+	apiClientOptions
+	Session bool `long:"session" description:"print the session cookie value instead of the bearer token"`
+}
+
+func (c *AuthLoginCmd) Execute(_ []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	chat := c.asChat()
+	baseURL, providers, _, _, _, _, err := chat.resolveBaseURL(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := forgetServerCredential(baseURL); err != nil {
+		return fmt.Errorf("reset cached credentials: %w", err)
+	}
+	httpClient := &http.Client{Jar: cliCookieJar(baseURL)}
+	opts := []sdk.HTTPOption{sdk.WithHTTPClient(httpClient)}
+	token := resolvedToken(chat.Token)
+	if token != "" {
+		opts = append(opts, sdk.WithAuthToken(token))
+	}
+	client, err := sdk.NewHTTP(baseURL, opts...)
+	if err != nil {
+		return err
+	}
+	if err := chat.ensureAuth(ctx, client, providers); err != nil {
+		return fmt.Errorf("login to %s: %w", baseURL, err)
+	}
+	if token != "" {
+		if err := storeCachedToken(baseURL, token); err != nil {
+			return fmt.Errorf("cache token: %w", err)
+		}
+	}
+	path, _ := credentialCachePath()
+	fmt.Printf("Logged in to %s (credentials cached in %s)\n", baseURL, path)
+	return nil
+}
+
+func (c *AuthLogoutCmd) Execute(_ []string) error {
+	if c.All {
+		count := 0
+		err := updateCredentialCache(func(cache *credentialCache) bool {
+			count = len(cache.Servers)
+			cache.Servers = map[string]*serverCredential{}
+			return count > 0
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Forgot cached credentials of %d server(s)\n", count)
+		return nil
+	}
+	baseURL, err := c.cachedServerURL(context.Background())
+	if err != nil {
+		return err
+	}
+	removed, err := forgetServerCredential(baseURL)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		fmt.Printf("No cached credentials for %s\n", baseURL)
+		return nil
+	}
+	fmt.Printf("Logged out of %s\n", baseURL)
+	return nil
+}
+
+func (c *AuthStatusCmd) Execute(_ []string) error {
+	path, err := credentialCachePath()
+	if err != nil {
+		return err
+	}
+	cache, err := loadCredentialCache(path)
+	if err != nil {
+		return err
+	}
+	views := credentialViews(cache, time.Now())
+	if c.JSON {
+		return printJSON(views)
+	}
+	if len(views) == 0 {
+		fmt.Printf("No cached credentials in %s\n", path)
+		return nil
+	}
+	printCredentialTable(os.Stdout, views)
+	return nil
+}
+
+func (c *AuthTokenCmd) Execute(_ []string) error {
+	baseURL, err := c.cachedServerURL(context.Background())
+	if err != nil {
+		return err
+	}
+	entry := cachedServerCredential(baseURL)
+	if c.Session {
+		if entry != nil {
+			for _, cookie := range entry.Cookies {
+				if cookie.Name == defaultSessionCookieName {
+					fmt.Println(cookie.Value)
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("no cached session for %s; run: agently auth login", baseURL)
+	}
+	if entry == nil || entry.Token == "" {
+		return fmt.Errorf("no cached bearer token for %s; run agently auth login --token, or use --session for the session cookie", baseURL)
+	}
+	fmt.Println(entry.Token)
+	return nil
+}
+
+// cachedServerURL resolves the server a cache lookup applies to. --api is
+// used as given so cached credentials of unreachable servers stay
+// addressable.
+func (o *apiClientOptions) cachedServerURL(ctx context.Context) (string, error) {
+	chat := o.asChat()
+	if err := chat.applyCLIContext(); err != nil {
+		return "", err
+	}
+	if api := strings.TrimSpace(chat.API); api != "" {
+		return api, nil
+	}
+	baseURL, _, _, _, _, _, err := chat.resolveBaseURL(ctx)
+	return baseURL, err
+}
+
+// credentialView is the redacted status of one cache entry.
+type credentialView struct {
+	Server       string     `json:"server"`
+	Session      bool       `json:"session"`
+	SessionUntil *time.Time `json:"sessionExpiry,omitempty"`
+	Token        bool       `json:"token"`
+	TokenUntil   *time.Time `json:"tokenExpiry,omitempty"`
+	TokenExpired bool       `json:"tokenExpired,omitempty"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+}
+
+func credentialViews(cache *credentialCache, now time.Time) []credentialView {
+	views := make([]credentialView, 0, len(cache.Servers))
+	for _, key := range cache.keys() {
+		entry := cache.Servers[key]
+		view := credentialView{Server: key, UpdatedAt: entry.UpdatedAt}
+		for _, cookie := range entry.Cookies {
+			if cookie.Name != defaultSessionCookieName || (cookie.Expires != nil && !cookie.Expires.After(now)) {
+				continue
+			}
+			view.Session = true
+			view.SessionUntil = cookie.Expires
+		}
+		if entry.Token != "" {
+			view.Token = true
+			view.TokenUntil = entry.TokenExpiry
+			view.TokenExpired = entry.TokenExpiry != nil && !entry.TokenExpiry.After(now)
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+func printCredentialTable(out io.Writer, views []credentialView) {
+	w := tabwriter.NewWriter(out, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVER\tSESSION\tTOKEN\tUPDATED")
+	for _, view := range views {
+		session := "-"
+		if view.Session {
+			session = formatCredentialExpiry(view.SessionUntil, "active")
+		}
+		token := "-"
+		switch {
+		case view.TokenExpired:
+			token = "expired"
+		case view.Token:
+			token = formatCredentialExpiry(view.TokenUntil, "no expiry")
+		}
+		updated := view.UpdatedAt
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", view.Server, session, token, formatConversationTime(&updated))
+	}
+	_ = w.Flush()
+}
+
+func formatCredentialExpiry(expiry *time.Time, fallback string) string {
+	if expiry == nil {
+		return fallback
+	}
+	return "until " + formatConversationTime(expiry)
+}
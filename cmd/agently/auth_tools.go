@@ -13,10 +13,13 @@ import (
 
 const defaultSessionCookieName = "agently_session"
 
-func ensureToolAuth(ctx context.Context, client *sdk.HTTPClient, providers []authProviderInfo, rawToken, rawSession, rawOOB, rawOAuthCfg, rawOAuthScopes string) error {
+func ensureToolAuth(ctx context.Context, client *sdk.HTTPClient, providers []authProviderInfo, rawToken, rawSession, rawOOB, rawOAuthCfg, rawOAuthScopes, api, instance, contextName string) error {
 	if client == nil {
 		return fmt.Errorf("client is required")
 	}
+	if err := (cliConnection{API: &api, Instance: &instance, Token: &rawToken, OOB: &rawOOB, OAuthConfig: &rawOAuthCfg, OAuthScopes: &rawOAuthScopes}).apply(contextName); err != nil {
+		return err
+	}
 	if sessionID := strings.TrimSpace(rawSession); sessionID != "" {
 		if err := applySessionCookie(client, sessionID); err != nil {
 			return err
@@ -28,6 +31,9 @@ func ensureToolAuth(ctx context.Context, client *sdk.HTTPClient, providers []aut
 	if _, err := client.AuthMe(ctx); err == nil {
 		return nil
 	}
+	if resumeCachedSession(ctx, client) {
+		return nil
+	}
 
 	if secretRef := strings.TrimSpace(rawOOB); secretRef != "" {
 		return authenticateWithOOB(ctx, client, secretRef, strings.TrimSpace(rawOAuthCfg), parseScopes(rawOAuthScopes))
@@ -44,7 +50,7 @@ func ensureToolAuth(ctx context.Context, client *sdk.HTTPClient, providers []aut
 		return nil
 	}
 
-	chat := &ChatCmd{Token: strings.TrimSpace(rawToken)}
+	chat := &ChatCmd{API: strings.TrimSpace(api), Instance: strings.TrimSpace(instance), Token: strings.TrimSpace(rawToken), CLIContext: contextName}
 	return chat.ensureAuth(ctx, client, providers)
 }
 
@@ -82,11 +88,19 @@ func authenticateWithOOB(ctx context.Context, client *sdk.HTTPClient, secretRef,
 	if secretRef == "" {
 		return fmt.Errorf("--oob requires a secrets URL value")
 	}
-	return client.AuthLocalOOBSession(ctx, &sdk.LocalOOBSessionOptions{
-		ConfigURL:  strings.TrimSpace(configURL),
+	configURL = strings.TrimSpace(configURL)
+	if err := client.AuthLocalOOBSession(ctx, &sdk.LocalOOBSessionOptions{
+		ConfigURL:  configURL,
 		SecretsURL: secretRef,
 		Scopes:     scopes,
-	})
+	}); err != nil {
+		return err
+	}
+	// Remember the login so the cached session can be renewed without it.
+	if jar, ok := client.HTTPClient().Jar.(*cachingCookieJar); ok {
+		_ = storeCachedLogin(jar.baseURL.String(), &cachedLogin{OOB: secretRef, OAuthConfig: configURL, Scopes: scopes})
+	}
+	return nil
 }
 
 func applySessionCookie(client *sdk.HTTPClient, sessionID string) error {
@@ -0,0 +1,283 @@
+package agently
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	chatgptauth "github.com/viant/agently-core/service/auth/chatgpt"
+)
+
+// defaultDeviceCodeInterval is used when the issuer does not return a poll
+// interval.
+const defaultDeviceCodeInterval = 5 * time.Second
+
+// loginWithDeviceCode runs the OpenAI device authorization flow: the user
+// enters a code on the issuer's verification page from any browser while the
+// CLI polls for the resulting authorization code. The code is exchanged by
+// the manager, so tokens end up in TokensURL exactly as with the callback
+// flow.
+func (c *ChatGPTLoginCmd) loginWithDeviceCode(ctx context.Context, manager *chatgptauth.Manager) error {
+	issuer, clientID, err := c.authorizeClient(ctx, manager)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeoutFromSeconds(c.TimeoutSec))
+	defer cancel()
+
+	client := &deviceCodeClient{issuer: issuer, clientID: clientID, httpClient: &http.Client{Timeout: 30 * time.Second}}
+	authorization, err := client.requestUserCode(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("To authenticate, open %s on any device and enter the code:\n\n    %s\n\n", authorization.VerificationURL, authorization.UserCode)
+	fmt.Printf("Waiting for authorization (expires in %s) ...\n", timeoutFromSeconds(c.TimeoutSec))
+
+	grant, err := client.pollAuthorization(ctx, authorization)
+	if err != nil {
+		return err
+	}
+	_, err = manager.ExchangeAuthorizationCode(ctx, client.redirectURL(), grant.CodeVerifier, grant.AuthorizationCode)
+	return err
+}
+
+// loginWithPastedRedirect runs the browser flow without a callback server.
+// The browser's redirect to localhost fails on a remote machine, but the
+// address bar still holds the code, so the user pastes that URL back.
+func (c *ChatGPTLoginCmd) loginWithPastedRedirect(ctx context.Context, manager *chatgptauth.Manager) error {
+	codeVerifier, err := randomToken(32)
+	if err != nil {
+		return err
+	}
+	state, err := randomToken(32)
+	if err != nil {
+		return err
+	}
+	port, err := resolveCallbackPort(c.Port)
+	if err != nil {
+		return err
+	}
+	if port == 0 {
+		return fmt.Errorf("--paste needs a fixed callback port that the issuer accepts; --port auto is not supported")
+	}
+	redirectURL := fmt.Sprintf("http://localhost:%d/auth/callback", port)
+	authURL, err := manager.BuildAuthorizeURL(ctx, redirectURL, state, codeVerifier)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Open this URL in any browser to authenticate:\n%s\n\n", authURL)
+	fmt.Printf("After signing in the browser is sent to %s, which will not load on this machine.\n", redirectURL)
+	fmt.Printf("Copy the full URL from the address bar and paste it here: ")
+
+	ctx, cancel := context.WithTimeout(ctx, timeoutFromSeconds(c.TimeoutSec))
+	defer cancel()
+	line, cancelled, err := readPromptLine(ctx, bufio.NewReader(os.Stdin))
+	if err != nil {
+		return err
+	}
+	if cancelled && line == "" {
+		return fmt.Errorf("login canceled")
+	}
+	code, err := parsePastedCallback(line, state)
+	if err != nil {
+		return err
+	}
+	_, err = manager.ExchangeAuthorizationCode(ctx, redirectURL, codeVerifier, code)
+	return err
+}
+
+// authorizeClient returns the issuer and OAuth client id the manager uses.
+// They are read back from an authorize URL so the device flow honours
+// --issuer and the client config the same way as the browser flow.
+func (c *ChatGPTLoginCmd) authorizeClient(ctx context.Context, manager *chatgptauth.Manager) (string, string, error) {
+	probe, err := randomToken(16)
+	if err != nil {
+		return "", "", err
+	}
+	authURL, err := manager.BuildAuthorizeURL(ctx, "http://localhost:1455/auth/callback", probe, probe)
+	if err != nil {
+		return "", "", err
+	}
+	issuer, clientID, err := issuerFromAuthorizeURL(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	if v := strings.TrimRight(strings.TrimSpace(c.Issuer), "/"); v != "" {
+		issuer = v
+	}
+	return issuer, clientID, nil
+}
+
+func issuerFromAuthorizeURL(authURL string) (string, string, error) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid authorize url: %w", err)
+	}
+	clientID := u.Query().Get("client_id")
+	if clientID == "" {
+		return "", "", fmt.Errorf("authorize url %q has no client_id", authURL)
+	}
+	path := strings.TrimSuffix(strings.TrimRight(u.Path, "/"), "/oauth/authorize")
+	return u.Scheme + "://" + u.Host + path, clientID, nil
+}
+
+// parsePastedCallback extracts the authorization code from a pasted redirect
+// URL. A bare query string is accepted as well.
+func parsePastedCallback(raw, expectedState string) (string, error) {
+	raw = strings.Trim(strings.TrimSpace(raw), `"'`)
+	if raw == "" {
+		return "", fmt.Errorf("no callback URL was pasted")
+	}
+	query := raw
+	if idx := strings.Index(raw, "?"); idx >= 0 {
+		query = raw[idx+1:]
+	}
+	query, _, _ = strings.Cut(query, "#")
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return "", fmt.Errorf("invalid callback URL: %w", err)
+	}
+	if e := values.Get("error"); e != "" {
+		if desc := values.Get("error_description"); desc != "" {
+			return "", fmt.Errorf("authorization failed: %s: %s", e, desc)
+		}
+		return "", fmt.Errorf("authorization failed: %s", e)
+	}
+	if expectedState != "" && values.Get("state") != expectedState {
+		return "", fmt.Errorf("state mismatch: paste the URL from the login started by this command")
+	}
+	code := values.Get("code")
+	if code == "" {
+		return "", fmt.Errorf("missing authorization code in pasted URL")
+	}
+	return code, nil
+}
+
+// deviceCodeClient talks to the issuer's device authorization endpoints.
+type deviceCodeClient struct {
+	issuer     string
+	clientID   string
+	httpClient *http.Client
+}
+
+type deviceAuthorization struct {
+	DeviceAuthID    string
+	UserCode        string
+	VerificationURL string
+	Interval        time.Duration
+}
+
+// deviceGrant is returned once the user approved the code. The issuer runs
+// PKCE on the user's behalf and hands back the verifier for the exchange.
+type deviceGrant struct {
+	AuthorizationCode string `json:"authorization_code"`
+	CodeChallenge     string `json:"code_challenge"`
+	CodeVerifier      string `json:"code_verifier"`
+}
+
+func (d *deviceCodeClient) redirectURL() string {
+	return d.issuer + "/deviceauth/callback"
+}
+
+func (d *deviceCodeClient) requestUserCode(ctx context.Context) (*deviceAuthorization, error) {
+	var payload struct {
+		DeviceAuthID string          `json:"device_auth_id"`
+		UserCode     string          `json:"user_code"`
+		UserCodeAlt  string          `json:"usercode"`
+		Interval     json.RawMessage `json:"interval"`
+	}
+	status, err := d.post(ctx, "/api/accounts/deviceauth/usercode", map[string]string{"client_id": d.clientID}, &payload)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, fmt.Errorf("issuer %s does not support device code login; use --paste instead", d.issuer)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("device code request failed: status %d", status)
+	}
+	userCode := firstNonEmpty(payload.UserCode, payload.UserCodeAlt)
+	if payload.DeviceAuthID == "" || userCode == "" {
+		return nil, fmt.Errorf("device code response is missing device_auth_id or user_code")
+	}
+	return &deviceAuthorization{
+		DeviceAuthID:    payload.DeviceAuthID,
+		UserCode:        userCode,
+		VerificationURL: d.issuer + "/codex/device",
+		Interval:        parseDeviceInterval(payload.Interval),
+	}, nil
+}
+
+// pollAuthorization polls until the user approves the code or ctx expires.
+// The issuer answers 403 or 404 while the authorization is pending.
+func (d *deviceCodeClient) pollAuthorization(ctx context.Context, authorization *deviceAuthorization) (*deviceGrant, error) {
+	body := map[string]string{"device_auth_id": authorization.DeviceAuthID, "user_code": authorization.UserCode}
+	for {
+		grant := &deviceGrant{}
+		status, err := d.post(ctx, "/api/accounts/deviceauth/token", body, grant)
+		if err != nil {
+			return nil, err
+		}
+		switch status {
+		case http.StatusOK:
+			if grant.AuthorizationCode == "" || grant.CodeVerifier == "" {
+				return nil, fmt.Errorf("device token response is missing authorization_code or code_verifier")
+			}
+			return grant, nil
+		case http.StatusForbidden, http.StatusNotFound:
+		default:
+			return nil, fmt.Errorf("device authorization failed: status %d", status)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("device code was not approved in time: %w", ctx.Err())
+		case <-time.After(authorization.Interval):
+		}
+	}
+}
+
+// post sends a JSON request and decodes a 200 response into out.
+func (d *deviceCodeClient) post(ctx context.Context, path string, body interface{}, out interface{}) (int, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.issuer+path, bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp.StatusCode, fmt.Errorf("decode %s response: %w", path, err)
+	}
+	return resp.StatusCode, nil
+}
+
+// parseDeviceInterval accepts the interval as a number or a numeric string.
+func parseDeviceInterval(raw json.RawMessage) time.Duration {
+	value := strings.Trim(strings.TrimSpace(string(raw)), `"`)
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds <= 0 {
+		return defaultDeviceCodeInterval
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,118 @@
+package agently
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeDeviceIssuer approves the device code after pending polls.
+func newFakeDeviceIssuer(t *testing.T, pending int32) (*httptest.Server, *int32) {
+	t.Helper()
+	var polls int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/accounts/deviceauth/usercode", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if body["client_id"] != "app_test" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"device_auth_id":"dev-1","user_code":"ABCD-1234","interval":"0.01"}`))
+	})
+	mux.HandleFunc("/api/accounts/deviceauth/token", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "dev-1", body["device_auth_id"])
+		assert.Equal(t, "ABCD-1234", body["user_code"])
+		if atomic.AddInt32(&polls, 1) <= pending {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"authorization_code":"code-1","code_challenge":"ch","code_verifier":"ver-1"}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &polls
+}
+
+func TestDeviceCodeClient_Flow(t *testing.T) {
+	server, polls := newFakeDeviceIssuer(t, 2)
+	client := &deviceCodeClient{issuer: server.URL, clientID: "app_test", httpClient: server.Client()}
+
+	authorization, err := client.requestUserCode(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "ABCD-1234", authorization.UserCode)
+	assert.Equal(t, server.URL+"/codex/device", authorization.VerificationURL)
+	assert.Equal(t, 10*time.Millisecond, authorization.Interval)
+
+	grant, err := client.pollAuthorization(context.Background(), authorization)
+	require.NoError(t, err)
+	assert.Equal(t, &deviceGrant{AuthorizationCode: "code-1", CodeChallenge: "ch", CodeVerifier: "ver-1"}, grant)
+	assert.EqualValues(t, 3, atomic.LoadInt32(polls))
+	assert.Equal(t, server.URL+"/deviceauth/callback", client.redirectURL())
+}
+
+func TestDeviceCodeClient_Timeout(t *testing.T) {
+	server, _ := newFakeDeviceIssuer(t, 1<<30)
+	client := &deviceCodeClient{issuer: server.URL, clientID: "app_test", httpClient: server.Client()}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.pollAuthorization(ctx, &deviceAuthorization{DeviceAuthID: "dev-1", UserCode: "ABCD-1234", Interval: 10 * time.Millisecond})
+	assert.ErrorContains(t, err, "not approved in time")
+}
+
+func TestDeviceCodeClient_Unsupported(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	client := &deviceCodeClient{issuer: server.URL, clientID: "app_test", httpClient: server.Client()}
+	_, err := client.requestUserCode(context.Background())
+	assert.ErrorContains(t, err, "use --paste instead")
+}
+
+func TestIssuerFromAuthorizeURL(t *testing.T) {
+	issuer, clientID, err := issuerFromAuthorizeURL("https://auth.openai.com/oauth/authorize?client_id=app_x&response_type=code")
+	require.NoError(t, err)
+	assert.Equal(t, "https://auth.openai.com", issuer)
+	assert.Equal(t, "app_x", clientID)
+
+	issuer, _, err = issuerFromAuthorizeURL("http://127.0.0.1:9000/tenant/oauth/authorize?client_id=app_x")
+	require.NoError(t, err)
+	assert.Equal(t, "http://127.0.0.1:9000/tenant", issuer)
+
+	_, _, err = issuerFromAuthorizeURL("https://auth.openai.com/oauth/authorize")
+	assert.ErrorContains(t, err, "no client_id")
+}
+
+func TestParsePastedCallback(t *testing.T) {
+	cases := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr string
+	}{
+		{name: "full url", raw: "http://localhost:1455/auth/callback?code=c1&state=s1", want: "c1"},
+		{name: "quoted with fragment", raw: ` "http://localhost:1455/auth/callback?state=s1&code=c2#done" `, want: "c2"},
+		{name: "query only", raw: "code=c3&state=s1", want: "c3"},
+		{name: "state mismatch", raw: "http://localhost:1455/auth/callback?code=c1&state=other", wantErr: "state mismatch"},
+		{name: "missing code", raw: "http://localhost:1455/auth/callback?state=s1", wantErr: "missing authorization code"},
+		{name: "issuer error", raw: "http://localhost:1455/auth/callback?error=access_denied&error_description=denied+by+user&state=s1", wantErr: "access_denied: denied by user"},
+		{name: "empty", raw: "  ", wantErr: "no callback URL"},
+	}
+	for _, tc := range cases {
+		got, err := parsePastedCallback(tc.raw, "s1")
+		if tc.wantErr != "" {
+			assert.ErrorContains(t, err, tc.wantErr, tc.name)
+			continue
+		}
+		require.NoError(t, err, tc.name)
+		assert.Equal(t, tc.want, got, tc.name)
+	}
+}
@@ -30,16 +30,22 @@ type ChatGPTLoginCmd struct {
 	Originator         string `long:"originator" description:"originator query param used by OpenAI auth (default: codex_cli_rs)" default:"codex_cli_rs"`
 	Port               string `long:"port" description:"local callback server port; integer, 'auto' for an OS-picked free port, or empty to use AGENTLY_CHATGPT_CALLBACK_PORT / default 1455 (must match OAuth redirect allowlist for OpenAI)" default:""`
 
+	Device bool `long:"device" description:"headless login: print a verification URL and user code, then poll the issuer instead of waiting for a local callback"`
+	Paste  bool `long:"paste" description:"headless login: paste the redirected callback URL from the browser instead of running a local callback server"`
+
 	NoOpenBrowser bool `long:"no-open-browser" description:"do not open the authorization URL in the default browser"`
 	NoMintAPIKey  bool `long:"no-mint-api-key" description:"do not mint and cache an OpenAI API key after login"`
 	RequireMint   bool `long:"require-mint-api-key" description:"fail the command if API key minting fails"`
-	TimeoutSec    int  `long:"timeout" description:"callback wait timeout in seconds" default:"300"`
+	TimeoutSec    int  `long:"timeout" description:"callback, device code or paste wait timeout in seconds" default:"300"`
 }
 
 func (c *ChatGPTLoginCmd) Execute(_ []string) error {
 	if strings.TrimSpace(c.ClientURL) == "" {
 		return fmt.Errorf("--clientURL is required")
 	}
+	if c.Device && c.Paste {
+		return fmt.Errorf("--device and --paste are mutually exclusive")
+	}
 	if strings.TrimSpace(c.TokensURL) == "" {
 		derived, err := deriveTokensURLFromClientURL(c.ClientURL)
 		if err != nil {
@@ -49,6 +55,57 @@ func (c *ChatGPTLoginCmd) Execute(_ []string) error {
 		fmt.Printf("Using derived tokensURL: %s\n", c.TokensURL)
 	}
 
+	manager, err := chatgptauth.NewManager(
+		&chatgptauth.Options{
+			ClientURL:          c.ClientURL,
+			TokensURL:          c.TokensURL,
+			Issuer:             c.Issuer,
+			AllowedWorkspaceID: c.AllowedWorkspaceID,
+			Originator:         c.Originator,
+		},
+		chatgptauth.NewScyOAuthClientLoader(c.ClientURL),
+		chatgptauth.NewScyTokenStateStore(c.TokensURL),
+		&http.Client{Timeout: 60 * time.Second},
+	)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch {
+	case c.Device:
+		err = c.loginWithDeviceCode(ctx, manager)
+	case c.Paste:
+		err = c.loginWithPastedRedirect(ctx, manager)
+	default:
+		err = c.loginWithCallback(ctx, manager)
+	}
+	if err != nil {
+		return err
+	}
+
+	apiKeyMinted := false
+	if c.mintAPIKeyEnabled() {
+		if _, err := manager.APIKey(ctx); err != nil {
+			fmt.Printf("Login successful, but failed to mint OpenAI API key: %v\n", err)
+			fmt.Printf("If this persists, ensure your OpenAI Platform account is set up (organization/project), then re-run `agently chatgpt-login`.\n")
+			if c.RequireMint {
+				return err
+			}
+		} else {
+			apiKeyMinted = true
+		}
+	}
+
+	fmt.Printf("Login successful. Tokens persisted to %s\n", c.TokensURL)
+	if apiKeyMinted {
+		fmt.Printf("OpenAI API key minted and cached.\n")
+	}
+	return nil
+}
+
+// loginWithCallback runs the browser flow with a loopback callback server.
+func (c *ChatGPTLoginCmd) loginWithCallback(ctx context.Context, manager *chatgptauth.Manager) error {
 	codeVerifier, err := randomToken(32)
 	if err != nil {
 		return err
@@ -68,23 +125,6 @@ func (c *ChatGPTLoginCmd) Execute(_ []string) error {
 	}
 	defer endpoint.Close()
 
-	manager, err := chatgptauth.NewManager(
-		&chatgptauth.Options{
-			ClientURL:          c.ClientURL,
-			TokensURL:          c.TokensURL,
-			Issuer:             c.Issuer,
-			AllowedWorkspaceID: c.AllowedWorkspaceID,
-			Originator:         c.Originator,
-		},
-		chatgptauth.NewScyOAuthClientLoader(c.ClientURL),
-		chatgptauth.NewScyTokenStateStore(c.TokensURL),
-		&http.Client{Timeout: 60 * time.Second},
-	)
-	if err != nil {
-		return err
-	}
-
-	ctx := context.Background()
 	authURL, err := manager.BuildAuthorizeURL(ctx, endpoint.RedirectURL(), state, codeVerifier)
 	if err != nil {
 		return err
@@ -108,28 +148,7 @@ func (c *ChatGPTLoginCmd) Execute(_ []string) error {
 	}
 
 	_, err = manager.ExchangeAuthorizationCode(ctx, endpoint.RedirectURL(), codeVerifier, code)
-	if err != nil {
-		return err
-	}
-
-	apiKeyMinted := false
-	if c.mintAPIKeyEnabled() {
-		if _, err := manager.APIKey(ctx); err != nil {
-			fmt.Printf("Login successful, but failed to mint OpenAI API key: %v\n", err)
-			fmt.Printf("If this persists, ensure your OpenAI Platform account is set up (organization/project), then re-run `agently chatgpt-login`.\n")
-			if c.RequireMint {
-				return err
-			}
-		} else {
-			apiKeyMinted = true
-		}
-	}
-
-	fmt.Printf("Login successful. Tokens persisted to %s\n", c.TokensURL)
-	if apiKeyMinted {
-		fmt.Printf("OpenAI API key minted and cached.\n")
-	}
-	return nil
+	return err
 }
 
 func deriveTokensURLFromClientURL(clientURL string) (string, error) {
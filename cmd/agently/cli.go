@@ -16,6 +16,10 @@ func Run(args []string) {
 	if len(args) > 0 {
 		first = args[0]
 	}
+	if first == completeCommand {
+		printCompletions(os.Stdout, args[1:])
+		return
+	}
 	opts.Init(first)
 
 	// Handle version early to avoid command requirement error from parser
@@ -0,0 +1,425 @@
+package agently
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// cliContextEnv selects the CLI context when no --context flag is given.
+const cliContextEnv = "AGENTLY_CONTEXT"
+
+// ContextCmd groups the named CLI context subcommands. A context stores the
+// server selection and auth flags of one Agently environment in
+// ~/.agently/cli.yaml so client commands need not repeat them.
+type ContextCmd struct {
+	Add     *ContextAddCmd     `command:"add" description:"Add or update a named context"`
+	Use     *ContextUseCmd     `command:"use" description:"Make a context the default for client commands"`
+	List    *ContextListCmd    `command:"list" description:"List contexts"`
+	Current *ContextCurrentCmd `command:"current" description:"Print the active context name"`
+	Remove  *ContextRemoveCmd  `command:"remove" description:"Remove a context"`
+}
+
+type contextNameArg struct {
+	Name string `positional-arg-name:"name" description:"context name"`
+}
+
+// ContextAddCmd creates or updates a context.
+type ContextAddCmd struct {
+	Args     contextNameArg `positional-args:"yes" required:"yes"`
+	API      string         `long:"api" description:"Agently base URL"`
+	Instance string         `long:"instance" description:"registered local instance name, port or pid"`
+	Token    string         `long:"token" description:"Bearer token for API requests"`
+	OOB      string         `long:"oob" description:"scy OAuth2 out-of-band secrets URL"`
+	OAuthCfg string         `long:"oauth-config" description:"scy OAuth config URL override for OOB login"`
+	OAuthScp string         `long:"oauth-scopes" description:"comma-separated OAuth scopes for OOB login"`
+	Use      bool           `long:"use" description:"also make this the current context"`
+}
+
+// ContextUseCmd sets the current context.
+type ContextUseCmd struct {
+	Args contextNameArg `positional-args:"yes" required:"yes"`
+}
+
+// ContextListCmd lists contexts.
+type ContextListCmd struct {
+	JSON bool `long:"json" description:"Print result as JSON instead of a table"`
+}
+
+// ContextCurrentCmd prints the active context.
+type ContextCurrentCmd struct{}
+
+// ContextRemoveCmd deletes a context.
+type ContextRemoveCmd struct {
+	Args contextNameArg `positional-args:"yes" required:"yes"`
+}
+
+// cliContext is one named environment in cli.yaml.
+type cliContext struct {
+	Name        string `yaml:"name" json:"name"`
+	API         string `yaml:"api,omitempty" json:"api,omitempty"`
+	Instance    string `yaml:"instance,omitempty" json:"instance,omitempty"`
+	Token       string `yaml:"token,omitempty" json:"token,omitempty"`
+	OOB         string `yaml:"oob,omitempty" json:"oob,omitempty"`
+	OAuthConfig string `yaml:"oauthConfig,omitempty" json:"oauthConfig,omitempty"`
+	OAuthScopes string `yaml:"oauthScopes,omitempty" json:"oauthScopes,omitempty"`
+}
+
+type cliConfig struct {
+	CurrentContext string        `yaml:"currentContext,omitempty"`
+	Contexts       []*cliContext `yaml:"contexts,omitempty"`
+}
+
+// cliConfigPath returns the CLI config file, ~/.agently/cli.yaml unless
+// AGENTLY_CLI_CONFIG points elsewhere.
+func cliConfigPath() (string, error) {
+	if path := strings.TrimSpace(os.Getenv("AGENTLY_CLI_CONFIG")); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("locate CLI config: %w", err)
+	}
+	return filepath.Join(home, ".agently", "cli.yaml"), nil
+}
+
+// loadCLIConfig reads path; a missing file is an empty config.
+func loadCLIConfig(path string) (*cliConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &cliConfig{}, nil
+		}
+		return nil, err
+	}
+	cfg := &cliConfig{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// save writes the config owner-readable only since contexts may hold tokens.
+func (c *cliConfig) save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+func (c *cliConfig) lookup(name string) *cliContext {
+	for _, item := range c.Contexts {
+		if item.Name == name {
+			return item
+		}
+	}
+	return nil
+}
+
+func (c *cliConfig) names() []string {
+	names := make([]string, 0, len(c.Contexts))
+	for _, item := range c.Contexts {
+		names = append(names, item.Name)
+	}
+	return names
+}
+
+// activeCLIContext returns the context selected by name, AGENTLY_CONTEXT or
+// currentContext, in that order. It returns nil when none is selected; a
+// name that is not defined is an error.
+func activeCLIContext(name string) (*cliContext, error) {
+	name = strings.TrimSpace(name)
+	explicit := name != ""
+	if !explicit {
+		name = strings.TrimSpace(os.Getenv(cliContextEnv))
+		explicit = name != ""
+	}
+	path, err := cliConfigPath()
+	if err != nil {
+		if explicit {
+			return nil, err
+		}
+		return nil, nil
+	}
+	cfg, err := loadCLIConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = strings.TrimSpace(cfg.CurrentContext)
+	}
+	if name == "" {
+		return nil, nil
+	}
+	if found := cfg.lookup(name); found != nil {
+		return found, nil
+	}
+	if len(cfg.Contexts) == 0 {
+		return nil, fmt.Errorf("context %q not found: no contexts defined in %s", name, path)
+	}
+	return nil, fmt.Errorf("context %q not found in %s (available: %s)", name, path, strings.Join(cfg.names(), ", "))
+}
+
+// cliConnection points at the connection settings of a command. apply fills
+// the ones left empty on the command line from the active CLI context, so
+// explicit flags always win. Nil fields are settings the command lacks; a
+// credential-only connection still passes API and Instance so the explicit
+// server rule below holds.
+type cliConnection struct {
+	API         *string
+	Instance    *string
+	Token       *string
+	OOB         *string
+	OAuthConfig *string
+	OAuthScopes *string
+}
+
+func (c cliConnection) apply(name string) error {
+	active, err := activeCLIContext(name)
+	if err != nil || active == nil {
+		return err
+	}
+	// An explicit --api or --instance points at another server, so none of
+	// the context applies: its credentials belong to the context's server.
+	if !isBlank(c.API) || !isBlank(c.Instance) {
+		return nil
+	}
+	fillBlank(c.API, active.API)
+	fillBlank(c.Instance, active.Instance)
+	fillBlank(c.Token, active.Token)
+	fillBlank(c.OOB, active.OOB)
+	fillBlank(c.OAuthConfig, active.OAuthConfig)
+	fillBlank(c.OAuthScopes, active.OAuthScopes)
+	return nil
+}
+
+func isBlank(target *string) bool {
+	return target == nil || strings.TrimSpace(*target) == ""
+}
+
+func fillBlank(target *string, value string) {
+	if target != nil && strings.TrimSpace(*target) == "" {
+		*target = strings.TrimSpace(value)
+	}
+}
+
+// contextToken resolves the bearer token like resolvedToken, with the active
+// context's token taking precedence over AGENTLY_TOKEN unless api or instance
+// was given explicitly.
+func contextToken(flagValue, api, instance, contextName string) string {
+	token := flagValue
+	_ = cliConnection{API: &api, Instance: &instance, Token: &token}.apply(contextName)
+	return resolvedToken(token)
+}
+
+func (c *ContextAddCmd) Execute(_ []string) error {
+	name := strings.TrimSpace(c.Args.Name)
+	if name == "" {
+		return fmt.Errorf("context name is required")
+	}
+	if strings.TrimSpace(c.API) != "" && strings.TrimSpace(c.Instance) != "" {
+		return fmt.Errorf("--api and --instance are mutually exclusive")
+	}
+	path, err := cliConfigPath()
+	if err != nil {
+		return err
+	}
+	cfg, err := loadCLIConfig(path)
+	if err != nil {
+		return err
+	}
+	item := cfg.lookup(name)
+	verb := "Updated"
+	if item == nil {
+		item = &cliContext{Name: name}
+		cfg.Contexts = append(cfg.Contexts, item)
+		verb = "Added"
+	}
+	if api, instance := strings.TrimSpace(c.API), strings.TrimSpace(c.Instance); api != "" || instance != "" {
+		item.API, item.Instance = api, instance
+	}
+	setIfPresent(&item.Token, c.Token)
+	setIfPresent(&item.OOB, c.OOB)
+	setIfPresent(&item.OAuthConfig, c.OAuthCfg)
+	setIfPresent(&item.OAuthScopes, c.OAuthScp)
+	if c.Use || cfg.CurrentContext == "" {
+		cfg.CurrentContext = name
+	}
+	if err := cfg.save(path); err != nil {
+		return fmt.Errorf("save %s: %w", path, err)
+	}
+	fmt.Printf("%s context %q in %s\n", verb, name, path)
+	if cfg.CurrentContext == name {
+		fmt.Printf("Current context is %q\n", name)
+	}
+	return nil
+}
+
+func setIfPresent(target *string, value string) {
+	if value = strings.TrimSpace(value); value != "" {
+		*target = value
+	}
+}
+
+func (c *ContextUseCmd) Execute(_ []string) error {
+	name := strings.TrimSpace(c.Args.Name)
+	path, err := cliConfigPath()
+	if err != nil {
+		return err
+	}
+	cfg, err := loadCLIConfig(path)
+	if err != nil {
+		return err
+	}
+	if cfg.lookup(name) == nil {
+		return fmt.Errorf("context %q not found in %s", name, path)
+	}
+	cfg.CurrentContext = name
+	if err := cfg.save(path); err != nil {
+		return fmt.Errorf("save %s: %w", path, err)
+	}
+	fmt.Printf("Switched to context %q\n", name)
+	return nil
+}
+
+func (c *ContextListCmd) Execute(_ []string) error {
+	path, err := cliConfigPath()
+	if err != nil {
+		return err
+	}
+	cfg, err := loadCLIConfig(path)
+	if err != nil {
+		return err
+	}
+	current := currentContextName(cfg)
+	if c.JSON {
+		type contextView struct {
+			*cliContext
+			Current bool `json:"current"`
+		}
+		views := make([]contextView, 0, len(cfg.Contexts))
+		for _, item := range cfg.Contexts {
+			redacted := *item
+			redacted.Token = redactToken(item.Token)
+			views = append(views, contextView{cliContext: &redacted, Current: item.Name == current})
+		}
+		return printJSON(views)
+	}
+	if len(cfg.Contexts) == 0 {
+		fmt.Printf("No contexts defined in %s. Add one with: agently context add <name> --api <url>\n", path)
+		return nil
+	}
+	printContextTable(os.Stdout, cfg.Contexts, current)
+	return nil
+}
+
+// currentContextName is the context client commands use without --context.
+func currentContextName(cfg *cliConfig) string {
+	if name := strings.TrimSpace(os.Getenv(cliContextEnv)); name != "" {
+		return name
+	}
+	return strings.TrimSpace(cfg.CurrentContext)
+}
+
+func printContextTable(out io.Writer, contexts []*cliContext, current string) {
+	w := tabwriter.NewWriter(out, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(w, "CURRENT\tNAME\tSERVER\tAUTH")
+	for _, item := range contexts {
+		marker := ""
+		if item.Name == current {
+			marker = "*"
+		}
+		server := firstNonEmpty(item.API, instanceServerLabel(item.Instance), "auto-detect")
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", marker, item.Name, server, contextAuthLabel(item))
+	}
+	_ = w.Flush()
+}
+
+func instanceServerLabel(instance string) string {
+	if instance = strings.TrimSpace(instance); instance != "" {
+		return "instance " + instance
+	}
+	return ""
+}
+
+func contextAuthLabel(item *cliContext) string {
+	var parts []string
+	if item.Token != "" {
+		parts = append(parts, "token")
+	}
+	if item.OOB != "" {
+		parts = append(parts, "oob")
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, ",")
+}
+
+// redactToken keeps enough of a token to tell contexts apart.
+func redactToken(token string) string {
+	if token == "" {
+		return ""
+	}
+	if len(token) <= 8 {
+		return "****"
+	}
+	return token[:4] + "****"
+}
+
+func (c *ContextCurrentCmd) Execute(_ []string) error {
+	path, err := cliConfigPath()
+	if err != nil {
+		return err
+	}
+	cfg, err := loadCLIConfig(path)
+	if err != nil {
+		return err
+	}
+	name := currentContextName(cfg)
+	if name == "" {
+		return fmt.Errorf("no current context; set one with: agently context use <name>")
+	}
+	fmt.Println(name)
+	return nil
+}
+
+func (c *ContextRemoveCmd) Execute(_ []string) error {
+	name := strings.TrimSpace(c.Args.Name)
+	path, err := cliConfigPath()
+	if err != nil {
+		return err
+	}
+	cfg, err := loadCLIConfig(path)
+	if err != nil {
+		return err
+	}
+	kept := cfg.Contexts[:0]
+	for _, item := range cfg.Contexts {
+		if item.Name != name {
+			kept = append(kept, item)
+		}
+	}
+	if len(kept) == len(cfg.Contexts) {
+		return fmt.Errorf("context %q not found in %s", name, path)
+	}
+	cfg.Contexts = kept
+	if cfg.CurrentContext == name {
+		cfg.CurrentContext = ""
+	}
+	if err := cfg.save(path); err != nil {
+		return fmt.Errorf("save %s: %w", path, err)
+	}
+	fmt.Printf("Removed context %q\n", name)
+	return nil
+}
@@ -0,0 +1,185 @@
+package agently
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestCLIConfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "cli.yaml")
+	t.Setenv("AGENTLY_CLI_CONFIG", path)
+	t.Setenv(cliContextEnv, "")
+	cfg := &cliConfig{
+		CurrentContext: "dev",
+		Contexts: []*cliContext{
+			{Name: "dev", Instance: "agently-8080", Token: "dev-token"},
+			{Name: "prod", API: "https://agently.example.com", OOB: "scy://prod.enc", OAuthScopes: "openid,email"},
+		},
+	}
+	require.NoError(t, cfg.save(path))
+	return path
+}
+
+func TestActiveCLIContext(t *testing.T) {
+	writeTestCLIConfig(t)
+
+	active, err := activeCLIContext("")
+	require.NoError(t, err)
+	assert.Equal(t, "dev", active.Name)
+
+	t.Setenv(cliContextEnv, "prod")
+	active, err = activeCLIContext("")
+	require.NoError(t, err)
+	assert.Equal(t, "prod", active.Name)
+
+	active, err = activeCLIContext("dev")
+	require.NoError(t, err)
+	assert.Equal(t, "dev", active.Name)
+
+	_, err = activeCLIContext("staging")
+	assert.ErrorContains(t, err, `context "staging" not found`)
+	assert.ErrorContains(t, err, "(available: dev, prod)")
+}
+
+func TestActiveCLIContext_NoConfig(t *testing.T) {
+	t.Setenv("AGENTLY_CLI_CONFIG", filepath.Join(t.TempDir(), "missing.yaml"))
+	t.Setenv(cliContextEnv, "")
+	active, err := activeCLIContext("")
+	require.NoError(t, err)
+	assert.Nil(t, active)
+
+	_, err = activeCLIContext("prod")
+	assert.ErrorContains(t, err, "no contexts defined")
+}
+
+func TestCLIConnectionApply_FlagsWin(t *testing.T) {
+	writeTestCLIConfig(t)
+
+	api, instance, token, oob, scopes := "", "", "flag-token", "", ""
+	conn := cliConnection{API: &api, Instance: &instance, Token: &token, OOB: &oob, OAuthScopes: &scopes}
+	require.NoError(t, conn.apply("prod"))
+	assert.Equal(t, "https://agently.example.com", api)
+	assert.Equal(t, "", instance)
+	assert.Equal(t, "flag-token", token)
+	assert.Equal(t, "scy://prod.enc", oob)
+	assert.Equal(t, "openid,email", scopes)
+
+}
+
+func TestCLIConnectionApply_ExplicitServerSkipsContext(t *testing.T) {
+	writeTestCLIConfig(t)
+
+	api, instance, token, oob, scopes := "", "coder-9393", "", "", ""
+	require.NoError(t, cliConnection{API: &api, Instance: &instance, Token: &token, OOB: &oob, OAuthScopes: &scopes}.apply("prod"))
+	assert.Equal(t, "", api)
+	assert.Equal(t, "coder-9393", instance)
+	assert.Empty(t, oob)
+	assert.Empty(t, scopes)
+
+	api, instance, token = "http://localhost:9000", "", ""
+	require.NoError(t, cliConnection{API: &api, Instance: &instance, Token: &token}.apply("dev"))
+	assert.Equal(t, "", instance)
+	assert.Empty(t, token)
+
+	t.Setenv("AGENTLY_TOKEN", "")
+	assert.Equal(t, "dev-token", contextToken("", "", "", "dev"))
+	assert.Empty(t, contextToken("", "http://localhost:9000", "", "dev"))
+
+	chat := &ChatCmd{CLIContext: "prod", API: "http://localhost:9000"}
+	require.NoError(t, chat.applyCLIContext())
+	assert.Empty(t, chat.OOB)
+	assert.Empty(t, chat.OAuthScp)
+}
+
+func TestResolveToolBaseURL_UsesContext(t *testing.T) {
+	writeTestCLIConfig(t)
+	got, err := resolveToolBaseURL(context.Background(), "", "", "prod")
+	require.NoError(t, err)
+	assert.Equal(t, "https://agently.example.com", got)
+
+	got, err = resolveToolBaseURL(context.Background(), "http://localhost:9000", "", "prod")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:9000", got)
+
+	_, err = resolveToolBaseURL(context.Background(), "", "", "staging")
+	assert.ErrorContains(t, err, `context "staging" not found`)
+}
+
+func TestChatCmd_ApplyCLIContext(t *testing.T) {
+	writeTestCLIConfig(t)
+	chat := &ChatCmd{CLIContext: "prod", OOB: "scy://flag.enc"}
+	require.NoError(t, chat.applyCLIContext())
+	assert.Equal(t, "https://agently.example.com", chat.API)
+	assert.Equal(t, "scy://flag.enc", chat.OOB)
+	assert.Equal(t, "openid,email", chat.OAuthScp)
+
+	local := &ChatCmd{Local: true}
+	require.NoError(t, local.applyCLIContext())
+	assert.Empty(t, local.Instance)
+	assert.Empty(t, local.Token)
+}
+
+func TestContextCommands(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "cli.yaml")
+	t.Setenv("AGENTLY_CLI_CONFIG", path)
+	t.Setenv(cliContextEnv, "")
+
+	add := &ContextAddCmd{API: "http://localhost:8080"}
+	add.Args.Name = "dev"
+	require.NoError(t, add.Execute(nil))
+	add = &ContextAddCmd{API: "https://agently.example.com", Token: "secret-token"}
+	add.Args.Name = "prod"
+	require.NoError(t, add.Execute(nil))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	cfg, err := loadCLIConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "dev", cfg.CurrentContext, "the first context becomes current")
+	require.Len(t, cfg.Contexts, 2)
+
+	// Updating keeps fields that are not passed again.
+	add = &ContextAddCmd{OOB: "scy://prod.enc", Use: true}
+	add.Args.Name = "prod"
+	require.NoError(t, add.Execute(nil))
+	cfg, err = loadCLIConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "prod", cfg.CurrentContext)
+	assert.Equal(t, &cliContext{Name: "prod", API: "https://agently.example.com", Token: "secret-token", OOB: "scy://prod.enc"}, cfg.lookup("prod"))
+
+	use := &ContextUseCmd{}
+	use.Args.Name = "staging"
+	assert.ErrorContains(t, use.Execute(nil), `context "staging" not found`)
+	use.Args.Name = "dev"
+	require.NoError(t, use.Execute(nil))
+
+	remove := &ContextRemoveCmd{}
+	remove.Args.Name = "dev"
+	require.NoError(t, remove.Execute(nil))
+	cfg, err = loadCLIConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "", cfg.CurrentContext)
+	assert.Equal(t, []string{"prod"}, cfg.names())
+}
+
+func TestPrintContextTable(t *testing.T) {
+	var out bytes.Buffer
+	printContextTable(&out, []*cliContext{
+		{Name: "dev", Instance: "agently-8080"},
+		{Name: "prod", API: "https://agently.example.com", Token: "t", OOB: "scy://prod.enc"},
+		{Name: "local"},
+	}, "prod")
+	assert.Regexp(t, `CURRENT +NAME +SERVER +AUTH`, out.String())
+	assert.Regexp(t, `\n +dev +instance agently-8080 +-\n`, out.String())
+	assert.Regexp(t, `\n\* +prod +https://agently.example.com +token,oob\n`, out.String())
+	assert.Regexp(t, `\n +local +auto-detect +-\n`, out.String())
+}
@@ -0,0 +1,59 @@
+package agently
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/viant/agently-core/sdk"
+)
+
+// apiClientOptions carries the server selection and auth flags shared by
+// client-side commands. It is embedded into command structs so go-flags
+// exposes the same flags everywhere.
+type apiClientOptions struct {
+	API      string `long:"api" description:"Agently base URL (skips auto-detect)"`
+	Instance string `long:"instance" description:"registered instance name, port or pid to connect to (see agently instances)"`
+	Token    string `long:"token" description:"Bearer token for API requests (overrides AGENTLY_TOKEN)"`
+	OOB      string `long:"oob" description:"Use local scy OAuth2 out-of-band login with the supplied secrets URL"`
+	OAuthCfg string `long:"oauth-config" description:"Optional scy OAuth config URL override for client-side OOB login"`
+	OAuthScp string `long:"oauth-scopes" description:"comma-separated OAuth scopes for OOB login"`
+	User     string `short:"u" long:"user" description:"user id for local auth fallback" default:"devuser"`
+	Context  string `long:"context" description:"CLI context supplying unset server and auth flags; spelled --cli-context on query and chat (overrides AGENTLY_CONTEXT; see agently context)"`
+}
+
+func (o *apiClientOptions) asChat() *ChatCmd {
+	return &ChatCmd{
+		API:        strings.TrimSpace(o.API),
+		Instance:   strings.TrimSpace(o.Instance),
+		Token:      strings.TrimSpace(o.Token),
+		OOB:        strings.TrimSpace(o.OOB),
+		OAuthCfg:   strings.TrimSpace(o.OAuthCfg),
+		OAuthScp:   strings.TrimSpace(o.OAuthScp),
+		User:       strings.TrimSpace(o.User),
+		CLIContext: strings.TrimSpace(o.Context),
+	}
+}
+
+// connect resolves the target instance the same way `query` does and returns
+// an authenticated SDK client.
+func (o *apiClientOptions) connect(ctx context.Context) (*sdk.HTTPClient, error) {
+	chat := o.asChat()
+	baseURL, providers, _, _, _, _, err := chat.resolveBaseURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := &http.Client{Jar: cliCookieJar(baseURL)}
+	opts := []sdk.HTTPOption{sdk.WithHTTPClient(httpClient)}
+	if token := serverToken(baseURL, chat.Token); token != "" {
+		opts = append(opts, sdk.WithAuthToken(token))
+	}
+	client, err := sdk.NewHTTP(baseURL, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := chat.ensureAuth(ctx, client, providers); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
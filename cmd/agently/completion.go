@@ -0,0 +1,410 @@
+package agently
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/viant/agently-core/sdk"
+	"github.com/viant/agently-core/workspace"
+	agentlyrt "github.com/viant/agently/runtime"
+)
+
+// completeCommand is the hidden entry point the shell scripts call with the
+// words typed so far; it prints one candidate per line, optionally followed
+// by a tab and a description.
+const completeCommand = "__complete"
+
+// completionTimeout bounds server lookups so a slow or missing server never
+// stalls the shell.
+const completionTimeout = 3 * time.Second
+
+// CompletionCmd prints a shell completion script.
+type CompletionCmd struct {
+	Args struct {
+		Shell string `positional-arg-name:"shell" description:"bash, zsh or fish"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+func (c *CompletionCmd) Execute(_ []string) error {
+	switch strings.ToLower(strings.TrimSpace(c.Args.Shell)) {
+	case "bash":
+		fmt.Print(bashCompletionScript)
+	case "zsh":
+		fmt.Print(zshCompletionScript)
+	case "fish":
+		fmt.Print(fishCompletionScript)
+	default:
+		return fmt.Errorf("unsupported shell %q (expected bash, zsh or fish)", c.Args.Shell)
+	}
+	return nil
+}
+
+// printCompletions writes the candidates for the last of args.
+func printCompletions(out io.Writer, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	for _, item := range completeArgs(ctx, args, newServerCompletionSource) {
+		if item.Description == "" {
+			fmt.Fprintln(out, item.Item)
+			continue
+		}
+		fmt.Fprintf(out, "%s\t%s\n", item.Item, strings.Join(strings.Fields(item.Description), " "))
+	}
+}
+
+// completionTarget is the server the completed command would talk to.
+type completionTarget struct {
+	API      string
+	Instance string
+	Context  string
+}
+
+// completionSource provides the dynamic candidates.
+type completionSource interface {
+	Agents(ctx context.Context) []flags.Completion
+	Models(ctx context.Context) []flags.Completion
+	Tools(ctx context.Context) []flags.Completion
+	Conversations(ctx context.Context) []flags.Completion
+}
+
+// toolNameCommands take a tool name through --name.
+var toolNameCommands = map[string]bool{"mcp run": true, "mcp list": true, "mcp codegen": true, "list-tools": true}
+
+// completeArgs completes option values that name agents, models, tools and
+// conversations from source, and leaves commands, flags and static values to
+// the go-flags completer.
+func completeArgs(ctx context.Context, args []string, source func(completionTarget) completionSource) []flags.Completion {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	opts := &Options{}
+	opts.Init(args[0])
+	parser := flags.NewParser(opts, flags.HelpFlag|flags.PassDoubleDash)
+	if items, ok := completeOptionValue(ctx, parser, args, source); ok {
+		return items
+	}
+
+	var items []flags.Completion
+	parser.CompletionHandler = func(completed []flags.Completion) { items = completed }
+	previous, had := os.LookupEnv("GO_FLAGS_COMPLETION")
+	_ = os.Setenv("GO_FLAGS_COMPLETION", "1")
+	defer func() {
+		if had {
+			_ = os.Setenv("GO_FLAGS_COMPLETION", previous)
+		} else {
+			_ = os.Unsetenv("GO_FLAGS_COMPLETION")
+		}
+	}()
+	_, _ = parser.ParseArgs(args)
+	return items
+}
+
+func completeOptionValue(ctx context.Context, parser *flags.Parser, args []string, source func(completionTarget) completionSource) ([]flags.Completion, bool) {
+	last := args[len(args)-1]
+	words := args[:len(args)-1]
+	command := parser.Command
+	var path []string
+	for _, word := range words {
+		if strings.HasPrefix(word, "-") {
+			continue
+		}
+		if sub := command.Find(word); sub != nil {
+			command = sub
+			path = append(path, word)
+		}
+	}
+
+	var option *flags.Option
+	prefix, match := "", last
+	if name, value, ok := strings.Cut(last, "="); ok && strings.HasPrefix(name, "--") {
+		option = command.FindOptionByLongName(strings.TrimPrefix(name, "--"))
+		prefix, match = name+"=", value
+	} else if len(words) > 0 {
+		option = findValueOption(command, words[len(words)-1])
+	}
+	if option == nil {
+		return nil, false
+	}
+
+	var lookup func(completionSource, context.Context) []flags.Completion
+	switch commandPath := strings.Join(path, " "); {
+	case option.LongName == "agent-id" || option.LongName == "agent":
+		lookup = completionSource.Agents
+	case option.LongName == "model":
+		lookup = completionSource.Models
+	case option.LongName == "conv":
+		lookup = completionSource.Conversations
+	case option.LongName == "name" && toolNameCommands[commandPath]:
+		lookup = completionSource.Tools
+	default:
+		return nil, false
+	}
+	var items []flags.Completion
+	for _, item := range lookup(source(completionTargetFromArgs(path, words)), ctx) {
+		if strings.HasPrefix(item.Item, match) {
+			items = append(items, flags.Completion{Item: prefix + item.Item, Description: item.Description})
+		}
+	}
+	return items, true
+}
+
+// findValueOption returns the option word names when it expects a value in
+// the next word.
+func findValueOption(command *flags.Command, word string) *flags.Option {
+	if strings.Contains(word, "=") {
+		return nil
+	}
+	var option *flags.Option
+	switch {
+	case strings.HasPrefix(word, "--"):
+		option = command.FindOptionByLongName(strings.TrimPrefix(word, "--"))
+	case len(word) == 2 && word[0] == '-':
+		option = command.FindOptionByShortName(rune(word[1]))
+	}
+	if option == nil || option.Field().Type.Kind() == reflect.Bool {
+		return nil
+	}
+	return option
+}
+
+// completionTargetFromArgs picks up the server flags already typed. query
+// spells the CLI context flag --cli-context because its --context takes data.
+func completionTargetFromArgs(path []string, words []string) completionTarget {
+	contextFlag := "context"
+	if len(path) > 0 && (path[0] == "query" || path[0] == "chat") {
+		contextFlag = "cli-context"
+	}
+	var target completionTarget
+	for i, word := range words {
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(word, "--"), "=")
+		if !strings.HasPrefix(word, "--") {
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(words) {
+				continue
+			}
+			value = words[i+1]
+		}
+		switch name {
+		case "api":
+			target.API = value
+		case "instance":
+			target.Instance = value
+		case contextFlag:
+			target.Context = value
+		}
+	}
+	return target
+}
+
+// serverCompletionSource queries the server the command would use, with the
+// cached CLI credentials. It never prompts; lookups that need a login the
+// cache cannot satisfy complete nothing.
+type serverCompletionSource struct {
+	target  completionTarget
+	baseURL string
+	client  *sdk.HTTPClient
+	http    *http.Client
+	err     error
+	meta    map[string]interface{}
+}
+
+func newServerCompletionSource(target completionTarget) completionSource {
+	return &serverCompletionSource{target: target}
+}
+
+func (s *serverCompletionSource) connect(ctx context.Context) error {
+	if s.client != nil || s.err != nil {
+		return s.err
+	}
+	s.baseURL, s.err = resolveToolBaseURL(ctx, s.target.API, s.target.Instance, s.target.Context)
+	if s.err != nil {
+		return s.err
+	}
+	s.http = &http.Client{Jar: cliCookieJar(s.baseURL), Timeout: completionTimeout}
+	opts := []sdk.HTTPOption{sdk.WithHTTPClient(s.http)}
+	if token := serverToken(s.baseURL, contextToken("", s.target.API, s.target.Instance, s.target.Context)); token != "" {
+		opts = append(opts, sdk.WithAuthToken(token))
+	}
+	s.client, s.err = sdk.NewHTTP(s.baseURL, opts...)
+	return s.err
+}
+
+// metadata returns the workspace metadata document, unwrapping {"data":...}.
+func (s *serverCompletionSource) metadata(ctx context.Context) map[string]interface{} {
+	if s.meta != nil || s.connect(ctx) != nil {
+		return s.meta
+	}
+	s.meta = map[string]interface{}{}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"/v1/workspace/metadata", nil)
+	if err != nil {
+		return s.meta
+	}
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return s.meta
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&s.meta) != nil {
+		return s.meta
+	}
+	if data, ok := s.meta["data"].(map[string]interface{}); ok {
+		s.meta = data
+	}
+	return s.meta
+}
+
+// Agents lists the agents the server reports, else those in the local
+// workspace.
+func (s *serverCompletionSource) Agents(ctx context.Context) []flags.Completion {
+	meta := s.metadata(ctx)
+	if items := completionNames(meta["agentInfos"]); len(items) > 0 {
+		return items
+	}
+	if items := completionNames(meta["agents"]); len(items) > 0 {
+		return items
+	}
+	var items []flags.Completion
+	for _, id := range agentlyrt.DiscoverAgentIDs(workspace.Root()) {
+		items = append(items, flags.Completion{Item: id})
+	}
+	return items
+}
+
+// Models lists the models the server reports, else those in the local
+// workspace.
+func (s *serverCompletionSource) Models(ctx context.Context) []flags.Completion {
+	if items := completionNames(s.metadata(ctx)["models"]); len(items) > 0 {
+		return items
+	}
+	configs, _ := agentlyrt.ListModelConfigs(workspace.Root())
+	var items []flags.Completion
+	for _, cfg := range configs {
+		items = append(items, flags.Completion{Item: cfg.ID})
+	}
+	return items
+}
+
+func (s *serverCompletionSource) Tools(ctx context.Context) []flags.Completion {
+	if s.connect(ctx) != nil {
+		return nil
+	}
+	defs, err := s.client.ListToolDefinitions(ctx)
+	if err != nil {
+		return nil
+	}
+	items := make([]flags.Completion, 0, len(defs))
+	for _, def := range defs {
+		description, _, _ := strings.Cut(strings.TrimSpace(def.Description), "\n")
+		items = append(items, flags.Completion{Item: def.Name, Description: truncateCell(description, 60)})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Item < items[j].Item })
+	return items
+}
+
+// Conversations lists the most recent conversations, newest first.
+func (s *serverCompletionSource) Conversations(ctx context.Context) []flags.Completion {
+	if s.connect(ctx) != nil {
+		return nil
+	}
+	page, err := s.client.ListConversations(ctx, &sdk.ListConversationsInput{Page: &sdk.PageInput{Limit: 20}})
+	if err != nil || page == nil {
+		return nil
+	}
+	rows, err := decodeConversationRows(page.Rows)
+	if err != nil {
+		return nil
+	}
+	items := make([]flags.Completion, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, flags.Completion{Item: row.ID, Description: truncateCell(firstNonEmpty(row.Title, row.AgentID), 60)})
+	}
+	return items
+}
+
+// completionNames reads a metadata list of ids or {id, name} objects.
+func completionNames(value interface{}) []flags.Completion {
+	list, _ := value.([]interface{})
+	var items []flags.Completion
+	for _, entry := range list {
+		switch actual := entry.(type) {
+		case string:
+			if strings.TrimSpace(actual) != "" {
+				items = append(items, flags.Completion{Item: strings.TrimSpace(actual)})
+			}
+		case map[string]interface{}:
+			id := firstNonEmpty(stringValue(actual["id"]), stringValue(actual["value"]))
+			if id == "" {
+				continue
+			}
+			name := firstNonEmpty(stringValue(actual["name"]), stringValue(actual["label"]), stringValue(actual["title"]))
+			if name == id {
+				name = ""
+			}
+			items = append(items, flags.Completion{Item: id, Description: name})
+		}
+	}
+	return items
+}
+
+const bashCompletionScript = `# bash completion for agently; load with: source <(agently completion bash)
+_agently_complete() {
+    local cur words cword
+    if declare -F _get_comp_words_by_ref >/dev/null 2>&1; then
+        _get_comp_words_by_ref -n =: cur words cword
+    else
+        cur="${COMP_WORDS[COMP_CWORD]}"
+        words=("${COMP_WORDS[@]}")
+        cword=$COMP_CWORD
+    fi
+    local IFS=$'\n'
+    COMPREPLY=($("${words[0]}" __complete "${words[@]:1:cword}" 2>/dev/null | cut -f1))
+    if declare -F __ltrim_colon_completions >/dev/null 2>&1; then
+        __ltrim_colon_completions "$cur"
+    fi
+    if [[ "$cur" == --*=* && "$COMP_WORDBREAKS" == *=* ]]; then
+        COMPREPLY=("${COMPREPLY[@]#"${cur%%=*}="}")
+    fi
+}
+complete -o default -F _agently_complete agently
+`
+
+const zshCompletionScript = `#compdef agently
+# zsh completion for agently; load with: source <(agently completion zsh)
+_agently() {
+    local -a candidates
+    local line item desc
+    for line in "${(@f)$("${words[1]}" __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}"; do
+        [[ -z "$line" ]] && continue
+        item="${line%%$'\t'*}"
+        desc=""
+        [[ "$line" == *$'\t'* ]] && desc="${line#*$'\t'}"
+        candidates+=("${item//:/\\:}${desc:+:$desc}")
+    done
+    if (( ${#candidates} )); then
+        _describe -t values 'agently' candidates
+    else
+        _files
+    fi
+}
+compdef _agently agently
+`
+
+const fishCompletionScript = `# fish completion for agently; load with: agently completion fish | source
+function __agently_complete
+    set -l tokens (commandline -opc)
+    set -e tokens[1]
+    agently __complete $tokens (commandline -ct) 2>/dev/null
+end
+complete -c agently -f -a '(__agently_complete)'
+`
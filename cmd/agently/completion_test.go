@@ -0,0 +1,127 @@
+package agently
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCompletionSource struct{}
+
+func (f fakeCompletionSource) Agents(context.Context) []flags.Completion {
+	return []flags.Completion{{Item: "chatter"}, {Item: "coder", Description: "Coder"}}
+}
+
+func (f fakeCompletionSource) Models(context.Context) []flags.Completion {
+	return []flags.Completion{{Item: "openai_gpt-5.2"}, {Item: "openai_gpt-5.4"}}
+}
+
+func (f fakeCompletionSource) Tools(context.Context) []flags.Completion {
+	return []flags.Completion{{Item: "system/exec:execute", Description: "Run a command"}, {Item: "system/os:getEnv"}, {Item: "resources:read"}}
+}
+
+func (f fakeCompletionSource) Conversations(context.Context) []flags.Completion {
+	return []flags.Completion{{Item: "conv-2", Description: "Latest"}, {Item: "conv-1"}}
+}
+
+func completeItems(t *testing.T, args ...string) ([]string, []completionTarget) {
+	t.Helper()
+	var targets []completionTarget
+	source := func(target completionTarget) completionSource {
+		targets = append(targets, target)
+		return fakeCompletionSource{}
+	}
+	var items []string
+	for _, item := range completeArgs(context.Background(), args, source) {
+		items = append(items, item.Item)
+	}
+	return items, targets
+}
+
+func TestCompleteArgs_Dynamic(t *testing.T) {
+	items, _ := completeItems(t, "mcp", "run", "--name", "system/")
+	assert.Equal(t, []string{"system/exec:execute", "system/os:getEnv"}, items)
+
+	items, _ = completeItems(t, "mcp", "run", "--name=system/e")
+	assert.Equal(t, []string{"--name=system/exec:execute"}, items)
+
+	items, _ = completeItems(t, "query", "--agent-id", "co")
+	assert.Equal(t, []string{"coder"}, items)
+
+	items, _ = completeItems(t, "query", "-a", "")
+	assert.Equal(t, []string{"chatter", "coder"}, items)
+
+	items, _ = completeItems(t, "query", "--model", "")
+	assert.Equal(t, []string{"openai_gpt-5.2", "openai_gpt-5.4"}, items)
+
+	items, _ = completeItems(t, "conversation", "show", "-c", "")
+	assert.Equal(t, []string{"conv-2", "conv-1"}, items, "conversations keep the server's recency order")
+
+	items, _ = completeItems(t, "conversation", "list", "--agent", "ch")
+	assert.Equal(t, []string{"chatter"}, items)
+}
+
+func TestCompleteArgs_Target(t *testing.T) {
+	_, targets := completeItems(t, "list-tools", "--api", "http://localhost:9000", "--context=prod", "--name", "")
+	require.Len(t, targets, 1)
+	assert.Equal(t, completionTarget{API: "http://localhost:9000", Context: "prod"}, targets[0])
+
+	_, targets = completeItems(t, "query", "--instance", "agently-8080", "--cli-context", "dev", "--context", "{}", "-c", "")
+	require.Len(t, targets, 1)
+	assert.Equal(t, completionTarget{Instance: "agently-8080", Context: "dev"}, targets[0])
+}
+
+func TestCompleteArgs_Static(t *testing.T) {
+	items, targets := completeItems(t, "mc")
+	assert.Equal(t, []string{"mcp"}, items)
+	assert.Empty(t, targets)
+
+	items, _ = completeItems(t, "completion", "")
+	assert.Empty(t, items)
+
+	items, targets = completeItems(t, "conversation", "show", "--c")
+	assert.Contains(t, items, "--conv")
+	assert.Empty(t, targets, "option names never query the server")
+
+	// --name of commands other than tool commands is left alone.
+	items, targets = completeItems(t, "agent", "new", "--name", "")
+	assert.Empty(t, items)
+	assert.Empty(t, targets)
+}
+
+func TestServerCompletionSource_Metadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/workspace/metadata" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"ok","data":{
+			"agentInfos":[{"id":"coder","name":"Coder"},{"id":"chatter","name":"chatter"}],
+			"models":["openai_gpt-5.4"]
+		}}`))
+	}))
+	defer server.Close()
+
+	source := newServerCompletionSource(completionTarget{API: server.URL})
+	assert.Equal(t, []flags.Completion{{Item: "coder", Description: "Coder"}, {Item: "chatter"}}, source.Agents(context.Background()))
+	assert.Equal(t, []flags.Completion{{Item: "openai_gpt-5.4"}}, source.Models(context.Background()))
+}
+
+func TestCompletionCmd(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		cmd := &CompletionCmd{}
+		cmd.Args.Shell = shell
+		assert.NoError(t, cmd.Execute(nil), shell)
+	}
+	cmd := &CompletionCmd{}
+	cmd.Args.Shell = "powershell"
+	assert.ErrorContains(t, cmd.Execute(nil), "unsupported shell")
+	assert.Contains(t, bashCompletionScript, completeCommand)
+	assert.Contains(t, zshCompletionScript, completeCommand)
+	assert.Contains(t, fishCompletionScript, completeCommand)
+}
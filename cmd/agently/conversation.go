@@ -0,0 +1,474 @@
+package agently
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/viant/agently-core/sdk"
+)
+
+// ConversationCmd groups conversation management subcommands.
+type ConversationCmd struct {
+	List   *ConversationListCmd   `command:"list" description:"List conversations"`
+	Show   *ConversationShowCmd   `command:"show" description:"Show conversation details and turns"`
+	Delete *ConversationDeleteCmd `command:"delete" description:"Delete one or more conversations"`
+	Rename *ConversationRenameCmd `command:"rename" description:"Rename a conversation"`
+	Fork   *ConversationForkCmd   `command:"fork" description:"Fork a conversation from a message into a new conversation"`
+}
+
+// ConversationListCmd lists conversations visible to the caller.
+type ConversationListCmd struct {
+	apiClientOptions
+	AgentID  string `short:"a" long:"agent" description:"only include conversations of this agent"`
+	Since    string `long:"since" description:"only include conversations active within a duration (e.g. 24h, 7d) or since an RFC3339 time"`
+	Query    string `long:"query" description:"free-text filter passed to the server"`
+	Limit    int    `long:"limit" description:"maximum number of conversations to list" default:"50"`
+	MaxPages int    `long:"max-pages" description:"maximum number of pages to read while --agent or --since filter the results" default:"20"`
+	JSON     bool   `long:"json" description:"Print result as JSON instead of a table"`
+}
+
+// ConversationShowCmd prints a conversation and its turns.
+type ConversationShowCmd struct {
+	apiClientOptions
+	ConvID string `short:"c" long:"conv" description:"conversation ID" required:"true"`
+	JSON   bool   `long:"json" description:"Print result as JSON instead of plain text"`
+}
+
+// ConversationDeleteCmd deletes conversations.
+type ConversationDeleteCmd struct {
+	apiClientOptions
+	ConvIDs []string `short:"c" long:"conv" description:"conversation ID (repeatable)" required:"true"`
+	Yes     bool     `short:"y" long:"yes" description:"do not ask for confirmation"`
+	JSON    bool     `long:"json" description:"Print result as JSON"`
+}
+
+// ConversationRenameCmd changes a conversation title.
+type ConversationRenameCmd struct {
+	apiClientOptions
+	ConvID string `short:"c" long:"conv" description:"conversation ID" required:"true"`
+	Title  string `long:"title" description:"new conversation title" required:"true"`
+	JSON   bool   `long:"json" description:"Print result as JSON"`
+}
+
+// ConversationForkCmd copies a conversation up to a message into a new one.
+type ConversationForkCmd struct {
+	apiClientOptions
+	ConvID      string `short:"c" long:"conv" description:"source conversation ID" required:"true"`
+	FromMessage string `long:"from-message" description:"last message ID to carry into the fork (defaults to the whole conversation)"`
+	Title       string `long:"title" description:"title of the forked conversation"`
+	JSON        bool   `long:"json" description:"Print result as JSON"`
+}
+
+// conversationRow is the CLI view of a conversation. SDK values are decoded
+// through JSON so the CLI only depends on the wire field names.
+type conversationRow struct {
+	ID           string     `json:"id"`
+	Title        string     `json:"title,omitempty"`
+	AgentID      string     `json:"agentId,omitempty"`
+	Status       string     `json:"status,omitempty"`
+	Visibility   string     `json:"visibility,omitempty"`
+	CreatedAt    *time.Time `json:"createdAt,omitempty"`
+	LastActivity *time.Time `json:"lastActivity,omitempty"`
+}
+
+type conversationTurnRow struct {
+	TurnID  string `json:"turnId"`
+	Status  string `json:"status,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+type conversationDetail struct {
+	*conversationRow
+	Turns []*conversationTurnRow `json:"turns,omitempty"`
+}
+
+func (c *ConversationListCmd) Execute(_ []string) error {
+	ctx := context.Background()
+	since, err := parseSince(c.Since, time.Now())
+	if err != nil {
+		return err
+	}
+	client, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+	limit := c.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	maxPages := c.MaxPages
+	if maxPages <= 0 {
+		maxPages = defaultConversationPages
+	}
+	agentID := strings.TrimSpace(c.AgentID)
+	query := strings.TrimSpace(c.Query)
+	rows, truncated, err := collectConversationRows(func(cursor string) (*sdk.ConversationPage, error) {
+		return client.ListConversations(ctx, &sdk.ListConversationsInput{
+			AgentID: agentID,
+			Query:   query,
+			Page:    &sdk.PageInput{Limit: limit, Cursor: cursor},
+		})
+	}, agentID, since, limit, maxPages)
+	if err != nil {
+		return err
+	}
+	if truncated {
+		fmt.Fprintf(os.Stderr, "[conversation] stopped after %d pages with %d of %d conversations; results may be incomplete (raise --max-pages)\n", maxPages, len(rows), limit)
+	}
+	if c.JSON {
+		return printJSON(rows)
+	}
+	if len(rows) == 0 {
+		fmt.Println("no conversations found")
+		return nil
+	}
+	printConversationTable(os.Stdout, rows)
+	return nil
+}
+
+func (c *ConversationShowCmd) Execute(_ []string) error {
+	ctx := context.Background()
+	client, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+	conversationID := strings.TrimSpace(c.ConvID)
+	conversation, err := client.GetConversation(ctx, conversationID)
+	if err != nil {
+		return fmt.Errorf("get conversation %q: %w", conversationID, err)
+	}
+	row := &conversationRow{}
+	if err := reencodeJSON(conversation, row); err != nil {
+		return fmt.Errorf("decode conversation: %w", err)
+	}
+	transcript, err := client.GetTranscript(ctx, &sdk.GetTranscriptInput{ConversationID: conversationID})
+	if err != nil {
+		return fmt.Errorf("get transcript: %w", err)
+	}
+	detail := &conversationDetail{conversationRow: row, Turns: transcriptTurnRows(transcript)}
+	if c.JSON {
+		return printJSON(detail)
+	}
+	fmt.Printf("ID           : %s\n", row.ID)
+	fmt.Printf("Title        : %s\n", row.Title)
+	fmt.Printf("Agent        : %s\n", row.AgentID)
+	if row.Status != "" {
+		fmt.Printf("Status       : %s\n", row.Status)
+	}
+	if row.Visibility != "" {
+		fmt.Printf("Visibility   : %s\n", row.Visibility)
+	}
+	fmt.Printf("Created      : %s\n", formatConversationTime(row.CreatedAt))
+	fmt.Printf("Last Activity: %s\n", formatConversationTime(row.LastActivity))
+	fmt.Printf("Turns        : %d\n", len(detail.Turns))
+	if len(detail.Turns) == 0 {
+		return nil
+	}
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(w, "TURN\tSTATUS\tRESPONSE")
+	for _, turn := range detail.Turns {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", turn.TurnID, turn.Status, truncateCell(turn.Content, 80))
+	}
+	return w.Flush()
+}
+
+func (c *ConversationDeleteCmd) Execute(_ []string) error {
+	ctx := context.Background()
+	ids := make([]string, 0, len(c.ConvIDs))
+	for _, id := range c.ConvIDs {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("--conv is required")
+	}
+	if !c.Yes {
+		if !stdinIsTTY() {
+			return fmt.Errorf("refusing to delete without confirmation; pass --yes when stdin is not a TTY")
+		}
+		fmt.Printf("Delete %d conversation(s): %s? [y/N]: ", len(ids), strings.Join(ids, ", "))
+		line, cancelled, err := readPromptLine(ctx, bufio.NewReader(os.Stdin))
+		if err != nil {
+			return fmt.Errorf("read confirmation: %w", err)
+		}
+		if cancelled || !isAffirmative(line) {
+			fmt.Println("aborted")
+			return nil
+		}
+	}
+	client, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+	deleted := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if err := client.DeleteConversation(ctx, id); err != nil {
+			return fmt.Errorf("delete conversation %q: %w", id, err)
+		}
+		deleted = append(deleted, id)
+		if !c.JSON {
+			fmt.Printf("deleted %s\n", id)
+		}
+	}
+	if c.JSON {
+		return printJSON(map[string]interface{}{"deleted": deleted})
+	}
+	return nil
+}
+
+func (c *ConversationRenameCmd) Execute(_ []string) error {
+	ctx := context.Background()
+	title := strings.TrimSpace(c.Title)
+	if title == "" {
+		return fmt.Errorf("--title must not be empty")
+	}
+	client, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+	conversationID := strings.TrimSpace(c.ConvID)
+	if err := client.UpdateConversation(ctx, &sdk.UpdateConversationInput{
+		ConversationID: conversationID,
+		Title:          title,
+	}); err != nil {
+		return fmt.Errorf("rename conversation %q: %w", conversationID, err)
+	}
+	if c.JSON {
+		return printJSON(&conversationRow{ID: conversationID, Title: title})
+	}
+	fmt.Printf("renamed %s to %q\n", conversationID, title)
+	return nil
+}
+
+func (c *ConversationForkCmd) Execute(_ []string) error {
+	ctx := context.Background()
+	client, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+	conversationID := strings.TrimSpace(c.ConvID)
+	forked, err := client.ForkConversation(ctx, &sdk.ForkConversationInput{
+		ConversationID: conversationID,
+		MessageID:      strings.TrimSpace(c.FromMessage),
+		Title:          strings.TrimSpace(c.Title),
+	})
+	if err != nil {
+		return fmt.Errorf("fork conversation %q: %w", conversationID, err)
+	}
+	row := &conversationRow{}
+	if err := reencodeJSON(forked, row); err != nil {
+		return fmt.Errorf("decode forked conversation: %w", err)
+	}
+	if strings.TrimSpace(row.ID) == "" {
+		return fmt.Errorf("fork conversation returned no id")
+	}
+	if c.JSON {
+		return printJSON(row)
+	}
+	fmt.Printf("[conversation-id] %s\n", row.ID)
+	return nil
+}
+
+// defaultConversationPages caps how far filtered listings page back through
+// history unless --max-pages says otherwise.
+const defaultConversationPages = 20
+
+// collectConversationRows pages through conversations until limit rows pass
+// the filters. The server has no time filter, so --since keeps paging rather
+// than filtering a single page, and stops once a page reaches rows older than
+// the cutoff. truncated reports that maxPages ran out while the server had
+// more.
+func collectConversationRows(fetch func(cursor string) (*sdk.ConversationPage, error), agentID string, since time.Time, limit, maxPages int) (rows []*conversationRow, truncated bool, err error) {
+	cursor := ""
+	for i := 0; ; i++ {
+		if i == maxPages {
+			return rows, true, nil
+		}
+		page, err := fetch(cursor)
+		if err != nil {
+			return nil, false, fmt.Errorf("list conversations: %w", err)
+		}
+		if page == nil {
+			break
+		}
+		pageRows, err := decodeConversationRows(page.Rows)
+		if err != nil {
+			return nil, false, err
+		}
+		rows = append(rows, filterConversationRows(pageRows, agentID, since)...)
+		if len(rows) >= limit {
+			return rows[:limit], false, nil
+		}
+		if !since.IsZero() && pastCutoff(pageRows, since) {
+			break
+		}
+		if !page.HasMore || page.NextCursor == "" || page.NextCursor == cursor {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	return rows, false, nil
+}
+
+// pastCutoff reports whether the page reached conversations older than since.
+// It relies on the server listing conversations newest first, by last
+// activity; with another order --since could stop before older pages that
+// still hold recent conversations.
+func pastCutoff(rows []*conversationRow, since time.Time) bool {
+	for _, row := range rows {
+		activity := row.LastActivity
+		if activity == nil {
+			activity = row.CreatedAt
+		}
+		if activity != nil && activity.Before(since) {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeConversationRows(value interface{}) ([]*conversationRow, error) {
+	var rows []*conversationRow
+	if err := reencodeJSON(value, &rows); err != nil {
+		return nil, fmt.Errorf("decode conversations: %w", err)
+	}
+	out := rows[:0]
+	for _, row := range rows {
+		if row != nil && strings.TrimSpace(row.ID) != "" {
+			out = append(out, row)
+		}
+	}
+	return out, nil
+}
+
+func filterConversationRows(rows []*conversationRow, agentID string, since time.Time) []*conversationRow {
+	if agentID == "" && since.IsZero() {
+		return rows
+	}
+	out := make([]*conversationRow, 0, len(rows))
+	for _, row := range rows {
+		if agentID != "" && !strings.EqualFold(strings.TrimSpace(row.AgentID), agentID) {
+			continue
+		}
+		if !since.IsZero() {
+			activity := row.LastActivity
+			if activity == nil {
+				activity = row.CreatedAt
+			}
+			if activity == nil || activity.Before(since) {
+				continue
+			}
+		}
+		out = append(out, row)
+	}
+	return out
+}
+
+func transcriptTurnRows(transcript *sdk.ConversationStateResponse) []*conversationTurnRow {
+	if transcript == nil || transcript.Conversation == nil {
+		return nil
+	}
+	var out []*conversationTurnRow
+	for _, turn := range transcript.Conversation.Turns {
+		if turn == nil {
+			continue
+		}
+		row := &conversationTurnRow{TurnID: turn.TurnID, Status: string(turn.Status)}
+		if turn.Assistant != nil && turn.Assistant.Final != nil {
+			row.Content = strings.TrimSpace(turn.Assistant.Final.Content)
+		}
+		out = append(out, row)
+	}
+	return out
+}
+
+func printConversationTable(out io.Writer, rows []*conversationRow) {
+	w := tabwriter.NewWriter(out, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tAGENT\tLAST ACTIVITY\tTITLE")
+	for _, row := range rows {
+		activity := row.LastActivity
+		if activity == nil {
+			activity = row.CreatedAt
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", row.ID, row.AgentID, formatConversationTime(activity), truncateCell(row.Title, 60))
+	}
+	_ = w.Flush()
+}
+
+// parseSince accepts a Go duration, a day count such as "7d", or an RFC3339
+// timestamp and returns the lower time bound. Empty input means no bound.
+func parseSince(raw string, now time.Time) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if strings.HasSuffix(raw, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(raw, "d")); err == nil && days >= 0 {
+			return now.Add(-time.Duration(days) * 24 * time.Hour), nil
+		}
+	}
+	if duration, err := time.ParseDuration(raw); err == nil {
+		if duration < 0 {
+			duration = -duration
+		}
+		return now.Add(-duration), nil
+	}
+	if ts, err := time.Parse(time.RFC3339, raw); err == nil {
+		return ts, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q (expected duration like 24h or 7d, or RFC3339 time)", raw)
+}
+
+func formatConversationTime(value *time.Time) string {
+	if value == nil || value.IsZero() {
+		return "-"
+	}
+	return value.Local().Format("2006-01-02 15:04")
+}
+
+func truncateCell(value string, max int) string {
+	value = strings.Join(strings.Fields(value), " ")
+	runes := []rune(value)
+	if max <= 0 || len(runes) <= max {
+		return value
+	}
+	if max <= 3 {
+		return string(runes[:max])
+	}
+	return string(runes[:max-3]) + "..."
+}
+
+func isAffirmative(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+func reencodeJSON(src interface{}, dst interface{}) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+func printJSON(value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
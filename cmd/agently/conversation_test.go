@@ -0,0 +1,156 @@
+package agently
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/viant/agently-core/sdk"
+)
+
+func TestOptionsInit_Conversation(t *testing.T) {
+	opts := &Options{}
+	opts.Init("conversation")
+	if opts.Conversation == nil {
+		t.Fatalf("expected conversation command to initialize")
+	}
+}
+
+func TestConversationListCmd_ParsesFlags(t *testing.T) {
+	cmd := &ConversationListCmd{}
+	parser := flags.NewParser(cmd, flags.HelpFlag|flags.PassDoubleDash)
+	_, err := parser.ParseArgs([]string{
+		"--api", "http://127.0.0.1:9191",
+		"--agent", "coder",
+		"--since", "7d",
+		"--limit", "5",
+		"--max-pages", "3",
+		"--json",
+	})
+	if err != nil {
+		t.Fatalf("parse args: %v", err)
+	}
+	if cmd.API != "http://127.0.0.1:9191" || cmd.AgentID != "coder" || cmd.Since != "7d" || cmd.Limit != 5 || cmd.MaxPages != 3 || !cmd.JSON {
+		t.Fatalf("unexpected parsed flags: %+v", cmd)
+	}
+	if cmd.User != "devuser" {
+		t.Fatalf("expected default user, got %q", cmd.User)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name    string
+		input   string
+		expect  time.Time
+		wantErr bool
+	}{
+		{name: "empty", input: "", expect: time.Time{}},
+		{name: "hours", input: "24h", expect: now.Add(-24 * time.Hour)},
+		{name: "days", input: "7d", expect: now.Add(-7 * 24 * time.Hour)},
+		{name: "rfc3339", input: "2026-05-01T00:00:00Z", expect: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "invalid", input: "last week", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseSince(tc.input, now)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for %q", tc.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSince(%q) error = %v", tc.input, err)
+			}
+			if !got.Equal(tc.expect) {
+				t.Fatalf("parseSince(%q) = %v, want %v", tc.input, got, tc.expect)
+			}
+		})
+	}
+}
+
+func TestDecodeAndFilterConversationRows(t *testing.T) {
+	raw := []map[string]interface{}{
+		{"id": "c1", "title": "recent", "agentId": "coder", "lastActivity": "2026-05-09T10:00:00Z"},
+		{"id": "c2", "title": "old", "agentId": "coder", "createdAt": "2026-04-01T10:00:00Z"},
+		{"id": "c3", "title": "other agent", "agentId": "chatter", "lastActivity": "2026-05-09T11:00:00Z"},
+		{"title": "missing id"},
+	}
+	rows, err := decodeConversationRows(raw)
+	if err != nil {
+		t.Fatalf("decodeConversationRows() error = %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected rows without id to be dropped, got %d", len(rows))
+	}
+	since := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	filtered := filterConversationRows(rows, "CODER", since)
+	if len(filtered) != 1 || filtered[0].ID != "c1" {
+		t.Fatalf("unexpected filtered rows: %+v", filtered)
+	}
+	if got := filterConversationRows(rows, "", time.Time{}); len(got) != 3 {
+		t.Fatalf("expected no filtering, got %d rows", len(got))
+	}
+}
+
+func TestCollectConversationRows_PagesPastServerLimit(t *testing.T) {
+	pages := map[string]*sdk.ConversationPage{
+		"": {Rows: []map[string]interface{}{
+			{"id": "c1", "agentId": "chatter", "lastActivity": "2026-05-09T12:00:00Z"},
+			{"id": "c2", "agentId": "chatter", "lastActivity": "2026-05-09T11:00:00Z"},
+		}, NextCursor: "p2", HasMore: true},
+		"p2": {Rows: []map[string]interface{}{
+			{"id": "c3", "agentId": "coder", "lastActivity": "2026-05-08T10:00:00Z"},
+			{"id": "c4", "agentId": "coder", "lastActivity": "2026-04-01T10:00:00Z"},
+		}, NextCursor: "p3", HasMore: true},
+		"p3": {Rows: []map[string]interface{}{
+			{"id": "c5", "agentId": "coder", "lastActivity": "2026-03-01T10:00:00Z"},
+		}},
+	}
+	var cursors []string
+	fetch := func(cursor string) (*sdk.ConversationPage, error) {
+		cursors = append(cursors, cursor)
+		return pages[cursor], nil
+	}
+	since := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	rows, truncated, err := collectConversationRows(fetch, "coder", since, 2, defaultConversationPages)
+	if err != nil || truncated {
+		t.Fatalf("collectConversationRows() error = %v", err)
+	}
+	if len(rows) != 1 || rows[0].ID != "c3" {
+		t.Fatalf("unexpected rows: %+v", rows)
+	}
+	if strings.Join(cursors, ",") != ",p2" {
+		t.Fatalf("expected paging to stop at the cutoff, fetched %q", cursors)
+	}
+
+	cursors = nil
+	rows, _, err = collectConversationRows(fetch, "", time.Time{}, 3, defaultConversationPages)
+	if err != nil {
+		t.Fatalf("collectConversationRows() error = %v", err)
+	}
+	if len(rows) != 3 || rows[2].ID != "c3" {
+		t.Fatalf("expected limit to apply after paging, got %+v", rows)
+	}
+
+	cursors = nil
+	rows, truncated, err = collectConversationRows(fetch, "coder", time.Time{}, 10, 2)
+	if err != nil {
+		t.Fatalf("collectConversationRows() error = %v", err)
+	}
+	if !truncated || len(rows) != 2 || strings.Join(cursors, ",") != ",p2" {
+		t.Fatalf("expected the page cap to truncate, got truncated=%v rows=%+v cursors=%q", truncated, rows, cursors)
+	}
+}
+
+func TestTruncateCell(t *testing.T) {
+	if got := truncateCell("hello\n  world", 20); got != "hello world" {
+		t.Fatalf("truncateCell() = %q", got)
+	}
+	if got := truncateCell("abcdefghij", 6); got != "abc..." {
+		t.Fatalf("truncateCell() = %q", got)
+	}
+}
@@ -0,0 +1,483 @@
+package agently
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/viant/agently-core/sdk"
+)
+
+// credentialCacheEnv overrides the credential cache file location.
+const credentialCacheEnv = "AGENTLY_CREDENTIALS_FILE"
+
+// credentialCache persists session cookies and bearer tokens per server so
+// client commands reuse them instead of repeating the login flow. Servers are
+// keyed by normalized base URL.
+type credentialCache struct {
+	Servers map[string]*serverCredential `json:"servers"`
+}
+
+// serverCredential is what the CLI keeps for one server.
+type serverCredential struct {
+	Token       string          `json:"token,omitempty"`
+	TokenExpiry *time.Time      `json:"tokenExpiry,omitempty"`
+	Cookies     []*cachedCookie `json:"cookies,omitempty"`
+	// Login is the non-interactive login that established the session, rerun
+	// to renew it before it expires.
+	Login     *cachedLogin `json:"login,omitempty"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+}
+
+// cachedLogin records an OOB login by reference: the secrets URL, never the
+// secrets themselves.
+type cachedLogin struct {
+	OOB         string   `json:"oob"`
+	OAuthConfig string   `json:"oauthConfig,omitempty"`
+	Scopes      []string `json:"scopes,omitempty"`
+}
+
+type cachedCookie struct {
+	Name     string     `json:"name"`
+	Value    string     `json:"value"`
+	Path     string     `json:"path,omitempty"`
+	Domain   string     `json:"domain,omitempty"`
+	Expires  *time.Time `json:"expires,omitempty"`
+	Secure   bool       `json:"secure,omitempty"`
+	HttpOnly bool       `json:"httpOnly,omitempty"`
+}
+
+// credentialRenewWindow is how long before expiry a session with a cached
+// login is renewed instead of reused.
+const credentialRenewWindow = 5 * time.Minute
+
+const (
+	credentialLockTimeout = 5 * time.Second
+	credentialLockStale   = 30 * time.Second
+	credentialLockPoll    = 20 * time.Millisecond
+)
+
+// credentialCacheMu serializes read-modify-write cycles within one process
+// and the lock file next to the cache serializes them across processes.
+// Writes go through a rename so readers never see a torn file.
+var credentialCacheMu sync.Mutex
+
+// credentialCachePath returns <user config dir>/agently/credentials.json
+// unless AGENTLY_CREDENTIALS_FILE points elsewhere.
+func credentialCachePath() (string, error) {
+	if path := strings.TrimSpace(os.Getenv(credentialCacheEnv)); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locate credential cache: %w", err)
+	}
+	return filepath.Join(dir, "agently", "credentials.json"), nil
+}
+
+func loadCredentialCache(path string) (*credentialCache, error) {
+	cache := &credentialCache{Servers: map[string]*serverCredential{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cache, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, cache); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if cache.Servers == nil {
+		cache.Servers = map[string]*serverCredential{}
+	}
+	return cache, nil
+}
+
+func (c *credentialCache) save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(0o600)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// lockCredentialCache takes the cross-process lock of the cache at path, a
+// lock file created exclusively next to it, and returns its release. A lock
+// older than credentialLockStale was left behind by a crashed process and is
+// broken.
+func lockCredentialCache(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	lock := path + ".lock"
+	deadline := time.Now().Add(credentialLockTimeout)
+	for {
+		file, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			_ = file.Close()
+			return func() { _ = os.Remove(lock) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, statErr := os.Stat(lock); statErr == nil && time.Since(info.ModTime()) > credentialLockStale {
+			_ = os.Remove(lock)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("credential cache %s is locked by another agently process (remove %s if none is running)", path, lock)
+		}
+		time.Sleep(credentialLockPoll)
+	}
+}
+
+// updateCredentialCache applies fn to the cache and writes it back. fn
+// returning false skips the write.
+func updateCredentialCache(fn func(cache *credentialCache) bool) error {
+	credentialCacheMu.Lock()
+	defer credentialCacheMu.Unlock()
+	path, err := credentialCachePath()
+	if err != nil {
+		return err
+	}
+	unlock, err := lockCredentialCache(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	cache, err := loadCredentialCache(path)
+	if err != nil {
+		return err
+	}
+	if !fn(cache) {
+		return nil
+	}
+	return cache.save(path)
+}
+
+// cachedServerCredential returns the unexpired credentials cached for
+// baseURL together with their login, or nil.
+func cachedServerCredential(baseURL string) *serverCredential {
+	credentialCacheMu.Lock()
+	defer credentialCacheMu.Unlock()
+	path, err := credentialCachePath()
+	if err != nil {
+		return nil
+	}
+	cache, err := loadCredentialCache(path)
+	if err != nil {
+		return nil
+	}
+	entry := cache.Servers[credentialKey(baseURL)]
+	if entry == nil {
+		return nil
+	}
+	now := time.Now()
+	result := &serverCredential{Login: entry.Login, UpdatedAt: entry.UpdatedAt}
+	if entry.Token != "" && (entry.TokenExpiry == nil || entry.TokenExpiry.After(now)) {
+		result.Token, result.TokenExpiry = entry.Token, entry.TokenExpiry
+	}
+	for _, item := range entry.Cookies {
+		if item.Expires == nil || item.Expires.After(now) {
+			result.Cookies = append(result.Cookies, item)
+		}
+	}
+	if result.Token == "" && len(result.Cookies) == 0 && result.Login == nil {
+		return nil
+	}
+	return result
+}
+
+// expiresBefore reports whether none of the credentials outlive t.
+func (s *serverCredential) expiresBefore(t time.Time) bool {
+	if s.Token != "" && (s.TokenExpiry == nil || s.TokenExpiry.After(t)) {
+		return false
+	}
+	for _, item := range s.Cookies {
+		if item.Expires == nil || item.Expires.After(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// empty reports whether nothing is left worth caching.
+func (s *serverCredential) empty() bool {
+	return s.Token == "" && len(s.Cookies) == 0 && s.Login == nil
+}
+
+// cachedToken returns the unexpired bearer token cached for baseURL.
+func cachedToken(baseURL string) string {
+	if entry := cachedServerCredential(baseURL); entry != nil {
+		return entry.Token
+	}
+	return ""
+}
+
+// serverToken resolves the bearer token sent to baseURL: --token (or the
+// CLI context), AGENTLY_TOKEN, then the credential cache.
+func serverToken(baseURL, flagValue string) string {
+	if token := resolvedToken(flagValue); token != "" {
+		return token
+	}
+	return cachedToken(baseURL)
+}
+
+// storeCachedToken records token for baseURL. JWT tokens expire with their
+// exp claim; opaque tokens are kept until logout or rejection.
+func storeCachedToken(baseURL, token string) error {
+	token = strings.TrimSpace(token)
+	return updateCredentialCache(func(cache *credentialCache) bool {
+		entry := cache.entry(baseURL)
+		entry.Token = token
+		entry.TokenExpiry = tokenExpiry(token)
+		entry.UpdatedAt = time.Now().UTC()
+		return true
+	})
+}
+
+// storeCachedLogin records the login that established the session of baseURL.
+func storeCachedLogin(baseURL string, login *cachedLogin) error {
+	return updateCredentialCache(func(cache *credentialCache) bool {
+		entry := cache.entry(baseURL)
+		entry.Login = login
+		entry.UpdatedAt = time.Now().UTC()
+		return true
+	})
+}
+
+// forgetServerCredential drops everything cached for baseURL.
+func forgetServerCredential(baseURL string) (bool, error) {
+	removed := false
+	err := updateCredentialCache(func(cache *credentialCache) bool {
+		key := credentialKey(baseURL)
+		if _, ok := cache.Servers[key]; !ok {
+			return false
+		}
+		delete(cache.Servers, key)
+		removed = true
+		return true
+	})
+	return removed, err
+}
+
+func (c *credentialCache) entry(baseURL string) *serverCredential {
+	key := credentialKey(baseURL)
+	entry := c.Servers[key]
+	if entry == nil {
+		entry = &serverCredential{}
+		c.Servers[key] = entry
+	}
+	return entry
+}
+
+func (c *credentialCache) keys() []string {
+	keys := make([]string, 0, len(c.Servers))
+	for key := range c.Servers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// credentialKey normalizes a base URL so http://Host:8080/ and
+// http://host:8080 share one cache entry.
+func credentialKey(baseURL string) string {
+	baseURL = strings.TrimSpace(baseURL)
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" {
+		return strings.TrimRight(baseURL, "/")
+	}
+	return strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Host) + strings.TrimRight(u.Path, "/")
+}
+
+// tokenExpiry reads the exp claim of a JWT without verifying it; nil means
+// the token is opaque or carries no expiry.
+func tokenExpiry(token string) *time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil
+	}
+	var claims struct {
+		Exp float64 `json:"exp"`
+	}
+	if json.Unmarshal(payload, &claims) != nil || claims.Exp <= 0 {
+		return nil
+	}
+	expiry := time.Unix(int64(claims.Exp), 0).UTC()
+	return &expiry
+}
+
+// cachingCookieJar is an in-memory jar seeded from the credential cache that
+// writes every cookie the server sets for the base URL back to the cache, so
+// a session established by one command is reused by the next.
+type cachingCookieJar struct {
+	http.CookieJar
+	baseURL *url.URL
+	// restored is set when the jar was seeded with a cached session.
+	restored bool
+}
+
+// cliCookieJar returns the cookie jar for requests to baseURL.
+func cliCookieJar(baseURL string) http.CookieJar {
+	jar, _ := cookiejar.New(nil)
+	u, err := url.Parse(strings.TrimSpace(baseURL))
+	if err != nil || u.Host == "" {
+		return jar
+	}
+	cached := &cachingCookieJar{CookieJar: jar, baseURL: u}
+	if entry := cachedServerCredential(baseURL); entry != nil && len(entry.Cookies) > 0 {
+		cookies := make([]*http.Cookie, 0, len(entry.Cookies))
+		for _, item := range entry.Cookies {
+			cookie := &http.Cookie{Name: item.Name, Value: item.Value, Path: item.Path, Domain: item.Domain, Secure: item.Secure, HttpOnly: item.HttpOnly}
+			if item.Expires != nil {
+				cookie.Expires = *item.Expires
+			}
+			cookies = append(cookies, cookie)
+		}
+		jar.SetCookies(u, cookies)
+		cached.restored = true
+	}
+	return cached
+}
+
+func (j *cachingCookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.CookieJar.SetCookies(u, cookies)
+	if len(cookies) == 0 || !strings.EqualFold(u.Host, j.baseURL.Host) {
+		return
+	}
+	baseURL := j.baseURL.String()
+	_ = updateCredentialCache(func(cache *credentialCache) bool {
+		entry := cache.entry(baseURL)
+		entry.Cookies = mergeCachedCookies(entry.Cookies, cookies, time.Now())
+		entry.UpdatedAt = time.Now().UTC()
+		if entry.empty() {
+			delete(cache.Servers, credentialKey(baseURL))
+		}
+		return true
+	})
+}
+
+// mergeCachedCookies replaces cookies by name and path; deleted or expired
+// cookies are dropped, and Max-Age is turned into an absolute expiry.
+func mergeCachedCookies(existing []*cachedCookie, updates []*http.Cookie, now time.Time) []*cachedCookie {
+	result := make([]*cachedCookie, 0, len(existing)+len(updates))
+	replaced := map[string]bool{}
+	for _, cookie := range updates {
+		replaced[cookie.Name+"\x00"+cookie.Path] = true
+	}
+	for _, item := range existing {
+		if !replaced[item.Name+"\x00"+item.Path] {
+			result = append(result, item)
+		}
+	}
+	for _, cookie := range updates {
+		item := &cachedCookie{Name: cookie.Name, Value: cookie.Value, Path: cookie.Path, Domain: cookie.Domain, Secure: cookie.Secure, HttpOnly: cookie.HttpOnly}
+		switch {
+		case cookie.MaxAge < 0:
+			continue
+		case cookie.MaxAge > 0:
+			expires := now.Add(time.Duration(cookie.MaxAge) * time.Second).UTC()
+			item.Expires = &expires
+		case !cookie.Expires.IsZero():
+			expires := cookie.Expires.UTC()
+			item.Expires = &expires
+		}
+		if item.Expires != nil && !item.Expires.After(now) {
+			continue
+		}
+		if item.Value == "" {
+			continue
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
+// resumeCachedSession verifies credentials restored from the cache before
+// any login flow runs. A cached token the server rejects is dropped so later
+// commands stop sending it. When the session came from a cached login, that
+// login is rerun once the credentials are rejected or about to expire.
+func resumeCachedSession(ctx context.Context, client *sdk.HTTPClient) bool {
+	if client == nil {
+		return false
+	}
+	baseURL := client.BaseURL()
+	entry := cachedServerCredential(baseURL)
+	if entry == nil {
+		return false
+	}
+	renew := entry.Login != nil && entry.expiresBefore(time.Now().Add(credentialRenewWindow))
+	if !renew && resumeCachedCredentials(ctx, client, baseURL, entry.Token) {
+		return true
+	}
+	if entry.Login == nil {
+		return false
+	}
+	if err := authenticateWithOOB(ctx, client, entry.Login.OOB, entry.Login.OAuthConfig, entry.Login.Scopes); err != nil {
+		return false
+	}
+	_, err := client.AuthMe(ctx)
+	return err == nil
+}
+
+func resumeCachedCredentials(ctx context.Context, client *sdk.HTTPClient, baseURL, token string) bool {
+	if jar, ok := client.HTTPClient().Jar.(*cachingCookieJar); ok && jar.restored {
+		if _, err := client.AuthMe(ctx); err == nil {
+			return true
+		}
+	}
+	if token == "" {
+		return false
+	}
+	if err := tryTokenAuth(ctx, client, token); err == nil {
+		return true
+	}
+	_ = updateCredentialCache(func(cache *credentialCache) bool {
+		entry := cache.Servers[credentialKey(baseURL)]
+		if entry == nil || entry.Token != token {
+			return false
+		}
+		entry.Token, entry.TokenExpiry = "", nil
+		if entry.empty() {
+			delete(cache.Servers, credentialKey(baseURL))
+		}
+		return true
+	})
+	return false
+}
@@ -0,0 +1,237 @@
+package agently
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func useTestCredentialCache(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	t.Setenv(credentialCacheEnv, path)
+	return path
+}
+
+func testJWT(exp time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"sub":"dev","exp":%d}`, exp.Unix())))
+	return "eyJhbGciOiJub25lIn0." + payload + ".sig"
+}
+
+func TestCLICookieJar_PersistsSession(t *testing.T) {
+	path := useTestCredentialCache(t)
+	var seen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cookie, err := r.Cookie(defaultSessionCookieName); err == nil {
+			seen = append(seen, cookie.Value)
+		}
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: defaultSessionCookieName, Value: "s-1", Path: "/", MaxAge: 3600, HttpOnly: true})
+		case "/logout":
+			http.SetCookie(w, &http.Cookie{Name: defaultSessionCookieName, Value: "", Path: "/", MaxAge: -1})
+		}
+	}))
+	defer server.Close()
+
+	first := &http.Client{Jar: cliCookieJar(server.URL)}
+	resp, err := first.Get(server.URL + "/login")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// A later command starts with the cached session.
+	jar := cliCookieJar(server.URL + "/")
+	assert.True(t, jar.(*cachingCookieJar).restored)
+	second := &http.Client{Jar: jar}
+	resp, err = second.Get(server.URL + "/v1/api/auth/me")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, []string{"s-1"}, seen)
+
+	entry := cachedServerCredential(server.URL)
+	require.NotNil(t, entry)
+	require.Len(t, entry.Cookies, 1)
+	require.NotNil(t, entry.Cookies[0].Expires)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), *entry.Cookies[0].Expires, time.Minute)
+
+	resp, err = second.Get(server.URL + "/logout")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Nil(t, cachedServerCredential(server.URL))
+	assert.False(t, cliCookieJar(server.URL).(*cachingCookieJar).restored)
+}
+
+func TestMergeCachedCookies(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Minute)
+	existing := []*cachedCookie{
+		{Name: "agently_session", Value: "old", Path: "/"},
+		{Name: "csrf", Value: "c1", Path: "/"},
+		{Name: "pref", Value: "dark", Path: "/"},
+	}
+	merged := mergeCachedCookies(existing, []*http.Cookie{
+		{Name: "agently_session", Value: "new", Path: "/", MaxAge: 60},
+		{Name: "csrf", Path: "/", MaxAge: -1},
+		{Name: "stale", Value: "x", Path: "/", Expires: past},
+	}, now)
+	require.Len(t, merged, 2)
+	assert.Equal(t, "pref", merged[0].Name)
+	assert.Equal(t, "new", merged[1].Value)
+	assert.Equal(t, now.Add(time.Minute), *merged[1].Expires)
+}
+
+func TestServerToken(t *testing.T) {
+	useTestCredentialCache(t)
+	t.Setenv("AGENTLY_TOKEN", "")
+	baseURL := "http://localhost:8080"
+	assert.Equal(t, "", serverToken(baseURL, ""))
+
+	token := testJWT(time.Now().Add(time.Hour))
+	require.NoError(t, storeCachedToken(baseURL+"/", token))
+	assert.Equal(t, token, serverToken("HTTP://LOCALHOST:8080", ""))
+	assert.Equal(t, "flag", serverToken(baseURL, "flag"))
+	t.Setenv("AGENTLY_TOKEN", "env")
+	assert.Equal(t, "env", serverToken(baseURL, ""))
+	t.Setenv("AGENTLY_TOKEN", "")
+
+	// Expired tokens are never sent.
+	require.NoError(t, storeCachedToken(baseURL, testJWT(time.Now().Add(-time.Minute))))
+	assert.Equal(t, "", serverToken(baseURL, ""))
+
+	removed, err := forgetServerCredential(baseURL)
+	require.NoError(t, err)
+	assert.True(t, removed)
+	removed, err = forgetServerCredential(baseURL)
+	require.NoError(t, err)
+	assert.False(t, removed)
+}
+
+func TestTokenExpiry(t *testing.T) {
+	exp := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	got := tokenExpiry(testJWT(exp))
+	require.NotNil(t, got)
+	assert.Equal(t, exp, *got)
+	assert.Nil(t, tokenExpiry("opaque-token"))
+	assert.Nil(t, tokenExpiry("a.!!!.c"))
+}
+
+func TestPrintCredentialTable(t *testing.T) {
+	now := time.Now()
+	hour := now.Add(time.Hour)
+	expired := now.Add(-time.Hour)
+	cache := &credentialCache{Servers: map[string]*serverCredential{
+		"https://agently.example.com": {Token: "t", TokenExpiry: &expired, UpdatedAt: now},
+		"http://localhost:8080": {
+			Cookies:   []*cachedCookie{{Name: defaultSessionCookieName, Value: "s", Expires: &hour}},
+			Token:     "opaque",
+			UpdatedAt: now,
+		},
+	}}
+	views := credentialViews(cache, now)
+	require.Len(t, views, 2)
+	assert.Equal(t, "http://localhost:8080", views[0].Server)
+	assert.True(t, views[0].Session)
+	assert.True(t, views[1].TokenExpired)
+
+	var out bytes.Buffer
+	printCredentialTable(&out, views)
+	assert.Regexp(t, `SERVER +SESSION +TOKEN +UPDATED`, out.String())
+	assert.Regexp(t, `http://localhost:8080 +until \d{4}-\d\d-\d\d \d\d:\d\d +no expiry`, out.String())
+	assert.Regexp(t, `https://agently.example.com +- +expired`, out.String())
+}
+
+func TestLockCredentialCache(t *testing.T) {
+	path := useTestCredentialCache(t)
+	unlock, err := lockCredentialCache(path)
+	require.NoError(t, err)
+	acquired := make(chan struct{})
+	go func() {
+		release, err := lockCredentialCache(path)
+		assert.NoError(t, err)
+		close(acquired)
+		if release != nil {
+			release()
+		}
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("lock acquired while held")
+	case <-time.After(100 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("lock not acquired after release")
+	}
+
+	// A lock left behind by a crashed process is broken.
+	require.NoError(t, os.WriteFile(path+".lock", nil, 0o600))
+	stale := time.Now().Add(-time.Minute)
+	require.NoError(t, os.Chtimes(path+".lock", stale, stale))
+	unlock, err = lockCredentialCache(path)
+	require.NoError(t, err)
+	unlock()
+	_, err = os.Stat(path + ".lock")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestUpdateCredentialCache_Concurrent(t *testing.T) {
+	path := useTestCredentialCache(t)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, storeCachedToken(fmt.Sprintf("http://localhost:%d", 8000+i), "token"))
+		}(i)
+	}
+	wg.Wait()
+	cache, err := loadCredentialCache(path)
+	require.NoError(t, err)
+	assert.Len(t, cache.Servers, 20)
+	leftovers, err := filepath.Glob(filepath.Join(filepath.Dir(path), "*.tmp"))
+	require.NoError(t, err)
+	assert.Empty(t, leftovers)
+}
+
+func TestCachedServerCredential_Login(t *testing.T) {
+	useTestCredentialCache(t)
+	baseURL := "http://localhost:8080"
+	login := &cachedLogin{OOB: "scy://secrets/dev.enc|blowfish://default", Scopes: []string{"openid"}}
+	require.NoError(t, storeCachedLogin(baseURL, login))
+	require.NoError(t, storeCachedToken(baseURL, testJWT(time.Now().Add(-time.Minute))))
+
+	// The login outlives the expired token, so the session can be renewed.
+	entry := cachedServerCredential(baseURL)
+	require.NotNil(t, entry)
+	assert.Equal(t, login, entry.Login)
+	assert.Empty(t, entry.Token)
+	assert.True(t, entry.expiresBefore(time.Now()))
+}
+
+func TestServerCredential_ExpiresBefore(t *testing.T) {
+	now := time.Now()
+	soon, later := now.Add(time.Minute), now.Add(time.Hour)
+	window := now.Add(credentialRenewWindow)
+	assert.True(t, (&serverCredential{}).expiresBefore(window))
+	assert.True(t, (&serverCredential{Token: "t", TokenExpiry: &soon}).expiresBefore(window))
+	assert.False(t, (&serverCredential{Token: "t", TokenExpiry: &later}).expiresBefore(window))
+	assert.False(t, (&serverCredential{Token: "opaque"}).expiresBefore(window))
+	assert.True(t, (&serverCredential{Cookies: []*cachedCookie{{Name: defaultSessionCookieName, Value: "s", Expires: &soon}}}).expiresBefore(window))
+	assert.False(t, (&serverCredential{Cookies: []*cachedCookie{{Name: defaultSessionCookieName, Value: "s"}}}).expiresBefore(window))
+}
@@ -11,6 +11,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 
 	coreplan "github.com/viant/agently-core/protocol/agent/execution"
 )
@@ -48,11 +49,19 @@ func readPromptLine(ctx context.Context, reader *bufio.Reader) (line string, can
 	}
 }
 
-func awaitCoreElicitation(ctx context.Context, req *coreplan.Elicitation) (*coreplan.ElicitResult, error) {
+// elicitationPromptMu keeps concurrent batch queries from interleaving
+// prompts on the shared terminal.
+var elicitationPromptMu sync.Mutex
+
+// awaitCoreElicitation prompts on w, which machine readable output modes
+// point at stderr to keep stdout parseable, and reads the answer from stdin.
+func awaitCoreElicitation(ctx context.Context, w io.Writer, req *coreplan.Elicitation) (*coreplan.ElicitResult, error) {
 	if req == nil || req.IsEmpty() {
 		return &coreplan.ElicitResult{Action: coreplan.ElicitResultActionAccept}, nil
 	}
-	return awaitFormElicitation(ctx, os.Stdout, os.Stdin, req)
+	elicitationPromptMu.Lock()
+	defer elicitationPromptMu.Unlock()
+	return awaitFormElicitation(ctx, w, os.Stdin, req)
 }
 
 func awaitFormElicitation(ctx context.Context, w io.Writer, r io.Reader, req *coreplan.Elicitation) (*coreplan.ElicitResult, error) {
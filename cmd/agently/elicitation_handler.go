@@ -0,0 +1,121 @@
+package agently
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	coreplan "github.com/viant/agently-core/protocol/agent/execution"
+	"github.com/viant/agently-core/sdk"
+)
+
+// elicitationHandlerRequest is written to the handler's stdin, one request per
+// invocation.
+type elicitationHandlerRequest struct {
+	ConversationID  string                `json:"conversationId"`
+	ElicitationID   string                `json:"elicitationId"`
+	Message         string                `json:"message,omitempty"`
+	RequestedSchema interface{}           `json:"requestedSchema,omitempty"`
+	Approval        *cliApprovalMeta      `json:"approval,omitempty"`
+	Elicitation     *coreplan.Elicitation `json:"elicitation"`
+}
+
+// elicitationHandlerResponse is read from the handler's stdout.
+type elicitationHandlerResponse struct {
+	Action  string                 `json:"action"`
+	Payload map[string]interface{} `json:"payload,omitempty"`
+	Reason  string                 `json:"reason,omitempty"`
+}
+
+func newElicitationHandlerRequest(conversationID string, req *coreplan.Elicitation) *elicitationHandlerRequest {
+	return &elicitationHandlerRequest{
+		ConversationID:  conversationID,
+		ElicitationID:   strings.TrimSpace(req.ElicitationId),
+		Message:         strings.TrimSpace(req.Message),
+		RequestedSchema: req.RequestedSchema,
+		Approval:        parseToolApprovalMeta(req),
+		Elicitation:     req,
+	}
+}
+
+// runElicitationHandler runs command through the shell with the pending
+// elicitation as JSON on stdin and decodes its decision from stdout. The
+// handler's stderr is passed through so policy scripts can log.
+func runElicitationHandler(ctx context.Context, command, conversationID string, req *coreplan.Elicitation) (*elicitationHandlerResponse, error) {
+	input, err := json.Marshal(newElicitationHandlerRequest(conversationID, req))
+	if err != nil {
+		return nil, fmt.Errorf("encode elicitation for handler: %w", err)
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"AGENTLY_CONVERSATION_ID="+conversationID,
+		"AGENTLY_ELICITATION_ID="+strings.TrimSpace(req.ElicitationId),
+	)
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("elicitation handler %q failed: %w", command, err)
+	}
+	return parseElicitationHandlerResponse(output)
+}
+
+func parseElicitationHandlerResponse(output []byte) (*elicitationHandlerResponse, error) {
+	output = bytes.TrimSpace(output)
+	if len(output) == 0 {
+		return nil, fmt.Errorf("elicitation handler returned no decision")
+	}
+	var resp elicitationHandlerResponse
+	if err := json.Unmarshal(output, &resp); err != nil {
+		return nil, fmt.Errorf("elicitation handler returned invalid JSON: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(resp.Action)) {
+	case "accept", "approve":
+		resp.Action = "accept"
+	case "decline", "deny", "reject":
+		resp.Action = "decline"
+	case "cancel":
+		resp.Action = "cancel"
+	default:
+		return nil, fmt.Errorf("elicitation handler returned unsupported action %q (want accept, decline or cancel)", resp.Action)
+	}
+	return &resp, nil
+}
+
+// resolveWithHandler answers req with the decision of the handler command:
+// accept with its payload, decline, or cancel. The reason given with a decline
+// or cancel is logged to stderr and sent as the payload's "reason".
+func resolveWithHandler(ctx context.Context, client *sdk.HTTPClient, handler, conversationID string, req *coreplan.Elicitation, seedPayload *map[string]interface{}) error {
+	resp, err := runElicitationHandler(ctx, handler, conversationID, req)
+	if err != nil {
+		return err
+	}
+	if resp.Action != "accept" {
+		input := &sdk.ResolveElicitationInput{
+			ConversationID: conversationID,
+			ElicitationID:  req.ElicitationId,
+			Action:         resp.Action,
+		}
+		if reason := strings.TrimSpace(resp.Reason); reason != "" {
+			fmt.Fprintf(os.Stderr, "[elicitation] handler chose %s for %s: %s\n", resp.Action, req.ElicitationId, reason)
+			input.Payload = map[string]interface{}{"reason": reason}
+		}
+		return client.ResolveElicitation(ctx, input)
+	}
+	if seedPayload != nil {
+		mergePayload(seedPayload, resp.Payload)
+	}
+	return client.ResolveElicitation(ctx, &sdk.ResolveElicitationInput{
+		ConversationID: conversationID,
+		ElicitationID:  req.ElicitationId,
+		Action:         "accept",
+		Payload:        resp.Payload,
+	})
+}
@@ -0,0 +1,174 @@
+package agently
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	coreplan "github.com/viant/agently-core/protocol/agent/execution"
+	"github.com/viant/agently-core/sdk"
+	mcpproto "github.com/viant/mcp-protocol/schema"
+)
+
+// policyHandlerScript approves system/os tool calls, denies exec calls that
+// mention rm and accepts anything else with a fixed payload. It records the
+// request it received next to itself.
+const policyHandlerScript = `#!/bin/sh
+input=$(cat)
+printf '%s' "$input" > "$(dirname "$0")/last-request.json"
+case "$input" in
+  *'"toolName":"system/os'*) echo '{"action":"accept","payload":{"editedFields":{"names":["HOME"]}}}' ;;
+  *'"toolName":"system/exec'*'rm '*) echo '{"action":"decline","reason":"rm is not allowed"}' ;;
+  *) echo '{"action":"accept","payload":{"color":"blue"}}' ;;
+esac
+`
+
+func writeHandlerScript(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "handler.sh")
+	if err := os.WriteFile(path, []byte(content), 0o755); err != nil {
+		t.Fatalf("write handler: %v", err)
+	}
+	return path
+}
+
+func toolApprovalElicitation(t *testing.T, toolName, message string) *coreplan.Elicitation {
+	t.Helper()
+	meta, err := json.Marshal(map[string]interface{}{"type": "tool_approval", "toolName": toolName, "title": "Approve " + toolName})
+	if err != nil {
+		t.Fatalf("marshal meta: %v", err)
+	}
+	req := &coreplan.Elicitation{
+		ElicitRequestParams: mcpproto.ElicitRequestParams{
+			Message: message,
+			RequestedSchema: mcpproto.ElicitRequestParamsRequestedSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"_approvalMeta": map[string]interface{}{"type": "string", "const": string(meta)},
+				},
+			},
+		},
+	}
+	req.ElicitationId = "elic-" + toolName
+	return req
+}
+
+func TestRunElicitationHandlerPolicy(t *testing.T) {
+	script := writeHandlerScript(t, policyHandlerScript)
+	ctx := context.Background()
+
+	resp, err := runElicitationHandler(ctx, script, "conv-1", toolApprovalElicitation(t, "system/os", "read HOME"))
+	if err != nil {
+		t.Fatalf("runElicitationHandler(system/os) error = %v", err)
+	}
+	if resp.Action != "accept" {
+		t.Fatalf("system/os action = %q, want accept", resp.Action)
+	}
+	if _, ok := resp.Payload["editedFields"]; !ok {
+		t.Fatalf("system/os payload = %#v, want editedFields", resp.Payload)
+	}
+
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(script), "last-request.json"))
+	if err != nil {
+		t.Fatalf("read recorded request: %v", err)
+	}
+	var got elicitationHandlerRequest
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("decode recorded request: %v", err)
+	}
+	if got.ConversationID != "conv-1" || got.ElicitationID != "elic-system/os" {
+		t.Fatalf("request ids = %q/%q, want conv-1/elic-system/os", got.ConversationID, got.ElicitationID)
+	}
+	if got.Approval == nil || got.Approval.ToolName != "system/os" {
+		t.Fatalf("request approval = %#v, want toolName system/os", got.Approval)
+	}
+
+	resp, err = runElicitationHandler(ctx, script, "conv-1", toolApprovalElicitation(t, "system/exec", "run: rm -rf /tmp/x"))
+	if err != nil {
+		t.Fatalf("runElicitationHandler(system/exec) error = %v", err)
+	}
+	if resp.Action != "decline" || resp.Reason != "rm is not allowed" {
+		t.Fatalf("system/exec response = %#v, want decline with reason", resp)
+	}
+
+	req := testElicitation()
+	req.ElicitationId = "elic-form"
+	resp, err = runElicitationHandler(ctx, script, "conv-1", req)
+	if err != nil {
+		t.Fatalf("runElicitationHandler(form) error = %v", err)
+	}
+	if resp.Action != "accept" || resp.Payload["color"] != "blue" {
+		t.Fatalf("form response = %#v, want accept with color blue", resp)
+	}
+}
+
+func TestResolveWithHandlerForwardsReason(t *testing.T) {
+	script := writeHandlerScript(t, policyHandlerScript)
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	client, err := sdk.NewHTTP(server.URL+"/", sdk.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatalf("sdk.NewHTTP() error = %v", err)
+	}
+
+	req := toolApprovalElicitation(t, "system/exec", "run: rm -rf /tmp/x")
+	if err := resolveWithHandler(context.Background(), client, script, "conv-1", req, nil); err != nil {
+		t.Fatalf("resolveWithHandler() error = %v", err)
+	}
+	if len(bodies) != 1 || !strings.Contains(bodies[0], `"decline"`) || !strings.Contains(bodies[0], `"reason":"rm is not allowed"`) {
+		t.Fatalf("resolve requests = %q, want a decline carrying the reason", bodies)
+	}
+}
+
+func TestRunElicitationHandlerFailure(t *testing.T) {
+	req := testElicitation()
+	req.ElicitationId = "elic-1"
+	_, err := runElicitationHandler(context.Background(), "echo boom >&2; exit 3", "conv-1", req)
+	if err == nil || !strings.Contains(err.Error(), "exit status 3") {
+		t.Fatalf("error = %v, want exit status 3", err)
+	}
+}
+
+func TestParseElicitationHandlerResponse(t *testing.T) {
+	testCases := []struct {
+		name   string
+		output string
+		action string
+		errMsg string
+	}{
+		{name: "accept", output: `{"action":"accept","payload":{"a":1}}`, action: "accept"},
+		{name: "deny alias", output: "{\"action\":\"Deny\"}\n", action: "decline"},
+		{name: "cancel", output: `{"action":"cancel"}`, action: "cancel"},
+		{name: "empty", output: "  \n", errMsg: "no decision"},
+		{name: "not json", output: "yes", errMsg: "invalid JSON"},
+		{name: "unknown action", output: `{"action":"maybe"}`, errMsg: `unsupported action "maybe"`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := parseElicitationHandlerResponse([]byte(tc.output))
+			if tc.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+					t.Fatalf("error = %v, want %q", err, tc.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseElicitationHandlerResponse() error = %v", err)
+			}
+			if resp.Action != tc.action {
+				t.Fatalf("action = %q, want %q", resp.Action, tc.action)
+			}
+		})
+	}
+}
@@ -8,11 +8,14 @@ import (
 	"net/http"
 	"os/exec"
 	"path/filepath"
+	goruntime "runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/viant/agently-core/sdk"
+	agentlyrt "github.com/viant/agently/runtime"
 )
 
 type authProviderInfo struct {
@@ -23,6 +26,10 @@ type authProviderInfo struct {
 }
 
 type instanceInfo struct {
+	// Name and PID come from the instance registry; Name is empty for
+	// servers found by process scanning.
+	Name          string
+	PID           int
 	BaseURL       string
 	Port          int
 	WorkspaceRoot string
@@ -35,53 +42,161 @@ type instanceInfo struct {
 	ElicitationTimeout time.Duration
 }
 
+// detectLocalInstances finds running servers. The instance registry written
+// by `agently serve` is consulted first; process scanning (/proc on Linux, ps
+// and lsof elsewhere) only runs when it has no reachable entry, which covers
+// servers started by releases that did not register themselves.
 func detectLocalInstances(ctx context.Context) ([]*instanceInfo, error) {
-	ports := map[int]struct{}{}
-	for _, res := range processesFromPS() {
+	entries, _, err := agentlyrt.ListInstances(agentlyrt.InstanceRegistryDir(), false)
+	if err == nil && len(entries) > 0 {
+		out := make([]*instanceInfo, 0, len(entries))
+		for _, entry := range entries {
+			inst := probeInstance(ctx, entry.BaseURL)
+			if inst == nil {
+				continue
+			}
+			inst.Name = entry.Name
+			inst.PID = entry.PID
+			if inst.WorkspaceRoot == "" {
+				inst.WorkspaceRoot = entry.Workspace
+			}
+			out = append(out, inst)
+		}
+		if len(out) > 0 {
+			return out, nil
+		}
+	}
+	ports := map[int]int{}
+	for _, res := range scanServeProcesses() {
 		if res.Port > 0 {
-			ports[res.Port] = struct{}{}
+			ports[res.Port] = res.PID
 			continue
 		}
-		for _, port := range portsFromPID(res.PID) {
-			ports[port] = struct{}{}
+		for _, port := range listeningPorts(res.PID) {
+			ports[port] = res.PID
 		}
 	}
 	if len(ports) == 0 {
 		return nil, nil
 	}
-	out := make([]*instanceInfo, 0, len(ports))
+	sortedPorts := make([]int, 0, len(ports))
 	for port := range ports {
 		if port <= 0 || port > 65535 {
 			continue
 		}
-		baseURL := fmt.Sprintf("http://localhost:%d", port)
-		meta, ok := fetchWorkspaceMetadata(ctx, baseURL)
-		providers, providersErr := fetchAuthProviders(ctx, baseURL)
-		if !ok && providersErr != nil {
+		sortedPorts = append(sortedPorts, port)
+	}
+	sort.Ints(sortedPorts)
+	out := make([]*instanceInfo, 0, len(sortedPorts))
+	for _, port := range sortedPorts {
+		inst := probeInstance(ctx, fmt.Sprintf("http://localhost:%d", port))
+		if inst == nil {
 			continue
 		}
-		if meta == nil {
-			meta = &workspaceMetadata{}
-		}
-		out = append(out, &instanceInfo{
-			BaseURL:            baseURL,
-			Port:               port,
-			WorkspaceRoot:      meta.WorkspaceRoot,
-			DefaultAgent:       meta.DefaultAgent,
-			DefaultModel:       meta.DefaultModel,
-			Models:             meta.Models,
-			Providers:          providers,
-			ElicitationTimeout: meta.ElicitationTimeout,
-		})
+		inst.PID = ports[port]
+		out = append(out, inst)
 	}
 	return out, nil
 }
 
+// probeInstance fetches workspace metadata and auth providers from baseURL and
+// returns nil when neither endpoint answers.
+func probeInstance(ctx context.Context, baseURL string) *instanceInfo {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		return nil
+	}
+	meta, ok := fetchWorkspaceMetadata(ctx, baseURL)
+	providers, providersErr := fetchAuthProviders(ctx, baseURL)
+	if !ok && providersErr != nil {
+		return nil
+	}
+	if meta == nil {
+		meta = &workspaceMetadata{}
+	}
+	return &instanceInfo{
+		BaseURL:            baseURL,
+		Port:               parsePort(baseURL),
+		WorkspaceRoot:      meta.WorkspaceRoot,
+		DefaultAgent:       meta.DefaultAgent,
+		DefaultModel:       meta.DefaultModel,
+		Models:             meta.Models,
+		Providers:          providers,
+		ElicitationTimeout: meta.ElicitationTimeout,
+	}
+}
+
+// selectInstance picks the instance named by --instance. The value matches a
+// registry name first, then a port or PID.
+func selectInstance(instances []*instanceInfo, name string) (*instanceInfo, error) {
+	name = strings.TrimSpace(name)
+	var matches []*instanceInfo
+	for _, inst := range instances {
+		if inst.Name == name {
+			matches = append(matches, inst)
+		}
+	}
+	if len(matches) == 0 {
+		if n, err := strconv.Atoi(name); err == nil && n > 0 {
+			for _, inst := range instances {
+				if inst.Port == n || inst.PID == n {
+					matches = append(matches, inst)
+				}
+			}
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		var names []string
+		for _, inst := range instances {
+			names = append(names, instanceLabel(inst))
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("no agently instance %q: no running instances found", name)
+		}
+		return nil, fmt.Errorf("no agently instance %q (available: %s)", name, strings.Join(names, ", "))
+	}
+	var pids []string
+	for _, inst := range matches {
+		pids = append(pids, strconv.Itoa(inst.PID))
+	}
+	return nil, fmt.Errorf("instance name %q is ambiguous (pids %s); select by pid or port instead", name, strings.Join(pids, ", "))
+}
+
+// instanceLabel is the registry name of inst, or its base URL for servers
+// found by process scanning.
+func instanceLabel(inst *instanceInfo) string {
+	if inst.Name != "" {
+		return inst.Name
+	}
+	return inst.BaseURL
+}
+
 type processInfo struct {
 	PID  int
 	Port int
 }
 
+func scanServeProcesses() []processInfo {
+	if goruntime.GOOS == "linux" {
+		if procs, ok := processesFromProc(procRoot); ok {
+			return procs
+		}
+	}
+	return processesFromPS()
+}
+
+func listeningPorts(pid int) []int {
+	if goruntime.GOOS == "linux" {
+		if ports, ok := portsFromProc(procRoot, pid); ok {
+			return ports
+		}
+	}
+	return portsFromPID(pid)
+}
+
 func processesFromPS() []processInfo {
 	cmd := exec.Command("ps", "-axo", "pid=,args=")
 	out, err := cmd.Output()
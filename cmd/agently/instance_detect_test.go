@@ -4,6 +4,8 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -122,3 +124,81 @@ func TestProcessesFromPS_QuotedPath(t *testing.T) {
 	assert.True(t, isAgentlyServeProcess(args))
 	assert.Equal(t, 9393, parsePortFromArgs(args))
 }
+
+func writeFakeProc(t *testing.T, root string, pid string, cmdline string) string {
+	t.Helper()
+	dir := filepath.Join(root, pid)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "fd"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cmdline"), []byte(cmdline), 0o644))
+	return dir
+}
+
+func TestProcessesFromProc(t *testing.T) {
+	root := t.TempDir()
+	writeFakeProc(t, root, "123", "agently\x00serve\x00-a\x00:9393\x00")
+	writeFakeProc(t, root, "456", "/opt/my agent/agently\x00serve\x00")
+	writeFakeProc(t, root, "789", "/bin/bash\x00-l\x00")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "sys"), 0o755))
+
+	procs, ok := processesFromProc(root)
+	require.True(t, ok)
+	assert.ElementsMatch(t, []processInfo{{PID: 123, Port: 9393}, {PID: 456}}, procs)
+
+	_, ok = processesFromProc(filepath.Join(root, "missing"))
+	assert.False(t, ok)
+}
+
+func TestPortsFromProc(t *testing.T) {
+	root := t.TempDir()
+	dir := writeFakeProc(t, root, "456", "agently\x00serve\x00")
+	require.NoError(t, os.Symlink("socket:[5555]", filepath.Join(dir, "fd", "3")))
+	require.NoError(t, os.Symlink("socket:[6666]", filepath.Join(dir, "fd", "4")))
+	require.NoError(t, os.Symlink("/dev/null", filepath.Join(dir, "fd", "0")))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "net"), 0o755))
+	header := "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"
+	tcp := header +
+		"   0: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 5555 1 0000000000000000 100 0 0 10 0\n" +
+		"   1: 0100007F:1F91 0100007F:D2F0 01 00000000:00000000 00:00000000 00000000  1000        0 6666 1 0000000000000000 20 4 30 10 -1\n" +
+		"   2: 00000000:0016 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 7777 1 0000000000000000 100 0 0 10 0\n"
+	tcp6 := header +
+		"   0: 00000000000000000000000000000000:1F90 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 5555 1 0000000000000000 100 0 0 10 0\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "net", "tcp"), []byte(tcp), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "net", "tcp6"), []byte(tcp6), 0o644))
+
+	ports, ok := portsFromProc(root, 456)
+	require.True(t, ok)
+	// Only the listening socket owned by the process counts, once.
+	assert.Equal(t, []int{8080}, ports)
+
+	_, ok = portsFromProc(root, 999)
+	assert.False(t, ok)
+}
+
+func TestSelectInstance(t *testing.T) {
+	instances := []*instanceInfo{
+		{Name: "support-8080", PID: 100, Port: 8080, BaseURL: "http://localhost:8080"},
+		{Name: "coder-9393", PID: 200, Port: 9393, BaseURL: "http://localhost:9393"},
+		{PID: 300, Port: 9001, BaseURL: "http://localhost:9001"},
+	}
+	inst, err := selectInstance(instances, "coder-9393")
+	require.NoError(t, err)
+	assert.Equal(t, 200, inst.PID)
+
+	inst, err = selectInstance(instances, "9001")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:9001", inst.BaseURL)
+
+	inst, err = selectInstance(instances, "100")
+	require.NoError(t, err)
+	assert.Equal(t, "support-8080", inst.Name)
+
+	_, err = selectInstance(instances, "missing")
+	assert.EqualError(t, err, `no agently instance "missing" (available: support-8080, coder-9393, http://localhost:9001)`)
+
+	dup := append(instances, &instanceInfo{Name: "coder-9393", PID: 201, Port: 9394})
+	_, err = selectInstance(dup, "coder-9393")
+	assert.ErrorContains(t, err, "is ambiguous (pids 200, 201)")
+
+	_, err = selectInstance(nil, "coder")
+	assert.EqualError(t, err, `no agently instance "coder": no running instances found`)
+}
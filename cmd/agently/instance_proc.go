@@ -0,0 +1,113 @@
+package agently
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// procRoot is the procfs mount scanned on Linux when the instance registry is
+// empty. It replaces ps and lsof, which minimal containers often lack.
+const procRoot = "/proc"
+
+// tcpListenState is the st column value of a listening socket in
+// /proc/net/tcp and /proc/net/tcp6.
+const tcpListenState = "0A"
+
+// processesFromProc lists agently serve processes from root/<pid>/cmdline.
+// ok is false when root cannot be read, so callers can fall back to ps.
+func processesFromProc(root string) ([]processInfo, bool) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, false
+	}
+	var procs []processInfo
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid <= 0 || !entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(root, entry.Name(), "cmdline"))
+		if err != nil || len(data) == 0 {
+			continue
+		}
+		args := strings.Split(strings.TrimRight(string(data), "\x00"), "\x00")
+		if !isAgentlyServeProcess(args) {
+			continue
+		}
+		procs = append(procs, processInfo{PID: pid, Port: parsePortFromArgs(args)})
+	}
+	return procs, true
+}
+
+// portsFromProc returns the TCP ports pid listens on by matching its socket
+// file descriptors against the listening sockets of its network namespace.
+func portsFromProc(root string, pid int) ([]int, bool) {
+	if pid <= 0 {
+		return nil, false
+	}
+	pidDir := filepath.Join(root, strconv.Itoa(pid))
+	fds, err := os.ReadDir(filepath.Join(pidDir, "fd"))
+	if err != nil {
+		return nil, false
+	}
+	inodes := map[string]bool{}
+	for _, fd := range fds {
+		target, err := os.Readlink(filepath.Join(pidDir, "fd", fd.Name()))
+		if err != nil {
+			continue
+		}
+		if strings.HasPrefix(target, "socket:[") && strings.HasSuffix(target, "]") {
+			inodes[strings.TrimSuffix(strings.TrimPrefix(target, "socket:["), "]")] = true
+		}
+	}
+	if len(inodes) == 0 {
+		return nil, true
+	}
+	seen := map[int]bool{}
+	var ports []int
+	for _, name := range []string{"tcp", "tcp6"} {
+		data, err := os.ReadFile(filepath.Join(pidDir, "net", name))
+		if err != nil {
+			continue
+		}
+		for _, port := range parseProcNetTCP(string(data), inodes) {
+			if !seen[port] {
+				seen[port] = true
+				ports = append(ports, port)
+			}
+		}
+	}
+	sort.Ints(ports)
+	return ports, true
+}
+
+// parseProcNetTCP returns the local ports of listening sockets in a
+// /proc/net/tcp{,6} table whose inode is in inodes.
+func parseProcNetTCP(table string, inodes map[string]bool) []int {
+	var ports []int
+	scanner := bufio.NewScanner(strings.NewReader(table))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+		if len(fields) < 10 || fields[0] == "sl" {
+			continue
+		}
+		if fields[3] != tcpListenState || !inodes[fields[9]] {
+			continue
+		}
+		idx := strings.LastIndex(fields[1], ":")
+		if idx == -1 {
+			continue
+		}
+		port, err := strconv.ParseUint(fields[1][idx+1:], 16, 16)
+		if err != nil || port == 0 {
+			continue
+		}
+		ports = append(ports, int(port))
+	}
+	return ports
+}
@@ -0,0 +1,68 @@
+package agently
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	agentlyrt "github.com/viant/agently/runtime"
+)
+
+// InstancesCmd lists the servers recorded in the local instance registry.
+// `agently serve` adds an entry on startup and removes it on shutdown; entries
+// left behind by crashed servers are pruned here.
+type InstancesCmd struct {
+	JSON    bool `long:"json" description:"Print result as JSON instead of a table"`
+	NoPrune bool `long:"no-prune" description:"report stale entries without removing them"`
+}
+
+func (c *InstancesCmd) Execute(_ []string) error {
+	dir := agentlyrt.InstanceRegistryDir()
+	live, stale, err := agentlyrt.ListInstances(dir, !c.NoPrune)
+	if err != nil {
+		return fmt.Errorf("read instance registry %s: %w", dir, err)
+	}
+	if c.JSON {
+		if live == nil {
+			live = []agentlyrt.InstanceEntry{}
+		}
+		return printJSON(live)
+	}
+	if len(live) == 0 {
+		fmt.Printf("No running agently instances registered in %s.\n", dir)
+	} else {
+		printInstanceTable(os.Stdout, live)
+	}
+	reportStaleInstances(os.Stderr, stale, !c.NoPrune)
+	return nil
+}
+
+func printInstanceTable(out io.Writer, entries []agentlyrt.InstanceEntry) {
+	w := tabwriter.NewWriter(out, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tPID\tURL\tWORKSPACE\tVERSION\tSTARTED")
+	for _, entry := range entries {
+		started := entry.StartedAt
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\n", entry.Name, entry.PID, entry.BaseURL,
+			firstNonEmpty(entry.Workspace, "-"), firstNonEmpty(entry.Version, "-"), formatConversationTime(&started))
+	}
+	_ = w.Flush()
+}
+
+func reportStaleInstances(out io.Writer, stale []agentlyrt.InstanceEntry, pruned bool) {
+	if len(stale) == 0 {
+		return
+	}
+	verb := "found"
+	if pruned {
+		verb = "pruned"
+	}
+	for _, entry := range stale {
+		pid := "-"
+		if entry.PID > 0 {
+			pid = strconv.Itoa(entry.PID)
+		}
+		fmt.Fprintf(out, "%s stale entry %s (pid %s)\n", verb, entry.Name, pid)
+	}
+}
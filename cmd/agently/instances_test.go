@@ -0,0 +1,32 @@
+package agently
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	agentlyrt "github.com/viant/agently/runtime"
+)
+
+func TestPrintInstanceTable(t *testing.T) {
+	var out bytes.Buffer
+	printInstanceTable(&out, []agentlyrt.InstanceEntry{
+		{Name: "support-8080", PID: 4242, BaseURL: "http://localhost:8080", Workspace: "/srv/support", Version: "1.4.0", StartedAt: time.Now()},
+		{Name: "scratch-9393", PID: 5151, BaseURL: "http://localhost:9393"},
+	})
+	assert.Regexp(t, `NAME +PID +URL +WORKSPACE +VERSION +STARTED`, out.String())
+	assert.Regexp(t, `support-8080 +4242 +http://localhost:8080 +/srv/support +1\.4\.0 +\d{4}-`, out.String())
+	assert.Regexp(t, `scratch-9393 +5151 +http://localhost:9393 +- +- +-`, out.String())
+}
+
+func TestReportStaleInstances(t *testing.T) {
+	stale := []agentlyrt.InstanceEntry{{Name: "old-8080", PID: 77}, {Name: "broken"}}
+	var out bytes.Buffer
+	reportStaleInstances(&out, stale, true)
+	assert.Equal(t, "pruned stale entry old-8080 (pid 77)\npruned stale entry broken (pid -)\n", out.String())
+
+	out.Reset()
+	reportStaleInstances(&out, stale[:1], false)
+	assert.Equal(t, "found stale entry old-8080 (pid 77)\n", out.String())
+}
@@ -0,0 +1,69 @@
+package agently
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/viant/agently/server"
+)
+
+// JWTCmd groups JWT signing key management.
+type JWTCmd struct {
+	Keygen *JWTKeygenCmd `command:"keygen" description:"Generate a JWT signing key pair"`
+	Rotate *JWTRotateCmd `command:"rotate" description:"Rotate the workspace JWT signing key, keeping previous keys valid for a grace period"`
+}
+
+// JWTKeygenCmd writes a PEM key pair.
+type JWTKeygenCmd struct {
+	Private   string `long:"private" description:"private key output path" required:"true"`
+	Public    string `long:"public" description:"public key output path" required:"true"`
+	Algorithm string `long:"alg" description:"key algorithm" choice:"rsa" choice:"ec" choice:"ed25519" default:"rsa"`
+	Bits      int    `long:"bits" description:"rsa key size" default:"2048"`
+	Curve     string `long:"curve" description:"ec curve" choice:"P-256" choice:"P-384" choice:"P-521" default:"P-256"`
+	Overwrite bool   `long:"overwrite" description:"overwrite existing files"`
+}
+
+func (c *JWTKeygenCmd) Execute(_ []string) error {
+	pair, err := server.GenerateJWTKeyPair(&server.JWTKeygenOptions{
+		PrivatePath: c.Private,
+		PublicPath:  c.Public,
+		Algorithm:   c.Algorithm,
+		Bits:        c.Bits,
+		Curve:       c.Curve,
+		Overwrite:   c.Overwrite,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("generated %s jwt key pair\n  private: %s\n  public:  %s\n  kid:     %s\n", pair.Algorithm, pair.PrivatePath, pair.PublicPath, pair.KeyID)
+	return nil
+}
+
+// JWTRotateCmd rotates the key configured under auth.jwt in config.yaml.
+type JWTRotateCmd struct {
+	workspaceRootOption
+	Dir   string        `long:"dir" description:"key directory (default: directory of the current private key, else <workspace>/keys/jwt)"`
+	Bits  int           `long:"bits" description:"rsa key size" default:"2048"`
+	Grace time.Duration `long:"grace" description:"how long previous keys stay valid for verification" default:"24h"`
+}
+
+func (c *JWTRotateCmd) Execute(_ []string) error {
+	result, err := server.RotateJWTKey(&server.JWTRotateOptions{
+		WorkspaceRoot: c.root(),
+		Dir:           c.Dir,
+		Bits:          c.Bits,
+		Grace:         c.Grace,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("new signing key %s\n  private: %s\n  public:  %s\n", result.Key.KeyID, result.Key.PrivatePath, result.Key.PublicPath)
+	for _, record := range result.Retiring {
+		fmt.Printf("retiring %s at %s\n", firstNonEmpty(record.KeyID, record.PublicKey), record.RetiresAt.Local().Format(time.RFC3339))
+	}
+	for _, record := range result.Removed {
+		fmt.Printf("removed expired %s\n", firstNonEmpty(record.KeyID, record.PublicKey))
+	}
+	fmt.Printf("updated %s; restart agently serve to sign with the new key\n", result.ConfigPath)
+	return nil
+}
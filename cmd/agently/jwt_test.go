@@ -0,0 +1,37 @@
+package agently
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/viant/agently/server"
+)
+
+func TestJWTKeygenCmd(t *testing.T) {
+	dir := t.TempDir()
+	cmd := &JWTKeygenCmd{
+		Private:   filepath.Join(dir, "private.pem"),
+		Public:    filepath.Join(dir, "public.pem"),
+		Algorithm: "ed25519",
+	}
+	require.NoError(t, cmd.Execute(nil))
+	assert.FileExists(t, cmd.Public)
+	assert.ErrorContains(t, cmd.Execute(nil), "already exists")
+}
+
+func TestJWTRotateCmd(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "config.yaml"), []byte("auth:\n  enabled: true\n"), 0o644))
+	cmd := &JWTRotateCmd{Bits: 2048, Grace: time.Hour}
+	cmd.Workspace = root
+	require.NoError(t, cmd.Execute(nil))
+	require.NoError(t, cmd.Execute(nil))
+
+	set, err := server.LoadWorkspaceJWKS(root, time.Now())
+	require.NoError(t, err)
+	assert.Len(t, set.Keys, 2, "the previous key stays published during the grace period")
+}
@@ -20,21 +20,23 @@ import (
 // a human-readable format.  When --json is provided without --name the full
 // catalogue is printed as JSON.
 type ListToolsCmd struct {
-	Name    string `short:"n" long:"name" description:"Exact tool name to show full definition"`
-	Service string `short:"s" long:"service" description:"Filter tools by service/prefix namespace"`
-	API     string `long:"api" description:"Server URL (skip local auto-detect)"`
-	JSON    bool   `long:"json" description:"Print result as JSON instead of table/plain text"`
+	Name     string `short:"n" long:"name" description:"Exact tool name to show full definition"`
+	Service  string `short:"s" long:"service" description:"Filter tools by service/prefix namespace"`
+	API      string `long:"api" description:"Server URL (skip local auto-detect)"`
+	Instance string `long:"instance" description:"registered instance name, port or pid to connect to (see agently instances)"`
+	Context  string `long:"context" description:"CLI context supplying unset server and auth flags; spelled --cli-context on query and chat (overrides AGENTLY_CONTEXT; see agently context)"`
+	JSON     bool   `long:"json" description:"Print result as JSON instead of table/plain text"`
 }
 
 func (c *ListToolsCmd) Execute(_ []string) error {
 	ctx := context.Background()
 
-	baseURL, err := resolveToolBaseURL(ctx, strings.TrimSpace(c.API))
+	baseURL, err := resolveToolBaseURL(ctx, strings.TrimSpace(c.API), c.Instance, c.Context)
 	if err != nil {
 		return fmt.Errorf("cannot find agently server: %w", err)
 	}
 
-	httpClient := &http.Client{Jar: cliCookieJar()}
+	httpClient := &http.Client{Jar: cliCookieJar(baseURL)}
 	opts := []sdk.HTTPOption{sdk.WithHTTPClient(httpClient)}
 	client, err := sdk.NewHTTP(baseURL, opts...)
 	if err != nil {
@@ -104,11 +106,27 @@ func (c *ListToolsCmd) printToolDefinition(def *sdk.ToolDefinitionInfo) error {
 	return nil
 }
 
-func resolveToolBaseURL(ctx context.Context, api string) (string, error) {
+func resolveToolBaseURL(ctx context.Context, api, instance, contextName string) (string, error) {
+	if err := (cliConnection{API: &api, Instance: &instance}).apply(contextName); err != nil {
+		return "", err
+	}
 	if strings.TrimSpace(api) != "" {
+		if strings.TrimSpace(instance) != "" {
+			return "", fmt.Errorf("--api and --instance are mutually exclusive")
+		}
 		return strings.TrimSpace(api), nil
 	}
 	instances, err := detectLocalInstances(ctx)
+	if strings.TrimSpace(instance) != "" {
+		if err != nil {
+			return "", err
+		}
+		inst, err := selectInstance(instances, instance)
+		if err != nil {
+			return "", err
+		}
+		return inst.BaseURL, nil
+	}
 	if err == nil {
 		for _, inst := range instances {
 			if strings.TrimSpace(inst.BaseURL) != "" {
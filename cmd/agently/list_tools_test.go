@@ -45,7 +45,7 @@ func TestFilterToolDefinitions_AcceptsCanonicalServiceVariants(t *testing.T) {
 }
 
 func TestListToolsCmd_ResolveBaseURL_UsesAPIOverride(t *testing.T) {
-	got, err := resolveToolBaseURL(context.Background(), "http://example:8080")
+	got, err := resolveToolBaseURL(context.Background(), "http://example:8080", "", "")
 	require.NoError(t, err)
 	require.Equal(t, "http://example:8080", got)
 }
@@ -0,0 +1,22 @@
+package agently
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestMain points the credential cache and CLI config at a scratch directory
+// so tests never read or write the developer's real files.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "agently-cli-test")
+	if err != nil {
+		panic(err)
+	}
+	_ = os.Setenv(credentialCacheEnv, filepath.Join(dir, "credentials.json"))
+	_ = os.Setenv("AGENTLY_CLI_CONFIG", filepath.Join(dir, "cli.yaml"))
+	_ = os.Unsetenv(cliContextEnv)
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
@@ -2,6 +2,7 @@ package agently
 
 // MCPCmd groups MCP-oriented subcommands.
 type MCPCmd struct {
-	List *MCPListCmd `command:"list" description:"List tools as MCP-style capabilities"`
-	Run  *MCPRunCmd  `command:"run" description:"Run a tool by exact name with JSON arguments"`
+	List    *MCPListCmd    `command:"list" description:"List tools as MCP-style capabilities"`
+	Run     *MCPRunCmd     `command:"run" description:"Run a tool by exact name with JSON arguments"`
+	Codegen *MCPCodegenCmd `command:"codegen" description:"Generate a typed Go client package from tool definitions"`
 }
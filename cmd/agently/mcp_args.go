@@ -0,0 +1,566 @@
+package agently
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/viant/agently-core/sdk"
+	agentlyrt "github.com/viant/agently/runtime"
+)
+
+// toolInputSchema returns the definition's input schema with the top-level
+// required list folded in, as the server reports it separately.
+func toolInputSchema(def *sdk.ToolDefinitionInfo) map[string]interface{} {
+	if def == nil {
+		return nil
+	}
+	return agentlyrt.ToolInputSchema(*def)
+}
+
+// toolArgError is a field-level validation failure; Path is empty for the
+// arguments object itself.
+type toolArgError struct {
+	Path    string
+	Message string
+}
+
+func (e toolArgError) String() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// toolArgsError reports every invalid field of a tool call at once.
+type toolArgsError struct {
+	Tool   string
+	Errors []toolArgError
+}
+
+func (e *toolArgsError) Error() string {
+	lines := make([]string, 0, len(e.Errors)+1)
+	lines = append(lines, fmt.Sprintf("invalid arguments for %s:", e.Tool))
+	for _, item := range e.Errors {
+		lines = append(lines, "  - "+item.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// validateToolArgs checks args against a JSON schema map and returns the
+// failures sorted by path. It covers the subset tool schemas use: types,
+// required, enum, additionalProperties, items, and numeric/string/array bounds.
+func validateToolArgs(schema map[string]interface{}, args map[string]interface{}) []toolArgError {
+	var errs []toolArgError
+	validateSchemaValue(schema, args, "", &errs)
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Path < errs[j].Path })
+	return errs
+}
+
+func validateSchemaValue(schema map[string]interface{}, value interface{}, path string, errs *[]toolArgError) {
+	if len(schema) == 0 {
+		return
+	}
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, toolArgError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	types := schemaTypes(schema)
+	if value == nil {
+		if len(types) > 0 && !containsString(types, "null") {
+			fail("expected %s, got null", strings.Join(types, " or "))
+		}
+		return
+	}
+	if len(types) > 0 {
+		matched := false
+		for _, typ := range types {
+			if valueHasType(value, typ) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("expected %s, got %s", strings.Join(types, " or "), jsonTypeName(value))
+			return
+		}
+	}
+	if options, ok := schema["enum"].([]interface{}); ok && len(options) > 0 {
+		found := false
+		for _, option := range options {
+			if jsonEqual(option, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("must be one of %s", formatEnum(options))
+		}
+	}
+	switch actual := value.(type) {
+	case map[string]interface{}:
+		props, _ := schema["properties"].(map[string]interface{})
+		for name := range requiredSet(schema["required"]) {
+			if _, ok := actual[name]; !ok {
+				*errs = append(*errs, toolArgError{Path: joinArgPath(path, name), Message: "is required"})
+			}
+		}
+		for _, key := range sortedKeys(actual) {
+			if propSchema, ok := props[key].(map[string]interface{}); ok {
+				validateSchemaValue(propSchema, actual[key], joinArgPath(path, key), errs)
+				continue
+			}
+			switch extra := schema["additionalProperties"].(type) {
+			case bool:
+				if !extra {
+					*errs = append(*errs, toolArgError{Path: joinArgPath(path, key), Message: "is not a known property" + knownPropertiesHint(props)})
+				}
+			case map[string]interface{}:
+				validateSchemaValue(extra, actual[key], joinArgPath(path, key), errs)
+			}
+		}
+	case []interface{}:
+		if minItems, ok := schemaNumber(schema["minItems"]); ok && float64(len(actual)) < minItems {
+			fail("must have at least %v item(s)", minItems)
+		}
+		if maxItems, ok := schemaNumber(schema["maxItems"]); ok && float64(len(actual)) > maxItems {
+			fail("must have at most %v item(s)", maxItems)
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range actual {
+				validateSchemaValue(items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	case string:
+		length := float64(len([]rune(actual)))
+		if minLength, ok := schemaNumber(schema["minLength"]); ok && length < minLength {
+			fail("must be at least %v character(s)", minLength)
+		}
+		if maxLength, ok := schemaNumber(schema["maxLength"]); ok && length > maxLength {
+			fail("must be at most %v character(s)", maxLength)
+		}
+		if pattern, ok := schema["pattern"].(string); ok && pattern != "" {
+			if expr, err := regexp.Compile(pattern); err == nil && !expr.MatchString(actual) {
+				fail("must match pattern %s", pattern)
+			}
+		}
+	default:
+		if number, ok := schemaNumber(value); ok {
+			if minimum, ok := schemaNumber(schema["minimum"]); ok && number < minimum {
+				fail("must be >= %v", minimum)
+			}
+			if maximum, ok := schemaNumber(schema["maximum"]); ok && number > maximum {
+				fail("must be <= %v", maximum)
+			}
+		}
+	}
+}
+
+func schemaTypes(schema map[string]interface{}) []string {
+	var types []string
+	switch actual := schema["type"].(type) {
+	case string:
+		if typ := strings.ToLower(strings.TrimSpace(actual)); typ != "" {
+			types = append(types, typ)
+		}
+	case []interface{}:
+		for _, item := range actual {
+			if typ := strings.ToLower(strings.TrimSpace(asString(item))); typ != "" {
+				types = append(types, typ)
+			}
+		}
+	case []string:
+		for _, item := range actual {
+			if typ := strings.ToLower(strings.TrimSpace(item)); typ != "" {
+				types = append(types, typ)
+			}
+		}
+	}
+	if len(types) == 0 {
+		if _, ok := schema["properties"].(map[string]interface{}); ok {
+			types = append(types, "object")
+		} else if schema["items"] != nil {
+			types = append(types, "array")
+		}
+	}
+	return types
+}
+
+func valueHasType(value interface{}, typ string) bool {
+	switch typ {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := schemaNumber(value)
+		return ok
+	case "integer":
+		number, ok := schemaNumber(value)
+		return ok && number == math.Trunc(number)
+	case "null":
+		return value == nil
+	}
+	return true
+}
+
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	}
+	if number, ok := schemaNumber(value); ok {
+		if number == math.Trunc(number) {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func schemaNumber(value interface{}) (float64, bool) {
+	switch actual := value.(type) {
+	case float64:
+		return actual, true
+	case float32:
+		return float64(actual), true
+	case int:
+		return float64(actual), true
+	case int64:
+		return float64(actual), true
+	case int32:
+		return float64(actual), true
+	case json.Number:
+		number, err := actual.Float64()
+		return number, err == nil
+	}
+	return 0, false
+}
+
+func jsonEqual(left, right interface{}) bool {
+	if l, ok := schemaNumber(left); ok {
+		r, ok := schemaNumber(right)
+		return ok && l == r
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+func formatEnum(options []interface{}) string {
+	parts := make([]string, 0, len(options))
+	for _, option := range options {
+		parts = append(parts, formatArgValue(option))
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+func formatArgValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return asString(value)
+	}
+	return string(data)
+}
+
+func knownPropertiesHint(props map[string]interface{}) string {
+	if len(props) == 0 {
+		return ""
+	}
+	return " (known: " + strings.Join(sortedKeys(props), ", ") + ")"
+}
+
+func joinArgPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaAtPath returns the schema of the property at a dotted path, or nil
+// when the schema does not describe it.
+func schemaAtPath(schema map[string]interface{}, path []string) map[string]interface{} {
+	current := schema
+	for _, segment := range path {
+		props, _ := current["properties"].(map[string]interface{})
+		next, ok := props[segment].(map[string]interface{})
+		if !ok {
+			if extra, ok := current["additionalProperties"].(map[string]interface{}); ok {
+				next = extra
+			} else {
+				return nil
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+// applyArgAssignments applies --set key=value pairs to args. Keys are dotted
+// paths into nested objects; values are coerced to the type the schema
+// declares at that path, or parsed as JSON with a plain string fallback.
+func applyArgAssignments(schema, args map[string]interface{}, assignments []string) error {
+	for _, assignment := range assignments {
+		key, raw, ok := strings.Cut(assignment, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return fmt.Errorf("invalid --set %q: expected key=value", assignment)
+		}
+		path := strings.Split(key, ".")
+		for _, segment := range path {
+			if strings.TrimSpace(segment) == "" {
+				return fmt.Errorf("invalid --set %q: empty path segment", assignment)
+			}
+		}
+		value, err := coerceArgValue(schemaAtPath(schema, path), raw)
+		if err != nil {
+			return fmt.Errorf("invalid --set %s: %w", key, err)
+		}
+		if err := setArgPath(args, path, value); err != nil {
+			return fmt.Errorf("invalid --set %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func setArgPath(args map[string]interface{}, path []string, value interface{}) error {
+	current := args
+	for i, segment := range path[:len(path)-1] {
+		next, exists := current[segment]
+		if !exists || next == nil {
+			child := map[string]interface{}{}
+			current[segment] = child
+			current = child
+			continue
+		}
+		child, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s is not an object (found %s)", strings.Join(path[:i+1], "."), jsonTypeName(next))
+		}
+		current = child
+	}
+	current[path[len(path)-1]] = value
+	return nil
+}
+
+// coerceArgValue converts raw text to the schema's type. Arrays accept JSON
+// or a comma-separated list; objects require JSON.
+func coerceArgValue(schema map[string]interface{}, raw string) (interface{}, error) {
+	types := schemaTypes(schema)
+	if len(types) == 0 {
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(raw), &decoded); err == nil {
+			return decoded, nil
+		}
+		return raw, nil
+	}
+	var lastErr error
+	for _, typ := range types {
+		value, err := coerceArgType(schema, typ, raw)
+		if err == nil {
+			return value, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+func coerceArgType(schema map[string]interface{}, typ, raw string) (interface{}, error) {
+	text := strings.TrimSpace(raw)
+	switch typ {
+	case "string":
+		return raw, nil
+	case "integer":
+		value, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", raw)
+		}
+		return value, nil
+	case "number":
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", raw)
+		}
+		return value, nil
+	case "boolean":
+		value, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", raw)
+		}
+		return value, nil
+	case "null":
+		if text == "null" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%q is not null", raw)
+	case "array":
+		if strings.HasPrefix(text, "[") {
+			var items []interface{}
+			if err := json.Unmarshal([]byte(text), &items); err != nil {
+				return nil, fmt.Errorf("invalid JSON array: %w", err)
+			}
+			return items, nil
+		}
+		items := []interface{}{}
+		if text == "" {
+			return items, nil
+		}
+		itemSchema, _ := schema["items"].(map[string]interface{})
+		for _, part := range strings.Split(text, ",") {
+			item, err := coerceArgValue(itemSchema, strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case "object":
+		var object map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &object); err != nil {
+			return nil, fmt.Errorf("expected a JSON object")
+		}
+		return object, nil
+	}
+	return raw, nil
+}
+
+// promptToolArgs asks for every property of an object schema, recursing
+// into nested objects. Enter keeps the current value or the schema default;
+// "-" clears an optional value. Values already in args are offered as the
+// default, so --args and --set can pre-fill the form.
+func promptToolArgs(ctx context.Context, w io.Writer, reader *bufio.Reader, schema, args map[string]interface{}, prefix string) error {
+	props, _ := schema["properties"].(map[string]interface{})
+	required := requiredSet(schema["required"])
+	keys := sortedKeys(props)
+	sort.SliceStable(keys, func(i, j int) bool {
+		_, left := required[keys[i]]
+		_, right := required[keys[j]]
+		return left && !right
+	})
+	for _, key := range keys {
+		propSchema, _ := props[key].(map[string]interface{})
+		path := joinArgPath(prefix, key)
+		_, isRequired := required[key]
+		if nested, ok := propSchema["properties"].(map[string]interface{}); ok && len(nested) > 0 {
+			child, _ := args[key].(map[string]interface{})
+			if child == nil {
+				child = map[string]interface{}{}
+			}
+			fmt.Fprintf(w, "%s:\n", path)
+			if err := promptToolArgs(ctx, w, reader, propSchema, child, path); err != nil {
+				return err
+			}
+			if len(child) > 0 || isRequired {
+				args[key] = child
+			}
+			continue
+		}
+		if err := promptToolArg(ctx, w, reader, propSchema, args, key, path, isRequired); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func promptToolArg(ctx context.Context, w io.Writer, reader *bufio.Reader, schema, args map[string]interface{}, key, path string, required bool) error {
+	current, hasCurrent := args[key]
+	if !hasCurrent {
+		current, hasCurrent = schema["default"]
+	}
+	options, _ := schema["enum"].([]interface{})
+	if description, _ := schema["description"].(string); strings.TrimSpace(description) != "" {
+		fmt.Fprintf(w, "  %s\n", strings.TrimSpace(description))
+	}
+	for i, option := range options {
+		fmt.Fprintf(w, "  %d) %s\n", i+1, formatArgValue(option))
+	}
+	for {
+		fmt.Fprint(w, toolArgPromptLabel(schema, path, required, current, hasCurrent))
+		line, eof, err := readPromptLine(ctx, reader)
+		if err != nil {
+			return err
+		}
+		if eof && line == "" {
+			return fmt.Errorf("input closed while reading %s", path)
+		}
+		switch {
+		case line == "" && hasCurrent:
+			args[key] = current
+			return nil
+		case line == "" && !required:
+			return nil
+		case line == "":
+			fmt.Fprintf(w, "%s is required\n", path)
+			continue
+		case line == "-" && !required:
+			delete(args, key)
+			return nil
+		}
+		if index, err := strconv.Atoi(line); err == nil && len(options) > 0 && index >= 1 && index <= len(options) {
+			args[key] = options[index-1]
+			return nil
+		}
+		value, err := coerceArgValue(schema, line)
+		if err == nil {
+			var errs []toolArgError
+			validateSchemaValue(schema, value, path, &errs)
+			if len(errs) > 0 {
+				err = fmt.Errorf("%s", errs[0].Message)
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(w, "invalid %s: %v\n", path, err)
+			continue
+		}
+		args[key] = value
+		return nil
+	}
+}
+
+func toolArgPromptLabel(schema map[string]interface{}, path string, required bool, current interface{}, hasCurrent bool) string {
+	details := strings.Join(schemaTypes(schema), "|")
+	if required {
+		if details != "" {
+			details += ", "
+		}
+		details += "required"
+	}
+	label := path
+	if details != "" {
+		label += " (" + details + ")"
+	}
+	if hasCurrent {
+		label += " [" + formatArgValue(current) + "]"
+	}
+	return label + ": "
+}
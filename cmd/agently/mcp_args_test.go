@@ -0,0 +1,126 @@
+package agently
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/viant/agently-core/sdk"
+)
+
+func testToolDefinition() *sdk.ToolDefinitionInfo {
+	return &sdk.ToolDefinitionInfo{
+		Name: "system/exec:execute",
+		Parameters: map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": false,
+			"properties": map[string]interface{}{
+				"commands":  map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "minItems": 1},
+				"timeoutMs": map[string]interface{}{"type": "integer", "minimum": 0, "default": 1000},
+				"shell":     map[string]interface{}{"type": "string", "enum": []interface{}{"bash", "sh"}},
+				"env": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"path":  map[string]interface{}{"type": "string"},
+						"debug": map[string]interface{}{"type": "boolean"},
+					},
+				},
+			},
+		},
+		Required: []string{"commands"},
+	}
+}
+
+func TestValidateToolArgs(t *testing.T) {
+	schema := toolInputSchema(testToolDefinition())
+
+	errs := validateToolArgs(schema, map[string]interface{}{"commands": []interface{}{"ls"}, "timeoutMs": float64(10)})
+	assert.Empty(t, errs)
+
+	errs = validateToolArgs(schema, map[string]interface{}{
+		"timeoutMs": -1.5,
+		"shell":     "zsh",
+		"env":       map[string]interface{}{"debug": "yes"},
+		"cwd":       "/tmp",
+	})
+	var got []string
+	for _, item := range errs {
+		got = append(got, item.String())
+	}
+	assert.Equal(t, []string{
+		"commands: is required",
+		"cwd: is not a known property (known: commands, env, shell, timeoutMs)",
+		"env.debug: expected boolean, got string",
+		"shell: must be one of [\"bash\", \"sh\"]",
+		"timeoutMs: expected integer, got number",
+	}, got)
+
+	errs = validateToolArgs(schema, map[string]interface{}{"commands": []interface{}{1.0}})
+	require.Len(t, errs, 1)
+	assert.Equal(t, "commands[0]: expected string, got integer", errs[0].String())
+
+	err := &toolArgsError{Tool: "system/exec:execute", Errors: errs}
+	assert.Equal(t, "invalid arguments for system/exec:execute:\n  - commands[0]: expected string, got integer", err.Error())
+}
+
+func TestApplyArgAssignments(t *testing.T) {
+	schema := toolInputSchema(testToolDefinition())
+	args := map[string]interface{}{"env": map[string]interface{}{"path": "/bin"}}
+
+	err := applyArgAssignments(schema, args, []string{"commands=ls -l,pwd", "timeoutMs=250", "env.debug=true", "extra.level=3"})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"ls -l", "pwd"}, args["commands"])
+	assert.EqualValues(t, 250, args["timeoutMs"])
+	assert.Equal(t, map[string]interface{}{"path": "/bin", "debug": true}, args["env"])
+	assert.Equal(t, map[string]interface{}{"level": float64(3)}, args["extra"])
+
+	assert.ErrorContains(t, applyArgAssignments(schema, args, []string{"timeoutMs=soon"}), `invalid --set timeoutMs: "soon" is not an integer`)
+	assert.ErrorContains(t, applyArgAssignments(schema, args, []string{"commands.first=x"}), "commands is not an object (found array)")
+	assert.ErrorContains(t, applyArgAssignments(schema, args, []string{"novalue"}), "expected key=value")
+}
+
+func TestPromptToolArgs(t *testing.T) {
+	schema := toolInputSchema(testToolDefinition())
+	args := map[string]interface{}{"shell": "sh"}
+	input := strings.Join([]string{
+		"",       // commands: required, re-prompted
+		"ls,pwd", // commands
+		"",       // env.debug: optional, skipped
+		"/usr",   // env.path
+		"3",      // shell: out of range index, treated as a value and rejected
+		"1",      // shell: first enum option
+		"-5",     // timeoutMs: below minimum
+		"",       // timeoutMs: schema default
+	}, "\n") + "\n"
+	var out bytes.Buffer
+
+	err := promptToolArgs(context.Background(), &out, bufio.NewReader(strings.NewReader(input)), schema, args, "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"commands":  []interface{}{"ls", "pwd"},
+		"env":       map[string]interface{}{"path": "/usr"},
+		"shell":     "bash",
+		"timeoutMs": 1000,
+	}, args)
+	assert.Contains(t, out.String(), "commands (array, required): ")
+	assert.Contains(t, out.String(), "commands is required")
+	assert.Contains(t, out.String(), `shell (string) ["sh"]: `)
+	assert.Contains(t, out.String(), "invalid shell: must be one of")
+	assert.Contains(t, out.String(), "invalid timeoutMs: must be >= 0")
+	assert.Empty(t, validateToolArgs(schema, args))
+
+	err = promptToolArgs(context.Background(), &out, bufio.NewReader(strings.NewReader("")), schema, map[string]interface{}{}, "")
+	assert.ErrorContains(t, err, "input closed while reading commands")
+}
+
+func TestMatchToolDefinition(t *testing.T) {
+	defs := []sdk.ToolDefinitionInfo{{Name: "system/os:getEnv"}, *testToolDefinition()}
+	def := matchToolDefinition(defs, "system/exec/execute")
+	require.NotNil(t, def)
+	assert.Equal(t, "system/exec:execute", def.Name)
+	assert.Nil(t, matchToolDefinition(defs, "system/exec:missing"))
+}
@@ -0,0 +1,314 @@
+package agently
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/viant/agently-core/sdk"
+)
+
+// MCPCodegenCmd writes a Go package with typed wrappers for tool definitions.
+type MCPCodegenCmd struct {
+	apiClientOptions
+	Service string   `short:"s" long:"service" description:"Generate tools of this service/prefix namespace"`
+	Names   []string `short:"n" long:"name" description:"Generate only this tool (repeatable)"`
+	Out     string   `short:"o" long:"out" description:"Output directory of the generated package" required:"true"`
+	Package string   `short:"p" long:"package" description:"Go package name (default: last segment of --service or the --out directory)"`
+	From    string   `long:"from" description:"Read tool definitions from a saved 'mcp list --json' file instead of the server"`
+}
+
+// codegenFileName is the single file written into --out; regenerating
+// overwrites it.
+const codegenFileName = "tools.go"
+
+func (c *MCPCodegenCmd) Execute(_ []string) error {
+	ctx := context.Background()
+	defs, err := c.definitions(ctx)
+	if err != nil {
+		return err
+	}
+	defs = filterToolDefinitions(defs, strings.TrimSpace(c.Service))
+	if len(c.Names) > 0 {
+		var selected []sdk.ToolDefinitionInfo
+		for _, name := range c.Names {
+			def := matchToolDefinition(defs, name)
+			if def == nil {
+				return fmt.Errorf("tool %q not found", name)
+			}
+			selected = append(selected, *def)
+		}
+		defs = selected
+	}
+	if len(defs) == 0 {
+		return fmt.Errorf("no tools matched; check --service and --name")
+	}
+
+	pkg := strings.TrimSpace(c.Package)
+	if pkg == "" {
+		pkg = defaultCodegenPackage(c.Service, c.Out)
+	}
+	if !token.IsIdentifier(pkg) || token.IsKeyword(pkg) {
+		return fmt.Errorf("invalid package name %q; set --package", pkg)
+	}
+	source, err := generateToolClient(pkg, defs)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.Out, 0o755); err != nil {
+		return fmt.Errorf("create %s: %w", c.Out, err)
+	}
+	target := filepath.Join(c.Out, codegenFileName)
+	if err := os.WriteFile(target, source, 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", target, err)
+	}
+	fmt.Printf("wrote %s (package %s, %d tool(s))\n", target, pkg, len(defs))
+	return nil
+}
+
+func (c *MCPCodegenCmd) definitions(ctx context.Context) ([]sdk.ToolDefinitionInfo, error) {
+	if from := strings.TrimSpace(c.From); from != "" {
+		return readToolDefinitionsFile(from)
+	}
+	client, err := c.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defs, err := client.ListToolDefinitions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list mcp tools: %w", err)
+	}
+	return defs, nil
+}
+
+// readToolDefinitionsFile loads the output of `mcp list --json`.
+func readToolDefinitionsFile(path string) ([]sdk.ToolDefinitionInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	var views []mcpToolView
+	if err := json.Unmarshal(data, &views); err != nil {
+		return nil, fmt.Errorf("decode %s: expected the output of 'mcp list --json': %w", path, err)
+	}
+	defs := make([]sdk.ToolDefinitionInfo, 0, len(views))
+	for _, view := range views {
+		defs = append(defs, sdk.ToolDefinitionInfo{
+			Name:         view.Name,
+			Description:  view.Description,
+			Parameters:   view.InputSchema,
+			Required:     view.Required,
+			OutputSchema: view.OutputSchema,
+			Cacheable:    view.Cacheable,
+		})
+	}
+	return defs, nil
+}
+
+func defaultCodegenPackage(service, out string) string {
+	base := strings.TrimSpace(service)
+	if base == "" {
+		base = filepath.Base(filepath.Clean(out))
+	}
+	if idx := strings.LastIndexAny(base, "/:."); idx != -1 {
+		base = base[idx+1:]
+	}
+	var builder strings.Builder
+	for _, r := range strings.ToLower(base) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+// toolCodegen accumulates the type declarations of one generated package.
+type toolCodegen struct {
+	decls strings.Builder
+	used  map[string]bool
+}
+
+// generateToolClient renders a gofmt'ed Go package with an input struct, an
+// output type and a typed Client method per tool.
+func generateToolClient(pkg string, defs []sdk.ToolDefinitionInfo) ([]byte, error) {
+	defs = append([]sdk.ToolDefinitionInfo(nil), defs...)
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	gen := &toolCodegen{used: map[string]bool{"Client": true, "New": true}}
+	qualify := len(toolServices(defs)) > 1
+
+	var methods strings.Builder
+	for _, def := range defs {
+		method := gen.typeName(toolMethodName(def.Name, qualify))
+		input := gen.structType(method+"Input", toolInputSchema(&def), fmt.Sprintf("is the input of the %s tool.", def.Name))
+		output := ""
+		if props, _ := def.OutputSchema["properties"].(map[string]interface{}); len(props) > 0 {
+			output = "*" + gen.structType(method+"Output", def.OutputSchema, fmt.Sprintf("is the output of the %s tool.", def.Name))
+		} else if goType := gen.goType(def.OutputSchema, method+"Output"); goType != "interface{}" {
+			output = goType
+		}
+		writeToolMethod(&methods, def, method, input, output)
+	}
+
+	var src strings.Builder
+	src.WriteString("// Code generated by agently mcp codegen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "// Package %s calls %s tools through an Agently server.\n", pkg, strings.Join(toolServices(defs), ", "))
+	fmt.Fprintf(&src, "package %s\n\n", pkg)
+	src.WriteString("import (\n\t\"context\"\n\t\"encoding/json\"\n\t\"fmt\"\n\n\t\"github.com/viant/agently-core/sdk\"\n)\n\n")
+	src.WriteString("// Client wraps an Agently SDK client with typed tool calls.\ntype Client struct {\n\tclient *sdk.HTTPClient\n}\n\n")
+	src.WriteString("// New returns a Client that executes tools through client.\nfunc New(client *sdk.HTTPClient) *Client {\n\treturn &Client{client: client}\n}\n\n")
+	src.WriteString(gen.decls.String())
+	src.WriteString(methods.String())
+	src.WriteString(`func (c *Client) execute(ctx context.Context, name string, input interface{}) (string, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return "", fmt.Errorf("encode %s input: %w", name, err)
+	}
+	var args map[string]interface{}
+	if err := json.Unmarshal(data, &args); err != nil {
+		return "", fmt.Errorf("encode %s input: %w", name, err)
+	}
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	return c.client.ExecuteTool(ctx, name, args)
+}
+`)
+	formatted, err := format.Source([]byte(src.String()))
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return formatted, nil
+}
+
+func writeToolMethod(w *strings.Builder, def sdk.ToolDefinitionInfo, method, input, output string) {
+	execName := executableToolName(def.Name)
+	fmt.Fprintf(w, "// %s calls the %s tool.\n", method, def.Name)
+	writeDocLines(w, def.Description, "")
+	if output == "" {
+		fmt.Fprintf(w, "func (c *Client) %s(ctx context.Context, input *%s) (string, error) {\n", method, input)
+		fmt.Fprintf(w, "\treturn c.execute(ctx, %q, input)\n}\n\n", execName)
+		return
+	}
+	fmt.Fprintf(w, "func (c *Client) %s(ctx context.Context, input *%s) (%s, error) {\n", method, input, output)
+	fmt.Fprintf(w, "\tvar output %s\n", output)
+	fmt.Fprintf(w, "\tresult, err := c.execute(ctx, %q, input)\n", execName)
+	w.WriteString("\tif err != nil {\n\t\treturn output, err\n\t}\n")
+	w.WriteString("\tif err := json.Unmarshal([]byte(result), &output); err != nil {\n")
+	fmt.Fprintf(w, "\t\treturn output, fmt.Errorf(\"decode %%s output: %%w\", %q, err)\n\t}\n", def.Name)
+	w.WriteString("\treturn output, nil\n}\n\n")
+}
+
+// writeDocLines appends text as comment lines, separated from the preceding
+// summary by an empty comment line.
+func writeDocLines(w *strings.Builder, text, indent string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	fmt.Fprintf(w, "%s//\n", indent)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			fmt.Fprintf(w, "%s//\n", indent)
+			continue
+		}
+		fmt.Fprintf(w, "%s// %s\n", indent, line)
+	}
+}
+
+// goType returns the Go type of schema, declaring named structs for objects
+// with properties. name is the type name to use for such a struct.
+func (g *toolCodegen) goType(schema interface{}, name string) string {
+	return schemaGoType(schema, name, func(name string, schema map[string]interface{}) string {
+		return "*" + g.structType(name, schema, "")
+	})
+}
+
+// structType declares a struct for an object schema and returns its name.
+// Optional properties get omitempty so unset fields are not sent.
+func (g *toolCodegen) structType(name string, schema map[string]interface{}, doc string) string {
+	name = g.typeName(name)
+	props, _ := schema["properties"].(map[string]interface{})
+	required := requiredSet(schema["required"])
+
+	var body strings.Builder
+	fieldNames := map[string]bool{}
+	for _, key := range sortedKeys(props) {
+		prop, _ := props[key].(map[string]interface{})
+		field := uniqueName(goExportedName(key), fieldNames)
+		fieldType := g.goType(prop, name+field)
+		tag := key
+		if _, ok := required[key]; !ok {
+			tag += ",omitempty"
+		}
+		description, _ := prop["description"].(string)
+		if options, ok := prop["enum"].([]interface{}); ok && len(options) > 0 {
+			description = strings.TrimSpace(strings.TrimSpace(description) + " One of: " + strings.Trim(formatEnum(options), "[]") + ".")
+		}
+		if description = strings.TrimSpace(description); description != "" {
+			for _, line := range strings.Split(description, "\n") {
+				if line = strings.TrimSpace(line); line == "" {
+					body.WriteString("\t//\n")
+					continue
+				}
+				fmt.Fprintf(&body, "\t// %s\n", line)
+			}
+		}
+		fmt.Fprintf(&body, "\t%s %s `json:\"%s\"`\n", field, fieldType, tag)
+	}
+
+	if doc == "" {
+		doc = "is a nested object of the tool schema."
+	}
+	fmt.Fprintf(&g.decls, "// %s %s\n", name, doc)
+	if description, _ := schema["description"].(string); strings.TrimSpace(description) != "" {
+		writeDocLines(&g.decls, description, "")
+	}
+	fmt.Fprintf(&g.decls, "type %s struct {\n%s}\n\n", name, body.String())
+	return name
+}
+
+func (g *toolCodegen) typeName(name string) string {
+	return uniqueName(name, g.used)
+}
+
+func uniqueName(name string, used map[string]bool) string {
+	candidate := name
+	for i := 2; used[candidate]; i++ {
+		candidate = fmt.Sprintf("%s%d", name, i)
+	}
+	used[candidate] = true
+	return candidate
+}
+
+// toolMethodName names the wrapper after the tool's method, prefixed with
+// its service when the package spans several services.
+func toolMethodName(name string, qualify bool) string {
+	service := toolServiceNamespace(name)
+	method := strings.TrimLeft(strings.TrimPrefix(strings.TrimSpace(name), service), ":./")
+	if method == "" || qualify {
+		return goExportedName(name)
+	}
+	return goExportedName(method)
+}
+
+func toolServices(defs []sdk.ToolDefinitionInfo) []string {
+	seen := map[string]bool{}
+	var services []string
+	for _, def := range defs {
+		service := toolServiceNamespace(def.Name)
+		if !seen[service] {
+			seen[service] = true
+			services = append(services, service)
+		}
+	}
+	sort.Strings(services)
+	return services
+}
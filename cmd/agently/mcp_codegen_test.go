@@ -0,0 +1,122 @@
+package agently
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/viant/agently-core/sdk"
+)
+
+func codegenTestDefinitions() []sdk.ToolDefinitionInfo {
+	exec := *testToolDefinition()
+	exec.Description = "Execute shell commands.\n\nCommands run in order."
+	exec.OutputSchema = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"stdout": map[string]interface{}{"type": "string"},
+			"status": map[string]interface{}{"type": "integer"},
+			"commands": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"input":  map[string]interface{}{"type": "string"},
+						"output": map[string]interface{}{"type": "string"},
+					},
+				},
+			},
+		},
+	}
+	return []sdk.ToolDefinitionInfo{
+		exec,
+		{
+			Name:       "system/exec:start",
+			Parameters: map[string]interface{}{"type": "object", "properties": map[string]interface{}{"sessionId": map[string]interface{}{"type": "string"}}},
+		},
+	}
+}
+
+// typeCheckGenerated compiles the generated package against its imports,
+// including the SDK it calls.
+func typeCheckGenerated(t *testing.T, pkg string, source []byte) {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "tools.go", source, parser.ParseComments)
+	require.NoError(t, err)
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check(pkg, fset, []*ast.File{file}, nil)
+	require.NoError(t, err, string(source))
+}
+
+func TestGenerateToolClient(t *testing.T) {
+	source, err := generateToolClient("exec", codegenTestDefinitions())
+	require.NoError(t, err)
+	typeCheckGenerated(t, "exec", source)
+
+	code := string(source)
+	assert.Contains(t, code, "// Code generated by agently mcp codegen. DO NOT EDIT.")
+	assert.Contains(t, code, "package exec")
+	assert.Contains(t, code, "// Package exec calls system/exec tools through an Agently server.")
+	assert.Regexp(t, "Commands +\\[\\]string +`json:\"commands\"`", code)
+	assert.Regexp(t, "TimeoutMs +int +`json:\"timeoutMs,omitempty\"`", code)
+	assert.Contains(t, code, "// One of: \"bash\", \"sh\".")
+	assert.Regexp(t, "Env +\\*ExecuteInputEnv +`json:\"env,omitempty\"`", code)
+	assert.Regexp(t, "Commands +\\[\\]\\*ExecuteOutputCommandsItem +`json:\"commands,omitempty\"`", code)
+	assert.Contains(t, code, "func (c *Client) Execute(ctx context.Context, input *ExecuteInput) (*ExecuteOutput, error) {")
+	assert.Contains(t, code, `result, err := c.execute(ctx, "system/exec/execute", input)`)
+	assert.Contains(t, code, "// Execute calls the system/exec:execute tool.\n//\n// Execute shell commands.\n//\n// Commands run in order.\n")
+	assert.Contains(t, code, "func (c *Client) Start(ctx context.Context, input *StartInput) (string, error) {")
+	assert.Contains(t, code, "SessionID string `json:\"sessionId,omitempty\"`")
+}
+
+func TestGenerateToolClient_QualifiesMethodsAcrossServices(t *testing.T) {
+	defs := append(codegenTestDefinitions(), sdk.ToolDefinitionInfo{Name: "system/os:getEnv"})
+	source, err := generateToolClient("tools", defs)
+	require.NoError(t, err)
+	typeCheckGenerated(t, "tools", source)
+	code := string(source)
+	assert.Contains(t, code, "// Package tools calls system/exec, system/os tools through an Agently server.")
+	assert.Contains(t, code, "func (c *Client) SystemExecExecute(")
+	assert.Contains(t, code, "func (c *Client) SystemOsGetEnv(ctx context.Context, input *SystemOsGetEnvInput) (string, error) {")
+}
+
+func TestReadToolDefinitionsFile(t *testing.T) {
+	var views []mcpToolView
+	for _, def := range codegenTestDefinitions() {
+		views = append(views, toolViewFromDefinition(def, false))
+	}
+	data, err := json.Marshal(views)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "tools.json")
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+
+	defs, err := readToolDefinitionsFile(path)
+	require.NoError(t, err)
+	require.Len(t, defs, 2)
+	assert.Equal(t, "system/exec:execute", defs[0].Name)
+	assert.Equal(t, []string{"commands"}, defs[0].Required)
+	assert.NotEmpty(t, defs[0].OutputSchema)
+}
+
+func TestGoExportedName(t *testing.T) {
+	assert.Equal(t, "TimeoutMs", goExportedName("timeoutMs"))
+	assert.Equal(t, "SessionID", goExportedName("sessionId"))
+	assert.Equal(t, "GetEnvURL", goExportedName("getEnvURL"))
+	assert.Equal(t, "HTTPServerName", goExportedName("HTTPServer_name"))
+	assert.Equal(t, "Field2fa", goExportedName("2fa"))
+}
+
+func TestDefaultCodegenPackage(t *testing.T) {
+	assert.Equal(t, "exec", defaultCodegenPackage("system/exec", "./gen"))
+	assert.Equal(t, "gen", defaultCodegenPackage("", "./gen/"))
+	assert.Equal(t, "myclient", defaultCodegenPackage("", "internal/my-client"))
+}
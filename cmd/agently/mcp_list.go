@@ -5,20 +5,24 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
-	"strconv"
 	"strings"
 	"unicode"
 
 	toolschema "github.com/viant/agently-core/protocol/tool/schema"
 	"github.com/viant/agently-core/sdk"
+	agentlyrt "github.com/viant/agently/runtime"
 )
 
 type MCPListCmd struct {
 	Name         string `short:"n" long:"name" description:"Exact tool name to show full definition"`
 	Service      string `short:"s" long:"service" description:"Filter tools by service/prefix namespace"`
 	API          string `long:"api" description:"Server URL (skip local auto-detect)"`
+	Instance     string `long:"instance" description:"registered instance name, port or pid to connect to (see agently instances)"`
+	Context      string `long:"context" description:"CLI context supplying unset server and auth flags; spelled --cli-context on query and chat (overrides AGENTLY_CONTEXT; see agently context)"`
 	Token        string `long:"token" description:"Bearer token for API requests (overrides AGENTLY_TOKEN)"`
 	Session      string `long:"session" description:"Session cookie value for API requests (agently_session)"`
 	OOB          string `long:"oob" description:"Use local scy OAuth2 out-of-band login with the supplied secrets URL"`
@@ -28,6 +32,8 @@ type MCPListCmd struct {
 	Example      bool   `long:"example" description:"Include an example request derived from the tool input schema"`
 	Schema       bool   `long:"schema" description:"Include input schema in plain-text output"`
 	SchemaFormat string `long:"schema-format" choice:"go" choice:"json" default:"go" description:"Schema rendering format for plain-text --schema output"`
+	Format       string `long:"format" choice:"text" choice:"json" choice:"openapi" choice:"jsonschema-bundle" default:"text" description:"Output format: openapi prints an OpenAPI 3.1 document, jsonschema-bundle writes one JSON Schema file per tool into --out"`
+	Out          string `short:"o" long:"out" description:"Output file for --format openapi (default stdout) or directory for --format jsonschema-bundle"`
 }
 
 type mcpToolView struct {
@@ -44,22 +50,22 @@ type mcpToolView struct {
 func (c *MCPListCmd) Execute(_ []string) error {
 	ctx := context.Background()
 
-	baseURL, err := resolveToolBaseURL(ctx, strings.TrimSpace(c.API))
+	baseURL, err := resolveToolBaseURL(ctx, strings.TrimSpace(c.API), c.Instance, c.Context)
 	if err != nil {
 		return fmt.Errorf("cannot find agently server: %w", err)
 	}
 	providers, _ := fetchAuthProviders(ctx, baseURL)
 
-	httpClient := &http.Client{Jar: cliCookieJar()}
+	httpClient := &http.Client{Jar: cliCookieJar(baseURL)}
 	opts := []sdk.HTTPOption{sdk.WithHTTPClient(httpClient)}
-	if token := resolvedToken(c.Token); token != "" {
+	if token := serverToken(baseURL, contextToken(c.Token, c.API, c.Instance, c.Context)); token != "" {
 		opts = append(opts, sdk.WithAuthToken(token))
 	}
 	client, err := sdk.NewHTTP(baseURL, opts...)
 	if err != nil {
 		return fmt.Errorf("sdk client: %w", err)
 	}
-	if err := ensureToolAuth(ctx, client, providers, c.Token, c.Session, c.OOB, c.OAuthCfg, c.OAuthScp); err != nil {
+	if err := ensureToolAuth(ctx, client, providers, c.Token, c.Session, c.OOB, c.OAuthCfg, c.OAuthScp, c.API, c.Instance, c.Context); err != nil {
 		return err
 	}
 
@@ -69,6 +75,15 @@ func (c *MCPListCmd) Execute(_ []string) error {
 	}
 	defs = filterToolDefinitions(defs, strings.TrimSpace(c.Service))
 
+	switch c.Format {
+	case "openapi":
+		return c.exportOpenAPI(selectToolDefinitions(defs, c.Name), baseURL)
+	case "jsonschema-bundle":
+		return c.exportJSONSchemaBundle(selectToolDefinitions(defs, c.Name))
+	case "json":
+		c.JSON = true
+	}
+
 	if c.Name != "" {
 		for _, d := range defs {
 			if d.Name == c.Name {
@@ -131,6 +146,70 @@ func (c *MCPListCmd) Execute(_ []string) error {
 	return nil
 }
 
+func (c *MCPListCmd) exportOpenAPI(defs []sdk.ToolDefinitionInfo, baseURL string) error {
+	doc := agentlyrt.ToolOpenAPIDocument(defs, agentlyrt.ToolOpenAPIInfo{Version: Version(), ServerURL: baseURL})
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode openapi document: %w", err)
+	}
+	out := strings.TrimSpace(c.Out)
+	if out == "" || out == "-" {
+		fmt.Println(string(data))
+		return nil
+	}
+	if err := os.WriteFile(out, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", out, err)
+	}
+	fmt.Printf("wrote %s (%d tool(s))\n", out, len(defs))
+	return nil
+}
+
+func (c *MCPListCmd) exportJSONSchemaBundle(defs []sdk.ToolDefinitionInfo) error {
+	out := strings.TrimSpace(c.Out)
+	if out == "" {
+		return fmt.Errorf("--format jsonschema-bundle requires --out <directory>")
+	}
+	count, err := writeToolSchemaBundle(out, defs)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("wrote %d schema file(s) to %s\n", count, out)
+	return nil
+}
+
+// writeToolSchemaBundle writes one JSON Schema file per tool into dir.
+func writeToolSchemaBundle(dir string, defs []sdk.ToolDefinitionInfo) (int, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, fmt.Errorf("create %s: %w", dir, err)
+	}
+	bundle := agentlyrt.ToolJSONSchemaBundle(defs)
+	for name, doc := range bundle {
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return 0, fmt.Errorf("encode %s: %w", name, err)
+		}
+		target := filepath.Join(dir, name)
+		if err := os.WriteFile(target, append(data, '\n'), 0o644); err != nil {
+			return 0, fmt.Errorf("write %s: %w", target, err)
+		}
+	}
+	return len(bundle), nil
+}
+
+// selectToolDefinitions narrows defs to the exact name when one is given.
+func selectToolDefinitions(defs []sdk.ToolDefinitionInfo, name string) []sdk.ToolDefinitionInfo {
+	if strings.TrimSpace(name) == "" {
+		return defs
+	}
+	var selected []sdk.ToolDefinitionInfo
+	for _, def := range defs {
+		if def.Name == name {
+			selected = append(selected, def)
+		}
+	}
+	return selected
+}
+
 func (c *MCPListCmd) printTool(view mcpToolView) error {
 	if c.JSON {
 		data, _ := json.MarshalIndent(view, "", "  ")
@@ -390,38 +469,47 @@ func schemaToGoShape(schema interface{}) string {
 }
 
 func schemaToGoType(schema interface{}) string {
+	return schemaGoType(schema, "", inlineGoStruct)
+}
+
+// inlineGoStruct renders an object schema as an anonymous struct.
+func inlineGoStruct(_ string, schema map[string]interface{}) string {
+	props, _ := schema["properties"].(map[string]interface{})
+	keys := sortedKeys(props)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, goExportedName(key)+" "+schemaToGoType(props[key]))
+	}
+	return "struct { " + strings.Join(parts, "; ") + " }"
+}
+
+// schemaGoType returns the Go type of a JSON schema. Objects that declare
+// properties are rendered by object: `mcp list` inlines them and `mcp codegen`
+// declares named structs. name is the type name for such an object; nested
+// array items and map values extend it.
+func schemaGoType(schema interface{}, name string, object func(name string, schema map[string]interface{}) string) string {
 	s, ok := schema.(map[string]interface{})
 	if !ok || len(s) == 0 {
 		return "interface{}"
 	}
-	if raw, ok := explicitSchemaExample(s); ok {
-		return goTypeForValue(raw)
-	}
-	typ := strings.ToLower(strings.TrimSpace(asString(s["type"])))
-	if typ == "" {
-		if _, ok := s["properties"].(map[string]interface{}); ok {
-			typ = "object"
-		} else if s["items"] != nil {
-			typ = "array"
+	typ := ""
+	for _, candidate := range schemaTypes(s) {
+		if candidate != "null" {
+			typ = candidate
+			break
 		}
 	}
 	switch typ {
 	case "object":
-		props, _ := s["properties"].(map[string]interface{})
-		if len(props) == 0 {
-			return "map[string]interface{}"
+		if props, _ := s["properties"].(map[string]interface{}); len(props) > 0 {
+			return object(name, s)
 		}
-		keys := sortedKeys(props)
-		parts := make([]string, 0, len(keys))
-		for _, key := range keys {
-			fieldName := goFieldName(key)
-			fieldType := schemaToGoType(props[key])
-			parts = append(parts, fieldName+" "+fieldType)
+		if extra, ok := s["additionalProperties"].(map[string]interface{}); ok && len(extra) > 0 {
+			return "map[string]" + schemaGoType(extra, name+"Value", object)
 		}
-		return "struct { " + strings.Join(parts, "; ") + " }"
+		return "map[string]interface{}"
 	case "array":
-		items := s["items"]
-		return "[]" + schemaToGoType(items)
+		return "[]" + schemaGoType(s["items"], name+"Item", object)
 	case "integer":
 		return "int"
 	case "number":
@@ -430,67 +518,57 @@ func schemaToGoType(schema interface{}) string {
 		return "bool"
 	case "string":
 		return "string"
-	default:
-		return "interface{}"
-	}
-}
-
-func goTypeForValue(value interface{}) string {
-	switch actual := value.(type) {
-	case nil:
-		return "interface{}"
-	case bool:
-		return "bool"
-	case float64:
-		if actual == float64(int64(actual)) {
-			return "int"
-		}
-		return "float64"
-	case float32:
-		return "float64"
-	case int, int8, int16, int32, int64:
-		return "int"
-	case uint, uint8, uint16, uint32, uint64:
-		return "int"
-	case string:
-		return "string"
-	case []interface{}:
-		if len(actual) == 0 {
-			return "[]interface{}"
-		}
-		return "[]" + goTypeForValue(actual[0])
-	case []string:
-		return "[]string"
-	case map[string]interface{}:
-		return schemaToGoType(map[string]interface{}{"type": "object", "properties": actual})
-	default:
-		return "interface{}"
 	}
+	return "interface{}"
 }
 
 var nonIdentifierChars = regexp.MustCompile(`[^a-zA-Z0-9]+`)
 
-func goFieldName(name string) string {
-	name = strings.TrimSpace(name)
-	if name == "" {
-		return "Field"
-	}
-	parts := nonIdentifierChars.Split(name, -1)
+// goInitialisms are name parts rendered in upper case, following Go naming.
+var goInitialisms = map[string]bool{"api": true, "id": true, "ids": true, "json": true, "http": true, "sql": true, "uri": true, "url": true, "uuid": true}
+
+// goExportedName turns a schema or tool name into an exported identifier,
+// keeping camelCase humps (timeoutMs -> TimeoutMs) and upper-casing
+// initialisms (sessionId -> SessionID).
+func goExportedName(name string) string {
 	var builder strings.Builder
-	for _, part := range parts {
-		if part == "" {
-			continue
+	for _, part := range nonIdentifierChars.Split(strings.TrimSpace(name), -1) {
+		for _, hump := range camelHumps(part) {
+			if goInitialisms[strings.ToLower(hump)] {
+				builder.WriteString(strings.ToUpper(hump))
+				continue
+			}
+			runes := []rune(hump)
+			runes[0] = unicode.ToUpper(runes[0])
+			builder.WriteString(string(runes))
 		}
-		runes := []rune(strings.ToLower(part))
-		runes[0] = unicode.ToUpper(runes[0])
-		builder.WriteString(string(runes))
 	}
 	result := builder.String()
 	if result == "" {
-		result = "Field"
+		return "Field"
 	}
-	if _, err := strconv.Atoi(string(result[0])); err == nil {
+	if unicode.IsDigit([]rune(result)[0]) {
 		result = "Field" + result
 	}
 	return result
 }
+
+// camelHumps splits "getEnvURL" into "get", "Env", "URL".
+func camelHumps(value string) []string {
+	runes := []rune(value)
+	var humps []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		lowerToUpper := !unicode.IsUpper(prev) && unicode.IsUpper(cur)
+		acronymEnd := unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if lowerToUpper || acronymEnd {
+			humps = append(humps, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start < len(runes) {
+		humps = append(humps, string(runes[start:]))
+	}
+	return humps
+}
@@ -0,0 +1,38 @@
+package agently
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/viant/agently-core/sdk"
+)
+
+func TestWriteToolSchemaBundle(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "schemas")
+	defs := selectToolDefinitions(codegenTestDefinitions(), "system/exec:execute")
+	require.Len(t, defs, 1)
+
+	count, err := writeToolSchemaBundle(dir, defs)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	data, err := os.ReadFile(filepath.Join(dir, "system_exec_execute.json"))
+	require.NoError(t, err)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, "system/exec:execute", doc["title"])
+	assert.Equal(t, "#/$defs/input", doc["$ref"])
+	input := doc["$defs"].(map[string]interface{})["input"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"commands"}, input["required"])
+}
+
+func TestSelectToolDefinitions(t *testing.T) {
+	defs := []sdk.ToolDefinitionInfo{{Name: "system/os:getEnv"}, {Name: "system/exec:execute"}}
+	assert.Len(t, selectToolDefinitions(defs, ""), 2)
+	assert.Len(t, selectToolDefinitions(defs, "system/os:getEnv"), 1)
+	assert.Empty(t, selectToolDefinitions(defs, "system/os"))
+}
@@ -1,10 +1,12 @@
 package agently
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
 	"sort"
 	"strings"
 
@@ -15,12 +17,18 @@ type MCPRunCmd struct {
 	Name     string `short:"n" long:"name" description:"Exact tool name to execute"`
 	Args     string `short:"a" long:"args" description:"Inline JSON object or @file with tool arguments"`
 	API      string `long:"api" description:"Server URL (skip local auto-detect)"`
+	Instance string `long:"instance" description:"registered instance name, port or pid to connect to (see agently instances)"`
+	Context  string `long:"context" description:"CLI context supplying unset server and auth flags; spelled --cli-context on query and chat (overrides AGENTLY_CONTEXT; see agently context)"`
 	Token    string `long:"token" description:"Bearer token for API requests (overrides AGENTLY_TOKEN)"`
 	Session  string `long:"session" description:"Session cookie value for API requests (agently_session)"`
 	OOB      string `long:"oob" description:"Use local scy OAuth2 out-of-band login with the supplied secrets URL"`
 	OAuthCfg string `long:"oauth-config" description:"Optional scy OAuth config URL override for client-side OOB login"`
 	OAuthScp string `long:"oauth-scopes" description:"comma-separated OAuth scopes for OOB login"`
 	JSON     bool   `long:"json" description:"Print result as JSON envelope instead of plain text"`
+
+	Set         []string `long:"set" description:"set one argument as key=value; dotted keys address nested objects and values follow the schema type (repeatable)"`
+	Interactive bool     `short:"i" long:"interactive" description:"prompt for each argument of the tool input schema"`
+	NoValidate  bool     `long:"no-validate" description:"skip client-side validation of arguments against the tool input schema"`
 }
 
 func (c *MCPRunCmd) Execute(_ []string) error {
@@ -38,29 +46,46 @@ func (c *MCPRunCmd) Execute(_ []string) error {
 		args = map[string]interface{}{}
 	}
 
-	baseURL, err := resolveToolBaseURL(ctx, strings.TrimSpace(c.API))
+	baseURL, err := resolveToolBaseURL(ctx, strings.TrimSpace(c.API), c.Instance, c.Context)
 	if err != nil {
 		return fmt.Errorf("cannot find agently server: %w", err)
 	}
 	providers, _ := fetchAuthProviders(ctx, baseURL)
 
-	httpClient := &http.Client{Jar: cliCookieJar()}
+	httpClient := &http.Client{Jar: cliCookieJar(baseURL)}
 	opts := []sdk.HTTPOption{sdk.WithHTTPClient(httpClient)}
-	if token := resolvedToken(c.Token); token != "" {
+	if token := serverToken(baseURL, contextToken(c.Token, c.API, c.Instance, c.Context)); token != "" {
 		opts = append(opts, sdk.WithAuthToken(token))
 	}
 	client, err := sdk.NewHTTP(baseURL, opts...)
 	if err != nil {
 		return fmt.Errorf("sdk client: %w", err)
 	}
-	if err := ensureToolAuth(ctx, client, providers, c.Token, c.Session, c.OOB, c.OAuthCfg, c.OAuthScp); err != nil {
+	if err := ensureToolAuth(ctx, client, providers, c.Token, c.Session, c.OOB, c.OAuthCfg, c.OAuthScp, c.API, c.Instance, c.Context); err != nil {
 		return err
 	}
 
-	execName, err := resolveExecutableToolName(ctx, client, name)
+	execName, def, err := resolveToolDefinition(ctx, client, name)
 	if err != nil {
 		return err
 	}
+	schema := toolInputSchema(def)
+	if err := applyArgAssignments(schema, args, c.Set); err != nil {
+		return err
+	}
+	if c.Interactive {
+		if def == nil {
+			return fmt.Errorf("--interactive needs the input schema of %s, but the server did not list it", name)
+		}
+		if err := promptToolArgs(ctx, os.Stderr, bufio.NewReader(os.Stdin), schema, args, ""); err != nil {
+			return err
+		}
+	}
+	if !c.NoValidate && def != nil {
+		if errs := validateToolArgs(schema, args); len(errs) > 0 {
+			return &toolArgsError{Tool: execName, Errors: errs}
+		}
+	}
 
 	result, err := client.ExecuteTool(ctx, execName, args)
 	if err != nil {
@@ -83,38 +108,47 @@ func (c *MCPRunCmd) Execute(_ []string) error {
 	return nil
 }
 
-func resolveExecutableToolName(ctx context.Context, client *sdk.HTTPClient, input string) (string, error) {
+// resolveToolDefinition maps the user supplied name onto a listed tool and
+// returns its executable name with its definition. The definition is nil when
+// the server lists no matching tool; the name is then used as given.
+func resolveToolDefinition(ctx context.Context, client *sdk.HTTPClient, input string) (string, *sdk.ToolDefinitionInfo, error) {
 	name := strings.TrimSpace(input)
 	if name == "" {
-		return "", fmt.Errorf("--name is required")
+		return "", nil, fmt.Errorf("--name is required")
 	}
 	defs, err := client.ListToolDefinitions(ctx)
 	if err != nil {
-		return "", fmt.Errorf("resolve tool name: %w", err)
+		return "", nil, fmt.Errorf("resolve tool name: %w", err)
 	}
-	if len(defs) == 0 {
-		return name, nil
+	def := matchToolDefinition(defs, name)
+	if def == nil {
+		return executableToolName(name), nil, nil
 	}
+	return executableToolName(def.Name), def, nil
+}
 
-	candidates := toolNameCandidates(name)
-	defMap := map[string]string{}
-	for _, def := range defs {
+func matchToolDefinition(defs []sdk.ToolDefinitionInfo, name string) *sdk.ToolDefinitionInfo {
+	if len(defs) == 0 {
+		return nil
+	}
+	defMap := map[string]int{}
+	for i, def := range defs {
 		defName := strings.TrimSpace(def.Name)
 		if defName == "" {
 			continue
 		}
 		for _, candidate := range toolNameCandidates(defName) {
 			if _, ok := defMap[candidate]; !ok {
-				defMap[candidate] = defName
+				defMap[candidate] = i
 			}
 		}
 	}
-	for _, candidate := range candidates {
-		if resolved, ok := defMap[candidate]; ok {
-			return executableToolName(resolved), nil
+	for _, candidate := range toolNameCandidates(name) {
+		if index, ok := defMap[candidate]; ok {
+			return &defs[index]
 		}
 	}
-	return executableToolName(name), nil
+	return nil
 }
 
 func toolNameCandidates(name string) []string {
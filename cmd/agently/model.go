@@ -0,0 +1,320 @@
+package agently
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/viant/agently-core/genai/llm"
+	provider "github.com/viant/agently-core/genai/llm/provider"
+	agentlyrt "github.com/viant/agently/runtime"
+	"gopkg.in/yaml.v3"
+)
+
+// ModelCmd groups model config subcommands. They read <workspace>/models
+// directly and do not need a running server.
+type ModelCmd struct {
+	List *ModelListCmd `command:"list" description:"List model configs with provider and credential status"`
+	Show *ModelShowCmd `command:"show" description:"Print a model's options with secrets masked"`
+	Test *ModelTestCmd `command:"test" description:"Build a model through the model finder and run a tiny completion"`
+}
+
+type ModelListCmd struct {
+	workspaceRootOption
+	JSON bool `long:"json" description:"print models as JSON"`
+}
+
+type modelIDArg struct {
+	ID string `positional-arg-name:"id" description:"model id"`
+}
+
+type ModelShowCmd struct {
+	workspaceRootOption
+	Args modelIDArg `positional-args:"yes" required:"yes"`
+	JSON bool       `long:"json" description:"print the model as JSON"`
+}
+
+type ModelTestCmd struct {
+	workspaceRootOption
+	Args    modelIDArg    `positional-args:"yes" required:"yes"`
+	Prompt  string        `long:"prompt" description:"prompt sent to the model" default:"Reply with the single word: pong"`
+	URL     string        `long:"url" description:"override the provider base URL, e.g. a local OpenAI-compatible stub (http://127.0.0.1:8089/v1)"`
+	Timeout time.Duration `long:"timeout" description:"completion timeout" default:"60s"`
+	JSON    bool          `long:"json" description:"print the result as JSON"`
+}
+
+// modelRow is one `model list` entry.
+type modelRow struct {
+	ID         string `json:"id"`
+	Provider   string `json:"provider,omitempty"`
+	Model      string `json:"model,omitempty"`
+	Credential string `json:"credential"`
+	Ready      bool   `json:"ready"`
+	Default    bool   `json:"default,omitempty"`
+	Path       string `json:"path"`
+	Error      string `json:"error,omitempty"`
+}
+
+func (c *ModelListCmd) Execute(_ []string) error {
+	root := c.root()
+	rows, err := listModels(root, os.Getenv)
+	if err != nil {
+		return err
+	}
+	if c.JSON {
+		if rows == nil {
+			rows = []*modelRow{}
+		}
+		return printJSON(rows)
+	}
+	if len(rows) == 0 {
+		fmt.Printf("no models found in %s\n", filepath.Join(root, "models"))
+		return nil
+	}
+	printModelTable(os.Stdout, rows)
+	return nil
+}
+
+func (c *ModelShowCmd) Execute(_ []string) error {
+	root := c.root()
+	path, err := agentlyrt.FindModelConfig(root, c.Args.ID)
+	if err != nil {
+		return err
+	}
+	config, err := readModelConfig(path)
+	if err != nil {
+		return err
+	}
+	masked := maskSecrets(config).(map[string]interface{})
+	credential, ready := modelCredential(config, os.Getenv)
+	if c.JSON {
+		return printJSON(map[string]interface{}{
+			"path":       path,
+			"credential": credential,
+			"ready":      ready,
+			"config":     masked,
+		})
+	}
+	fmt.Printf("# %s\n# credential: %s\n", path, credential)
+	data, err := yaml.Marshal(masked)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(data))
+	return nil
+}
+
+// modelTestResult is the outcome of `model test`.
+type modelTestResult struct {
+	ID        string     `json:"id"`
+	OK        bool       `json:"ok"`
+	LatencyMs int64      `json:"latencyMs"`
+	Content   string     `json:"content,omitempty"`
+	Usage     *llm.Usage `json:"usage,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+func (c *ModelTestCmd) Execute(_ []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+	result := testModel(ctx, c.root(), c.Args.ID, c.Prompt, strings.TrimSpace(c.URL))
+	if c.JSON {
+		if err := printJSON(result); err != nil {
+			return err
+		}
+	} else if result.OK {
+		fmt.Printf("model %s OK in %dms\n", result.ID, result.LatencyMs)
+		fmt.Printf("response: %s\n", truncateCell(strings.TrimSpace(result.Content), 200))
+		if result.Usage != nil {
+			fmt.Printf("usage: prompt=%d completion=%d total=%d\n", result.Usage.PromptTokens, result.Usage.CompletionTokens, result.Usage.TotalTokens)
+		}
+	}
+	if !result.OK {
+		return fmt.Errorf("model %s failed: %s", result.ID, result.Error)
+	}
+	return nil
+}
+
+// testModel builds the model the way the runtime does (ModelFinder over the
+// workspace configs) and runs a single completion.
+func testModel(ctx context.Context, root, id, prompt, baseURL string) *modelTestResult {
+	result := &modelTestResult{ID: strings.TrimSpace(id)}
+	var loader provider.ConfigLoader = agentlyrt.NewWorkspaceModelLoader(root)
+	if baseURL != "" {
+		loader = &urlOverrideLoader{ConfigLoader: loader, url: baseURL}
+	}
+	started := time.Now()
+	model, err := agentlyrt.NewModelFinder(loader).Find(ctx, result.ID)
+	if err != nil {
+		result.Error = fmt.Sprintf("build model: %v", err)
+		return result
+	}
+	response, err := model.Generate(ctx, &llm.GenerateRequest{
+		Messages: []llm.Message{llm.NewUserMessage(prompt)},
+	})
+	result.LatencyMs = time.Since(started).Milliseconds()
+	if err != nil {
+		result.Error = fmt.Sprintf("generate: %v", err)
+		return result
+	}
+	if response == nil || len(response.Choices) == 0 {
+		result.Error = "generate: empty response"
+		return result
+	}
+	result.OK = true
+	result.Content = response.Choices[0].Message.Content
+	result.Usage = response.Usage
+	return result
+}
+
+// urlOverrideLoader points a model at another base URL, typically a local
+// OpenAI-compatible stub for offline checks.
+type urlOverrideLoader struct {
+	provider.ConfigLoader
+	url string
+}
+
+func (l *urlOverrideLoader) Load(ctx context.Context, id string) (*provider.Config, error) {
+	cfg, err := l.ConfigLoader.Load(ctx, id)
+	if err != nil || cfg == nil {
+		return cfg, err
+	}
+	cfg.Options.URL = l.url
+	return cfg, nil
+}
+
+func listModels(root string, getenv func(string) string) ([]*modelRow, error) {
+	configs, err := agentlyrt.ListModelConfigs(root)
+	if err != nil {
+		return nil, err
+	}
+	defaultModel := workspaceDefault(root, "model")
+	var rows []*modelRow
+	for _, cfg := range configs {
+		row := &modelRow{ID: cfg.ID, Path: cfg.Path, Default: cfg.ID == defaultModel, Credential: "-"}
+		config, err := readModelConfig(cfg.Path)
+		if err != nil {
+			row.Error = err.Error()
+			rows = append(rows, row)
+			continue
+		}
+		options, _ := config["options"].(map[string]interface{})
+		row.Provider = stringValue(options["provider"])
+		row.Model = stringValue(options["model"])
+		row.Credential, row.Ready = modelCredential(config, getenv)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func readModelConfig(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// modelCredential describes where the model's credential comes from and
+// whether it looks available. Only env vars can be checked locally; secret
+// references and cloud credentials are reported as configured.
+func modelCredential(config map[string]interface{}, getenv func(string) string) (string, bool) {
+	options, _ := config["options"].(map[string]interface{})
+	if envKey := stringValue(options["envKey"]); envKey != "" {
+		if strings.TrimSpace(getenv(envKey)) != "" {
+			return "env " + envKey + " (set)", true
+		}
+		return "env " + envKey + " (missing)", false
+	}
+	for _, key := range []string{"apiKeyURL", "credentialsURL"} {
+		if ref := stringValue(options[key]); ref != "" {
+			return key + " " + maskSecretValue(key, ref), true
+		}
+	}
+	if project := stringValue(options["projectID"]); project != "" {
+		return "cloud project " + project, true
+	}
+	return "none", true
+}
+
+func printModelTable(out io.Writer, rows []*modelRow) {
+	w := tabwriter.NewWriter(out, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tPROVIDER\tMODEL\tCREDENTIAL")
+	for _, row := range rows {
+		id := row.ID
+		if row.Default {
+			id += " *"
+		}
+		credential := row.Credential
+		if row.Error != "" {
+			credential = "error: " + row.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", id, row.Provider, truncateCell(row.Model, 50), credential)
+	}
+	w.Flush()
+}
+
+// maskSecrets returns a copy of value with secret-looking fields masked.
+func maskSecrets(value interface{}) interface{} {
+	switch actual := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(actual))
+		for key, item := range actual {
+			if text, ok := item.(string); ok {
+				out[key] = maskSecretValue(key, text)
+				continue
+			}
+			out[key] = maskSecrets(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(actual))
+		for i, item := range actual {
+			out[i] = maskSecrets(item)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+func maskSecretValue(key, value string) string {
+	if strings.HasPrefix(strings.TrimSpace(value), scyInlineBase64Prefix) {
+		return scyInlineBase64Prefix + "****"
+	}
+	if !isSecretKey(key) || value == "" {
+		return value
+	}
+	if len(value) > 12 {
+		return "****" + value[len(value)-4:]
+	}
+	return "****"
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(strings.TrimSpace(key))
+	switch key {
+	case "key", "token", "authorization", "password":
+		return true
+	}
+	for _, suffix := range []string{"apikey", "secret", "password", "accesstoken", "refreshtoken", "bearertoken", "privatekey"} {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func stringValue(value interface{}) string {
+	text, _ := value.(string)
+	return strings.TrimSpace(text)
+}
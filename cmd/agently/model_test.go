@@ -0,0 +1,139 @@
+package agently
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	agentlyrt "github.com/viant/agently/runtime"
+)
+
+func modelTestWorkspace(t *testing.T, extra map[string]string) string {
+	files := map[string]string{
+		"config.yaml":           "default:\n  model: openai_small\n",
+		"models/openai.yaml":    "id: openai_small\noptions:\n  provider: openai\n  model: gpt-small\n  envKey: TEST_OPENAI_KEY\n",
+		"models/bedrock.yaml":   "id: bedrock_big\noptions:\n  provider: bedrock\n  model: big\n  credentialsURL: aws-e2e\n",
+		"models/vertex.yaml":    "id: vertex_pro\noptions:\n  provider: gemini\n  model: pro\n  projectID: demo\n",
+		"models/unnamed.yaml":   "options:\n  provider: grok\n  model: grok-4\n  envKey: TEST_XAI_KEY\n",
+		"embedders/ignore.yaml": "id: not_a_model\n",
+	}
+	for name, content := range extra {
+		files[name] = content
+	}
+	return writeAttachmentTree(t, files)
+}
+
+func TestListModels(t *testing.T) {
+	root := modelTestWorkspace(t, nil)
+	env := map[string]string{"TEST_OPENAI_KEY": "sk-test"}
+	rows, err := listModels(root, func(key string) string { return env[key] })
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+
+	byID := map[string]*modelRow{}
+	for _, row := range rows {
+		byID[row.ID] = row
+	}
+	assert.Equal(t, "env TEST_OPENAI_KEY (set)", byID["openai_small"].Credential)
+	assert.True(t, byID["openai_small"].Ready)
+	assert.True(t, byID["openai_small"].Default)
+	assert.Equal(t, "openai", byID["openai_small"].Provider)
+	assert.Equal(t, "env TEST_XAI_KEY (missing)", byID["unnamed"].Credential)
+	assert.False(t, byID["unnamed"].Ready)
+	assert.Equal(t, "credentialsURL aws-e2e", byID["bedrock_big"].Credential)
+	assert.Equal(t, "cloud project demo", byID["vertex_pro"].Credential)
+}
+
+func TestFindModelConfig_ListsAvailableIDs(t *testing.T) {
+	root := modelTestWorkspace(t, nil)
+	path, err := agentlyrt.FindModelConfig(root, "openai_small")
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(path, "openai.yaml"))
+	path, err = agentlyrt.FindModelConfig(root, "models/unnamed")
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(path, "unnamed.yaml"))
+
+	_, err = agentlyrt.FindModelConfig(root, "openai_gpt-9")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "model config not found: openai_gpt-9")
+	assert.Contains(t, err.Error(), "bedrock_big, openai_small, unnamed, vertex_pro")
+}
+
+func TestMaskSecrets(t *testing.T) {
+	masked := maskSecrets(map[string]interface{}{
+		"options": map[string]interface{}{
+			"envKey":         "OPENAI_API_KEY",
+			"apiKey":         "sk-abcdefghijklmnop",
+			"maxTokens":      1000,
+			"headers":        []interface{}{map[string]interface{}{"authorization": "Bearer x"}},
+			"credentialsURL": scyInlineBase64Prefix + "c2VjcmV0",
+		},
+	}).(map[string]interface{})
+	options := masked["options"].(map[string]interface{})
+	assert.Equal(t, "OPENAI_API_KEY", options["envKey"])
+	assert.Equal(t, "****mnop", options["apiKey"])
+	assert.Equal(t, 1000, options["maxTokens"])
+	assert.Equal(t, "****", options["headers"].([]interface{})[0].(map[string]interface{})["authorization"])
+	assert.Equal(t, scyInlineBase64Prefix+"****", options["credentialsURL"])
+}
+
+// TestTestModel_OpenAICompatibleStub runs `model test` offline against a
+// local server speaking the OpenAI chat completions and responses APIs.
+func TestTestModel_OpenAICompatibleStub(t *testing.T) {
+	var requests atomic.Int32
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/chat/completions"):
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"id":      "chatcmpl-1",
+				"object":  "chat.completion",
+				"created": time.Now().Unix(),
+				"model":   "gpt-small",
+				"choices": []interface{}{map[string]interface{}{
+					"index":         0,
+					"message":       map[string]interface{}{"role": "assistant", "content": "pong"},
+					"finish_reason": "stop",
+				}},
+				"usage": map[string]interface{}{"prompt_tokens": 5, "completion_tokens": 1, "total_tokens": 6},
+			})
+		case strings.HasSuffix(r.URL.Path, "/responses"):
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"id":     "resp-1",
+				"object": "response",
+				"status": "completed",
+				"model":  "gpt-small",
+				"output": []interface{}{map[string]interface{}{
+					"type":    "message",
+					"role":    "assistant",
+					"content": []interface{}{map[string]interface{}{"type": "output_text", "text": "pong"}},
+				}},
+				"usage": map[string]interface{}{"input_tokens": 5, "output_tokens": 1, "total_tokens": 6},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer stub.Close()
+	t.Setenv("TEST_OPENAI_KEY", "sk-stub")
+	root := modelTestWorkspace(t, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result := testModel(ctx, root, "openai_small", "Reply with the single word: pong", stub.URL+"/v1")
+	require.True(t, result.OK, result.Error)
+	assert.Equal(t, "pong", strings.TrimSpace(result.Content))
+	assert.Positive(t, requests.Load())
+
+	result = testModel(ctx, root, "missing_model", "ping", stub.URL+"/v1")
+	assert.False(t, result.OK)
+	assert.Contains(t, result.Error, "available in")
+}
@@ -6,14 +6,28 @@ type Options struct {
 	Version       bool              `short:"v" long:"version" description:"Show agently version and exit"`
 	Serve         *ServeCmd         `command:"serve" description:"Start HTTP server"`
 	Scheduler     *SchedulerCmd     `command:"scheduler" description:"Scheduler runner and utilities"`
+	Schedule      *ScheduleCmd      `command:"schedule" description:"List, create, update, pause, resume and run schedules"`
 	Query         *ChatCmd          `command:"query" description:"Query an agent (single turn or continuation)"`
 	Chat          *ChatCmd          `command:"chat"  description:"Deprecated alias of query"`
 	Transcript    *TranscriptCmd    `command:"transcript" description:"Fetch a conversation transcript"`
+	Tail          *TailCmd          `command:"tail" description:"Follow a running conversation's stream without sending a query"`
+	Cancel        *CancelCmd        `command:"cancel" description:"Cancel the running turn of a conversation"`
+	Steer         *SteerCmd         `command:"steer" description:"Add a message to the running turn of a conversation"`
+	Conversation  *ConversationCmd  `command:"conversation" description:"List, show, delete, rename and fork conversations"`
+	Workspace     *WorkspaceCmd     `command:"workspace" description:"Initialize, validate, diff and upgrade a workspace"`
+	Approvals     *ApprovalsCmd     `command:"approvals" description:"List, show, approve and deny queued tool approvals"`
+	Agent         *AgentCmd         `command:"agent" description:"List, show, scaffold and lint workspace agents"`
+	Model         *ModelCmd         `command:"model" description:"List, show and smoke-test workspace model configs"`
 	EvalWorkspace *EvalWorkspaceCmd `command:"eval-workspace" description:"Run generic workspace eval/contract checks"`
 	ListTools     *ListToolsCmd     `command:"list-tools" description:"List available tools"`
 	TemplateLoad  *TemplateLoadCmd  `command:"template-load" description:"Load and validate a template file or workspace template"`
 	MCP           *MCPCmd           `command:"mcp" description:"MCP-oriented tool discovery and execution"`
 	ChatGPTLogin  *ChatGPTLoginCmd  `command:"chatgpt-login" description:"Login via ChatGPT OAuth and persist tokens for OpenAI providers"`
+	Instances     *InstancesCmd     `command:"instances" description:"List running agently servers from the local instance registry"`
+	CLIContext    *ContextCmd       `command:"context" description:"Manage named CLI contexts (server and auth settings) in ~/.agently/cli.yaml"`
+	Auth          *AuthCmd          `command:"auth" description:"Log in, log out and inspect cached CLI credentials"`
+	JWT           *JWTCmd           `command:"jwt" description:"Generate and rotate JWT signing keys"`
+	Completion    *CompletionCmd    `command:"completion" description:"Print a bash, zsh or fish completion script"`
 }
 
 // Init instantiates the sub-command referenced by the first argument so that
@@ -24,12 +38,30 @@ func (o *Options) Init(firstArg string) {
 		o.Serve = &ServeCmd{}
 	case "scheduler":
 		o.Scheduler = &SchedulerCmd{}
+	case "schedule":
+		o.Schedule = &ScheduleCmd{}
 	case "chat":
 		o.Chat = &ChatCmd{}
 	case "query":
 		o.Query = &ChatCmd{}
 	case "transcript":
 		o.Transcript = &TranscriptCmd{}
+	case "tail":
+		o.Tail = &TailCmd{}
+	case "cancel":
+		o.Cancel = &CancelCmd{}
+	case "steer":
+		o.Steer = &SteerCmd{}
+	case "conversation":
+		o.Conversation = &ConversationCmd{}
+	case "workspace":
+		o.Workspace = &WorkspaceCmd{}
+	case "approvals":
+		o.Approvals = &ApprovalsCmd{}
+	case "agent":
+		o.Agent = &AgentCmd{}
+	case "model":
+		o.Model = &ModelCmd{}
 	case "eval-workspace":
 		o.EvalWorkspace = &EvalWorkspaceCmd{}
 	case "list-tools":
@@ -40,5 +72,15 @@ func (o *Options) Init(firstArg string) {
 		o.MCP = &MCPCmd{}
 	case "chatgpt-login":
 		o.ChatGPTLogin = &ChatGPTLoginCmd{}
+	case "instances":
+		o.Instances = &InstancesCmd{}
+	case "context":
+		o.CLIContext = &ContextCmd{}
+	case "auth":
+		o.Auth = &AuthCmd{}
+	case "jwt":
+		o.JWT = &JWTCmd{}
+	case "completion":
+		o.Completion = &CompletionCmd{}
 	}
 }
@@ -3,13 +3,18 @@ package agently
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/cookiejar"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
 	"time"
 
+	root "github.com/viant/agently"
 	"github.com/viant/agently-core/sdk"
 	agentsvc "github.com/viant/agently-core/service/agent"
 )
@@ -24,20 +29,53 @@ type ChatCmd struct {
 	Timeout   int      `short:"t" long:"timeout" description:"timeout in seconds for the agent response (0=none)"`
 	User      string   `short:"u" long:"user" description:"user id for the chat" default:"devuser"`
 	API       string   `long:"api" description:"Agently base URL (skips auto-detect)"`
+	Instance  string   `long:"instance" description:"registered instance name, port or pid to connect to (see agently instances)"`
 	Token     string   `long:"token" description:"Bearer token for API requests (overrides AGENTLY_TOKEN)"`
 	OOB       string   `long:"oob" description:"Use local scy OAuth2 out-of-band login with the supplied secrets URL"`
 	OAuthCfg  string   `long:"oauth-config" description:"Optional scy OAuth config URL override for client-side OOB login"`
 	OAuthScp  string   `long:"oauth-scopes" description:"comma-separated OAuth scopes for OOB login"`
 	ElicitDef string   `long:"elicitation-default" description:"JSON or @file to auto-accept elicitations when stdin is not a TTY"`
+	ElicitCmd string   `long:"elicitation-handler" description:"command that answers elicitations: gets each one as JSON on stdin, prints {\"action\":\"accept|decline|cancel\",\"payload\":{...}} on stdout"`
 	Context   string   `long:"context" description:"inline JSON object or @file with context data"`
-	Attach    []string `long:"attach" description:"file to attach (repeatable). Format: <path>"`
+	Attach    []string `long:"attach" description:"file, directory, glob (** for any depth) or - for stdin to attach (repeatable)"`
+	Output    string   `long:"output" description:"output format: text renders the answer, ndjson emits every stream event as a JSON line followed by a summary record" choice:"text" choice:"ndjson" default:"text"`
+
+	// CLIContext is --cli-context because --context already carries context data.
+	CLIContext string `long:"cli-context" description:"CLI context supplying unset server and auth flags; other commands call it --context, which query and chat use for context data (overrides AGENTLY_CONTEXT; see agently context)"`
+
+	Batch       string `long:"batch" description:"JSONL file of query records to run (- reads stdin); each record has query and optional id, context, attachments, agent, conversationId"`
+	Concurrency int    `long:"concurrency" description:"number of batch records to run in parallel" default:"1"`
+	BatchOut    string `long:"batch-output" description:"JSONL results file for --batch (default stdout)"`
+	BatchShared bool   `long:"batch-shared" description:"run batch records in order on one shared conversation (-c or the first record's)"`
+
+	Local     bool   `long:"local" description:"build the workspace runtime in-process instead of connecting to a running server"`
+	Workspace string `short:"w" long:"workspace" description:"workspace root for --local (overrides AGENTLY_WORKSPACE when set)"`
+	Policy    string `long:"policy" description:"tool policy for --local: auto|ask|deny" default:"auto"`
+
+	AttachInclude  []string `long:"attach-include" description:"only attach files from directories/globs matching this pattern (repeatable or comma-separated)"`
+	AttachExclude  []string `long:"attach-exclude" description:"skip files from directories/globs matching this pattern (repeatable or comma-separated)"`
+	AttachMaxFile  string   `long:"attach-max-file" description:"per-file attachment size limit (e.g. 512KB, 10MB)" default:"10MB"`
+	AttachMaxTotal string   `long:"attach-max-total" description:"total attachment size limit per message" default:"25MB"`
 
 	// elicitationTimeout is sourced from the resolved instance's workspace
 	// defaults. Zero means fall back to defaultElicitationResponseTimeout.
 	elicitationTimeout time.Duration
+	// elicitationHandler is the trimmed --elicitation-handler command. When
+	// set it answers every elicitation instead of the terminal or the static
+	// --elicitation-default payload.
+	elicitationHandler string
+	// promptOutput receives interactive elicitation prompts: stdout, or
+	// stderr under --output ndjson to keep stdout parseable.
+	promptOutput io.Writer
+	// ndjson is set for --output ndjson and receives stream events and the
+	// closing summary record.
+	ndjson *ndjsonSink
+	// cliContextApplied records that the CLI context already filled unset
+	// connection flags, since both resolveBaseURL and ensureAuth apply it.
+	cliContextApplied bool
 }
 
-func (c *ChatCmd) Execute(_ []string) error {
+func (c *ChatCmd) Execute(_ []string) (err error) {
 	if strings.TrimSpace(c.AgentID) == "" {
 		c.AgentID = "chatter"
 	}
@@ -51,7 +89,11 @@ func (c *ChatCmd) Execute(_ []string) error {
 	if err != nil {
 		return err
 	}
-	attachments, err := parseAttachments(c.Attach)
+	attachOptions, err := c.attachmentOptions()
+	if err != nil {
+		return err
+	}
+	attachments, err := loadAttachments(c.Attach, attachOptions)
 	if err != nil {
 		return err
 	}
@@ -59,8 +101,62 @@ func (c *ChatCmd) Execute(_ []string) error {
 	if err != nil {
 		return fmt.Errorf("parse --elicitation-default: %w", err)
 	}
+	c.elicitationHandler = strings.TrimSpace(c.ElicitCmd)
+	c.promptOutput = os.Stdout
+	session := &querySession{convID: strings.TrimSpace(c.ConvID)}
+	switch strings.ToLower(strings.TrimSpace(c.Output)) {
+	case "", queryOutputText:
+	case queryOutputNDJSON:
+		c.ndjson = newNDJSONSink(os.Stdout)
+		c.promptOutput = os.Stderr
+		// Every ndjson run ends with a summary, including failed ones, so
+		// consumers never have to guess whether the stream was truncated.
+		defer func() {
+			code := 0
+			runErr := err
+			var exitErr *commandExitCode
+			if errors.As(err, &exitErr) {
+				code, runErr = exitErr.code, nil
+			} else if err != nil {
+				code = 1
+			}
+			if werr := c.ndjson.WriteSummary(session.convID, code, runErr); werr != nil && err == nil {
+				err = werr
+			}
+		}()
+	default:
+		return fmt.Errorf("unsupported --output %q (expected text or ndjson)", c.Output)
+	}
+	batchMode := strings.TrimSpace(c.Batch) != ""
+	if batchMode {
+		if len(c.Query) > 0 {
+			return fmt.Errorf("--batch cannot be combined with -q")
+		}
+		if c.ndjson != nil {
+			return fmt.Errorf("--batch writes JSONL results; --output ndjson is not supported")
+		}
+		if strings.TrimSpace(c.Batch) == "-" && containsStdinAttachment(c.Attach) {
+			return fmt.Errorf("--attach - cannot be combined with --batch -")
+		}
+		if session.convID != "" && !c.BatchShared {
+			return fmt.Errorf("-c with --batch requires --batch-shared; without it every record starts a fresh conversation")
+		}
+		if err := c.checkBatchPrompts(stdinIsTTY()); err != nil {
+			return err
+		}
+	}
 
 	ctxBase := context.Background()
+	if c.Local {
+		local, err := c.startLocal(ctxBase)
+		if err != nil {
+			return err
+		}
+		defer local.Close()
+		c.API = local.BaseURL
+	} else if strings.TrimSpace(c.Workspace) != "" {
+		return fmt.Errorf("-w/--workspace requires --local")
+	}
 	baseURL, providers, workspaceRoot, defaultAgent, defaultModel, models, err := c.resolveBaseURL(ctxBase)
 	if err != nil {
 		return err
@@ -69,12 +165,19 @@ func (c *ChatCmd) Execute(_ []string) error {
 		c.AgentID = strings.TrimSpace(defaultAgent)
 	}
 
-	httpClient := &http.Client{Jar: cliCookieJar()}
+	httpClient := &http.Client{}
+	if c.Local {
+		// The in-process server listens on a fresh port every run, so its
+		// session is never cached.
+		httpClient.Jar, _ = cookiejar.New(nil)
+	} else {
+		httpClient.Jar = cliCookieJar(baseURL)
+	}
 	if c.Timeout > 0 {
 		httpClient.Timeout = time.Duration(c.Timeout) * time.Second
 	}
 	opts := []sdk.HTTPOption{sdk.WithHTTPClient(httpClient)}
-	if token := resolvedToken(c.Token); token != "" {
+	if token := serverToken(baseURL, c.Token); token != "" {
 		opts = append(opts, sdk.WithAuthToken(token))
 	}
 	client, err := sdk.NewHTTP(baseURL, opts...)
@@ -114,93 +217,162 @@ func (c *ChatCmd) Execute(_ []string) error {
 		modelOverride = explicitModel
 	}
 
-	if strings.TrimSpace(workspaceRoot) != "" {
-		fmt.Printf("[workspace] %s\n", workspaceRoot)
-	} else {
-		fmt.Printf("[workspace] <unknown>\n")
-	}
-	if modelOverride != "" {
-		fmt.Printf("[agent] %s [model] %s\n", c.AgentID, modelOverride)
-	} else {
-		fmt.Printf("[agent] %s\n", c.AgentID)
-	}
-
-	convID := strings.TrimSpace(c.ConvID)
-	var lastElicitationPayload map[string]interface{}
-	sentAttachments := false
-
-	runQuery := func(query string) error {
-		query = strings.TrimSpace(query)
-		if query == "" {
-			return nil
-		}
-		ctx := ctxBase
-		if c.Timeout > 0 {
-			var cancel context.CancelFunc
-			ctx, cancel = context.WithTimeout(ctx, time.Duration(c.Timeout)*time.Second)
-			defer cancel()
+	if c.ndjson != nil {
+		if err := c.ndjson.WriteSession(strings.TrimSpace(workspaceRoot), c.AgentID, modelOverride); err != nil {
+			return err
 		}
-		input := &agentsvc.QueryInput{
-			AgentID:        c.AgentID,
-			ConversationID: convID,
-			Query:          query,
-			UserId:         strings.TrimSpace(c.User),
-			ModelOverride:  modelOverride,
-			Context:        buildQueryContext(contextData, defaultElicitationPayload, lastElicitationPayload),
+	} else {
+		header := io.Writer(os.Stdout)
+		if batchMode {
+			// batch results may go to stdout
+			header = os.Stderr
 		}
-		if !sentAttachments && len(attachments) > 0 {
-			input.Attachments = attachments
-			sentAttachments = true
+		if strings.TrimSpace(workspaceRoot) != "" {
+			fmt.Fprintf(header, "[workspace] %s\n", workspaceRoot)
+		} else {
+			fmt.Fprintf(header, "[workspace] <unknown>\n")
 		}
-		out, _, err := c.executeQuery(ctx, client, input, defaultElicitationPayload, &lastElicitationPayload)
-		if err != nil {
-			return err
+		if modelOverride != "" {
+			fmt.Fprintf(header, "[agent] %s [model] %s\n", c.AgentID, modelOverride)
+		} else {
+			fmt.Fprintf(header, "[agent] %s\n", c.AgentID)
 		}
-		convID = strings.TrimSpace(out.ConversationID)
-		return nil
+	}
+	if batchMode {
+		return c.runBatch(ctxBase, &batchRun{
+			client:         client,
+			modelOverride:  modelOverride,
+			baseContext:    contextData,
+			baseAttach:     attachments,
+			attachOptions:  attachOptions,
+			defaultPayload: defaultElicitationPayload,
+		})
 	}
 
+	session.client = client
+	session.agentID = c.AgentID
+	session.model = modelOverride
+	session.context = contextData
+	session.attachments = attachments
+
 	if len(c.Query) > 0 {
 		for _, query := range c.Query {
-			if err := runQuery(query); err != nil {
+			if err := c.runTurn(ctxBase, session, query, defaultElicitationPayload); err != nil {
+				if errors.Is(err, errTurnInterrupted) {
+					return &commandExitCode{code: 130}
+				}
 				return err
 			}
 		}
-		fmt.Printf("[conversation-id] %s\n", convID)
-		code, err := resolveConversationExitCode(ctxBase, client, convID)
-		if err != nil {
-			return err
-		}
-		if code != 0 {
-			return &commandExitCode{code: code}
-		}
-		return nil
+		return c.finishQuery(ctxBase, client, session.convID)
 	}
 
 	reader := bufio.NewReader(os.Stdin)
 	for {
-		fmt.Print("> ")
+		if c.ndjson == nil {
+			fmt.Print("> ")
+		}
 		line, cancelled, rerr := readPromptLine(ctxBase, reader)
 		if rerr != nil {
 			return fmt.Errorf("read stdin: %w", rerr)
 		}
-		if cancelled || line == "" || line == "exit" || line == "quit" {
-			fmt.Printf("[conversation-id] %s\n", convID)
-			code, err := resolveConversationExitCode(ctxBase, client, convID)
-			if err != nil {
-				return err
-			}
-			if code != 0 {
-				return &commandExitCode{code: code}
-			}
-			return nil
+		if cancelled || line == "" || line == "exit" || line == "quit" || line == "/exit" || line == "/quit" {
+			return c.finishQuery(ctxBase, client, session.convID)
+		}
+		if isSlashCommand(line) {
+			// Like prompts, command output stays off the ndjson stream.
+			c.runSlashCommand(ctxBase, c.promptOutput, session, line)
+			continue
 		}
-		if err := runQuery(line); err != nil {
+		if err := c.runTurn(ctxBase, session, line, defaultElicitationPayload); err != nil {
+			if errors.Is(err, errTurnInterrupted) {
+				continue
+			}
 			return err
 		}
 	}
 }
 
+// runTurn sends one query using the session's current agent, model, context
+// and pending attachments, and records the resulting conversation.
+func (c *ChatCmd) runTurn(ctx context.Context, session *querySession, query string, defaultPayload map[string]interface{}) error {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(c.Timeout)*time.Second)
+		defer cancel()
+	}
+	input := &agentsvc.QueryInput{
+		AgentID:        session.agentID,
+		ConversationID: session.convID,
+		Query:          query,
+		UserId:         strings.TrimSpace(c.User),
+		ModelOverride:  session.model,
+		Context:        buildQueryContext(session.context, defaultPayload, session.lastElicitationPayload),
+	}
+	if len(session.attachments) > 0 {
+		input.Attachments = session.attachments
+		session.attachments = nil
+	}
+	// The conversation is created up front so Ctrl-C can cancel its turn.
+	if err := ensureConversation(ctx, session.client, input, query); err != nil {
+		return err
+	}
+	conversationID := input.ConversationID
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+	var out *agentsvc.QueryOutput
+	err := cancelOnInterrupt(ctx, interrupts, func(ctx context.Context) (string, error) {
+		return cancelActiveTurn(ctx, session.client, conversationID)
+	}, func(ctx context.Context) (err error) {
+		out, _, err = c.executeQuery(ctx, session.client, input, defaultPayload, &session.lastElicitationPayload)
+		return err
+	})
+	if err != nil {
+		session.convID = conversationID
+		return err
+	}
+	session.convID = strings.TrimSpace(out.ConversationID)
+	c.ndjson.AddTurnUsage(out)
+	return nil
+}
+
+// startLocal builds the workspace runtime in this process. The rest of the
+// query flow talks to it over loopback exactly as it would to `agently serve`.
+func (c *ChatCmd) startLocal(ctx context.Context) (*root.LocalServer, error) {
+	if strings.TrimSpace(c.API) != "" {
+		return nil, fmt.Errorf("--local cannot be combined with --api")
+	}
+	local, err := root.StartLocal(ctx, root.LocalOptions{
+		WorkspacePath: strings.TrimSpace(c.Workspace),
+		Policy:        strings.TrimSpace(c.Policy),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("start local runtime: %w", err)
+	}
+	return local, nil
+}
+
+// finishQuery reports the conversation and turns the platform exit code into
+// the command's exit status.
+func (c *ChatCmd) finishQuery(ctx context.Context, client *sdk.HTTPClient, convID string) error {
+	if c.ndjson == nil {
+		fmt.Printf("[conversation-id] %s\n", convID)
+	}
+	code, err := resolveConversationExitCode(ctx, client, convID)
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		return &commandExitCode{code: code}
+	}
+	return nil
+}
+
 var (
 	defaultDebugLogPath = "/tmp/agently-debug.log"
 	envTraceFilePath    = "AGENTLY_DEBUG_TRACE_FILE"
@@ -11,8 +11,31 @@ import (
 	"github.com/viant/agently-core/sdk"
 )
 
+// applyCLIContext fills connection flags left unset from the active CLI
+// context. In-process --local runs never read it.
+func (c *ChatCmd) applyCLIContext() error {
+	if c.cliContextApplied || c.Local {
+		return nil
+	}
+	c.cliContextApplied = true
+	return cliConnection{
+		API:         &c.API,
+		Instance:    &c.Instance,
+		Token:       &c.Token,
+		OOB:         &c.OOB,
+		OAuthConfig: &c.OAuthCfg,
+		OAuthScopes: &c.OAuthScp,
+	}.apply(c.CLIContext)
+}
+
 func (c *ChatCmd) resolveBaseURL(ctx context.Context) (string, []authProviderInfo, string, string, string, []string, error) {
+	if err := c.applyCLIContext(); err != nil {
+		return "", nil, "", "", "", nil, err
+	}
 	if strings.TrimSpace(c.API) != "" {
+		if strings.TrimSpace(c.Instance) != "" {
+			return "", nil, "", "", "", nil, fmt.Errorf("--api and --instance are mutually exclusive")
+		}
 		baseURL := strings.TrimSpace(c.API)
 		providers, _ := fetchAuthProviders(ctx, baseURL)
 		meta, _ := fetchWorkspaceMetadata(ctx, baseURL)
@@ -26,6 +49,14 @@ func (c *ChatCmd) resolveBaseURL(ctx context.Context) (string, []authProviderInf
 	if err != nil {
 		return "", nil, "", "", "", nil, err
 	}
+	if strings.TrimSpace(c.Instance) != "" {
+		inst, err := selectInstance(instances, c.Instance)
+		if err != nil {
+			return "", nil, "", "", "", nil, err
+		}
+		c.elicitationTimeout = inst.ElicitationTimeout
+		return inst.BaseURL, inst.Providers, inst.WorkspaceRoot, inst.DefaultAgent, inst.DefaultModel, inst.Models, nil
+	}
 	if len(instances) == 0 {
 		return "", nil, "", "", "", nil, fmt.Errorf("no local agently instance detected; use --api to specify the server URL")
 	}
@@ -40,6 +71,10 @@ func (c *ChatCmd) resolveBaseURL(ctx context.Context) (string, []authProviderInf
 		if root == "" {
 			root = "<unknown>"
 		}
+		if inst.Name != "" {
+			fmt.Printf("  %d) %s %s (workspace: %s)\n", i+1, inst.Name, inst.BaseURL, root)
+			continue
+		}
 		fmt.Printf("  %d) %s (workspace: %s)\n", i+1, inst.BaseURL, root)
 	}
 	fmt.Print("Select instance [1]: ")
@@ -76,9 +111,15 @@ func pickModel(defaultModel string, models []string) string {
 }
 
 func (c *ChatCmd) ensureAuth(ctx context.Context, client *sdk.HTTPClient, providers []authProviderInfo) error {
+	if err := c.applyCLIContext(); err != nil {
+		return err
+	}
 	if err := tryTokenAuth(ctx, client, c.Token); err == nil {
 		return nil
 	}
+	if resumeCachedSession(ctx, client) {
+		return nil
+	}
 	hasBFF := findProvider(providers, "bff") != nil
 	if strings.TrimSpace(c.OOB) != "" {
 		return c.authenticateWithOOB(ctx, client, strings.TrimSpace(c.OOB), parseScopes(c.OAuthScp))
@@ -0,0 +1,327 @@
+package agently
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/viant/agently-core/protocol/binding"
+	"github.com/viant/agently-core/sdk"
+	agentsvc "github.com/viant/agently-core/service/agent"
+)
+
+// maxBatchRecordBytes bounds a single JSONL record; inline context can be
+// large, so this is well above bufio.Scanner's 64KiB default.
+const maxBatchRecordBytes = 8 << 20
+
+// batchRecord is one line of a `query --batch` input file.
+type batchRecord struct {
+	ID             string                 `json:"id,omitempty"`
+	Query          string                 `json:"query"`
+	Context        map[string]interface{} `json:"context,omitempty"`
+	Attachments    []string               `json:"attachments,omitempty"`
+	AgentID        string                 `json:"agent,omitempty"`
+	ConversationID string                 `json:"conversationId,omitempty"`
+
+	line int
+}
+
+// batchResult is one line of the batch results file. Results are written in
+// completion order; Line ties a result back to its input record.
+type batchResult struct {
+	Line           int          `json:"line"`
+	ID             string       `json:"id,omitempty"`
+	AgentID        string       `json:"agent,omitempty"`
+	ConversationID string       `json:"conversationId,omitempty"`
+	Content        string       `json:"content,omitempty"`
+	ExitCode       int          `json:"exitCode"`
+	Usage          *ndjsonUsage `json:"usage,omitempty"`
+	Error          string       `json:"error,omitempty"`
+	DurationMs     int64        `json:"durationMs"`
+}
+
+// batchRun carries the settings shared by every record of a batch.
+type batchRun struct {
+	client         *sdk.HTTPClient
+	modelOverride  string
+	baseContext    map[string]interface{}
+	baseAttach     []*binding.Attachment
+	attachOptions  *attachmentOptions
+	defaultPayload map[string]interface{}
+	baseDir        string
+}
+
+// checkBatchPrompts refuses parallel records that would answer elicitations
+// on the terminal: their prompts and answers would interleave on the shared
+// stdin and stdout.
+func (c *ChatCmd) checkBatchPrompts(tty bool) error {
+	if !tty || c.Concurrency <= 1 || c.BatchShared {
+		return nil
+	}
+	if strings.TrimSpace(c.ElicitDef) != "" || strings.TrimSpace(c.ElicitCmd) != "" {
+		return nil
+	}
+	return fmt.Errorf("--concurrency %d on a terminal requires --elicitation-default or --elicitation-handler; parallel records cannot share elicitation prompts", c.Concurrency)
+}
+
+func (c *ChatCmd) runBatch(ctx context.Context, run *batchRun) error {
+	records, baseDir, err := readBatchRecords(c.Batch)
+	if err != nil {
+		return err
+	}
+	run.baseDir = baseDir
+	output := io.Writer(os.Stdout)
+	if path := strings.TrimSpace(c.BatchOut); path != "" && path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("create batch output: %w", err)
+		}
+		defer file.Close()
+		output = file
+	}
+	writer := &batchResultWriter{encoder: json.NewEncoder(output)}
+
+	concurrency := c.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if c.BatchShared {
+		// Turns of one conversation must run in order.
+		concurrency = 1
+	}
+	fmt.Fprintf(os.Stderr, "[batch] %d record(s), concurrency %d\n", len(records), concurrency)
+
+	if c.BatchShared {
+		conversationID := strings.TrimSpace(c.ConvID)
+		for _, record := range records {
+			if conversationID != "" && strings.TrimSpace(record.ConversationID) == "" {
+				record.ConversationID = conversationID
+			}
+			result := c.runBatchRecord(ctx, run, record)
+			if result.ConversationID != "" {
+				conversationID = result.ConversationID
+			}
+			if err := writer.Write(result); err != nil {
+				return err
+			}
+		}
+		return writer.exitError()
+	}
+
+	jobs := make(chan *batchRecord)
+	var wg sync.WaitGroup
+	var writeErr error
+	var writeOnce sync.Once
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for record := range jobs {
+				if err := writer.Write(c.runBatchRecord(ctx, run, record)); err != nil {
+					writeOnce.Do(func() { writeErr = err })
+				}
+			}
+		}()
+	}
+	for _, record := range records {
+		jobs <- record
+	}
+	close(jobs)
+	wg.Wait()
+	if writeErr != nil {
+		return writeErr
+	}
+	return writer.exitError()
+}
+
+// runBatchRecord executes one record through executeQuery, so elicitations
+// are handled exactly as for a single query. Stream output is discarded; the
+// record's own sink only collects token usage.
+func (c *ChatCmd) runBatchRecord(ctx context.Context, run *batchRun, record *batchRecord) *batchResult {
+	startedAt := time.Now()
+	worker := *c
+	worker.ndjson = newNDJSONSink(io.Discard)
+	result := &batchResult{Line: record.line, ID: record.ID, AgentID: c.AgentID}
+	if agentID := strings.TrimSpace(record.AgentID); agentID != "" {
+		result.AgentID = agentID
+	}
+	fail := func(err error) *batchResult {
+		result.ExitCode = 1
+		result.Error = err.Error()
+		result.DurationMs = time.Since(startedAt).Milliseconds()
+		fmt.Fprintf(os.Stderr, "[batch] line %d failed: %v\n", record.line, err)
+		return result
+	}
+
+	attachments := append([]*binding.Attachment(nil), run.baseAttach...)
+	if len(record.Attachments) > 0 {
+		extra, err := loadAttachments(resolveBatchPaths(record.Attachments, run.baseDir), run.attachOptions)
+		if err != nil {
+			return fail(err)
+		}
+		attachments = append(attachments, extra...)
+		if limit := run.attachOptions.maxTotal(); limit > 0 && attachmentBytes(attachments) > limit {
+			return fail(fmt.Errorf("attachments exceed the total size limit of %s (raise --attach-max-total)", formatByteSize(limit)))
+		}
+	}
+	contextData := map[string]interface{}{}
+	for key, value := range run.baseContext {
+		contextData[key] = value
+	}
+	for key, value := range record.Context {
+		contextData[key] = value
+	}
+
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(c.Timeout)*time.Second)
+		defer cancel()
+	}
+	var seedPayload map[string]interface{}
+	input := &agentsvc.QueryInput{
+		AgentID:        result.AgentID,
+		ConversationID: strings.TrimSpace(record.ConversationID),
+		Query:          strings.TrimSpace(record.Query),
+		UserId:         strings.TrimSpace(c.User),
+		ModelOverride:  run.modelOverride,
+		Context:        buildQueryContext(contextData, run.defaultPayload, nil),
+		Attachments:    attachments,
+	}
+	out, _, err := worker.executeQuery(ctx, run.client, input, run.defaultPayload, &seedPayload)
+	result.ConversationID = strings.TrimSpace(input.ConversationID)
+	if err != nil {
+		return fail(err)
+	}
+	worker.ndjson.AddTurnUsage(out)
+	result.Content = out.Content
+	if usage := worker.ndjson.Usage(); !usage.isZero() {
+		result.Usage = &usage
+	}
+	code, err := resolveConversationExitCode(ctx, run.client, result.ConversationID)
+	if err != nil {
+		return fail(fmt.Errorf("resolve exit code: %w", err))
+	}
+	result.ExitCode = code
+	result.DurationMs = time.Since(startedAt).Milliseconds()
+	return result
+}
+
+// readBatchRecords loads a JSONL batch file ("-" reads stdin). Blank lines
+// are skipped; every other line must be an object with a non-empty query.
+// The returned directory is used to resolve relative attachment paths.
+func readBatchRecords(path string) ([]*batchRecord, string, error) {
+	path = strings.TrimSpace(path)
+	var reader io.Reader
+	baseDir := "."
+	if path == "-" {
+		reader = os.Stdin
+	} else {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("open batch file: %w", err)
+		}
+		defer file.Close()
+		reader = file
+		baseDir = filepath.Dir(path)
+	}
+	records, err := parseBatchRecords(reader)
+	if err != nil {
+		return nil, "", err
+	}
+	return records, baseDir, nil
+}
+
+func parseBatchRecords(reader io.Reader) ([]*batchRecord, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxBatchRecordBytes)
+	var records []*batchRecord
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		record := &batchRecord{}
+		if err := json.Unmarshal([]byte(text), record); err != nil {
+			return nil, fmt.Errorf("batch line %d: %w", line, err)
+		}
+		if strings.TrimSpace(record.Query) == "" {
+			return nil, fmt.Errorf("batch line %d: query is required", line)
+		}
+		record.line = line
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read batch file: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("batch file has no records")
+	}
+	return records, nil
+}
+
+func resolveBatchPaths(paths []string, baseDir string) []string {
+	out := make([]string, 0, len(paths))
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path != "" && !filepath.IsAbs(path) && baseDir != "" {
+			path = filepath.Join(baseDir, path)
+		}
+		out = append(out, path)
+	}
+	return out
+}
+
+// batchResultWriter serializes result lines from concurrent workers and
+// tracks the worst outcome for the command exit status.
+type batchResultWriter struct {
+	mu       sync.Mutex
+	encoder  *json.Encoder
+	total    int
+	failed   int
+	maxCode  int
+	hadError bool
+}
+
+func (w *batchResultWriter) Write(result *batchResult) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.total++
+	if result.Error != "" {
+		w.hadError = true
+	}
+	if result.Error != "" || result.ExitCode != 0 {
+		w.failed++
+	}
+	if result.ExitCode > w.maxCode {
+		w.maxCode = result.ExitCode
+	}
+	if err := w.encoder.Encode(result); err != nil {
+		return fmt.Errorf("write batch result: %w", err)
+	}
+	return nil
+}
+
+// exitError reports the batch outcome: nil when every record succeeded,
+// otherwise the highest record exit code.
+func (w *batchResultWriter) exitError() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	fmt.Fprintf(os.Stderr, "[batch] %d completed, %d failed\n", w.total, w.failed)
+	code := w.maxCode
+	if code == 0 && w.hadError {
+		code = 1
+	}
+	if code != 0 {
+		return &commandExitCode{code: code}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package agently
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatCmd_ParsesBatchFlags(t *testing.T) {
+	cmd := &ChatCmd{}
+	parser := flags.NewParser(cmd, flags.HelpFlag|flags.PassDoubleDash)
+	_, err := parser.ParseArgs([]string{"--batch", "prompts.jsonl", "--concurrency", "4", "--batch-output", "results.jsonl"})
+	require.NoError(t, err)
+	assert.Equal(t, "prompts.jsonl", cmd.Batch)
+	assert.Equal(t, 4, cmd.Concurrency)
+	assert.Equal(t, "results.jsonl", cmd.BatchOut)
+	assert.False(t, cmd.BatchShared)
+}
+
+func TestChatCmd_CheckBatchPrompts(t *testing.T) {
+	assert.NoError(t, (&ChatCmd{Concurrency: 4}).checkBatchPrompts(false))
+	assert.NoError(t, (&ChatCmd{Concurrency: 1}).checkBatchPrompts(true))
+	assert.NoError(t, (&ChatCmd{Concurrency: 4, BatchShared: true}).checkBatchPrompts(true))
+	assert.NoError(t, (&ChatCmd{Concurrency: 4, ElicitDef: "{}"}).checkBatchPrompts(true))
+	assert.NoError(t, (&ChatCmd{Concurrency: 4, ElicitCmd: "./policy.sh"}).checkBatchPrompts(true))
+	assert.ErrorContains(t, (&ChatCmd{Concurrency: 4}).checkBatchPrompts(true), "--concurrency 4 on a terminal requires --elicitation-default or --elicitation-handler")
+}
+
+func TestParseBatchRecords(t *testing.T) {
+	input := strings.Join([]string{
+		`{"id":"t-1","query":"summarize","context":{"ticket":1},"attachments":["a.txt"]}`,
+		``,
+		`{"query":"triage","agent":"coder","conversationId":"conv-9"}`,
+	}, "\n")
+	records, err := parseBatchRecords(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "t-1", records[0].ID)
+	assert.Equal(t, 1, records[0].line)
+	assert.EqualValues(t, 1, records[0].Context["ticket"])
+	assert.Equal(t, []string{"a.txt"}, records[0].Attachments)
+	assert.Equal(t, 3, records[1].line)
+	assert.Equal(t, "coder", records[1].AgentID)
+	assert.Equal(t, "conv-9", records[1].ConversationID)
+}
+
+func TestParseBatchRecords_Errors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  string
+		expect string
+	}{
+		{name: "missing query", input: "{\"query\":\"ok\"}\n{\"id\":\"x\"}", expect: "batch line 2: query is required"},
+		{name: "invalid json", input: "{\"query\":", expect: "batch line 1"},
+		{name: "empty", input: "\n\n", expect: "no records"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseBatchRecords(strings.NewReader(tc.input))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expect)
+		})
+	}
+}
+
+func TestResolveBatchPaths(t *testing.T) {
+	abs := filepath.Join(t.TempDir(), "b.png")
+	got := resolveBatchPaths([]string{"a.txt", abs}, "batches")
+	assert.Equal(t, []string{filepath.Join("batches", "a.txt"), abs}, got)
+}
+
+func TestBatchResultWriter(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer := &batchResultWriter{encoder: json.NewEncoder(&buf)}
+		require.NoError(t, writer.Write(&batchResult{Line: 1, ConversationID: "c1", Content: "done"}))
+		assert.NoError(t, writer.exitError())
+
+		var result batchResult
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+		assert.Equal(t, "c1", result.ConversationID)
+		assert.Equal(t, "done", result.Content)
+	})
+
+	t.Run("highest exit code wins", func(t *testing.T) {
+		writer := &batchResultWriter{encoder: json.NewEncoder(&bytes.Buffer{})}
+		require.NoError(t, writer.Write(&batchResult{Line: 1, ExitCode: 2}))
+		require.NoError(t, writer.Write(&batchResult{Line: 2, ExitCode: 1, Error: "boom"}))
+		var exitErr *commandExitCode
+		require.True(t, errors.As(writer.exitError(), &exitErr))
+		assert.Equal(t, 2, exitErr.code)
+		assert.Equal(t, 2, writer.failed)
+	})
+}
@@ -0,0 +1,41 @@
+package agently
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatCmd_ParsesLocalFlags(t *testing.T) {
+	cmd := &ChatCmd{}
+	parser := flags.NewParser(cmd, flags.HelpFlag|flags.PassDoubleDash)
+	_, err := parser.ParseArgs([]string{"--local", "-w", "/tmp/ws", "--policy", "ask", "-q", "hi"})
+	require.NoError(t, err)
+	assert.True(t, cmd.Local)
+	assert.Equal(t, "/tmp/ws", cmd.Workspace)
+	assert.Equal(t, "ask", cmd.Policy)
+
+	cmd = &ChatCmd{}
+	parser = flags.NewParser(cmd, flags.HelpFlag|flags.PassDoubleDash)
+	_, err = parser.ParseArgs([]string{"-q", "hi"})
+	require.NoError(t, err)
+	assert.False(t, cmd.Local)
+	assert.Equal(t, "auto", cmd.Policy)
+}
+
+func TestChatCmd_WorkspaceRequiresLocal(t *testing.T) {
+	cmd := &ChatCmd{Workspace: "/tmp/ws", Query: []string{"hi"}}
+	err := cmd.Execute(nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requires --local")
+}
+
+func TestChatCmd_LocalRejectsAPI(t *testing.T) {
+	cmd := &ChatCmd{Local: true, API: "http://127.0.0.1:9191"}
+	_, err := cmd.startLocal(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--api")
+}
@@ -0,0 +1,183 @@
+package agently
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+const (
+	queryOutputText   = "text"
+	queryOutputNDJSON = "ndjson"
+)
+
+// ndjsonSink writes stream events and the closing summary of an
+// `agently query --output ndjson` run as one JSON object per line. It is
+// shared by the stream consumer goroutine and the command, so writes are
+// serialized.
+type ndjsonSink struct {
+	mu          sync.Mutex
+	w           io.Writer
+	streamUsage ndjsonUsage
+	turnUsage   ndjsonUsage
+}
+
+type ndjsonUsage struct {
+	PromptTokens     int `json:"promptTokens"`
+	CompletionTokens int `json:"completionTokens"`
+	TotalTokens      int `json:"totalTokens"`
+}
+
+type ndjsonSession struct {
+	Type      string `json:"type"`
+	Workspace string `json:"workspace,omitempty"`
+	AgentID   string `json:"agentId,omitempty"`
+	Model     string `json:"model,omitempty"`
+}
+
+type ndjsonSummary struct {
+	Type           string      `json:"type"`
+	ConversationID string      `json:"conversationId,omitempty"`
+	ExitCode       int         `json:"exitCode"`
+	Usage          ndjsonUsage `json:"usage"`
+	Error          string      `json:"error,omitempty"`
+}
+
+func newNDJSONSink(w io.Writer) *ndjsonSink {
+	return &ndjsonSink{w: w}
+}
+
+func (u *ndjsonUsage) add(other ndjsonUsage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += other.TotalTokens
+}
+
+func (u ndjsonUsage) isZero() bool {
+	return u.PromptTokens == 0 && u.CompletionTokens == 0 && u.TotalTokens == 0
+}
+
+// WriteEvent emits one stream event. Events are encoded as-is; the type is
+// filled in when the event payload does not carry it. Usage events are
+// accumulated for the summary record.
+func (s *ndjsonSink) WriteEvent(eventType string, event interface{}) error {
+	if s == nil {
+		return nil
+	}
+	record := map[string]interface{}{}
+	if err := reencodeJSON(event, &record); err != nil {
+		return fmt.Errorf("encode stream event: %w", err)
+	}
+	if _, ok := record["type"]; !ok && eventType != "" {
+		record["type"] = eventType
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if strings.EqualFold(strings.TrimSpace(eventType), "usage") {
+		if usage, ok := record["usage"].(map[string]interface{}); ok {
+			s.streamUsage.add(usageFromMap(usage))
+		} else {
+			s.streamUsage.add(usageFromMap(record))
+		}
+	}
+	return s.writeLocked(record)
+}
+
+// AddTurnUsage records usage reported on a query response. When any turn
+// reports usage, it takes precedence over usage accumulated from events.
+func (s *ndjsonSink) AddTurnUsage(output interface{}) {
+	if s == nil || output == nil {
+		return
+	}
+	record := map[string]interface{}{}
+	if err := reencodeJSON(output, &record); err != nil {
+		return
+	}
+	usage, ok := record["usage"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	s.turnUsage.add(usageFromMap(usage))
+	s.mu.Unlock()
+}
+
+// WriteSession emits the resolved workspace, agent and model before any
+// stream events, replacing the text-mode `[workspace]`/`[agent]` lines.
+func (s *ndjsonSink) WriteSession(workspace, agentID, model string) error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.writeLocked(&ndjsonSession{Type: "session", Workspace: workspace, AgentID: agentID, Model: model})
+}
+
+// WriteSummary emits the final record of a run.
+func (s *ndjsonSink) WriteSummary(conversationID string, exitCode int, runErr error) error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	summary := &ndjsonSummary{
+		Type:           "summary",
+		ConversationID: strings.TrimSpace(conversationID),
+		ExitCode:       exitCode,
+		Usage:          s.usageLocked(),
+	}
+	if runErr != nil {
+		summary.Error = runErr.Error()
+	}
+	return s.writeLocked(summary)
+}
+
+// Usage returns the token usage accumulated so far.
+func (s *ndjsonSink) Usage() ndjsonUsage {
+	if s == nil {
+		return ndjsonUsage{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.usageLocked()
+}
+
+func (s *ndjsonSink) usageLocked() ndjsonUsage {
+	usage := s.turnUsage
+	if usage.isZero() {
+		usage = s.streamUsage
+	}
+	if usage.TotalTokens == 0 {
+		usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	}
+	return usage
+}
+
+func (s *ndjsonSink) writeLocked(value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	_, err = s.w.Write(data)
+	return err
+}
+
+func usageFromMap(values map[string]interface{}) ndjsonUsage {
+	return ndjsonUsage{
+		PromptTokens:     firstIntField(values, "promptTokens", "prompt_tokens", "inputTokens", "input_tokens"),
+		CompletionTokens: firstIntField(values, "completionTokens", "completion_tokens", "outputTokens", "output_tokens"),
+		TotalTokens:      firstIntField(values, "totalTokens", "total_tokens"),
+	}
+}
+
+func firstIntField(values map[string]interface{}, keys ...string) int {
+	for _, key := range keys {
+		if number, ok := values[key].(float64); ok {
+			return int(number)
+		}
+	}
+	return 0
+}
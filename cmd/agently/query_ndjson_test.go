@@ -0,0 +1,100 @@
+package agently
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatCmd_ParsesOutputFlag(t *testing.T) {
+	cmd := &ChatCmd{}
+	parser := flags.NewParser(cmd, flags.HelpFlag|flags.PassDoubleDash)
+	_, err := parser.ParseArgs([]string{"-q", "hi", "--output", "ndjson"})
+	require.NoError(t, err)
+	assert.Equal(t, queryOutputNDJSON, cmd.Output)
+
+	cmd = &ChatCmd{}
+	parser = flags.NewParser(cmd, flags.HelpFlag|flags.PassDoubleDash)
+	_, err = parser.ParseArgs([]string{"-q", "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, queryOutputText, cmd.Output)
+
+	cmd = &ChatCmd{}
+	parser = flags.NewParser(cmd, flags.HelpFlag|flags.PassDoubleDash)
+	_, err = parser.ParseArgs([]string{"--output", "yaml"})
+	require.Error(t, err)
+}
+
+func TestNDJSONSink_WritesEventsAndSummary(t *testing.T) {
+	var buf bytes.Buffer
+	sink := newNDJSONSink(&buf)
+
+	require.NoError(t, sink.WriteSession("/ws", "coder", "openai_gpt-5.4"))
+	require.NoError(t, sink.WriteEvent("text_delta", map[string]interface{}{"content": "Hel"}))
+	require.NoError(t, sink.WriteEvent("tool_call_started", map[string]interface{}{"type": "tool_call_started", "toolName": "system/exec.execute"}))
+	require.NoError(t, sink.WriteEvent("usage", map[string]interface{}{"usage": map[string]interface{}{"promptTokens": 10, "completionTokens": 4}}))
+	require.NoError(t, sink.WriteEvent("usage", map[string]interface{}{"promptTokens": 3, "completionTokens": 1, "totalTokens": 4}))
+	require.NoError(t, sink.WriteSummary("conv-1", 2, nil))
+
+	records := decodeNDJSON(t, buf.Bytes())
+	require.Len(t, records, 6)
+	assert.Equal(t, "session", records[0]["type"])
+	assert.Equal(t, "coder", records[0]["agentId"])
+	assert.Equal(t, "text_delta", records[1]["type"])
+	assert.Equal(t, "Hel", records[1]["content"])
+	assert.Equal(t, "system/exec.execute", records[2]["toolName"])
+
+	summary := records[5]
+	assert.Equal(t, "summary", summary["type"])
+	assert.Equal(t, "conv-1", summary["conversationId"])
+	assert.EqualValues(t, 2, summary["exitCode"])
+	usage := summary["usage"].(map[string]interface{})
+	assert.EqualValues(t, 13, usage["promptTokens"])
+	assert.EqualValues(t, 5, usage["completionTokens"])
+	assert.EqualValues(t, 4, usage["totalTokens"])
+	_, hasError := summary["error"]
+	assert.False(t, hasError)
+}
+
+func TestNDJSONSink_TurnUsageTakesPrecedence(t *testing.T) {
+	var buf bytes.Buffer
+	sink := newNDJSONSink(&buf)
+	require.NoError(t, sink.WriteEvent("usage", map[string]interface{}{"promptTokens": 99}))
+	sink.AddTurnUsage(map[string]interface{}{"usage": map[string]interface{}{"prompt_tokens": 7, "completion_tokens": 3}})
+	require.NoError(t, sink.WriteSummary("conv-2", 1, errors.New("turn failed")))
+
+	records := decodeNDJSON(t, buf.Bytes())
+	summary := records[len(records)-1]
+	usage := summary["usage"].(map[string]interface{})
+	assert.EqualValues(t, 7, usage["promptTokens"])
+	assert.EqualValues(t, 3, usage["completionTokens"])
+	assert.EqualValues(t, 10, usage["totalTokens"])
+	assert.Equal(t, "turn failed", summary["error"])
+}
+
+func TestChatStreamerFlush_NDJSONPrintsNothing(t *testing.T) {
+	streamer := &chatStreamer{sink: newNDJSONSink(&bytes.Buffer{})}
+	output := captureStdout(t, func() {
+		assert.False(t, streamer.Flush("final answer"))
+	})
+	assert.Equal(t, "", output)
+}
+
+func decodeNDJSON(t *testing.T, data []byte) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		record := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record), "line: %s", scanner.Text())
+		records = append(records, record)
+	}
+	require.NoError(t, scanner.Err())
+	return records
+}
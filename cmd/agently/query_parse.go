@@ -4,15 +4,9 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"mime"
-	"net/http"
-	"net/http/cookiejar"
 	"os"
-	"path/filepath"
 	"strings"
 	"unicode"
-
-	"github.com/viant/agently-core/protocol/binding"
 )
 
 const scyInlineBase64Prefix = "inlined://base64/"
@@ -37,30 +31,6 @@ func parseContextArg(raw string) (map[string]interface{}, error) {
 	return result, nil
 }
 
-func parseAttachments(values []string) ([]*binding.Attachment, error) {
-	var out []*binding.Attachment
-	for _, item := range values {
-		path := strings.TrimSpace(item)
-		if path == "" {
-			return nil, fmt.Errorf("attachment path is required")
-		}
-		mimeType := mime.TypeByExtension(filepath.Ext(path))
-		if strings.TrimSpace(mimeType) == "" {
-			return nil, fmt.Errorf("unsupported attachment type for %q", path)
-		}
-		data, err := os.ReadFile(path)
-		if err != nil {
-			return nil, fmt.Errorf("read attachment %q: %w", path, err)
-		}
-		out = append(out, &binding.Attachment{
-			Name: filepath.Base(path),
-			Mime: mimeType,
-			Data: data,
-		})
-	}
-	return out, nil
-}
-
 func resolvedToken(flagValue string) string {
 	if token := strings.TrimSpace(flagValue); token != "" {
 		return token
@@ -88,11 +58,6 @@ func parseJSONArg(raw string) (map[string]interface{}, error) {
 	return payload, nil
 }
 
-func cliCookieJar() http.CookieJar {
-	jar, _ := cookiejar.New(nil)
-	return jar
-}
-
 func normalizeCLIContent(value string) string {
 	value = strings.TrimSpace(value)
 	if value == "" {
@@ -0,0 +1,204 @@
+package agently
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/viant/agently-core/protocol/binding"
+	"github.com/viant/agently-core/sdk"
+)
+
+// querySession is the mutable state of a query run. Interactive slash
+// commands change it between turns.
+type querySession struct {
+	client      *sdk.HTTPClient
+	agentID     string
+	model       string
+	convID      string
+	context     map[string]interface{}
+	attachments []*binding.Attachment // sent with the next turn, then cleared
+	// lastElicitationPayload seeds defaults of later elicitations in the
+	// same conversation.
+	lastElicitationPayload map[string]interface{}
+}
+
+const replHelp = `Commands:
+  /agent [id]        show or switch the agent
+  /model [id]        show or switch the model ("/model -" clears the override)
+  /attach <path>     attach a file, directory or glob to the next message
+  /context [k=v]     show or set a context value ("k=" removes it)
+  /new               start a new conversation with the next message
+  /transcript        print the current conversation
+  /tools             list available tools
+  /exitcode          show the current conversation exit code
+  /cancel            cancel a turn still running in the current conversation
+  /help              show this help
+  exit, quit         leave the session
+
+Press Ctrl-C while a turn runs to cancel it and return to the prompt.`
+
+func isSlashCommand(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "/")
+}
+
+// runSlashCommand executes one REPL command against the session. Failures
+// are reported to w and never end the session.
+func (c *ChatCmd) runSlashCommand(ctx context.Context, w io.Writer, session *querySession, line string) {
+	name, arg := splitSlashCommand(line)
+	switch name {
+	case "help", "?":
+		fmt.Fprintln(w, replHelp)
+	case "agent":
+		if arg == "" {
+			fmt.Fprintf(w, "[agent] %s\n", session.agentID)
+			return
+		}
+		session.agentID = arg
+		fmt.Fprintf(w, "[agent] %s\n", session.agentID)
+	case "model":
+		switch arg {
+		case "":
+		case "-":
+			session.model = ""
+		default:
+			session.model = arg
+		}
+		if session.model == "" {
+			fmt.Fprintln(w, "[model] <agent default>")
+			return
+		}
+		fmt.Fprintf(w, "[model] %s\n", session.model)
+	case "attach":
+		if arg == "" {
+			fmt.Fprintln(w, "usage: /attach <path>")
+			return
+		}
+		if arg == "-" {
+			fmt.Fprintln(w, "[attach] stdin cannot be attached in an interactive session")
+			return
+		}
+		options, err := c.attachmentOptions()
+		if err != nil {
+			fmt.Fprintf(w, "[attach] %v\n", err)
+			return
+		}
+		attachments, err := loadAttachments([]string{arg}, options)
+		if err != nil {
+			fmt.Fprintf(w, "[attach] %v\n", err)
+			return
+		}
+		// The limit covers everything sent with the next message, not
+		// each /attach on its own.
+		if limit := options.maxTotal(); limit > 0 && attachmentBytes(session.attachments)+attachmentBytes(attachments) > limit {
+			fmt.Fprintf(w, "[attach] queued attachments would exceed the total size limit of %s (raise --attach-max-total)\n", formatByteSize(limit))
+			return
+		}
+		session.attachments = append(session.attachments, attachments...)
+		for _, item := range attachments {
+			fmt.Fprintf(w, "[attach] %s (%s, %d bytes) queued for the next message\n", item.Name, item.Mime, len(item.Data))
+		}
+	case "context":
+		if arg == "" {
+			data, _ := json.MarshalIndent(session.context, "", "  ")
+			fmt.Fprintf(w, "[context] %s\n", string(data))
+			return
+		}
+		key, value, ok := strings.Cut(arg, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			fmt.Fprintln(w, "usage: /context key=value")
+			return
+		}
+		if session.context == nil {
+			session.context = map[string]interface{}{}
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			delete(session.context, key)
+			fmt.Fprintf(w, "[context] removed %s\n", key)
+			return
+		}
+		session.context[key] = parseContextValue(value)
+		fmt.Fprintf(w, "[context] %s=%s\n", key, value)
+	case "new":
+		session.convID = ""
+		session.lastElicitationPayload = nil
+		fmt.Fprintln(w, "[conversation] the next message starts a new conversation")
+	case "transcript":
+		if session.convID == "" {
+			fmt.Fprintln(w, "[transcript] no conversation yet")
+			return
+		}
+		transcript, err := session.client.GetTranscript(ctx, &sdk.GetTranscriptInput{ConversationID: session.convID})
+		if err != nil {
+			fmt.Fprintf(w, "[transcript] %v\n", err)
+			return
+		}
+		turns := transcriptTurnRows(transcript)
+		fmt.Fprintf(w, "[conversation-id] %s (%d turn(s))\n", session.convID, len(turns))
+		for _, turn := range turns {
+			fmt.Fprintf(w, "--- %s [%s]\n%s\n", turn.TurnID, turn.Status, turn.Content)
+		}
+	case "tools":
+		defs, err := session.client.ListToolDefinitions(ctx)
+		if err != nil {
+			fmt.Fprintf(w, "[tools] %v\n", err)
+			return
+		}
+		defs = filterToolDefinitions(defs, arg)
+		sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+		for _, def := range defs {
+			fmt.Fprintf(w, "%s\t%s\n", def.Name, truncateCell(def.Description, 80))
+		}
+		if len(defs) == 0 {
+			fmt.Fprintln(w, "[tools] no tools registered")
+		}
+	case "exitcode":
+		if session.convID == "" {
+			fmt.Fprintln(w, "[exit-code] 0 (no conversation yet)")
+			return
+		}
+		code, err := resolveConversationExitCode(ctx, session.client, session.convID)
+		if err != nil {
+			fmt.Fprintf(w, "[exit-code] %v\n", err)
+			return
+		}
+		fmt.Fprintf(w, "[exit-code] %d\n", code)
+	case "cancel":
+		if session.convID == "" {
+			fmt.Fprintln(w, "[cancel] no conversation yet")
+			return
+		}
+		turnID, err := cancelActiveTurn(ctx, session.client, session.convID)
+		switch {
+		case err != nil:
+			fmt.Fprintf(w, "[cancel] %v\n", err)
+		case turnID == "":
+			fmt.Fprintln(w, "[cancel] no running turn; press Ctrl-C while a turn runs to cancel it")
+		default:
+			fmt.Fprintf(w, "[cancel] canceled turn %s\n", turnID)
+		}
+	default:
+		fmt.Fprintf(w, "unknown command /%s; type /help\n", name)
+	}
+}
+
+func splitSlashCommand(line string) (string, string) {
+	line = strings.TrimPrefix(strings.TrimSpace(line), "/")
+	name, arg, _ := strings.Cut(line, " ")
+	return strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(arg)
+}
+
+// parseContextValue keeps JSON scalars, arrays and objects typed so
+// `/context limit=10` sends a number; anything else is sent as a string.
+func parseContextValue(raw string) interface{} {
+	var value interface{}
+	if err := json.Unmarshal([]byte(raw), &value); err == nil {
+		return value
+	}
+	return raw
+}
@@ -0,0 +1,129 @@
+package agently
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/viant/agently-core/sdk"
+)
+
+func TestRunSlashCommand_UpdatesSession(t *testing.T) {
+	cmd := &ChatCmd{}
+	session := &querySession{agentID: "chatter", model: "openai_gpt-5.4", convID: "conv-1", lastElicitationPayload: map[string]interface{}{"a": 1}}
+	run := func(line string) string {
+		var buf bytes.Buffer
+		cmd.runSlashCommand(context.Background(), &buf, session, line)
+		return buf.String()
+	}
+
+	assert.Equal(t, "[agent] coder\n", run("/agent coder"))
+	assert.Equal(t, "coder", session.agentID)
+	assert.Equal(t, "[agent] coder\n", run("/agent"))
+
+	assert.Equal(t, "[model] xai_grok-4\n", run("/model xai_grok-4"))
+	assert.Equal(t, "xai_grok-4", session.model)
+	assert.Equal(t, "[model] <agent default>\n", run("/model -"))
+	assert.Equal(t, "", session.model)
+
+	run("/context limit=10")
+	run("/context region=us-east")
+	run(`/context filter={"status":"open"}`)
+	assert.EqualValues(t, 10, session.context["limit"])
+	assert.Equal(t, "us-east", session.context["region"])
+	assert.Equal(t, map[string]interface{}{"status": "open"}, session.context["filter"])
+	assert.Equal(t, "[context] removed region\n", run("/context region="))
+	assert.NotContains(t, session.context, "region")
+	assert.Contains(t, run("/context nokey"), "usage")
+
+	assert.Contains(t, run("/new"), "new conversation")
+	assert.Equal(t, "", session.convID)
+	assert.Nil(t, session.lastElicitationPayload)
+
+	assert.Contains(t, run("/exitcode"), "no conversation yet")
+	assert.Contains(t, run("/bogus"), "unknown command /bogus")
+	assert.Contains(t, run("/help"), "/transcript")
+	assert.Contains(t, run("/help"), "Ctrl-C")
+	assert.Contains(t, run("/help"), "/cancel")
+}
+
+func TestRunSlashCommand_Cancel(t *testing.T) {
+	var posts int
+	transcriptStatus := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			posts++
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(transcriptStatus)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	client, err := sdk.NewHTTP(server.URL+"/", sdk.WithHTTPClient(server.Client()))
+	require.NoError(t, err)
+	run := func(session *querySession) string {
+		var buf bytes.Buffer
+		(&ChatCmd{}).runSlashCommand(context.Background(), &buf, session, "/cancel")
+		return buf.String()
+	}
+
+	assert.Equal(t, "[cancel] no conversation yet\n", run(&querySession{client: client}))
+
+	out := run(&querySession{client: client, convID: "conv-1"})
+	assert.Contains(t, out, "[cancel] no running turn")
+	assert.Contains(t, out, "Ctrl-C")
+	assert.Zero(t, posts, "nothing running, nothing to cancel")
+
+	transcriptStatus = http.StatusInternalServerError
+	assert.Contains(t, run(&querySession{client: client, convID: "conv-1"}), "[cancel] get transcript")
+}
+
+func TestRunSlashCommand_Attach(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	require.NoError(t, os.WriteFile(path, []byte("hello"), 0o644))
+	session := &querySession{}
+	var buf bytes.Buffer
+	(&ChatCmd{}).runSlashCommand(context.Background(), &buf, session, "/attach "+path)
+	require.Len(t, session.attachments, 1)
+	assert.Equal(t, "notes.txt", session.attachments[0].Name)
+	assert.Contains(t, buf.String(), "queued for the next message")
+
+	buf.Reset()
+	(&ChatCmd{}).runSlashCommand(context.Background(), &buf, session, "/attach "+filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Len(t, session.attachments, 1)
+	assert.Contains(t, buf.String(), "[attach]")
+}
+
+func TestRunSlashCommand_AttachTotalLimitSpansCalls(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "a.txt")
+	second := filepath.Join(dir, "b.txt")
+	require.NoError(t, os.WriteFile(first, []byte("hello"), 0o644))
+	require.NoError(t, os.WriteFile(second, []byte("world"), 0o644))
+	cmd := &ChatCmd{AttachMaxTotal: "8B"}
+	session := &querySession{}
+	var buf bytes.Buffer
+	cmd.runSlashCommand(context.Background(), &buf, session, "/attach "+first)
+	require.Len(t, session.attachments, 1)
+
+	buf.Reset()
+	cmd.runSlashCommand(context.Background(), &buf, session, "/attach "+second)
+	assert.Len(t, session.attachments, 1)
+	assert.Contains(t, buf.String(), "exceed the total size limit of 8B")
+}
+
+func TestIsActiveTurnStatus(t *testing.T) {
+	for _, status := range []string{"running", "waiting_for_user", "queued"} {
+		assert.True(t, isActiveTurnStatus(status), status)
+	}
+	for _, status := range []string{"", "completed", "Failed", "canceled", "cancelled"} {
+		assert.False(t, isActiveTurnStatus(status), status)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"strings"
@@ -21,7 +22,7 @@ func (c *ChatCmd) executeQuery(ctx context.Context, client *sdk.HTTPClient, inpu
 	if err := ensureConversation(ctx, client, input, strings.TrimSpace(input.Query)); err != nil {
 		return nil, false, err
 	}
-	inlineElicitation := len(defaultPayload) > 0 || stdinIsTTY()
+	inlineElicitation := len(defaultPayload) > 0 || c.elicitationHandler != "" || stdinIsTTY()
 	if inlineElicitation {
 		input.ElicitationMode = ""
 	} else {
@@ -29,7 +30,7 @@ func (c *ChatCmd) executeQuery(ctx context.Context, client *sdk.HTTPClient, inpu
 	}
 	streamCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
-	streamer, err := startChatStream(streamCtx, client, strings.TrimSpace(input.ConversationID))
+	streamer, err := startChatStream(streamCtx, client, strings.TrimSpace(input.ConversationID), c.ndjson)
 	if err != nil {
 		return nil, false, err
 	}
@@ -46,7 +47,7 @@ func (c *ChatCmd) executeQuery(ctx context.Context, client *sdk.HTTPClient, inpu
 		resolverWG.Add(1)
 		go func() {
 			defer resolverWG.Done()
-			watchPendingElicitations(resolverCtx, client, strings.TrimSpace(input.ConversationID), defaultPayload, seedPayload, c.elicitationTimeout, resolverErr)
+			watchPendingElicitations(resolverCtx, client, strings.TrimSpace(input.ConversationID), defaultPayload, seedPayload, c.elicitationTimeout, c.elicitationHandler, c.promptOutput, resolverErr)
 		}()
 	}
 	// Ensure the watcher goroutine has exited before the function returns so
@@ -93,9 +94,9 @@ func (c *ChatCmd) executeQuery(ctx context.Context, client *sdk.HTTPClient, inpu
 	}
 	if elicitation != nil && !inlineElicitation {
 		streamer.Close()
-		return nil, false, fmt.Errorf("elicitation required; run interactively or provide --elicitation-default")
+		return nil, false, fmt.Errorf("elicitation required; run interactively or provide --elicitation-default or --elicitation-handler")
 	}
-	content, err := waitForAssistantContent(ctx, client, streamer, strings.TrimSpace(out.ConversationID), startedAt, defaultPayload, seedPayload, c.elicitationTimeout)
+	content, err := waitForAssistantContent(ctx, client, streamer, strings.TrimSpace(out.ConversationID), startedAt, defaultPayload, seedPayload, c.elicitationTimeout, c.elicitationHandler, c.promptOutput)
 	if err != nil {
 		return nil, false, err
 	}
@@ -108,6 +109,9 @@ type chatStreamer struct {
 	sub     streamingrt.Subscription
 	done    chan struct{}
 	tracker *sdk.ConversationStreamTracker
+	// sink, when set, receives every event as an NDJSON record instead of
+	// the text rendering below.
+	sink *ndjsonSink
 	// mu guards the fields below against races between consume() (which
 	// writes) and Flush() (which reads). Close() blocks on done before Flush
 	// reads, but Close has a safety timeout so we keep the lock for
@@ -118,7 +122,7 @@ type chatStreamer struct {
 	tail    string
 }
 
-func startChatStream(ctx context.Context, client *sdk.HTTPClient, conversationID string) (*chatStreamer, error) {
+func startChatStream(ctx context.Context, client *sdk.HTTPClient, conversationID string, sink *ndjsonSink) (*chatStreamer, error) {
 	sub, err := client.StreamEvents(ctx, &sdk.StreamEventsInput{ConversationID: conversationID})
 	if err != nil {
 		return nil, fmt.Errorf("stream events: %w", err)
@@ -127,6 +131,7 @@ func startChatStream(ctx context.Context, client *sdk.HTTPClient, conversationID
 		sub:     sub,
 		done:    make(chan struct{}),
 		tracker: sdk.NewConversationStreamTracker(conversationID),
+		sink:    sink,
 	}
 	go streamer.consume()
 	return streamer, nil
@@ -144,6 +149,12 @@ func (s *chatStreamer) consume() {
 		if s.tracker != nil {
 			s.tracker.ApplyEvent(event)
 		}
+		if s.sink != nil {
+			if err := s.sink.WriteEvent(string(event.Type), event); err != nil {
+				fmt.Fprintf(os.Stderr, "[stream-error] %v\n", err)
+			}
+			continue
+		}
 		switch event.Type {
 		case streamingrt.EventTypeTextDelta:
 			if event.Content == "" {
@@ -198,6 +209,9 @@ func (s *chatStreamer) Flush(final string) bool {
 	if s == nil {
 		return false
 	}
+	if s.sink != nil {
+		return false
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	streamed := s.content.String()
@@ -268,7 +282,7 @@ func (s *chatStreamer) Close() {
 	}
 }
 
-func waitForAssistantContent(ctx context.Context, client *sdk.HTTPClient, streamer *chatStreamer, conversationID string, startedAt time.Time, defaultPayload map[string]interface{}, seedPayload *map[string]interface{}, elicitationTimeout time.Duration) (string, error) {
+func waitForAssistantContent(ctx context.Context, client *sdk.HTTPClient, streamer *chatStreamer, conversationID string, startedAt time.Time, defaultPayload map[string]interface{}, seedPayload *map[string]interface{}, elicitationTimeout time.Duration, handler string, promptOutput io.Writer) (string, error) {
 	_ = startedAt
 	if strings.TrimSpace(conversationID) == "" {
 		return "", nil
@@ -287,7 +301,7 @@ func waitForAssistantContent(ctx context.Context, client *sdk.HTTPClient, stream
 					return content, nil
 				}
 			}
-			if handled, err := handlePendingElicitation(ctx, client, conversationID, defaultPayload, seedPayload, elicitationTimeout); err != nil {
+			if handled, err := handlePendingElicitation(ctx, client, conversationID, defaultPayload, seedPayload, elicitationTimeout, handler, promptOutput); err != nil {
 				return "", err
 			} else if handled {
 				continue
@@ -358,18 +372,18 @@ func effectiveElicitationTimeout(configured time.Duration) time.Duration {
 	return defaultElicitationResponseTimeout
 }
 
-func resolveWithDeadline(ctx context.Context, client *sdk.HTTPClient, conversationID string, req *coreplan.Elicitation, defaultPayload map[string]interface{}, seedPayload *map[string]interface{}, timeout time.Duration) error {
+func resolveWithDeadline(ctx context.Context, client *sdk.HTTPClient, conversationID string, req *coreplan.Elicitation, defaultPayload map[string]interface{}, seedPayload *map[string]interface{}, timeout time.Duration, handler string, promptOutput io.Writer) error {
 	timeout = effectiveElicitationTimeout(timeout)
 	resolveCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
-	err := resolvePlannedElicitation(resolveCtx, client, conversationID, req, defaultPayload, seedPayload)
+	err := resolvePlannedElicitation(resolveCtx, client, conversationID, req, defaultPayload, seedPayload, handler, promptOutput)
 	if errors.Is(err, context.DeadlineExceeded) {
 		return fmt.Errorf("elicitation %q timed out after %s with no response", strings.TrimSpace(req.ElicitationId), timeout)
 	}
 	return err
 }
 
-func handlePendingElicitation(ctx context.Context, client *sdk.HTTPClient, conversationID string, defaultPayload map[string]interface{}, seedPayload *map[string]interface{}, timeout time.Duration) (bool, error) {
+func handlePendingElicitation(ctx context.Context, client *sdk.HTTPClient, conversationID string, defaultPayload map[string]interface{}, seedPayload *map[string]interface{}, timeout time.Duration, handler string, promptOutput io.Writer) (bool, error) {
 	rows, err := client.ListPendingElicitations(ctx, &sdk.ListPendingElicitationsInput{ConversationID: conversationID})
 	if err != nil {
 		return false, err
@@ -390,13 +404,13 @@ func handlePendingElicitation(ctx context.Context, client *sdk.HTTPClient, conve
 	if req == nil {
 		return false, nil
 	}
-	if err := resolveWithDeadline(ctx, client, conversationID, req, defaultPayload, seedPayload, timeout); err != nil {
+	if err := resolveWithDeadline(ctx, client, conversationID, req, defaultPayload, seedPayload, timeout, handler, promptOutput); err != nil {
 		return false, err
 	}
 	return true, nil
 }
 
-func watchPendingElicitations(ctx context.Context, client *sdk.HTTPClient, conversationID string, defaultPayload map[string]interface{}, seedPayload *map[string]interface{}, timeout time.Duration, errs chan<- error) {
+func watchPendingElicitations(ctx context.Context, client *sdk.HTTPClient, conversationID string, defaultPayload map[string]interface{}, seedPayload *map[string]interface{}, timeout time.Duration, handler string, promptOutput io.Writer, errs chan<- error) {
 	ticker := time.NewTicker(250 * time.Millisecond)
 	defer ticker.Stop()
 	resolved := map[string]struct{}{}
@@ -439,7 +453,7 @@ func watchPendingElicitations(ctx context.Context, client *sdk.HTTPClient, conve
 				continue
 			}
 			resolved[strings.TrimSpace(req.ElicitationId)] = struct{}{}
-			if err := resolveWithDeadline(ctx, client, conversationID, req, defaultPayload, seedPayload, timeout); err != nil {
+			if err := resolveWithDeadline(ctx, client, conversationID, req, defaultPayload, seedPayload, timeout, handler, promptOutput); err != nil {
 				select {
 				case errs <- err:
 				default:
@@ -483,13 +497,16 @@ func plannedElicitationFromPending(input *sdk.PendingElicitation) *coreplan.Elic
 	return req
 }
 
-func resolvePlannedElicitation(ctx context.Context, client *sdk.HTTPClient, conversationID string, req *coreplan.Elicitation, defaultPayload map[string]interface{}, seedPayload *map[string]interface{}) error {
+func resolvePlannedElicitation(ctx context.Context, client *sdk.HTTPClient, conversationID string, req *coreplan.Elicitation, defaultPayload map[string]interface{}, seedPayload *map[string]interface{}, handler string, promptOutput io.Writer) error {
 	if req == nil || strings.TrimSpace(req.ElicitationId) == "" {
 		return nil
 	}
 	if seedPayload != nil {
 		applyElicitationDefaults(req, *seedPayload)
 	}
+	if handler != "" {
+		return resolveWithHandler(ctx, client, handler, conversationID, req, seedPayload)
+	}
 	if len(defaultPayload) > 0 {
 		return client.ResolveElicitation(ctx, &sdk.ResolveElicitationInput{
 			ConversationID: conversationID,
@@ -499,9 +516,9 @@ func resolvePlannedElicitation(ctx context.Context, client *sdk.HTTPClient, conv
 		})
 	}
 	if !stdinIsTTY() {
-		return fmt.Errorf("elicitation required; run interactively or provide --elicitation-default")
+		return fmt.Errorf("elicitation required; run interactively or provide --elicitation-default or --elicitation-handler")
 	}
-	result, err := awaitCoreElicitation(ctx, req)
+	result, err := awaitCoreElicitation(ctx, promptOutput, req)
 	if err != nil || result == nil {
 		return err
 	}
@@ -0,0 +1,718 @@
+package agently
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/viant/agently-core/sdk"
+	"gopkg.in/yaml.v3"
+)
+
+// ScheduleCmd groups schedule management subcommands. They use the SDK
+// scheduler calls of a running server, the same ones the web UI makes.
+type ScheduleCmd struct {
+	List    *ScheduleListCmd    `command:"list" description:"List schedules"`
+	Create  *ScheduleCreateCmd  `command:"create" description:"Create schedules from a YAML file"`
+	Update  *ScheduleUpdateCmd  `command:"update" description:"Update schedules from a YAML file"`
+	Pause   *SchedulePauseCmd   `command:"pause" description:"Disable a schedule"`
+	Resume  *ScheduleResumeCmd  `command:"resume" description:"Enable a schedule"`
+	RunNow  *ScheduleRunNowCmd  `command:"run-now" description:"Trigger a schedule immediately"`
+	History *ScheduleHistoryCmd `command:"history" description:"Show runs of a schedule"`
+}
+
+type scheduleIDArg struct {
+	ID string `positional-arg-name:"id" description:"schedule ID"`
+}
+
+// ScheduleListCmd lists schedules visible to the caller.
+type ScheduleListCmd struct {
+	apiClientOptions
+	JSON bool `long:"json" description:"Print result as JSON instead of a table"`
+}
+
+// ScheduleCreateCmd creates one or more schedules described in YAML.
+type ScheduleCreateCmd struct {
+	apiClientOptions
+	File string `short:"f" long:"file" description:"YAML file with one schedule or a list of schedules (- for stdin)" required:"true"`
+	JSON bool   `long:"json" description:"Print result as JSON"`
+}
+
+// ScheduleUpdateCmd merges YAML fields into existing schedules.
+type ScheduleUpdateCmd struct {
+	apiClientOptions
+	Args scheduleIDArg `positional-args:"yes"`
+	File string        `short:"f" long:"file" description:"YAML file with the fields to change; entries are matched by id (- for stdin)" required:"true"`
+	JSON bool          `long:"json" description:"Print result as JSON"`
+}
+
+// SchedulePauseCmd disables a schedule without deleting it.
+type SchedulePauseCmd struct {
+	apiClientOptions
+	Args scheduleIDArg `positional-args:"yes" required:"yes"`
+	JSON bool          `long:"json" description:"Print result as JSON"`
+}
+
+// ScheduleResumeCmd re-enables a paused schedule.
+type ScheduleResumeCmd struct {
+	apiClientOptions
+	Args scheduleIDArg `positional-args:"yes" required:"yes"`
+	JSON bool          `long:"json" description:"Print result as JSON"`
+}
+
+// ScheduleRunNowCmd triggers a schedule outside of its cadence.
+type ScheduleRunNowCmd struct {
+	apiClientOptions
+	Args scheduleIDArg `positional-args:"yes" required:"yes"`
+	JSON bool          `long:"json" description:"Print result as JSON"`
+}
+
+// ScheduleHistoryCmd lists the runs of a schedule, newest first.
+type ScheduleHistoryCmd struct {
+	apiClientOptions
+	Args  scheduleIDArg `positional-args:"yes" required:"yes"`
+	Limit int           `long:"limit" description:"maximum number of runs to show (0 shows all)" default:"20"`
+	JSON  bool          `long:"json" description:"Print result as JSON instead of a table"`
+}
+
+// scheduleRow is the CLI view of a schedule. Mutations send the server's own
+// document back (see scheduleDoc) so fields the CLI does not know survive.
+type scheduleRow struct {
+	ID              string     `json:"id"`
+	Name            string     `json:"name,omitempty"`
+	Description     string     `json:"description,omitempty"`
+	AgentRef        string     `json:"agentRef,omitempty"`
+	ModelOverride   string     `json:"modelOverride,omitempty"`
+	Visibility      string     `json:"visibility,omitempty"`
+	Enabled         bool       `json:"enabled"`
+	ScheduleType    string     `json:"scheduleType,omitempty"`
+	CronExpr        string     `json:"cronExpr,omitempty"`
+	IntervalSeconds *int       `json:"intervalSeconds,omitempty"`
+	Timezone        string     `json:"timezone,omitempty"`
+	TaskPromptURI   string     `json:"taskPromptUri,omitempty"`
+	NextRunAt       *time.Time `json:"nextRunAt,omitempty"`
+	LastRunAt       *time.Time `json:"lastRunAt,omitempty"`
+	LastStatus      string     `json:"lastStatus,omitempty"`
+}
+
+// scheduleRunRow is one entry of `schedule history`.
+type scheduleRunRow struct {
+	ID             string     `json:"id"`
+	ScheduleID     string     `json:"scheduleId,omitempty"`
+	Status         string     `json:"status,omitempty"`
+	CreatedAt      *time.Time `json:"createdAt,omitempty"`
+	StartedAt      *time.Time `json:"startedAt,omitempty"`
+	CompletedAt    *time.Time `json:"completedAt,omitempty"`
+	DurationMs     int64      `json:"durationMs,omitempty"`
+	ConversationID string     `json:"conversationId,omitempty"`
+	ErrorMessage   string     `json:"errorMessage,omitempty"`
+}
+
+// scheduleDoc is a schedule as exchanged with the server or read from YAML.
+type scheduleDoc map[string]interface{}
+
+func (d scheduleDoc) id() string {
+	return stringValue(d["id"])
+}
+
+func (c *ScheduleListCmd) Execute(_ []string) error {
+	ctx := context.Background()
+	api, err := c.scheduleAPI(ctx)
+	if err != nil {
+		return err
+	}
+	docs, err := api.list(ctx)
+	if err != nil {
+		return err
+	}
+	rows, err := decodeScheduleRows(docs)
+	if err != nil {
+		return err
+	}
+	if c.JSON {
+		return printJSON(rows)
+	}
+	if len(rows) == 0 {
+		fmt.Println("no schedules found")
+		return nil
+	}
+	printScheduleTable(os.Stdout, rows)
+	return nil
+}
+
+func (c *ScheduleCreateCmd) Execute(_ []string) error {
+	ctx := context.Background()
+	docs, err := readScheduleDocs(c.File)
+	if err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		if doc.id() == "" {
+			doc["id"] = newScheduleID()
+		}
+		if err := validateScheduleDoc(doc); err != nil {
+			return err
+		}
+	}
+	api, err := c.scheduleAPI(ctx)
+	if err != nil {
+		return err
+	}
+	existing, err := api.list(ctx)
+	if err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		if findScheduleDoc(existing, doc.id()) != nil {
+			return fmt.Errorf("schedule %q already exists; use `agently schedule update`", doc.id())
+		}
+	}
+	if err := api.upsert(ctx, docs); err != nil {
+		return err
+	}
+	return printScheduleMutation(c.JSON, "created", docs)
+}
+
+func (c *ScheduleUpdateCmd) Execute(_ []string) error {
+	ctx := context.Background()
+	patches, err := readScheduleDocs(c.File)
+	if err != nil {
+		return err
+	}
+	if id := strings.TrimSpace(c.Args.ID); id != "" {
+		if len(patches) != 1 {
+			return fmt.Errorf("an explicit schedule id needs a file with exactly one schedule, got %d", len(patches))
+		}
+		if fileID := patches[0].id(); fileID != "" && fileID != id {
+			return fmt.Errorf("schedule id %q does not match id %q in %s", id, fileID, c.File)
+		}
+		patches[0]["id"] = id
+	}
+	api, err := c.scheduleAPI(ctx)
+	if err != nil {
+		return err
+	}
+	updated := make([]scheduleDoc, 0, len(patches))
+	for _, patch := range patches {
+		if patch.id() == "" {
+			return fmt.Errorf("every schedule in %s needs an id", c.File)
+		}
+		current, err := api.get(ctx, patch.id())
+		if err != nil {
+			return err
+		}
+		merged := mergeScheduleDoc(current, patch)
+		if err := validateScheduleDoc(merged); err != nil {
+			return err
+		}
+		updated = append(updated, merged)
+	}
+	if err := api.upsert(ctx, updated); err != nil {
+		return err
+	}
+	return printScheduleMutation(c.JSON, "updated", updated)
+}
+
+func (c *SchedulePauseCmd) Execute(_ []string) error {
+	return setScheduleEnabled(&c.apiClientOptions, c.Args.ID, false, c.JSON)
+}
+
+func (c *ScheduleResumeCmd) Execute(_ []string) error {
+	return setScheduleEnabled(&c.apiClientOptions, c.Args.ID, true, c.JSON)
+}
+
+func setScheduleEnabled(options *apiClientOptions, id string, enabled bool, asJSON bool) error {
+	ctx := context.Background()
+	api, err := options.scheduleAPI(ctx)
+	if err != nil {
+		return err
+	}
+	doc, err := api.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	doc = mergeScheduleDoc(doc, scheduleDoc{"enabled": enabled})
+	if err := api.upsert(ctx, []scheduleDoc{doc}); err != nil {
+		return err
+	}
+	action := "paused"
+	if enabled {
+		action = "resumed"
+	}
+	return printScheduleMutation(asJSON, action, []scheduleDoc{doc})
+}
+
+func (c *ScheduleRunNowCmd) Execute(_ []string) error {
+	ctx := context.Background()
+	api, err := c.scheduleAPI(ctx)
+	if err != nil {
+		return err
+	}
+	id := strings.TrimSpace(c.Args.ID)
+	if err := api.runNow(ctx, id); err != nil {
+		return err
+	}
+	if c.JSON {
+		return printJSON(map[string]interface{}{"triggered": id})
+	}
+	fmt.Printf("triggered %s; follow it with `agently schedule history %s`\n", id, id)
+	return nil
+}
+
+func (c *ScheduleHistoryCmd) Execute(_ []string) error {
+	ctx := context.Background()
+	api, err := c.scheduleAPI(ctx)
+	if err != nil {
+		return err
+	}
+	runs, err := api.runs(ctx, strings.TrimSpace(c.Args.ID))
+	if err != nil {
+		return err
+	}
+	if c.Limit > 0 && len(runs) > c.Limit {
+		runs = runs[:c.Limit]
+	}
+	if c.JSON {
+		return printJSON(runs)
+	}
+	if len(runs) == 0 {
+		fmt.Printf("no runs found for schedule %s\n", c.Args.ID)
+		return nil
+	}
+	printScheduleRunTable(os.Stdout, runs)
+	return nil
+}
+
+// scheduleAPI connects like any other client command and returns a scheduler
+// client that shares the authenticated session.
+func (o *apiClientOptions) scheduleAPI(ctx context.Context) (*scheduleClient, error) {
+	client, err := o.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token := serverToken(client.BaseURL(), contextToken(o.Token, o.API, o.Instance, o.Context))
+	return &scheduleClient{client: client, token: token}, nil
+}
+
+// scheduleClient adapts the SDK scheduler calls to schedule documents, which
+// keep fields the CLI does not model when a schedule is read and saved back.
+type scheduleClient struct {
+	client *sdk.HTTPClient
+	// token authorizes the run history request, which has no SDK call.
+	token string
+}
+
+// schedulerRunPath is the datly view of schedule runs behind the run history
+// grid of the web UI. Neither the SDK nor the scheduler service lists runs, so
+// the CLI reads the view the way the grid does, one page at a time.
+const schedulerRunPath = "/v1/api/agently/scheduler/run"
+
+// scheduleRunPageSize is the number of runs requested per page.
+const scheduleRunPageSize = 100
+
+// scheduleRunsResponse is the datly envelope of schedulerRunPath.
+type scheduleRunsResponse struct {
+	Status string            `json:"status,omitempty"`
+	Data   []*scheduleRunRow `json:"data"`
+	Info   *struct {
+		PageCount  int `json:"pageCount"`
+		TotalCount int `json:"totalCount"`
+	} `json:"info,omitempty"`
+}
+
+func (c *scheduleClient) list(ctx context.Context) ([]scheduleDoc, error) {
+	schedules, err := c.client.ListSchedules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list schedules: %w", err)
+	}
+	var docs []scheduleDoc
+	if err := reencodeJSON(schedules, &docs); err != nil {
+		return nil, fmt.Errorf("decode schedules: %w", err)
+	}
+	return docs, nil
+}
+
+func (c *scheduleClient) get(ctx context.Context, id string) (scheduleDoc, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, fmt.Errorf("schedule id is required")
+	}
+	schedule, err := c.client.GetSchedule(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get schedule %q: %w", id, err)
+	}
+	var doc scheduleDoc
+	if schedule != nil {
+		if err := reencodeJSON(schedule, &doc); err != nil {
+			return nil, fmt.Errorf("decode schedule %q: %w", id, err)
+		}
+	}
+	if doc.id() == "" {
+		return nil, fmt.Errorf("schedule %q not found", id)
+	}
+	return doc, nil
+}
+
+func (c *scheduleClient) upsert(ctx context.Context, docs []scheduleDoc) error {
+	var schedules []*sdk.Schedule
+	if err := reencodeJSON(docs, &schedules); err != nil {
+		return fmt.Errorf("encode schedules: %w", err)
+	}
+	if err := c.client.UpsertSchedules(ctx, schedules); err != nil {
+		return fmt.Errorf("save schedules: %w", err)
+	}
+	return nil
+}
+
+func (c *scheduleClient) runNow(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("schedule id is required")
+	}
+	if err := c.client.RunScheduleNow(ctx, id); err != nil {
+		return fmt.Errorf("run schedule %q: %w", id, err)
+	}
+	return nil
+}
+
+func (c *scheduleClient) runs(ctx context.Context, id string) ([]*scheduleRunRow, error) {
+	if id == "" {
+		return nil, fmt.Errorf("schedule id is required")
+	}
+	var out []*scheduleRunRow
+	seen := map[string]bool{}
+	for page := 1; ; page++ {
+		response, err := c.fetchRuns(ctx, id, page)
+		if err != nil {
+			return nil, fmt.Errorf("list runs of schedule %q: %w", id, err)
+		}
+		added := 0
+		for _, run := range response.Data {
+			if run == nil || (run.ScheduleID != "" && run.ScheduleID != id) || seen[run.ID] {
+				continue
+			}
+			seen[run.ID] = true
+			added++
+			if run.StartedAt != nil && run.CompletedAt != nil {
+				run.DurationMs = run.CompletedAt.Sub(*run.StartedAt).Milliseconds()
+			}
+			out = append(out, run)
+		}
+		// A page with nothing new means the server ignores paging and already
+		// returned every run.
+		if added == 0 || len(response.Data) < scheduleRunPageSize {
+			break
+		}
+		if response.Info != nil && page >= response.Info.PageCount {
+			break
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return scheduleRunTime(out[i]).After(scheduleRunTime(out[j]))
+	})
+	return out, nil
+}
+
+// fetchRuns reads one page of the runs of a schedule; pages start at 1.
+func (c *scheduleClient) fetchRuns(ctx context.Context, id string, page int) (*scheduleRunsResponse, error) {
+	query := url.Values{}
+	query.Set("scheduleId", id)
+	query.Set("_page", strconv.Itoa(page))
+	query.Set("_limit", strconv.Itoa(scheduleRunPageSize))
+	endpoint := strings.TrimRight(c.client.BaseURL(), "/") + schedulerRunPath + "?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	httpClient := c.client.HTTPClient()
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if message := schedulerErrorMessage(data); message != "" {
+			return nil, fmt.Errorf("%s: %s", resp.Status, message)
+		}
+		return nil, fmt.Errorf("%s", resp.Status)
+	}
+	response := &scheduleRunsResponse{}
+	if err := json.Unmarshal(data, response); err != nil {
+		return nil, fmt.Errorf("decode schedule runs: %w", err)
+	}
+	return response, nil
+}
+
+// schedulerErrorMessage extracts the message of an error response body.
+func schedulerErrorMessage(data []byte) string {
+	var payload struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(data, &payload); err == nil {
+		if message := strings.TrimSpace(payload.Error + " " + payload.Message); message != "" {
+			return message
+		}
+	}
+	return truncateCell(string(data), 200)
+}
+
+// readScheduleDocs loads one schedule or a list of schedules from a YAML (or
+// JSON) file.
+func readScheduleDocs(path string) ([]scheduleDoc, error) {
+	path = strings.TrimSpace(path)
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read schedules: %w", err)
+	}
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse schedules %s: %w", path, err)
+	}
+	if object, ok := raw.(map[string]interface{}); ok {
+		if list, ok := object["schedules"]; ok {
+			raw = list
+		} else {
+			raw = []interface{}{object}
+		}
+	}
+	list, ok := raw.([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, fmt.Errorf("%s: expected a schedule or a list of schedules", path)
+	}
+	docs := make([]scheduleDoc, 0, len(list))
+	for i, item := range list {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: schedule #%d is not a mapping", path, i+1)
+		}
+		docs = append(docs, scheduleDoc(object))
+	}
+	return docs, nil
+}
+
+// validateScheduleDoc applies the checks the schedule editor applies before
+// saving.
+func validateScheduleDoc(doc scheduleDoc) error {
+	var problems []string
+	if stringValue(doc["name"]) == "" {
+		problems = append(problems, "name is required")
+	}
+	if stringValue(doc["agentRef"]) == "" {
+		problems = append(problems, "agentRef is required")
+	}
+	if stringValue(doc["taskPrompt"]) == "" && stringValue(doc["taskPromptUri"]) == "" {
+		problems = append(problems, "taskPrompt or taskPromptUri is required")
+	}
+	switch scheduleType := stringValue(doc["scheduleType"]); scheduleType {
+	case "cron":
+		if stringValue(doc["cronExpr"]) == "" {
+			problems = append(problems, "cronExpr is required for scheduleType cron")
+		}
+	case "interval":
+		if seconds, ok := scheduleInt(doc["intervalSeconds"]); !ok || seconds <= 0 {
+			problems = append(problems, "intervalSeconds must be positive for scheduleType interval")
+		}
+	case "adhoc":
+	case "":
+		problems = append(problems, "scheduleType is required (cron, interval or adhoc)")
+	default:
+		problems = append(problems, fmt.Sprintf("unsupported scheduleType %q (cron, interval or adhoc)", scheduleType))
+	}
+	for _, key := range []string{"startAt", "endAt"} {
+		value := stringValue(doc[key])
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s %q is not an RFC3339 time", key, value))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	id := doc.id()
+	if id == "" {
+		id = stringValue(doc["name"])
+	}
+	return fmt.Errorf("schedule %q: %s", id, strings.Join(problems, "; "))
+}
+
+func scheduleInt(value interface{}) (int, bool) {
+	switch actual := value.(type) {
+	case int:
+		return actual, true
+	case int64:
+		return int(actual), true
+	case float64:
+		return int(actual), actual == float64(int(actual))
+	case string:
+		parsed, err := strconv.Atoi(strings.TrimSpace(actual))
+		return parsed, err == nil
+	}
+	return 0, false
+}
+
+func findScheduleDoc(docs []scheduleDoc, id string) scheduleDoc {
+	for _, doc := range docs {
+		if doc.id() == id {
+			return doc
+		}
+	}
+	return nil
+}
+
+// mergeScheduleDoc returns a copy of current with the patch fields applied.
+// Server-maintained bookkeeping is dropped so the upsert only carries editable
+// fields.
+func mergeScheduleDoc(current, patch scheduleDoc) scheduleDoc {
+	merged := scheduleDoc{}
+	for key, value := range current {
+		switch key {
+		case "nextRunAt", "lastRunAt", "lastStatus", "lastError", "createdAt", "updatedAt", "leaseOwner", "leaseUntil":
+			continue
+		}
+		merged[key] = value
+	}
+	for key, value := range patch {
+		merged[key] = value
+	}
+	return merged
+}
+
+func newScheduleID() string {
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return "sched_" + strconv.FormatInt(time.Now().UnixMilli(), 36) + "_" + hex.EncodeToString(suffix)
+}
+
+func decodeScheduleRows(docs []scheduleDoc) ([]*scheduleRow, error) {
+	rows := []*scheduleRow{}
+	if err := reencodeJSON(docs, &rows); err != nil {
+		return nil, fmt.Errorf("decode schedules: %w", err)
+	}
+	out := rows[:0]
+	for _, row := range rows {
+		if row != nil && strings.TrimSpace(row.ID) != "" {
+			out = append(out, row)
+		}
+	}
+	if len(out) == 0 {
+		return []*scheduleRow{}, nil
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func printScheduleMutation(asJSON bool, action string, docs []scheduleDoc) error {
+	if asJSON {
+		rows, err := decodeScheduleRows(docs)
+		if err != nil {
+			return err
+		}
+		return printJSON(map[string]interface{}{action: rows})
+	}
+	for _, doc := range docs {
+		fmt.Printf("%s %s (%s)\n", action, doc.id(), stringValue(doc["name"]))
+	}
+	return nil
+}
+
+func printScheduleTable(out io.Writer, rows []*scheduleRow) {
+	w := tabwriter.NewWriter(out, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tAGENT\tCADENCE\tENABLED\tNEXT RUN\tLAST STATUS")
+	for _, row := range rows {
+		lastStatus := row.LastStatus
+		if lastStatus == "" {
+			lastStatus = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\t%s\t%s\n", row.ID, truncateCell(row.Name, 40), row.AgentRef,
+			scheduleCadence(row), row.Enabled, formatConversationTime(row.NextRunAt), lastStatus)
+	}
+	_ = w.Flush()
+}
+
+func scheduleCadence(row *scheduleRow) string {
+	switch row.ScheduleType {
+	case "cron":
+		cadence := "cron " + row.CronExpr
+		if row.Timezone != "" {
+			cadence += " " + row.Timezone
+		}
+		return cadence
+	case "interval":
+		if row.IntervalSeconds != nil {
+			return "every " + (time.Duration(*row.IntervalSeconds) * time.Second).String()
+		}
+	}
+	if row.ScheduleType == "" {
+		return "-"
+	}
+	return row.ScheduleType
+}
+
+func printScheduleRunTable(out io.Writer, runs []*scheduleRunRow) {
+	w := tabwriter.NewWriter(out, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(w, "RUN\tSTATUS\tSTARTED\tDURATION\tCONVERSATION\tERROR")
+	for _, run := range runs {
+		started := run.StartedAt
+		if started == nil {
+			started = run.CreatedAt
+		}
+		conversationID := run.ConversationID
+		if conversationID == "" {
+			conversationID = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", run.ID, run.Status, formatConversationTime(started),
+			scheduleRunDuration(run), conversationID, truncateCell(run.ErrorMessage, 60))
+	}
+	_ = w.Flush()
+}
+
+func scheduleRunDuration(run *scheduleRunRow) string {
+	switch {
+	case run.StartedAt != nil && run.CompletedAt != nil:
+		return (time.Duration(run.DurationMs) * time.Millisecond).Round(time.Second / 10).String()
+	case run.StartedAt != nil:
+		return "running"
+	}
+	return "-"
+}
+
+func scheduleRunTime(run *scheduleRunRow) time.Time {
+	for _, value := range []*time.Time{run.StartedAt, run.CreatedAt} {
+		if value != nil {
+			return *value
+		}
+	}
+	return time.Time{}
+}
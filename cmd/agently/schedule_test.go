@@ -0,0 +1,222 @@
+package agently
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/viant/agently-core/sdk"
+)
+
+const fakeSchedulerPath = "/v1/api/agently/scheduler"
+
+// fakeScheduler serves the scheduler endpoints from memory.
+type fakeScheduler struct {
+	mu        sync.Mutex
+	schedules map[string]map[string]interface{}
+	runs      []map[string]interface{}
+	triggered []string
+	pages     int
+}
+
+func (f *fakeScheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Header.Get("Authorization") != "Bearer t0k" {
+		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+	schedulePath := fakeSchedulerPath + "/schedule/"
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == fakeSchedulerPath+"/":
+		var rows []map[string]interface{}
+		for _, row := range f.schedules {
+			rows = append(rows, row)
+		}
+		_ = json.NewEncoder(w).Encode(rows)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, schedulePath):
+		row, ok := f.schedules[strings.TrimPrefix(r.URL.Path, schedulePath)]
+		if !ok {
+			http.Error(w, `{"message":"schedule not found"}`, http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(row)
+	case r.Method == http.MethodPatch && r.URL.Path == fakeSchedulerPath+"/":
+		var body struct {
+			Schedules []map[string]interface{} `json:"schedules"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, row := range body.Schedules {
+			f.schedules[row["id"].(string)] = row
+		}
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	case r.Method == http.MethodPost && r.URL.Path == fakeSchedulerPath+"/run-now/nightly":
+		f.triggered = append(f.triggered, "nightly")
+		_, _ = w.Write([]byte(`{}`))
+	case r.Method == http.MethodGet && r.URL.Path == schedulerRunPath:
+		var rows []map[string]interface{}
+		for _, run := range f.runs {
+			if run["scheduleId"] == r.URL.Query().Get("scheduleId") {
+				rows = append(rows, run)
+			}
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("_page"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("_limit"))
+		pageCount := (len(rows) + limit - 1) / limit
+		from, to := min((page-1)*limit, len(rows)), min(page*limit, len(rows))
+		f.pages++
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "data": rows[from:to], "info": map[string]int{"pageCount": pageCount, "totalCount": len(rows)}})
+	default:
+		http.Error(w, `{"message":"schedule not found"}`, http.StatusNotFound)
+	}
+}
+
+func newFakeSchedulerClient(t *testing.T) (*fakeScheduler, *scheduleClient) {
+	fake := &fakeScheduler{schedules: map[string]map[string]interface{}{
+		"nightly": {
+			"id": "nightly", "name": "Nightly report", "agentRef": "coder", "enabled": true,
+			"scheduleType": "cron", "cronExpr": "0 2 * * *", "timezone": "UTC",
+			"taskPrompt": "summarize", "nextRunAt": "2026-10-17T02:00:00Z", "lastStatus": "succeeded",
+		},
+	}}
+	fake.runs = []map[string]interface{}{
+		{"id": "run-1", "scheduleId": "nightly", "status": "succeeded", "startedAt": "2026-10-15T02:00:00Z", "completedAt": "2026-10-15T02:01:30Z", "conversationId": "conv-1"},
+		{"id": "run-2", "scheduleId": "nightly", "status": "failed", "startedAt": "2026-10-16T02:00:00Z", "completedAt": "2026-10-16T02:00:05Z", "conversationId": "conv-2", "errorMessage": "model timeout"},
+		{"id": "run-3", "scheduleId": "other", "status": "succeeded"},
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	client, err := sdk.NewHTTP(server.URL, sdk.WithHTTPClient(server.Client()), sdk.WithAuthToken("t0k"))
+	require.NoError(t, err)
+	return fake, &scheduleClient{client: client, token: "t0k"}
+}
+
+func TestScheduleClient_ListAndHistory(t *testing.T) {
+	_, api := newFakeSchedulerClient(t)
+	ctx := context.Background()
+
+	docs, err := api.list(ctx)
+	require.NoError(t, err)
+	rows, err := decodeScheduleRows(docs)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "Nightly report", rows[0].Name)
+	assert.Equal(t, "cron 0 2 * * * UTC", scheduleCadence(rows[0]))
+
+	runs, err := api.runs(ctx, "nightly")
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, "run-2", runs[0].ID)
+	assert.Equal(t, "model timeout", runs[0].ErrorMessage)
+	assert.Equal(t, "conv-1", runs[1].ConversationID)
+	assert.EqualValues(t, 90000, runs[1].DurationMs)
+	assert.Equal(t, "1m30s", scheduleRunDuration(runs[1]))
+
+	var buf bytes.Buffer
+	printScheduleRunTable(&buf, runs)
+	assert.Contains(t, buf.String(), "conv-2")
+
+	_, err = api.get(ctx, "missing")
+	assert.ErrorContains(t, err, `get schedule "missing"`)
+	err = api.runNow(ctx, "missing")
+	assert.ErrorContains(t, err, `run schedule "missing"`)
+}
+
+func TestScheduleClient_HistoryReadsEveryPage(t *testing.T) {
+	fake, api := newFakeSchedulerClient(t)
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < scheduleRunPageSize+50; i++ {
+		fake.runs = append(fake.runs, map[string]interface{}{
+			"id": fmt.Sprintf("busy-%03d", i), "scheduleId": "busy", "status": "succeeded",
+			"startedAt": start.Add(time.Duration(i) * time.Minute).Format(time.RFC3339),
+		})
+	}
+
+	runs, err := api.runs(context.Background(), "busy")
+	require.NoError(t, err)
+	assert.Len(t, runs, scheduleRunPageSize+50)
+	assert.Equal(t, 2, fake.pages)
+	assert.Equal(t, fmt.Sprintf("busy-%03d", scheduleRunPageSize+49), runs[0].ID, "newest run first across pages")
+}
+
+func TestScheduleClient_PauseResumeAndRunNow(t *testing.T) {
+	fake, api := newFakeSchedulerClient(t)
+	ctx := context.Background()
+
+	doc, err := api.get(ctx, "nightly")
+	require.NoError(t, err)
+	paused := mergeScheduleDoc(doc, scheduleDoc{"enabled": false})
+	require.NoError(t, api.upsert(ctx, []scheduleDoc{paused}))
+	assert.Equal(t, false, fake.schedules["nightly"]["enabled"])
+	assert.Equal(t, "0 2 * * *", fake.schedules["nightly"]["cronExpr"])
+	assert.Empty(t, fake.schedules["nightly"]["nextRunAt"])
+
+	require.NoError(t, api.runNow(ctx, "nightly"))
+	assert.Equal(t, []string{"nightly"}, fake.triggered)
+
+	api.token = "wrong"
+	_, err = api.runs(ctx, "nightly")
+	assert.ErrorContains(t, err, "401 Unauthorized: unauthorized")
+}
+
+func TestReadScheduleDocs(t *testing.T) {
+	dir := t.TempDir()
+	single := filepath.Join(dir, "single.yaml")
+	require.NoError(t, os.WriteFile(single, []byte("id: weekly\nname: Weekly\nagentRef: coder\nscheduleType: interval\nintervalSeconds: 604800\ntaskPrompt: review\n"), 0o644))
+	docs, err := readScheduleDocs(single)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "weekly", docs[0].id())
+	assert.NoError(t, validateScheduleDoc(docs[0]))
+
+	list := filepath.Join(dir, "list.yaml")
+	require.NoError(t, os.WriteFile(list, []byte("schedules:\n  - name: A\n  - name: B\n"), 0o644))
+	docs, err = readScheduleDocs(list)
+	require.NoError(t, err)
+	assert.Len(t, docs, 2)
+
+	scalar := filepath.Join(dir, "scalar.yaml")
+	require.NoError(t, os.WriteFile(scalar, []byte("just text\n"), 0o644))
+	_, err = readScheduleDocs(scalar)
+	assert.ErrorContains(t, err, "expected a schedule or a list of schedules")
+}
+
+func TestValidateScheduleDoc(t *testing.T) {
+	testCases := []struct {
+		name   string
+		doc    scheduleDoc
+		expect string
+	}{
+		{name: "valid cron", doc: scheduleDoc{"id": "a", "name": "A", "agentRef": "coder", "taskPromptUri": "file://p.md", "scheduleType": "cron", "cronExpr": "* * * * *"}},
+		{name: "valid adhoc", doc: scheduleDoc{"id": "a", "name": "A", "agentRef": "coder", "taskPrompt": "go", "scheduleType": "adhoc"}},
+		{name: "missing fields", doc: scheduleDoc{"id": "a"}, expect: `schedule "a": name is required; agentRef is required; taskPrompt or taskPromptUri is required; scheduleType is required (cron, interval or adhoc)`},
+		{name: "cron without expression", doc: scheduleDoc{"id": "a", "name": "A", "agentRef": "coder", "taskPrompt": "go", "scheduleType": "cron"}, expect: "cronExpr is required"},
+		{name: "bad interval", doc: scheduleDoc{"id": "a", "name": "A", "agentRef": "coder", "taskPrompt": "go", "scheduleType": "interval", "intervalSeconds": 0}, expect: "intervalSeconds must be positive"},
+		{name: "bad start", doc: scheduleDoc{"id": "a", "name": "A", "agentRef": "coder", "taskPrompt": "go", "scheduleType": "adhoc", "startAt": "tomorrow"}, expect: `startAt "tomorrow" is not an RFC3339 time`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateScheduleDoc(tc.doc)
+			if tc.expect == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expect)
+		})
+	}
+}
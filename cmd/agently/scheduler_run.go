@@ -1,6 +1,8 @@
 package agently
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	root "github.com/viant/agently"
@@ -10,7 +12,10 @@ import (
 // Usage: agently scheduler run --interval 30s
 type SchedulerRunCmd struct {
 	Interval          string `long:"interval" description:"RunDue polling interval (e.g. 30s, 1m)" default:"30s"`
-	Once              bool   `long:"once" description:"Run one RunDue cycle and exit"`
+	Once              bool   `long:"once" description:"Run one RunDue cycle and exit; exits non-zero when a run fails"`
+	DryRun            bool   `long:"dry-run" description:"List which schedules are due and why without running them (implies --once)"`
+	Report            string `long:"report" description:"print a per-schedule cycle report (text or json) with --once or --dry-run" choice:"text" choice:"json"`
+	WaitTimeout       string `long:"wait-timeout" description:"with --once, how long to wait for started runs before reporting them as failed (e.g. 10m); defaults to the longest run timeout of the due schedules"`
 	ScratchpadRootURI string `short:"s" long:"scratchpad-root-uri" description:"User-scoped scratchpad URI template (overrides AGENTLY_SCRATCHPAD_URI when set)"`
 }
 
@@ -33,9 +38,30 @@ func (s *SchedulerRunCmd) schedulerRunOptions() (root.SchedulerRunOptions, error
 			interval = parsed
 		}
 	}
+	report := strings.ToLower(strings.TrimSpace(s.Report))
+	if report != "" && !s.Once && !s.DryRun {
+		return root.SchedulerRunOptions{}, fmt.Errorf("--report requires --once or --dry-run")
+	}
+	var waitTimeout time.Duration
+	if s.WaitTimeout != "" {
+		if !s.Once {
+			return root.SchedulerRunOptions{}, fmt.Errorf("--wait-timeout requires --once")
+		}
+		parsed, err := time.ParseDuration(s.WaitTimeout)
+		if err != nil {
+			return root.SchedulerRunOptions{}, fmt.Errorf("invalid --wait-timeout: %w", err)
+		}
+		if parsed <= 0 {
+			return root.SchedulerRunOptions{}, fmt.Errorf("--wait-timeout must be positive")
+		}
+		waitTimeout = parsed
+	}
 	return root.SchedulerRunOptions{
 		Interval:          interval,
-		Once:              s.Once,
+		Once:              s.Once || s.DryRun,
 		ScratchpadRootURI: s.ScratchpadRootURI,
+		DryRun:            s.DryRun,
+		Report:            report,
+		WaitTimeout:       waitTimeout,
 	}, nil
 }
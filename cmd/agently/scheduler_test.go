@@ -63,3 +63,64 @@ func TestMCPListCmd_ParsesOOBFlags(t *testing.T) {
 		t.Fatalf("expected oauth-scopes flag to parse, got %q", cmd.OAuthScp)
 	}
 }
+
+func TestSchedulerRunCmd_DryRunAndReport(t *testing.T) {
+	cmd := &SchedulerRunCmd{}
+	parser := flags.NewParser(cmd, flags.HelpFlag|flags.PassDoubleDash)
+	if _, err := parser.ParseArgs([]string{"--dry-run", "--report", "json"}); err != nil {
+		t.Fatalf("parse args: %v", err)
+	}
+	options, err := cmd.schedulerRunOptions()
+	if err != nil {
+		t.Fatalf("build scheduler options: %v", err)
+	}
+	if !options.DryRun || !options.Once || options.Report != "json" {
+		t.Fatalf("unexpected options: %+v", options)
+	}
+
+	cmd = &SchedulerRunCmd{}
+	parser = flags.NewParser(cmd, flags.HelpFlag|flags.PassDoubleDash)
+	if _, err := parser.ParseArgs([]string{"--report", "json"}); err != nil {
+		t.Fatalf("parse args: %v", err)
+	}
+	if _, err := cmd.schedulerRunOptions(); err == nil {
+		t.Fatalf("expected --report without --once to fail")
+	}
+	if _, err := parser.ParseArgs([]string{"--once", "--report", "yaml"}); err == nil {
+		t.Fatalf("expected unsupported report format to fail")
+	}
+}
+
+func TestSchedulerRunCmd_WaitTimeout(t *testing.T) {
+	parse := func(args ...string) (*SchedulerRunCmd, error) {
+		cmd := &SchedulerRunCmd{}
+		parser := flags.NewParser(cmd, flags.HelpFlag|flags.PassDoubleDash)
+		_, err := parser.ParseArgs(args)
+		return cmd, err
+	}
+	cmd, err := parse("--once", "--wait-timeout", "10m")
+	if err != nil {
+		t.Fatalf("parse args: %v", err)
+	}
+	options, err := cmd.schedulerRunOptions()
+	if err != nil {
+		t.Fatalf("build scheduler options: %v", err)
+	}
+	if options.WaitTimeout != 10*time.Minute {
+		t.Fatalf("WaitTimeout = %s, want 10m", options.WaitTimeout)
+	}
+
+	for _, args := range [][]string{
+		{"--wait-timeout", "10m"},
+		{"--once", "--wait-timeout", "soon"},
+		{"--once", "--wait-timeout", "0s"},
+	} {
+		cmd, err := parse(args...)
+		if err != nil {
+			t.Fatalf("parse %v: %v", args, err)
+		}
+		if _, err := cmd.schedulerRunOptions(); err == nil {
+			t.Fatalf("expected %v to fail", args)
+		}
+	}
+}
@@ -13,6 +13,7 @@ type ServeCmd struct {
 	ExposeMCP         bool   `long:"expose-mcp" description:"Expose Agently tools over an MCP HTTP server (requires mcpServer.port and tool patterns in config)"`
 	UIDist            string `long:"ui-dist" description:"Optional local UI dist directory override"`
 	Debug             bool   `short:"d" long:"debug" description:"Enable debug mode"`
+	Name              string `long:"name" description:"instance registry name used by --instance (default <workspace>-<port>)"`
 }
 
 func (c *ServeCmd) Execute(_ []string) error {
@@ -28,5 +29,6 @@ func (c *ServeCmd) serveOptions() root.ServeOptions {
 		Debug:             c.Debug,
 		Policy:            c.Policy,
 		ExposeMCP:         c.ExposeMCP,
+		Name:              c.Name,
 	}
 }
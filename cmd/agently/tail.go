@@ -0,0 +1,450 @@
+package agently
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/viant/agently-core/sdk"
+)
+
+// TailCmd follows the event stream of an existing conversation without
+// sending a query, e.g. a scheduled run or a turn started from the UI.
+type TailCmd struct {
+	apiClientOptions
+	ConvID    string        `short:"c" long:"conv" description:"conversation ID" required:"true"`
+	Output    string        `long:"output" description:"output format: text renders the stream, ndjson emits every stream event as a JSON line" choice:"text" choice:"ndjson" default:"text"`
+	Answer    bool          `long:"answer" description:"answer elicitations from this terminal (implied by --elicitation-default and --elicitation-handler)"`
+	ElicitDef string        `long:"elicitation-default" description:"JSON or @file to auto-accept elicitations"`
+	ElicitCmd string        `long:"elicitation-handler" description:"command that answers elicitations: gets each one as JSON on stdin, prints {\"action\":\"accept|decline|cancel\",\"payload\":{...}} on stdout"`
+	UntilDone bool          `long:"until-done" description:"exit once no turn is running; a failed or canceled turn exits non-zero"`
+	Reconnect time.Duration `long:"reconnect" description:"maximum delay between reconnect attempts after the stream drops (0 exits instead)" default:"30s"`
+}
+
+func (c *TailCmd) Execute(_ []string) error {
+	conversationID := strings.TrimSpace(c.ConvID)
+	defaultPayload, err := parseJSONArg(c.ElicitDef)
+	if err != nil {
+		return fmt.Errorf("parse --elicitation-default: %w", err)
+	}
+	handler := strings.TrimSpace(c.ElicitCmd)
+	answer := c.Answer || len(defaultPayload) > 0 || handler != ""
+	if answer && len(defaultPayload) == 0 && handler == "" && !stdinIsTTY() {
+		return fmt.Errorf("--answer needs a terminal; use --elicitation-default or --elicitation-handler otherwise")
+	}
+	tailer := newConversationTailer(os.Stdout)
+	var promptOutput io.Writer = os.Stdout
+	switch strings.ToLower(strings.TrimSpace(c.Output)) {
+	case "", queryOutputText:
+	case queryOutputNDJSON:
+		tailer.sink = newNDJSONSink(os.Stdout)
+		promptOutput = os.Stderr
+	default:
+		return fmt.Errorf("unsupported --output %q (expected text or ndjson)", c.Output)
+	}
+	tailer.answer = answer
+	tailer.untilDone = c.UntilDone
+	tailer.maxDelay = c.Reconnect
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	client, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+	tailer.open = func(ctx context.Context) (<-chan map[string]interface{}, func(), error) {
+		return openEventRecords(ctx, client, conversationID)
+	}
+	tailer.state = func(ctx context.Context) (*sdk.ConversationState, error) {
+		transcript, err := client.GetTranscript(ctx, &sdk.GetTranscriptInput{ConversationID: conversationID})
+		if err != nil {
+			return nil, fmt.Errorf("get transcript: %w", err)
+		}
+		if transcript == nil {
+			return nil, nil
+		}
+		return transcript.Conversation, nil
+	}
+	if answer {
+		errs := make(chan error, 1)
+		go watchPendingElicitations(ctx, client, conversationID, defaultPayload, nil, 0, handler, promptOutput, errs)
+		tailer.elicitationErrs = errs
+	}
+	err = tailer.follow(ctx)
+	if ctx.Err() != nil && errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// openEventRecords subscribes to the conversation stream and decodes every
+// event into a JSON record, so rendering does not depend on which fields the
+// SDK event type declares. Closing the subscription closes the channel.
+func openEventRecords(ctx context.Context, client *sdk.HTTPClient, conversationID string) (<-chan map[string]interface{}, func(), error) {
+	sub, err := client.StreamEvents(ctx, &sdk.StreamEventsInput{ConversationID: conversationID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("stream events: %w", err)
+	}
+	records := make(chan map[string]interface{})
+	go func() {
+		defer close(records)
+		for event := range sub.C() {
+			if event == nil {
+				continue
+			}
+			record := map[string]interface{}{}
+			if err := reencodeJSON(event, &record); err != nil {
+				continue
+			}
+			if _, ok := record["type"]; !ok {
+				record["type"] = string(event.Type)
+			}
+			select {
+			case records <- record:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return records, func() { _ = sub.Close() }, nil
+}
+
+// conversationTailer renders a conversation stream as it happens. The stream
+// only carries live events, so after a reconnect the transcript fills in what
+// was missed: any turn that started and ended while disconnected, and, once it
+// ends, the answer of a turn that was running across the reconnect.
+type conversationTailer struct {
+	out  io.Writer
+	sink *ndjsonSink
+
+	open  func(ctx context.Context) (<-chan map[string]interface{}, func(), error)
+	state func(ctx context.Context) (*sdk.ConversationState, error)
+
+	answer          bool
+	untilDone       bool
+	maxDelay        time.Duration
+	elicitationErrs <-chan error
+
+	// turnID is the running turn; streamed is the answer text printed for it.
+	turnID   string
+	streamed strings.Builder
+	midLine  bool
+	known    map[string]bool
+	// reconnected holds turns that were running across a reconnect; their
+	// end is reconciled with the transcript.
+	reconnected map[string]bool
+	// failure is the error --until-done exits with after a turn that did
+	// not complete.
+	failure error
+}
+
+func newConversationTailer(out io.Writer) *conversationTailer {
+	return &conversationTailer{out: out, known: map[string]bool{}, reconnected: map[string]bool{}}
+}
+
+// follow streams until ctx ends, the stream cannot be reopened, or with
+// untilDone, no turn is running.
+func (t *conversationTailer) follow(ctx context.Context) error {
+	state, err := t.state(ctx)
+	if err != nil {
+		return err
+	}
+	if t.attach(state) && t.untilDone {
+		return nil
+	}
+	delay := time.Second
+	for attempt := 0; ; attempt++ {
+		records, closeStream, err := t.open(ctx)
+		if err != nil && attempt == 0 {
+			return err
+		}
+		if err == nil {
+			if attempt > 0 {
+				if state, err = t.state(ctx); err == nil && t.resume(state) && t.untilDone {
+					closeStream()
+					return t.failure
+				}
+			}
+			delay = time.Second
+			done, err := t.consume(ctx, records)
+			closeStream()
+			if done || err != nil {
+				return err
+			}
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if t.maxDelay <= 0 {
+			return fmt.Errorf("event stream closed")
+		}
+		wait := min(delay, t.maxDelay)
+		fmt.Fprintf(os.Stderr, "[reconnect] event stream closed; retrying in %s\n", wait)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		delay *= 2
+	}
+}
+
+// consume renders records until the channel closes. done reports that
+// --until-done is satisfied.
+func (t *conversationTailer) consume(ctx context.Context, records <-chan map[string]interface{}) (bool, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		case err := <-t.elicitationErrs:
+			if err != nil && !isShutdownElicitationError(err) {
+				return true, err
+			}
+			t.elicitationErrs = nil
+		case record, ok := <-records:
+			if !ok {
+				return false, nil
+			}
+			if isTurnEndEvent(stringValue(record["type"])) {
+				if turnID := firstNonEmpty(stringValue(record["turnId"]), t.turnID); t.reconnected[turnID] {
+					t.reconcileTurnEnd(ctx, turnID, record)
+				}
+			}
+			if t.render(record) && t.untilDone {
+				return true, t.failure
+			}
+		}
+	}
+}
+
+// attach records the turns that already exist and reports whether none is
+// running.
+func (t *conversationTailer) attach(state *sdk.ConversationState) bool {
+	var running *sdk.TurnState
+	for _, turn := range stateTurns(state) {
+		t.known[turn.TurnID] = true
+		if isActiveTurnStatus(string(turn.Status)) {
+			running = turn
+		}
+	}
+	if running == nil {
+		if t.untilDone {
+			t.note("[tail] no turn is running")
+		} else {
+			t.note("[tail] no turn is running; waiting for the next one")
+		}
+		return true
+	}
+	t.startTurn(running.TurnID, "running")
+	return false
+}
+
+// resume reconciles the transcript after a reconnect and reports whether no
+// turn is running.
+func (t *conversationTailer) resume(state *sdk.ConversationState) bool {
+	running := false
+	for _, turn := range stateTurns(state) {
+		active := isActiveTurnStatus(string(turn.Status))
+		switch {
+		case turn.TurnID == t.turnID:
+		case t.known[turn.TurnID]:
+			continue
+		case active:
+			t.startTurn(turn.TurnID, "running")
+		default:
+			t.startTurn(turn.TurnID, "ran while disconnected")
+		}
+		if active {
+			running = true
+			t.reconnected[turn.TurnID] = true
+			continue
+		}
+		delete(t.reconnected, turn.TurnID)
+		if t.sink != nil {
+			record := map[string]interface{}{"type": "turn_" + strings.ToLower(string(turn.Status)), "turnId": turn.TurnID, "content": turnFinalContent(turn), "resumed": true}
+			if err := t.sink.WriteEvent("", record); err != nil {
+				fmt.Fprintf(os.Stderr, "[stream-error] %v\n", err)
+			}
+		} else {
+			t.catchUp(turnFinalContent(turn))
+		}
+		t.endTurn(string(turn.Status), "")
+	}
+	return !running
+}
+
+// reconcileTurnEnd fills in what the stream missed of a turn that was running
+// across a reconnect before its end record is rendered: the text rendering
+// prints the rest of the final answer, and ndjson carries it on the record.
+func (t *conversationTailer) reconcileTurnEnd(ctx context.Context, turnID string, record map[string]interface{}) {
+	delete(t.reconnected, turnID)
+	state, err := t.state(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[stream-error] %v\n", err)
+		return
+	}
+	for _, turn := range stateTurns(state) {
+		if turn.TurnID != turnID {
+			continue
+		}
+		final := turnFinalContent(turn)
+		if t.sink == nil {
+			t.catchUp(final)
+		} else if final != "" {
+			record["content"], record["resumed"] = final, true
+		}
+		return
+	}
+}
+
+// catchUp prints the part of final that was not streamed, or all of it on a
+// new line when the streamed text diverged.
+func (t *conversationTailer) catchUp(final string) {
+	if final == "" {
+		return
+	}
+	streamed := t.streamed.String()
+	if !strings.HasPrefix(final, streamed) {
+		t.endLine()
+		streamed = ""
+	}
+	t.text(final[len(streamed):])
+}
+
+// render prints one record and reports whether it ended a turn.
+func (t *conversationTailer) render(record map[string]interface{}) bool {
+	eventType := strings.ToLower(stringValue(record["type"]))
+	if turnID := stringValue(record["turnId"]); turnID != "" && turnID != t.turnID && !t.known[turnID] {
+		t.startTurn(turnID, "running")
+	}
+	if t.sink != nil {
+		if err := t.sink.WriteEvent(eventType, record); err != nil {
+			fmt.Fprintf(os.Stderr, "[stream-error] %v\n", err)
+		}
+	}
+	switch eventType {
+	case "text_delta":
+		t.text(normalizeCLIStreamDelta(cliStreamTail(t.streamed.String()), stringValue(record["content"])))
+	case "tool_call_started":
+		t.line("[tool] %s started", firstNonEmpty(stringValue(record["toolName"]), "tool"))
+	case "tool_call_completed", "tool_call_failed", "tool_call_canceled":
+		status := strings.TrimPrefix(eventType, "tool_call_")
+		if reason := stringValue(record["error"]); reason != "" {
+			t.line("[tool] %s %s: %s", firstNonEmpty(stringValue(record["toolName"]), "tool"), status, reason)
+		} else {
+			t.line("[tool] %s %s", firstNonEmpty(stringValue(record["toolName"]), "tool"), status)
+		}
+	case "elicitation_requested":
+		message := stringValue(record["content"])
+		if elicitation, ok := record["elicitation"].(map[string]interface{}); ok {
+			message = firstNonEmpty(stringValue(elicitation["message"]), message)
+		}
+		if t.answer {
+			t.line("[elicitation] %s", firstNonEmpty(message, "input requested"))
+		} else {
+			t.line("[elicitation] %s (answer it in the UI, or tail with --answer)", firstNonEmpty(message, "input requested"))
+		}
+	case "turn_completed", "turn_failed", "turn_canceled", "turn_cancelled":
+		t.endTurn(strings.TrimPrefix(eventType, "turn_"), stringValue(record["error"]))
+		return true
+	case "error":
+		if reason := stringValue(record["error"]); reason != "" {
+			fmt.Fprintf(os.Stderr, "[stream-error] %s\n", reason)
+		}
+	}
+	return false
+}
+
+func (t *conversationTailer) startTurn(turnID, state string) {
+	t.turnID = turnID
+	t.known[turnID] = true
+	t.streamed.Reset()
+	t.line("[turn] %s %s", turnID, state)
+}
+
+func (t *conversationTailer) endTurn(status, reason string) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if status == "cancelled" {
+		status = "canceled"
+	}
+	t.failure = nil
+	if status == "failed" || status == "canceled" {
+		t.failure = &commandExitCode{code: 1}
+	}
+	if reason != "" {
+		t.line("[turn] %s %s: %s", firstNonEmpty(t.turnID, "-"), status, reason)
+	} else {
+		t.line("[turn] %s %s", firstNonEmpty(t.turnID, "-"), status)
+	}
+	t.turnID = ""
+	t.streamed.Reset()
+}
+
+func (t *conversationTailer) text(value string) {
+	if value == "" {
+		return
+	}
+	t.streamed.WriteString(value)
+	if t.sink != nil {
+		return
+	}
+	fmt.Fprint(t.out, value)
+	t.midLine = !strings.HasSuffix(value, "\n")
+}
+
+// line prints a status line of the text rendering on its own line.
+func (t *conversationTailer) line(format string, args ...interface{}) {
+	if t.sink != nil {
+		return
+	}
+	t.endLine()
+	fmt.Fprintf(t.out, format+"\n", args...)
+}
+
+func (t *conversationTailer) endLine() {
+	if t.midLine {
+		fmt.Fprintln(t.out)
+		t.midLine = false
+	}
+}
+
+func (t *conversationTailer) note(message string) {
+	if t.sink != nil {
+		return
+	}
+	fmt.Fprintln(os.Stderr, message)
+}
+
+func isTurnEndEvent(eventType string) bool {
+	switch strings.ToLower(eventType) {
+	case "turn_completed", "turn_failed", "turn_canceled", "turn_cancelled":
+		return true
+	}
+	return false
+}
+
+func stateTurns(state *sdk.ConversationState) []*sdk.TurnState {
+	if state == nil {
+		return nil
+	}
+	var turns []*sdk.TurnState
+	for _, turn := range state.Turns {
+		if turn != nil && strings.TrimSpace(turn.TurnID) != "" {
+			turns = append(turns, turn)
+		}
+	}
+	return turns
+}
+
+func turnFinalContent(turn *sdk.TurnState) string {
+	if turn == nil || turn.Assistant == nil || turn.Assistant.Final == nil {
+		return ""
+	}
+	return strings.TrimSpace(turn.Assistant.Final.Content)
+}
@@ -0,0 +1,185 @@
+package agently
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/viant/agently-core/sdk"
+)
+
+func recordStream(records ...map[string]interface{}) <-chan map[string]interface{} {
+	ch := make(chan map[string]interface{}, len(records))
+	for _, record := range records {
+		ch <- record
+	}
+	close(ch)
+	return ch
+}
+
+func TestConversationTailer_Render(t *testing.T) {
+	var out bytes.Buffer
+	tailer := newConversationTailer(&out)
+	records := []map[string]interface{}{
+		{"type": "turn_started", "turnId": "turn-2"},
+		{"type": "text_delta", "turnId": "turn-2", "content": "Hel"},
+		{"type": "text_delta", "turnId": "turn-2", "content": "lo"},
+		{"type": "tool_call_started", "toolName": "system/exec:execute"},
+		{"type": "tool_call_failed", "toolName": "system/exec:execute", "error": "exit 1"},
+		{"type": "text_delta", "content": "Done."},
+		{"type": "elicitation_requested", "elicitation": map[string]interface{}{"message": "Pick one"}},
+	}
+	for _, record := range records {
+		assert.False(t, tailer.render(record))
+	}
+	assert.True(t, tailer.render(map[string]interface{}{"type": "turn_completed", "turnId": "turn-2"}))
+	assert.Equal(t, "[turn] turn-2 running\n"+
+		"Hello\n"+
+		"[tool] system/exec:execute started\n"+
+		"[tool] system/exec:execute failed: exit 1\n"+
+		"Done.\n"+
+		"[elicitation] Pick one (answer it in the UI, or tail with --answer)\n"+
+		"[turn] turn-2 completed\n", out.String())
+	assert.NoError(t, tailer.failure)
+}
+
+func TestConversationTailer_FollowResumesAfterReconnect(t *testing.T) {
+	var out bytes.Buffer
+	tailer := newConversationTailer(&out)
+	tailer.untilDone = true
+	tailer.maxDelay = time.Millisecond
+	states := []*sdk.ConversationState{
+		{Turns: []*sdk.TurnState{
+			{TurnID: "turn-1", Status: sdk.TurnStatusCompleted},
+			{TurnID: "turn-2", Status: "running"},
+		}},
+		{Turns: []*sdk.TurnState{
+			{TurnID: "turn-1", Status: sdk.TurnStatusCompleted},
+			{TurnID: "turn-2", Status: sdk.TurnStatusCompleted, Assistant: &sdk.AssistantState{Final: &sdk.AssistantMessageState{Content: "Partial answer"}}},
+			{TurnID: "turn-3", Status: sdk.TurnStatusCompleted, Assistant: &sdk.AssistantState{Final: &sdk.AssistantMessageState{Content: "Other"}}},
+		}},
+	}
+	tailer.state = func(context.Context) (*sdk.ConversationState, error) {
+		state := states[0]
+		states = states[1:]
+		return state, nil
+	}
+	opened := 0
+	tailer.open = func(context.Context) (<-chan map[string]interface{}, func(), error) {
+		opened++
+		switch opened {
+		case 1:
+			return recordStream(map[string]interface{}{"type": "text_delta", "turnId": "turn-2", "content": "Partial"}), func() {}, nil
+		case 2:
+			return nil, nil, errors.New("connection refused")
+		}
+		return make(chan map[string]interface{}), func() {}, nil
+	}
+
+	require.NoError(t, tailer.follow(context.Background()))
+	assert.Equal(t, 3, opened)
+	assert.Equal(t, "[turn] turn-2 running\n"+
+		"Partial answer\n"+
+		"[turn] turn-2 completed\n"+
+		"[turn] turn-3 ran while disconnected\n"+
+		"Other\n"+
+		"[turn] turn-3 completed\n", out.String())
+}
+
+func TestConversationTailer_ReconcilesTurnSpanningReconnect(t *testing.T) {
+	running := &sdk.ConversationState{Turns: []*sdk.TurnState{{TurnID: "turn-1", Status: "running"}}}
+	done := &sdk.ConversationState{Turns: []*sdk.TurnState{
+		{TurnID: "turn-1", Status: sdk.TurnStatusCompleted, Assistant: &sdk.AssistantState{Final: &sdk.AssistantMessageState{Content: "Partial answer."}}},
+	}}
+	follow := func(tailer *conversationTailer) {
+		states := []*sdk.ConversationState{running, running, done}
+		tailer.untilDone = true
+		tailer.maxDelay = time.Millisecond
+		tailer.state = func(context.Context) (*sdk.ConversationState, error) {
+			state := states[0]
+			states = states[1:]
+			return state, nil
+		}
+		opened := 0
+		tailer.open = func(context.Context) (<-chan map[string]interface{}, func(), error) {
+			opened++
+			if opened == 1 {
+				return recordStream(map[string]interface{}{"type": "text_delta", "turnId": "turn-1", "content": "Partial"}), func() {}, nil
+			}
+			// " answer" was emitted while disconnected.
+			return recordStream(
+				map[string]interface{}{"type": "text_delta", "turnId": "turn-1", "content": "."},
+				map[string]interface{}{"type": "turn_completed", "turnId": "turn-1"},
+			), func() {}, nil
+		}
+		require.NoError(t, tailer.follow(context.Background()))
+	}
+
+	var out bytes.Buffer
+	follow(newConversationTailer(&out))
+	assert.Equal(t, "[turn] turn-1 running\n"+
+		"Partial.\n"+
+		"Partial answer.\n"+
+		"[turn] turn-1 completed\n", out.String())
+
+	out.Reset()
+	tailer := newConversationTailer(&out)
+	tailer.sink = newNDJSONSink(&out)
+	follow(tailer)
+	assert.Contains(t, out.String(), `"content":"Partial answer."`)
+	assert.Contains(t, out.String(), `"resumed":true`)
+}
+
+func TestConversationTailer_FollowUntilDone(t *testing.T) {
+	idle := newConversationTailer(&bytes.Buffer{})
+	idle.untilDone = true
+	idle.state = func(context.Context) (*sdk.ConversationState, error) {
+		return &sdk.ConversationState{Turns: []*sdk.TurnState{{TurnID: "turn-1", Status: sdk.TurnStatusCompleted}}}, nil
+	}
+	idle.open = func(context.Context) (<-chan map[string]interface{}, func(), error) {
+		t.Fatal("an idle conversation is not streamed with --until-done")
+		return nil, nil, nil
+	}
+	require.NoError(t, idle.follow(context.Background()))
+
+	var out bytes.Buffer
+	failed := newConversationTailer(&out)
+	failed.untilDone = true
+	failed.state = func(context.Context) (*sdk.ConversationState, error) {
+		return &sdk.ConversationState{Turns: []*sdk.TurnState{{TurnID: "turn-1", Status: "running"}}}, nil
+	}
+	failed.open = func(context.Context) (<-chan map[string]interface{}, func(), error) {
+		return recordStream(map[string]interface{}{"type": "turn_failed", "turnId": "turn-1", "error": "model unavailable"}), func() {}, nil
+	}
+	err := failed.follow(context.Background())
+	var exitErr *commandExitCode
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 1, exitErr.code)
+	assert.Equal(t, "[turn] turn-1 running\n[turn] turn-1 failed: model unavailable\n", out.String())
+}
+
+func TestConversationTailer_NoReconnect(t *testing.T) {
+	tailer := newConversationTailer(&bytes.Buffer{})
+	tailer.state = func(context.Context) (*sdk.ConversationState, error) { return nil, nil }
+	tailer.open = func(context.Context) (<-chan map[string]interface{}, func(), error) {
+		return recordStream(), func() {}, nil
+	}
+	assert.EqualError(t, tailer.follow(context.Background()), "event stream closed")
+}
+
+func TestConversationTailer_NDJSONResume(t *testing.T) {
+	var out bytes.Buffer
+	tailer := newConversationTailer(&out)
+	tailer.sink = newNDJSONSink(&out)
+	tailer.turnID = "turn-1"
+	tailer.known["turn-1"] = true
+	done := tailer.resume(&sdk.ConversationState{Turns: []*sdk.TurnState{
+		{TurnID: "turn-1", Status: sdk.TurnStatusCompleted, Assistant: &sdk.AssistantState{Final: &sdk.AssistantMessageState{Content: "Answer"}}},
+	}})
+	assert.True(t, done)
+	assert.JSONEq(t, `{"type":"turn_completed","turnId":"turn-1","content":"Answer","resumed":true}`, out.String())
+}
@@ -15,6 +15,8 @@ import (
 type TranscriptCmd struct {
 	ConvID           string `short:"c" long:"conv" description:"conversation ID" required:"true"`
 	API              string `long:"api" description:"Agently base URL (skips auto-detect)"`
+	Instance         string `long:"instance" description:"registered instance name, port or pid to connect to (see agently instances)"`
+	Context          string `long:"context" description:"CLI context supplying unset server and auth flags; spelled --cli-context on query and chat (overrides AGENTLY_CONTEXT; see agently context)"`
 	Token            string `long:"token" description:"Bearer token for API requests (overrides AGENTLY_TOKEN)"`
 	OOB              string `long:"oob" description:"Use local scy OAuth2 out-of-band login with the supplied secrets URL"`
 	OAuthCfg         string `long:"oauth-config" description:"Optional scy OAuth config URL override for client-side OOB login"`
@@ -27,14 +29,15 @@ type TranscriptCmd struct {
 }
 
 func (c *TranscriptCmd) Execute(_ []string) error {
-	baseURL, providers, _, _, _, _, err := c.asChat().resolveBaseURL(context.Background())
+	chat := c.asChat()
+	baseURL, providers, _, _, _, _, err := chat.resolveBaseURL(context.Background())
 	if err != nil {
 		return err
 	}
 
-	httpClient := &http.Client{Jar: cliCookieJar()}
+	httpClient := &http.Client{Jar: cliCookieJar(baseURL)}
 	opts := []sdk.HTTPOption{sdk.WithHTTPClient(httpClient)}
-	if token := resolvedToken(c.Token); token != "" {
+	if token := serverToken(baseURL, chat.Token); token != "" {
 		opts = append(opts, sdk.WithAuthToken(token))
 	}
 	client, err := sdk.NewHTTP(baseURL, opts...)
@@ -44,7 +47,7 @@ func (c *TranscriptCmd) Execute(_ []string) error {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer cancel()
-	if err := c.asChat().ensureAuth(ctx, client, providers); err != nil {
+	if err := chat.ensureAuth(ctx, client, providers); err != nil {
 		return err
 	}
 
@@ -83,11 +86,13 @@ func (c *TranscriptCmd) Execute(_ []string) error {
 
 func (c *TranscriptCmd) asChat() *ChatCmd {
 	return &ChatCmd{
-		API:      strings.TrimSpace(c.API),
-		Token:    strings.TrimSpace(c.Token),
-		OOB:      strings.TrimSpace(c.OOB),
-		OAuthCfg: strings.TrimSpace(c.OAuthCfg),
-		OAuthScp: strings.TrimSpace(c.OAuthScp),
-		User:     strings.TrimSpace(c.User),
+		API:        strings.TrimSpace(c.API),
+		Instance:   strings.TrimSpace(c.Instance),
+		Token:      strings.TrimSpace(c.Token),
+		OOB:        strings.TrimSpace(c.OOB),
+		OAuthCfg:   strings.TrimSpace(c.OAuthCfg),
+		OAuthScp:   strings.TrimSpace(c.OAuthScp),
+		User:       strings.TrimSpace(c.User),
+		CLIContext: strings.TrimSpace(c.Context),
 	}
 }
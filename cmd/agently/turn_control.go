@@ -0,0 +1,186 @@
+package agently
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/viant/agently-core/sdk"
+)
+
+// activeTurnID returns the most recent turn of the conversation that has not
+// reached a terminal status, or "" when nothing is running.
+func activeTurnID(ctx context.Context, client *sdk.HTTPClient, conversationID string) (string, error) {
+	transcript, err := client.GetTranscript(ctx, &sdk.GetTranscriptInput{ConversationID: conversationID})
+	if err != nil {
+		return "", fmt.Errorf("get transcript: %w", err)
+	}
+	if transcript == nil || transcript.Conversation == nil {
+		return "", nil
+	}
+	turns := transcript.Conversation.Turns
+	for i := len(turns) - 1; i >= 0; i-- {
+		turn := turns[i]
+		if turn == nil || strings.TrimSpace(turn.TurnID) == "" {
+			continue
+		}
+		if isActiveTurnStatus(string(turn.Status)) {
+			return strings.TrimSpace(turn.TurnID), nil
+		}
+	}
+	return "", nil
+}
+
+func isActiveTurnStatus(status string) bool {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "", "completed", "succeeded", "success", "done", "failed", "error", "canceled", "cancelled", "terminated":
+		return false
+	}
+	return true
+}
+
+// cancelActiveTurn asks the server to cancel the running turn of a
+// conversation. It returns the canceled turn ID, or "" when no turn was
+// running.
+func cancelActiveTurn(ctx context.Context, client *sdk.HTTPClient, conversationID string) (string, error) {
+	conversationID = strings.TrimSpace(conversationID)
+	if conversationID == "" {
+		return "", fmt.Errorf("conversation ID is required")
+	}
+	turnID, err := activeTurnID(ctx, client, conversationID)
+	if err != nil || turnID == "" {
+		return "", err
+	}
+	canceled, err := client.CancelTurn(ctx, turnID)
+	if err != nil {
+		return "", fmt.Errorf("cancel turn %q: %w", turnID, err)
+	}
+	if !canceled {
+		return "", nil
+	}
+	return turnID, nil
+}
+
+// errTurnInterrupted reports a turn stopped with Ctrl-C.
+var errTurnInterrupted = errors.New("turn interrupted")
+
+// cancelOnInterrupt runs turn and turns the first interrupt into a server-side
+// cancel of the running turn, so Ctrl-C does not leave it running after the
+// CLI stops waiting. turn's context ends once the cancel request returns. A
+// second interrupt gives up waiting with exit status 130.
+func cancelOnInterrupt(ctx context.Context, interrupts <-chan os.Signal, cancelTurn func(context.Context) (string, error), turn func(context.Context) error) error {
+	turnCtx, stopTurn := context.WithCancel(ctx)
+	defer stopTurn()
+	done := make(chan error, 1)
+	go func() { done <- turn(turnCtx) }()
+	interrupted := false
+	for {
+		select {
+		case err := <-done:
+			if interrupted {
+				return errTurnInterrupted
+			}
+			return err
+		case <-interrupts:
+			if interrupted {
+				return &commandExitCode{code: 130}
+			}
+			interrupted = true
+			fmt.Fprintln(os.Stderr, "\n[cancel] canceling the running turn; press Ctrl-C again to quit")
+			go func() {
+				defer stopTurn()
+				cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+				defer cancel()
+				turnID, err := cancelTurn(cancelCtx)
+				switch {
+				case err != nil:
+					fmt.Fprintf(os.Stderr, "[cancel] %v\n", err)
+				case turnID == "":
+					fmt.Fprintln(os.Stderr, "[cancel] no running turn")
+				default:
+					fmt.Fprintf(os.Stderr, "[cancel] canceled turn %s\n", turnID)
+				}
+			}()
+		}
+	}
+}
+
+// steerActiveTurn adds a user message to the running turn of a conversation
+// and returns the steered turn ID.
+func steerActiveTurn(ctx context.Context, client *sdk.HTTPClient, conversationID, content string) (string, error) {
+	conversationID = strings.TrimSpace(conversationID)
+	if conversationID == "" {
+		return "", fmt.Errorf("conversation ID is required")
+	}
+	if strings.TrimSpace(content) == "" {
+		return "", fmt.Errorf("steering message is required")
+	}
+	turnID, err := activeTurnID(ctx, client, conversationID)
+	if err != nil {
+		return "", err
+	}
+	if turnID == "" {
+		return "", fmt.Errorf("conversation %s has no running turn; use agently query -c %s to continue it", conversationID, conversationID)
+	}
+	if err := steerTurn(ctx, client, conversationID, turnID, content); err != nil {
+		return "", err
+	}
+	return turnID, nil
+}
+
+// steerTurn posts a user message to a running turn.
+func steerTurn(ctx context.Context, client *sdk.HTTPClient, conversationID, turnID, content string) error {
+	if _, err := client.SteerTurn(ctx, conversationID, turnID, &sdk.SteerTurnInput{Content: content, Role: "user"}); err != nil {
+		return fmt.Errorf("steer turn %q: %w", turnID, err)
+	}
+	return nil
+}
+
+// CancelCmd cancels the running turn of a conversation on the server.
+type CancelCmd struct {
+	apiClientOptions
+	ConvID string `short:"c" long:"conv" description:"conversation ID" required:"true"`
+}
+
+func (c *CancelCmd) Execute(_ []string) error {
+	ctx := context.Background()
+	client, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+	turnID, err := cancelActiveTurn(ctx, client, c.ConvID)
+	if err != nil {
+		return err
+	}
+	if turnID == "" {
+		fmt.Printf("conversation %s has no running turn\n", strings.TrimSpace(c.ConvID))
+		return nil
+	}
+	fmt.Printf("canceled turn %s\n", turnID)
+	return nil
+}
+
+// SteerCmd sends a correction to the running turn of a conversation without
+// starting a new one.
+type SteerCmd struct {
+	apiClientOptions
+	ConvID string `short:"c" long:"conv" description:"conversation ID" required:"true"`
+	Query  string `short:"q" long:"query" description:"message to add to the running turn" required:"true"`
+}
+
+func (c *SteerCmd) Execute(_ []string) error {
+	ctx := context.Background()
+	client, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+	turnID, err := steerActiveTurn(ctx, client, c.ConvID, strings.TrimSpace(c.Query))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("steered turn %s\n", turnID)
+	return nil
+}
@@ -0,0 +1,94 @@
+package agently
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/viant/agently-core/sdk"
+)
+
+func TestCancelOnInterrupt(t *testing.T) {
+	t.Run("cancels the server turn", func(t *testing.T) {
+		interrupts := make(chan os.Signal, 1)
+		var canceled []string
+		err := cancelOnInterrupt(context.Background(), interrupts, func(context.Context) (string, error) {
+			canceled = append(canceled, "turn-1")
+			return "turn-1", nil
+		}, func(ctx context.Context) error {
+			interrupts <- os.Interrupt
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		assert.ErrorIs(t, err, errTurnInterrupted)
+		assert.Equal(t, []string{"turn-1"}, canceled)
+	})
+
+	t.Run("second interrupt stops waiting", func(t *testing.T) {
+		interrupts := make(chan os.Signal, 2)
+		release := make(chan struct{})
+		defer close(release)
+		err := cancelOnInterrupt(context.Background(), interrupts, func(context.Context) (string, error) {
+			interrupts <- os.Interrupt
+			<-release
+			return "", nil
+		}, func(ctx context.Context) error {
+			interrupts <- os.Interrupt
+			<-release
+			return nil
+		})
+		var exitErr *commandExitCode
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 130, exitErr.code)
+	})
+
+	t.Run("turn result passes through", func(t *testing.T) {
+		err := cancelOnInterrupt(context.Background(), make(chan os.Signal), func(context.Context) (string, error) {
+			t.Fatal("no interrupt, no cancel")
+			return "", nil
+		}, func(context.Context) error {
+			return assert.AnError
+		})
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
+
+func TestSteerTurn(t *testing.T) {
+	var body map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "Bearer token-1", r.Header.Get("Authorization"))
+		if r.URL.Path == "/v1/conversations/conv-1/turns/turn-done/steer" {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"error":"turn is not running"}`))
+			return
+		}
+		assert.Equal(t, "/v1/conversations/conv-1/turns/turn-1/steer", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	client, err := sdk.NewHTTP(server.URL+"/", sdk.WithHTTPClient(server.Client()), sdk.WithAuthToken("token-1"))
+	require.NoError(t, err)
+	require.NoError(t, steerTurn(context.Background(), client, "conv-1", "turn-1", "use the v2 API instead"))
+	assert.Equal(t, map[string]string{"content": "use the v2 API instead", "role": "user"}, body)
+
+	err = steerTurn(context.Background(), client, "conv-1", "turn-done", "stop")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `steer turn "turn-done"`)
+}
+
+func TestOptionsInit_TurnControl(t *testing.T) {
+	opts := &Options{}
+	opts.Init("cancel")
+	assert.NotNil(t, opts.Cancel)
+	opts = &Options{}
+	opts.Init("steer")
+	assert.NotNil(t, opts.Steer)
+}
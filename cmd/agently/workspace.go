@@ -0,0 +1,252 @@
+package agently
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/viant/agently-core/workspace"
+	"github.com/viant/agently/bootstrap"
+)
+
+// WorkspaceCmd groups workspace maintenance subcommands.
+type WorkspaceCmd struct {
+	Init     *WorkspaceInitCmd     `command:"init" description:"Seed a new workspace from a bundled template"`
+	Validate *WorkspaceValidateCmd `command:"validate" description:"Parse every workspace resource and report errors with file and line"`
+	Diff     *WorkspaceDiffCmd     `command:"diff" description:"Show drift between the workspace and the bundled defaults"`
+	Upgrade  *WorkspaceUpgradeCmd  `command:"upgrade" description:"Merge new bundled defaults without overwriting local edits"`
+}
+
+type workspaceRootOption struct {
+	Workspace string `short:"w" long:"workspace" description:"workspace root path (defaults to AGENTLY_WORKSPACE resolution)"`
+}
+
+func (o *workspaceRootOption) root() string {
+	if value := strings.TrimSpace(o.Workspace); value != "" {
+		return value
+	}
+	return workspace.Root()
+}
+
+// WorkspaceInitCmd seeds an empty workspace.
+type WorkspaceInitCmd struct {
+	workspaceRootOption
+	Template string `short:"t" long:"template" description:"bundled workspace template" default:"default"`
+	List     bool   `long:"list" description:"list available templates and exit"`
+}
+
+func (c *WorkspaceInitCmd) Execute(_ []string) error {
+	if c.List {
+		for _, tpl := range bootstrap.WorkspaceTemplates() {
+			fmt.Printf("%-10s %s\n", tpl.Name, tpl.Description)
+		}
+		return nil
+	}
+	root := c.root()
+	if err := bootstrap.InitWorkspace(root, c.Template, os.Getenv); err != nil {
+		return err
+	}
+	fmt.Printf("initialized workspace %s from template %q\n", root, strings.TrimSpace(c.Template))
+	return nil
+}
+
+// WorkspaceValidateCmd checks agents, models, embedders, bundles, feeds,
+// templates and prompts.
+type WorkspaceValidateCmd struct {
+	workspaceRootOption
+	Strict bool `long:"strict" description:"treat warnings as errors"`
+	JSON   bool `long:"json" description:"print issues as JSON"`
+}
+
+func (c *WorkspaceValidateCmd) Execute(_ []string) error {
+	root := c.root()
+	issues, err := validateWorkspace(context.Background(), root)
+	if err != nil {
+		return err
+	}
+	if c.JSON {
+		if issues == nil {
+			issues = []*workspaceIssue{}
+		}
+		if err := printJSON(issues); err != nil {
+			return err
+		}
+	} else {
+		for _, issue := range issues {
+			fmt.Println(issue.String())
+		}
+	}
+	errorCount := countIssues(issues, issueError)
+	warningCount := countIssues(issues, issueWarning)
+	if c.Strict {
+		errorCount += warningCount
+	}
+	if errorCount > 0 {
+		return fmt.Errorf("workspace %s: %d error(s), %d warning(s)", root, countIssues(issues, issueError), warningCount)
+	}
+	if !c.JSON {
+		fmt.Printf("workspace %s is valid (%d warning(s))\n", root, warningCount)
+	}
+	return nil
+}
+
+// WorkspaceDiffCmd reports drift from the embedded defaults.
+type WorkspaceDiffCmd struct {
+	workspaceRootOption
+	Patch    bool `short:"p" long:"patch" description:"print a line diff for modified files"`
+	All      bool `long:"all" description:"also list files that match the defaults"`
+	ExitCode bool `long:"exit-code" description:"exit with status 1 when the workspace differs from the defaults"`
+	JSON     bool `long:"json" description:"print the comparison as JSON"`
+}
+
+func (c *WorkspaceDiffCmd) Execute(_ []string) error {
+	diffs, err := bootstrap.DiffWorkspace(c.root())
+	if err != nil {
+		return err
+	}
+	drift := 0
+	var shown []*bootstrap.FileDiff
+	for _, diff := range diffs {
+		if diff.Status != bootstrap.DiffUnchanged {
+			drift++
+		} else if !c.All {
+			continue
+		}
+		shown = append(shown, diff)
+	}
+	if c.JSON {
+		if shown == nil {
+			shown = []*bootstrap.FileDiff{}
+		}
+		if err := printJSON(shown); err != nil {
+			return err
+		}
+	} else {
+		for _, diff := range shown {
+			fmt.Printf("%-9s %s\n", diff.Status, diff.Path)
+			if c.Patch && diff.Status == bootstrap.DiffModified {
+				fmt.Print(lineDiff("defaults/"+diff.Path, diff.Path, string(diff.Default), string(diff.Local)))
+			}
+		}
+		if drift == 0 {
+			fmt.Println("workspace matches the bundled defaults")
+		}
+	}
+	if c.ExitCode && drift > 0 {
+		return &commandExitCode{code: 1}
+	}
+	return nil
+}
+
+// WorkspaceUpgradeCmd merges newer bundled defaults into a workspace.
+type WorkspaceUpgradeCmd struct {
+	workspaceRootOption
+	DryRun bool `long:"dry-run" description:"report what would change without writing"`
+	JSON   bool `long:"json" description:"print changes as JSON"`
+}
+
+func (c *WorkspaceUpgradeCmd) Execute(_ []string) error {
+	root := c.root()
+	changes, err := bootstrap.UpgradeWorkspace(root, c.DryRun)
+	if err != nil {
+		return err
+	}
+	if c.JSON {
+		if changes == nil {
+			changes = []*bootstrap.UpgradeChange{}
+		}
+		return printJSON(changes)
+	}
+	for _, change := range changes {
+		line := fmt.Sprintf("%-8s %s", change.Action, change.Path)
+		if change.Reason != "" {
+			line += " (" + change.Reason + ")"
+		}
+		fmt.Println(line)
+	}
+	switch {
+	case len(changes) == 0:
+		fmt.Printf("workspace %s is up to date\n", root)
+	case c.DryRun:
+		fmt.Println("dry run: no files were written")
+	}
+	return nil
+}
+
+// lineDiff renders a unified-style diff of two texts with three lines of
+// context around each change.
+func lineDiff(fromName, toName, from, to string) string {
+	a := splitDiffLines(from)
+	b := splitDiffLines(to)
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	type op struct {
+		kind byte
+		text string
+	}
+	var ops []op
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, op{' ', a[i]})
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] > lcs[i+1][j]):
+			ops = append(ops, op{'+', b[j]})
+			j++
+		default:
+			ops = append(ops, op{'-', a[i]})
+			i++
+		}
+	}
+	const contextLines = 3
+	keep := make([]bool, len(ops))
+	for index, item := range ops {
+		if item.kind == ' ' {
+			continue
+		}
+		for k := index - contextLines; k <= index+contextLines; k++ {
+			if k >= 0 && k < len(ops) {
+				keep[k] = true
+			}
+		}
+	}
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+	for index, item := range ops {
+		if !keep[index] {
+			continue
+		}
+		if index > 0 && !keep[index-1] {
+			out.WriteString("@@\n")
+		}
+		out.WriteByte(item.kind)
+		out.WriteString(item.text)
+		out.WriteByte('\n')
+	}
+	return out.String()
+}
+
+func splitDiffLines(text string) []string {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
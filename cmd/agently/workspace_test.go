@@ -0,0 +1,80 @@
+package agently
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/viant/agently/bootstrap"
+)
+
+func TestValidateWorkspace_BundledDefaults(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "ws")
+	require.NoError(t, bootstrap.InitWorkspace(root, "default", func(string) string { return "" }))
+	issues, err := validateWorkspace(context.Background(), root)
+	require.NoError(t, err)
+	assert.Zero(t, countIssues(issues, issueError), "%v", issues)
+}
+
+func TestValidateWorkspace_ReportsFileAndLine(t *testing.T) {
+	root := writeAttachmentTree(t, map[string]string{
+		"config.yaml":         "default:\n  agent: helper\n  model: missing_model\n",
+		"models/good.yaml":    "id: good\noptions:\n  provider: openai\n  model: gpt\n",
+		"models/dup.yaml":     "id: good\noptions:\n  provider: openai\n  model: gpt\n",
+		"models/bad.yaml":     "id: bad\noptions:\n  provider: openai\n",
+		"models/broken.yaml":  "id: broken\noptions:\n  provider: [openai\n",
+		"embedders/list.yaml": "- id: nope\n",
+		"agents/helper/helper.yaml": "id: helper\nmodelRef: good\n" +
+			"prompt:\n  uri: prompt/user.tmpl\n" +
+			"tool:\n  bundles:\n    - custom\n" +
+			"template:\n  bundles:\n    - unknown-output\n",
+		"agents/helper/notes.yaml": "not an agent: true\n",
+		"feeds/untitled.yaml":      "match:\n  service: x\n",
+	})
+
+	issues, err := validateWorkspace(context.Background(), root)
+	require.NoError(t, err)
+	var lines []string
+	for _, issue := range issues {
+		lines = append(lines, issue.String())
+	}
+	assert.Equal(t, []string{
+		`agents/helper/helper.yaml:4: error: prompt.uri "prompt/user.tmpl" not found`,
+		`agents/helper/helper.yaml:7: warning: tool bundle "custom" is not defined in tools/bundles`,
+		`agents/helper/helper.yaml:10: error: unknown template bundle "unknown-output"`,
+		`config.yaml:3: error: unknown model "missing_model"`,
+		`embedders/list.yaml:1: error: expected a mapping at the top level`,
+		`feeds/untitled.yaml:1: error: feed is missing required field "id"`,
+		`models/bad.yaml:3: error: model is missing required field "options.model"`,
+		`models/broken.yaml:2: error: invalid YAML: did not find expected ',' or ']'`,
+		`models/good.yaml:1: error: duplicate model id "good"`,
+	}, lines)
+}
+
+func TestLineDiff(t *testing.T) {
+	from := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	to := "a\nb\nC\nd\ne\nf\ng\nh\ni\nj\nk\n"
+	assert.Equal(t, "--- defaults/x\n+++ x\n"+
+		"a\nb\n-c\n+C\nd\ne\nf\n"+
+		"@@\nh\ni\nj\n+k\n",
+		stripDiffContextPrefix(lineDiff("defaults/x", "x", from, to)))
+	assert.Equal(t, "--- a\n+++ b\n", lineDiff("a", "b", "same\n", "same\n"))
+}
+
+// stripDiffContextPrefix drops the leading space of context lines to keep
+// expectations readable.
+func stripDiffContextPrefix(diff string) string {
+	var out []byte
+	atLineStart := true
+	for i := 0; i < len(diff); i++ {
+		if atLineStart && diff[i] == ' ' {
+			atLineStart = false
+			continue
+		}
+		atLineStart = diff[i] == '\n'
+		out = append(out, diff[i])
+	}
+	return string(out)
+}
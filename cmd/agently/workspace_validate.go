@@ -0,0 +1,376 @@
+package agently
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/viant/afs"
+	templ "github.com/viant/agently-core/protocol/template"
+	meta "github.com/viant/agently-core/workspace/service/meta"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	issueError   = "error"
+	issueWarning = "warning"
+)
+
+// workspaceIssue is one validation finding, located by workspace-relative
+// path and 1-based line (0 when the problem concerns the whole file).
+type workspaceIssue struct {
+	Path     string `json:"path"`
+	Line     int    `json:"line,omitempty"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+func (i *workspaceIssue) String() string {
+	location := i.Path
+	if i.Line > 0 {
+		location += ":" + strconv.Itoa(i.Line)
+	}
+	return fmt.Sprintf("%s: %s: %s", location, i.Severity, i.Message)
+}
+
+// workspaceDoc is a parsed workspace YAML resource.
+type workspaceDoc struct {
+	kind string
+	path string // workspace-relative, slash separated
+	root *yaml.Node
+	id   string
+}
+
+// workspaceValidator parses every workspace resource and checks required
+// fields and cross references (agent -> model, bundles, prompts; config ->
+// defaults).
+type workspaceValidator struct {
+	root   string
+	issues []*workspaceIssue
+	docs   map[string][]*workspaceDoc
+	ids    map[string]map[string]bool
+}
+
+func validateWorkspace(ctx context.Context, root string) ([]*workspaceIssue, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("workspace %s: %w", root, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("workspace %s is not a directory", root)
+	}
+	v := &workspaceValidator{root: root, docs: map[string][]*workspaceDoc{}, ids: map[string]map[string]bool{}}
+	for _, source := range []struct {
+		kind    string
+		pattern string
+	}{
+		{kind: "model", pattern: "models/*.yaml"},
+		{kind: "embedder", pattern: "embedders/*.yaml"},
+		{kind: "agent", pattern: "agents/*.yaml"},
+		{kind: "agent", pattern: "agents/*/*.yaml"},
+		{kind: "feed", pattern: "feeds/*.yaml"},
+		{kind: "tool bundle", pattern: "tools/bundles/*.yaml"},
+		{kind: "template", pattern: "templates/*.yaml"},
+		{kind: "template bundle", pattern: "templates/bundles/*.yaml"},
+		{kind: "prompt", pattern: "prompts/*.yaml"},
+	} {
+		if err := v.load(source.kind, source.pattern); err != nil {
+			return nil, err
+		}
+	}
+	for _, kind := range []string{"model", "embedder", "agent", "feed", "tool bundle", "template", "template bundle", "prompt"} {
+		for _, doc := range v.docs[kind] {
+			v.checkDoc(ctx, doc)
+		}
+	}
+	v.checkConfig()
+	sort.SliceStable(v.issues, func(i, j int) bool {
+		if v.issues[i].Path != v.issues[j].Path {
+			return v.issues[i].Path < v.issues[j].Path
+		}
+		return v.issues[i].Line < v.issues[j].Line
+	})
+	return v.issues, nil
+}
+
+func (v *workspaceValidator) load(kind, pattern string) error {
+	matches, err := filepath.Glob(filepath.Join(v.root, filepath.FromSlash(pattern)))
+	if err != nil {
+		return err
+	}
+	sort.Strings(matches)
+	for _, match := range matches {
+		rel, _ := filepath.Rel(v.root, match)
+		rel = filepath.ToSlash(rel)
+		// agents/<name>/ holds one agent definition named after the folder;
+		// other YAML files in it are agent assets.
+		if kind == "agent" && strings.Count(rel, "/") == 2 {
+			dir := filepath.Base(filepath.Dir(match))
+			if strings.TrimSuffix(filepath.Base(match), ".yaml") != dir {
+				continue
+			}
+		}
+		node, ok := v.parse(rel, match)
+		if !ok {
+			continue
+		}
+		doc := &workspaceDoc{kind: kind, path: rel, root: node, id: scalarField(node, "id")}
+		if doc.id != "" {
+			if v.ids[kind] == nil {
+				v.ids[kind] = map[string]bool{}
+			}
+			if v.ids[kind][doc.id] {
+				v.add(rel, fieldLine(node, "id"), issueError, fmt.Sprintf("duplicate %s id %q", kind, doc.id))
+			}
+			v.ids[kind][doc.id] = true
+		}
+		v.docs[kind] = append(v.docs[kind], doc)
+	}
+	return nil
+}
+
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+// parse decodes a YAML file into its mapping node, reporting syntax errors
+// and non-mapping documents.
+func (v *workspaceValidator) parse(rel, filename string) (*yaml.Node, bool) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		v.add(rel, 0, issueError, err.Error())
+		return nil, false
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		line := 0
+		if match := yamlErrorLine.FindStringSubmatch(err.Error()); match != nil {
+			line, _ = strconv.Atoi(match[1])
+		}
+		message := strings.TrimPrefix(err.Error(), "yaml: ")
+		message = yamlErrorLine.ReplaceAllString(message, "")
+		message = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(message), ":"))
+		v.add(rel, line, issueError, "invalid YAML: "+message)
+		return nil, false
+	}
+	if len(document.Content) == 0 {
+		v.add(rel, 0, issueError, "file is empty")
+		return nil, false
+	}
+	node := document.Content[0]
+	if node.Kind != yaml.MappingNode {
+		v.add(rel, node.Line, issueError, "expected a mapping at the top level")
+		return nil, false
+	}
+	return node, true
+}
+
+func (v *workspaceValidator) checkDoc(ctx context.Context, doc *workspaceDoc) {
+	switch doc.kind {
+	case "model", "embedder":
+		v.require(doc, "id", "options", "options.provider", "options.model")
+	case "agent":
+		v.require(doc, "id")
+		v.checkAgent(doc)
+	case "feed":
+		v.require(doc, "id")
+	case "tool bundle":
+		v.require(doc, "id", "match")
+	case "template":
+		v.require(doc, "id")
+		v.checkTemplate(ctx, doc)
+	case "template bundle":
+		v.require(doc, "id", "templates")
+		for _, item := range sequenceField(doc.root, "templates") {
+			v.reference(doc, item, "template", "template")
+		}
+	case "prompt":
+		v.require(doc, "id", "messages")
+	}
+}
+
+func (v *workspaceValidator) checkAgent(doc *workspaceDoc) {
+	if node := field(doc.root, "modelRef"); node != nil {
+		v.reference(doc, node, "model", "model")
+	} else if workspaceDefault(v.root, "model") == "" {
+		v.add(doc.path, doc.root.Line, issueError, "agent has no modelRef and the workspace has no default model")
+	}
+	if node := field(doc.root, "intake.model"); node != nil {
+		v.reference(doc, node, "model", "model")
+	}
+	for _, item := range sequenceField(doc.root, "tool.bundles") {
+		if !v.ids["tool bundle"][item.Value] {
+			// Bundles may also be derived from built-in services at runtime.
+			v.add(doc.path, item.Line, issueWarning, fmt.Sprintf("tool bundle %q is not defined in tools/bundles", item.Value))
+		}
+	}
+	for _, item := range sequenceField(doc.root, "template.bundles") {
+		v.reference(doc, item, "template bundle", "template bundle")
+	}
+	dir := filepath.Dir(filepath.Join(v.root, filepath.FromSlash(doc.path)))
+	for _, key := range []string{"prompt.uri", "systemPrompt.uri"} {
+		node := field(doc.root, key)
+		if node == nil || node.Value == "" || strings.Contains(node.Value, "://") {
+			continue
+		}
+		target := node.Value
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(dir, filepath.FromSlash(target))
+		}
+		if _, err := os.Stat(target); err != nil {
+			v.add(doc.path, node.Line, issueError, fmt.Sprintf("%s %q not found", key, node.Value))
+		}
+	}
+	// knowledge locations may be relative to the agent folder or the workspace.
+	for _, key := range []string{"knowledge", "systemKnowledge"} {
+		list := field(doc.root, key)
+		if list == nil || list.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, item := range list.Content {
+			node := field(item, "url")
+			if node == nil || node.Value == "" || strings.Contains(node.Value, "://") || filepath.IsAbs(node.Value) {
+				continue
+			}
+			_, agentErr := os.Stat(filepath.Join(dir, filepath.FromSlash(node.Value)))
+			_, rootErr := os.Stat(filepath.Join(v.root, filepath.FromSlash(node.Value)))
+			if agentErr != nil && rootErr != nil {
+				v.add(doc.path, node.Line, issueError, fmt.Sprintf("%s url %q not found", key, node.Value))
+			}
+		}
+	}
+}
+
+// checkTemplate loads the template through the same loader the server uses
+// so schema-level problems surface here too.
+func (v *workspaceValidator) checkTemplate(ctx context.Context, doc *workspaceDoc) {
+	filename := filepath.Join(v.root, filepath.FromSlash(doc.path))
+	var tpl templ.Template
+	if err := meta.New(afs.New(), filepath.Dir(filename)).Load(ctx, filename, &tpl); err != nil {
+		v.add(doc.path, 0, issueError, fmt.Sprintf("load template: %v", err))
+		return
+	}
+	if err := tpl.Validate(); err != nil {
+		v.add(doc.path, 0, issueError, err.Error())
+	}
+}
+
+func (v *workspaceValidator) checkConfig() {
+	rel := "config.yaml"
+	filename := filepath.Join(v.root, rel)
+	if _, err := os.Stat(filename); err != nil {
+		if os.IsNotExist(err) {
+			v.add(rel, 0, issueError, "config.yaml is missing")
+			return
+		}
+		v.add(rel, 0, issueError, err.Error())
+		return
+	}
+	node, ok := v.parse(rel, filename)
+	if !ok {
+		return
+	}
+	doc := &workspaceDoc{kind: "config", path: rel, root: node}
+	for key, kind := range map[string]string{
+		"default.agent":                    "agent",
+		"default.model":                    "model",
+		"default.summaryModel":             "model",
+		"default.agentAutoSelection.model": "model",
+		"default.toolAutoSelection.model":  "model",
+		"default.embedder":                 "embedder",
+	} {
+		if target := field(node, key); target != nil {
+			v.reference(doc, target, kind, kind)
+		}
+	}
+}
+
+func (v *workspaceValidator) require(doc *workspaceDoc, keys ...string) {
+	for _, key := range keys {
+		node := field(doc.root, key)
+		if node == nil || (node.Kind == yaml.ScalarNode && strings.TrimSpace(node.Value) == "") {
+			line := doc.root.Line
+			if parent := strings.LastIndex(key, "."); parent != -1 {
+				if owner := field(doc.root, key[:parent]); owner != nil {
+					line = owner.Line
+				}
+			}
+			v.add(doc.path, line, issueError, fmt.Sprintf("%s is missing required field %q", doc.kind, key))
+		}
+	}
+}
+
+func (v *workspaceValidator) reference(doc *workspaceDoc, node *yaml.Node, kind, label string) {
+	value := strings.TrimSpace(node.Value)
+	if value == "" {
+		return
+	}
+	if !v.ids[kind][value] {
+		v.add(doc.path, node.Line, issueError, fmt.Sprintf("unknown %s %q", label, value))
+	}
+}
+
+func (v *workspaceValidator) add(path string, line int, severity, message string) {
+	v.issues = append(v.issues, &workspaceIssue{Path: path, Line: line, Severity: severity, Message: message})
+}
+
+func countIssues(issues []*workspaceIssue, severity string) int {
+	count := 0
+	for _, issue := range issues {
+		if issue.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// field resolves a dotted key in a mapping node.
+func field(node *yaml.Node, key string) *yaml.Node {
+	current := node
+	for _, part := range strings.Split(key, ".") {
+		if current == nil || current.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(current.Content); i += 2 {
+			if current.Content[i].Value == part {
+				next = current.Content[i+1]
+				break
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+func fieldLine(node *yaml.Node, key string) int {
+	if value := field(node, key); value != nil {
+		return value.Line
+	}
+	return node.Line
+}
+
+func scalarField(node *yaml.Node, key string) string {
+	value := field(node, key)
+	if value == nil || value.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return strings.TrimSpace(value.Value)
+}
+
+func sequenceField(node *yaml.Node, key string) []*yaml.Node {
+	value := field(node, key)
+	if value == nil || value.Kind != yaml.SequenceNode {
+		return nil
+	}
+	var items []*yaml.Node
+	for _, item := range value.Content {
+		if item.Kind == yaml.ScalarNode {
+			items = append(items, item)
+		}
+	}
+	return items
+}
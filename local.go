@@ -0,0 +1,104 @@
+package agently
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/viant/agently-core/workspace"
+)
+
+// LocalOptions configures an in-process runtime for `agently query --local`.
+type LocalOptions struct {
+	WorkspacePath     string
+	ScratchpadRootURI string
+	Policy            string // tool policy: auto|ask|deny
+	Debug             bool
+}
+
+// LocalServer is a workspace runtime built in the current process and served
+// on an ephemeral loopback port, so CLI commands can drive it through the same
+// SDK client they use against `agently serve`. The runtime is built by the
+// same code as Serve; it does not start the UI, the MCP server, the scheduler
+// watchdog or the agent watchdog.
+type LocalServer struct {
+	BaseURL       string
+	WorkspaceRoot string
+
+	srv    *http.Server
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// StartLocal builds the workspace runtime headless and starts serving its API
+// on 127.0.0.1. The returned server must be closed by the caller.
+func StartLocal(ctx context.Context, options LocalOptions) (*LocalServer, error) {
+	applyScratchpadRootURI(options.ScratchpadRootURI)
+	if options.Debug {
+		enableDebugLogging()
+	}
+	workspacePath := envOr("AGENTLY_WORKSPACE", defaultWorkspace())
+	if value := strings.TrimSpace(options.WorkspacePath); value != "" {
+		workspacePath = value
+	}
+	wsConfig, err := loadWorkspace(workspacePath)
+	workspace.SetBootstrapHook(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	local := &LocalServer{WorkspaceRoot: workspace.Root(), cancel: cancel, done: make(chan struct{})}
+	fail := func(err error) (*LocalServer, error) {
+		cancel()
+		return nil, err
+	}
+	wr, err := buildWorkspaceRuntime(runCtx, wsConfig, workspaceRuntimeOptions{
+		Policy:            options.Policy,
+		SchedulerHeadless: true,
+	})
+	if err != nil {
+		return fail(err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fail(fmt.Errorf("failed to listen on loopback: %w", err))
+	}
+	local.BaseURL = "http://" + listener.Addr().String()
+	local.srv = &http.Server{
+		Handler:           wr.Handler,
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+	go func() {
+		defer close(local.done)
+		if err := local.srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("agently local server error: %v", err)
+		}
+	}()
+	return local, nil
+}
+
+// Close stops the loopback listener and releases the runtime context.
+func (s *LocalServer) Close() error {
+	if s == nil {
+		return nil
+	}
+	defer s.cancel()
+	if s.srv == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := s.srv.Shutdown(ctx)
+	<-s.done
+	if errors.Is(err, context.DeadlineExceeded) {
+		return s.srv.Close()
+	}
+	return err
+}
@@ -0,0 +1,118 @@
+package agently
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/viant/agently/server"
+)
+
+func TestStartLocal_ServesWorkspaceRuntime(t *testing.T) {
+	t.Setenv("AGENTLY_ADMIN_TOKEN", "local-test-admin")
+	root := t.TempDir()
+	config := "default:\n  agent: chat\nauth:\n  jwt:\n    rsa:\n      - keys/old.pub.pem\n    rsaPrivateKey: keys/old.pem\n"
+	if err := os.WriteFile(filepath.Join(root, "config.yaml"), []byte(config), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	old, err := server.GenerateJWTKeyPair(&server.JWTKeygenOptions{
+		PrivatePath: filepath.Join(root, "keys", "old.pem"),
+		PublicPath:  filepath.Join(root, "keys", "old.pub.pem"),
+	})
+	if err != nil {
+		t.Fatalf("GenerateJWTKeyPair() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	local, err := StartLocal(ctx, LocalOptions{WorkspacePath: root, Policy: "ask"})
+	if err != nil {
+		t.Fatalf("StartLocal() error = %v", err)
+	}
+	defer func() {
+		if err := local.Close(); err != nil {
+			t.Errorf("Close() error = %v", err)
+		}
+	}()
+	if !strings.HasPrefix(local.BaseURL, "http://127.0.0.1:") {
+		t.Fatalf("BaseURL = %q, want a loopback address", local.BaseURL)
+	}
+
+	get := func(path, token string) (int, []byte) {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, local.BaseURL+path, nil)
+		if err != nil {
+			t.Fatalf("new request: %v", err)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, body
+	}
+
+	status, body := get(server.ToolPolicyPath, "local-test-admin")
+	if status != http.StatusOK {
+		t.Fatalf("tool policy status = %d, body %s", status, body)
+	}
+	var policy struct {
+		Mode string `json:"mode"`
+	}
+	if err := json.Unmarshal(body, &policy); err != nil || policy.Mode != "ask" {
+		t.Fatalf("tool policy = %s (%v), want mode ask", body, err)
+	}
+
+	status, body = get(server.JWKSPath, "")
+	if status != http.StatusOK || !strings.Contains(string(body), old.KeyID) {
+		t.Fatalf("jwks status = %d, body %s, want key %s", status, body, old.KeyID)
+	}
+
+	// The old key's grace period ended an hour ago; the runtime loaded it at
+	// startup, so only the guard stands between its tokens and the API.
+	if _, err := server.RotateJWTKey(&server.JWTRotateOptions{WorkspaceRoot: root, Grace: time.Hour, Now: time.Now().Add(-2 * time.Hour)}); err != nil {
+		t.Fatalf("RotateJWTKey() error = %v", err)
+	}
+	status, body = get("/v1/api/auth/me", signLocalTestJWT(t, old.PrivatePath))
+	if status != http.StatusUnauthorized || !strings.Contains(string(body), "retired key") {
+		t.Fatalf("retired key status = %d, body %s, want %d from the guard", status, body, http.StatusUnauthorized)
+	}
+}
+
+func signLocalTestJWT(t *testing.T, privatePath string) string {
+	t.Helper()
+	data, err := os.ReadFile(privatePath)
+	if err != nil {
+		t.Fatalf("read private key: %v", err)
+	}
+	block, _ := pem.Decode(data)
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		t.Fatalf("parse private key: %v", err)
+	}
+	encode := base64.RawURLEncoding.EncodeToString
+	input := encode([]byte(`{"alg":"RS256","typ":"JWT"}`)) + "." + encode([]byte(`{"sub":"dev"}`))
+	sum := sha256.Sum256([]byte(input))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return input + "." + encode(signature)
+}
@@ -0,0 +1,179 @@
+package runtime
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// InstanceEntry describes a running `agently serve` process. Each server
+// writes one entry into the instance registry directory on startup and
+// removes it on shutdown so clients can find it without scanning processes.
+type InstanceEntry struct {
+	Name      string    `json:"name"`
+	PID       int       `json:"pid"`
+	Addr      string    `json:"addr"`
+	BaseURL   string    `json:"baseURL"`
+	Workspace string    `json:"workspace,omitempty"`
+	Version   string    `json:"version,omitempty"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// InstanceRegistryDir returns the directory holding instance entries:
+// $XDG_RUNTIME_DIR/agently when set, otherwise a per-user directory under the
+// system temp dir.
+func InstanceRegistryDir() string {
+	if dir := strings.TrimSpace(os.Getenv("XDG_RUNTIME_DIR")); dir != "" {
+		return filepath.Join(dir, "agently")
+	}
+	name := "agently"
+	if uid := os.Getuid(); uid >= 0 {
+		name += "-" + strconv.Itoa(uid)
+	}
+	return filepath.Join(os.TempDir(), name)
+}
+
+// RegisterInstance writes entry into dir and returns a func removing it.
+// The file is keyed by PID so concurrent servers never overwrite each other.
+func RegisterInstance(dir string, entry InstanceEntry) (func() error, error) {
+	if entry.PID <= 0 {
+		entry.PID = os.Getpid()
+	}
+	if entry.StartedAt.IsZero() {
+		entry.StartedAt = time.Now().UTC()
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create instance registry %s: %w", dir, err)
+	}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	path := instanceEntryPath(dir, entry.PID)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return nil, fmt.Errorf("write instance entry: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return nil, fmt.Errorf("write instance entry: %w", err)
+	}
+	return func() error {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}, nil
+}
+
+// ListInstances reads the registry, sorted by name then PID. Entries whose
+// process is gone are returned separately as stale; with prune set their
+// files are removed as well. A missing directory is an empty registry.
+func ListInstances(dir string, prune bool) (live []InstanceEntry, stale []InstanceEntry, err error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(dir, file.Name())
+		data, rerr := os.ReadFile(path)
+		if rerr != nil {
+			continue
+		}
+		var entry InstanceEntry
+		if json.Unmarshal(data, &entry) != nil || entry.PID <= 0 {
+			stale = append(stale, InstanceEntry{Name: strings.TrimSuffix(file.Name(), ".json")})
+			if prune {
+				_ = os.Remove(path)
+			}
+			continue
+		}
+		if !ProcessAlive(entry.PID) {
+			stale = append(stale, entry)
+			if prune {
+				_ = os.Remove(path)
+			}
+			continue
+		}
+		live = append(live, entry)
+	}
+	sortInstances(live)
+	sortInstances(stale)
+	return live, stale, nil
+}
+
+// InstanceName is the default registry name of a server: the workspace
+// directory name followed by the listen port, e.g. agently-8080.
+func InstanceName(workspace string, port int) string {
+	base := strings.TrimLeft(filepath.Base(filepath.Clean(strings.TrimSpace(workspace))), ".")
+	if base == "" || base == string(filepath.Separator) {
+		base = "agently"
+	}
+	if port <= 0 {
+		return base
+	}
+	return base + "-" + strconv.Itoa(port)
+}
+
+// InstanceBaseURL turns a listener address into the URL clients use; wildcard
+// hosts map to localhost.
+func InstanceBaseURL(addr net.Addr) string {
+	host, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return "http://" + addr.String()
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, port)
+}
+
+// ProcessAlive reports whether pid refers to a running process.
+func ProcessAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	switch goruntime.GOOS {
+	case "linux":
+		_, err := os.Stat(filepath.Join("/proc", strconv.Itoa(pid)))
+		return err == nil
+	case "windows":
+		// FindProcess opens a handle and fails once the process has exited.
+		_, err := os.FindProcess(pid)
+		return err == nil
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = proc.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, os.ErrPermission)
+}
+
+func instanceEntryPath(dir string, pid int) string {
+	return filepath.Join(dir, strconv.Itoa(pid)+".json")
+}
+
+func sortInstances(entries []InstanceEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Name != entries[j].Name {
+			return entries[i].Name < entries[j].Name
+		}
+		return entries[i].PID < entries[j].PID
+	})
+}
@@ -0,0 +1,90 @@
+package runtime
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegisterAndListInstances(t *testing.T) {
+	dir := t.TempDir()
+	remove, err := RegisterInstance(dir, InstanceEntry{Name: "ws-8080", Addr: "[::]:8080", BaseURL: "http://localhost:8080", Workspace: "/tmp/ws"})
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	// A crashed server leaves its entry behind; a corrupt file is stale too.
+	if _, err := RegisterInstance(dir, InstanceEntry{Name: "gone", PID: 1 << 30}); err != nil {
+		t.Fatalf("register stale: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0o600); err != nil {
+		t.Fatalf("write broken entry: %v", err)
+	}
+
+	live, stale, err := ListInstances(dir, false)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(live) != 1 || live[0].Name != "ws-8080" || live[0].PID != os.Getpid() || live[0].StartedAt.IsZero() {
+		t.Fatalf("unexpected live entries: %+v", live)
+	}
+	if len(stale) != 2 || stale[0].Name != "broken" || stale[1].Name != "gone" {
+		t.Fatalf("unexpected stale entries: %+v", stale)
+	}
+
+	if _, _, err := ListInstances(dir, true); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 {
+		t.Fatalf("expected only the live entry after prune, got %d files", len(files))
+	}
+
+	if err := remove(); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := remove(); err != nil {
+		t.Fatalf("second remove should be a no-op: %v", err)
+	}
+	live, _, _ = ListInstances(dir, false)
+	if len(live) != 0 {
+		t.Fatalf("expected empty registry, got %+v", live)
+	}
+}
+
+func TestListInstances_MissingDir(t *testing.T) {
+	live, stale, err := ListInstances(filepath.Join(t.TempDir(), "missing"), true)
+	if err != nil || live != nil || stale != nil {
+		t.Fatalf("expected empty registry, got %+v %+v %v", live, stale, err)
+	}
+}
+
+func TestInstanceRegistryDir(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	if got := InstanceRegistryDir(); got != filepath.Join("/run/user/1000", "agently") {
+		t.Fatalf("unexpected dir: %s", got)
+	}
+}
+
+func TestInstanceNameAndBaseURL(t *testing.T) {
+	if got := InstanceName("/home/dev/.agently", 8080); got != "agently-8080" {
+		t.Fatalf("unexpected name: %s", got)
+	}
+	if got := InstanceName("/srv/support-bot", 0); got != "support-bot" {
+		t.Fatalf("unexpected name: %s", got)
+	}
+	cases := map[string]string{
+		"[::]:8080":      "http://localhost:8080",
+		"0.0.0.0:9393":   "http://localhost:9393",
+		"127.0.0.1:9001": "http://127.0.0.1:9001",
+	}
+	for addr, want := range cases {
+		tcpAddr, err := net.ResolveTCPAddr("tcp", addr)
+		if err != nil {
+			t.Fatalf("resolve %s: %v", addr, err)
+		}
+		if got := InstanceBaseURL(tcpAddr); got != want {
+			t.Fatalf("InstanceBaseURL(%s) = %s, want %s", addr, got, want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	provider "github.com/viant/agently-core/genai/llm/provider"
+	"gopkg.in/yaml.v3"
+)
+
+// ModelConfigFile is a model definition found in a workspace.
+type ModelConfigFile struct {
+	ID   string
+	Path string
+}
+
+// WorkspaceModelLoader is a provider.ConfigLoader that reads model configs
+// straight from <workspace>/models, so a ModelFinder can be used without
+// building the full runtime (e.g. from CLI diagnostics).
+type WorkspaceModelLoader struct {
+	root string
+}
+
+func NewWorkspaceModelLoader(workspaceRoot string) *WorkspaceModelLoader {
+	return &WorkspaceModelLoader{root: strings.TrimSpace(workspaceRoot)}
+}
+
+func (l *WorkspaceModelLoader) Load(_ context.Context, id string) (*provider.Config, error) {
+	path, err := FindModelConfig(l.root, id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &provider.Config{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parse model config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ListModelConfigs returns the model definitions in <workspaceRoot>/models
+// ordered by id. Files without an id are listed under their base name.
+func ListModelConfigs(workspaceRoot string) ([]*ModelConfigFile, error) {
+	dir := filepath.Join(strings.TrimSpace(workspaceRoot), "models")
+	matches, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	var result []*ModelConfigFile
+	for _, match := range matches {
+		data, err := os.ReadFile(match)
+		if err != nil {
+			return nil, err
+		}
+		var header struct {
+			ID string `yaml:"id"`
+		}
+		_ = yaml.Unmarshal(data, &header)
+		id := strings.TrimSpace(header.ID)
+		if id == "" {
+			id = strings.TrimSuffix(filepath.Base(match), filepath.Ext(match))
+		}
+		result = append(result, &ModelConfigFile{ID: id, Path: match})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+// FindModelConfig resolves a model id (or "models/<name>" / file base name)
+// to its config file. The error lists the ids that do exist, which is what
+// one needs when diagnosing "model not found".
+func FindModelConfig(workspaceRoot, id string) (string, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return "", fmt.Errorf("model id is required")
+	}
+	configs, err := ListModelConfigs(workspaceRoot)
+	if err != nil {
+		return "", err
+	}
+	name := strings.TrimSuffix(strings.TrimPrefix(id, "models/"), ".yaml")
+	for _, cfg := range configs {
+		if cfg.ID == id {
+			return cfg.Path, nil
+		}
+	}
+	for _, cfg := range configs {
+		if strings.TrimSuffix(filepath.Base(cfg.Path), ".yaml") == name {
+			return cfg.Path, nil
+		}
+	}
+	ids := make([]string, 0, len(configs))
+	for _, cfg := range configs {
+		ids = append(ids, cfg.ID)
+	}
+	dir := filepath.Join(strings.TrimSpace(workspaceRoot), "models")
+	if len(ids) == 0 {
+		return "", fmt.Errorf("model config not found: %s (no models in %s)", id, dir)
+	}
+	return "", fmt.Errorf("model config not found: %s (available in %s: %s)", id, dir, strings.Join(ids, ", "))
+}
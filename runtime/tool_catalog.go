@@ -0,0 +1,255 @@
+package runtime
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/viant/agently-core/sdk"
+)
+
+// ToolOpenAPIInfo fills the info and servers sections of the catalog document.
+type ToolOpenAPIInfo struct {
+	Title     string
+	Version   string
+	ServerURL string
+}
+
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// ToolOpenAPIDocument renders tool definitions as an OpenAPI 3.1 document.
+// Every tool becomes a POST operation on its execute endpoint
+// (/v1/tools/{name}/execute) with the input schema as request body and the
+// output schema, when declared, as the 200 response. Schemas live under
+// components/schemas so gateways can reuse them.
+func ToolOpenAPIDocument(defs []sdk.ToolDefinitionInfo, info ToolOpenAPIInfo) map[string]interface{} {
+	defs = append([]sdk.ToolDefinitionInfo(nil), defs...)
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	title := strings.TrimSpace(info.Title)
+	if title == "" {
+		title = "Agently tools"
+	}
+	version := strings.TrimSpace(info.Version)
+	if version == "" {
+		version = "v1"
+	}
+
+	paths := map[string]interface{}{}
+	schemas := map[string]interface{}{}
+	usedIDs := map[string]bool{}
+	tagSet := map[string]bool{}
+	for _, def := range defs {
+		name := strings.TrimSpace(def.Name)
+		if name == "" {
+			continue
+		}
+		operationID := uniqueOperationID(toolOperationID(name), usedIDs)
+		inputName := upperFirst(operationID) + "Input"
+		schemas[inputName] = ToolInputSchema(def)
+		responseSchema := map[string]interface{}{}
+		if len(def.OutputSchema) > 0 {
+			outputName := upperFirst(operationID) + "Output"
+			schemas[outputName] = def.OutputSchema
+			responseSchema = map[string]interface{}{"$ref": "#/components/schemas/" + outputName}
+		}
+		operation := map[string]interface{}{
+			"operationId": operationID,
+			"summary":     toolSummary(def),
+			"parameters": []interface{}{map[string]interface{}{
+				"name":        "conversationId",
+				"in":          "query",
+				"required":    false,
+				"description": "Conversation the tool call is attributed to.",
+				"schema":      map[string]interface{}{"type": "string"},
+			}},
+			"requestBody": map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": map[string]interface{}{"$ref": "#/components/schemas/" + inputName}},
+				},
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "Tool result",
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{"schema": responseSchema},
+					},
+				},
+				"default": map[string]interface{}{"description": "Tool execution error"},
+			},
+			"x-agently-tool": name,
+		}
+		if description := strings.TrimSpace(def.Description); description != "" {
+			operation["description"] = description
+		}
+		if service := toolService(name); service != "" {
+			operation["tags"] = []interface{}{service}
+			tagSet[service] = true
+		}
+		paths[ToolExecutePath(name)] = map[string]interface{}{"post": operation}
+	}
+
+	tagNames := make([]string, 0, len(tagSet))
+	for tag := range tagSet {
+		tagNames = append(tagNames, tag)
+	}
+	sort.Strings(tagNames)
+	tags := make([]interface{}, 0, len(tagNames))
+	for _, tag := range tagNames {
+		tags = append(tags, map[string]interface{}{"name": tag})
+	}
+
+	doc := map[string]interface{}{
+		"openapi":           "3.1.0",
+		"jsonSchemaDialect": jsonSchemaDialect,
+		"info":              map[string]interface{}{"title": title, "version": version},
+		"paths":             paths,
+		"tags":              tags,
+		"components": map[string]interface{}{
+			"schemas":         schemas,
+			"securitySchemes": map[string]interface{}{"bearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer"}},
+		},
+		"security": []interface{}{map[string]interface{}{"bearerAuth": []interface{}{}}},
+	}
+	if serverURL := strings.TrimRight(strings.TrimSpace(info.ServerURL), "/"); serverURL != "" {
+		doc["servers"] = []interface{}{map[string]interface{}{"url": serverURL}}
+	}
+	return doc
+}
+
+// ToolJSONSchemaBundle returns one standalone JSON Schema document per tool,
+// keyed by file name. The document validates the tool input; the output
+// schema, when declared, is kept under $defs/output.
+func ToolJSONSchemaBundle(defs []sdk.ToolDefinitionInfo) map[string]map[string]interface{} {
+	bundle := map[string]map[string]interface{}{}
+	for _, def := range defs {
+		name := strings.TrimSpace(def.Name)
+		if name == "" {
+			continue
+		}
+		defsSection := map[string]interface{}{"input": ToolInputSchema(def)}
+		if len(def.OutputSchema) > 0 {
+			defsSection["output"] = def.OutputSchema
+		}
+		doc := map[string]interface{}{
+			"$schema": jsonSchemaDialect,
+			"$id":     "agently:tool/" + name,
+			"title":   name,
+			"$defs":   defsSection,
+			"$ref":    "#/$defs/input",
+		}
+		if description := strings.TrimSpace(def.Description); description != "" {
+			doc["description"] = description
+		}
+		fileName := ToolSchemaFileName(name)
+		for i := 2; bundle[fileName] != nil; i++ {
+			fileName = strings.TrimSuffix(ToolSchemaFileName(name), ".json") + "_" + strconv.Itoa(i) + ".json"
+		}
+		bundle[fileName] = doc
+	}
+	return bundle
+}
+
+// ToolInputSchema returns the definition's parameters as an object schema
+// with the separately reported required list folded in.
+func ToolInputSchema(def sdk.ToolDefinitionInfo) map[string]interface{} {
+	schema := make(map[string]interface{}, len(def.Parameters)+2)
+	for key, value := range def.Parameters {
+		schema[key] = value
+	}
+	if _, ok := schema["type"]; !ok {
+		schema["type"] = "object"
+	}
+	if _, ok := schema["required"]; !ok && len(def.Required) > 0 {
+		required := make([]interface{}, 0, len(def.Required))
+		for _, item := range def.Required {
+			required = append(required, item)
+		}
+		schema["required"] = required
+	}
+	return schema
+}
+
+// ToolExecutePath is the API path that executes the named tool.
+func ToolExecutePath(name string) string {
+	return "/v1/tools/" + url.PathEscape(strings.TrimSpace(name)) + "/execute"
+}
+
+var nonFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// ToolSchemaFileName maps a tool name to the bundle file holding its schema,
+// e.g. system/exec:execute -> system_exec_execute.json.
+func ToolSchemaFileName(name string) string {
+	base := strings.Trim(nonFileNameChars.ReplaceAllString(strings.TrimSpace(name), "_"), "_.")
+	if base == "" {
+		base = "tool"
+	}
+	return base + ".json"
+}
+
+func toolSummary(def sdk.ToolDefinitionInfo) string {
+	description := strings.TrimSpace(def.Description)
+	if description == "" {
+		return def.Name
+	}
+	if idx := strings.IndexByte(description, '\n'); idx != -1 {
+		description = strings.TrimSpace(description[:idx])
+	}
+	return description
+}
+
+func toolService(name string) string {
+	if idx := strings.Index(name, ":"); idx != -1 {
+		return strings.TrimSpace(name[:idx])
+	}
+	if idx := strings.LastIndexAny(name, "./"); idx != -1 {
+		return strings.TrimSpace(name[:idx])
+	}
+	return ""
+}
+
+// toolOperationID turns system/exec:execute into systemExecExecute.
+func toolOperationID(name string) string {
+	var builder strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		if builder.Len() == 0 {
+			builder.WriteString(lowerFirst(part))
+			continue
+		}
+		builder.WriteString(upperFirst(part))
+	}
+	if builder.Len() == 0 {
+		return "tool"
+	}
+	return builder.String()
+}
+
+func uniqueOperationID(id string, used map[string]bool) string {
+	candidate := id
+	for i := 2; used[candidate]; i++ {
+		candidate = id + strconv.Itoa(i)
+	}
+	used[candidate] = true
+	return candidate
+}
+
+func upperFirst(value string) string {
+	runes := []rune(value)
+	if len(runes) == 0 {
+		return value
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+func lowerFirst(value string) string {
+	runes := []rune(value)
+	if len(runes) == 0 {
+		return value
+	}
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
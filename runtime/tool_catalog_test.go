@@ -0,0 +1,131 @@
+package runtime
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/viant/agently-core/sdk"
+)
+
+func catalogTestDefinitions() []sdk.ToolDefinitionInfo {
+	return []sdk.ToolDefinitionInfo{
+		{
+			Name:        "system/exec:execute",
+			Description: "Execute shell commands.\nCommands run in order.",
+			Parameters: map[string]interface{}{
+				"properties": map[string]interface{}{"commands": map[string]interface{}{"type": "array"}},
+			},
+			Required:     []string{"commands"},
+			OutputSchema: map[string]interface{}{"type": "object", "properties": map[string]interface{}{"stdout": map[string]interface{}{"type": "string"}}},
+		},
+		{Name: "resources.list", Parameters: map[string]interface{}{"type": "object"}},
+		{Name: "system_exec:execute"},
+	}
+}
+
+func TestToolOpenAPIDocument(t *testing.T) {
+	doc := ToolOpenAPIDocument(catalogTestDefinitions(), ToolOpenAPIInfo{Version: "1.2.3", ServerURL: "https://agently.example.com/"})
+	// Round-trip through JSON to assert on the document exactly as served.
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("marshal document: %v", err)
+	}
+	var got struct {
+		OpenAPI string `json:"openapi"`
+		Info    struct {
+			Title   string `json:"title"`
+			Version string `json:"version"`
+		} `json:"info"`
+		Servers []struct {
+			URL string `json:"url"`
+		} `json:"servers"`
+		Paths map[string]struct {
+			Post struct {
+				OperationID string   `json:"operationId"`
+				Summary     string   `json:"summary"`
+				Tags        []string `json:"tags"`
+				RequestBody struct {
+					Content map[string]struct {
+						Schema map[string]string `json:"schema"`
+					} `json:"content"`
+				} `json:"requestBody"`
+				Responses map[string]struct {
+					Content map[string]struct {
+						Schema map[string]string `json:"schema"`
+					} `json:"content"`
+				} `json:"responses"`
+			} `json:"post"`
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]map[string]interface{} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal document: %v", err)
+	}
+	if got.OpenAPI != "3.1.0" || got.Info.Title != "Agently tools" || got.Info.Version != "1.2.3" {
+		t.Fatalf("header = %s %q %q", got.OpenAPI, got.Info.Title, got.Info.Version)
+	}
+	if len(got.Servers) != 1 || got.Servers[0].URL != "https://agently.example.com" {
+		t.Fatalf("servers = %#v", got.Servers)
+	}
+	if len(got.Paths) != 3 {
+		t.Fatalf("paths = %d, want 3", len(got.Paths))
+	}
+
+	execute, ok := got.Paths["/v1/tools/system%2Fexec:execute/execute"]
+	if !ok {
+		t.Fatalf("missing execute path in %v", got.Paths)
+	}
+	if execute.Post.OperationID != "systemExecExecute" || execute.Post.Summary != "Execute shell commands." {
+		t.Fatalf("operation = %q %q", execute.Post.OperationID, execute.Post.Summary)
+	}
+	if len(execute.Post.Tags) != 1 || execute.Post.Tags[0] != "system/exec" {
+		t.Fatalf("tags = %v", execute.Post.Tags)
+	}
+	if ref := execute.Post.RequestBody.Content["application/json"].Schema["$ref"]; ref != "#/components/schemas/SystemExecExecuteInput" {
+		t.Fatalf("request ref = %q", ref)
+	}
+	if ref := execute.Post.Responses["200"].Content["application/json"].Schema["$ref"]; ref != "#/components/schemas/SystemExecExecuteOutput" {
+		t.Fatalf("response ref = %q", ref)
+	}
+	input := got.Components.Schemas["SystemExecExecuteInput"]
+	if input["type"] != "object" {
+		t.Fatalf("input type = %v, want object", input["type"])
+	}
+	if required, _ := input["required"].([]interface{}); len(required) != 1 || required[0] != "commands" {
+		t.Fatalf("input required = %v", input["required"])
+	}
+	if _, ok := got.Components.Schemas["ResourcesListOutput"]; ok {
+		t.Fatalf("unexpected output schema for a tool without one")
+	}
+
+	// system_exec:execute sanitizes to the same operation id and gets a suffix.
+	if id := got.Paths["/v1/tools/system_exec:execute/execute"].Post.OperationID; id != "systemExecExecute2" {
+		t.Fatalf("duplicate operation id = %q, want systemExecExecute2", id)
+	}
+}
+
+func TestToolJSONSchemaBundle(t *testing.T) {
+	bundle := ToolJSONSchemaBundle(catalogTestDefinitions())
+	if len(bundle) != 3 {
+		t.Fatalf("bundle files = %d, want 3", len(bundle))
+	}
+	doc, ok := bundle["system_exec_execute.json"]
+	if !ok {
+		t.Fatalf("missing system_exec_execute.json in %v", bundle)
+	}
+	if doc["$ref"] != "#/$defs/input" || doc["title"] != "system/exec:execute" {
+		t.Fatalf("document = %#v", doc)
+	}
+	defs := doc["$defs"].(map[string]interface{})
+	if _, ok := defs["output"]; !ok {
+		t.Fatalf("missing output schema in $defs")
+	}
+	if _, ok := bundle["system_exec_execute_2.json"]; !ok {
+		t.Fatalf("colliding file name was not suffixed: %v", bundle)
+	}
+	if _, ok := bundle["resources.list.json"]; !ok {
+		t.Fatalf("missing resources.list.json in %v", bundle)
+	}
+}
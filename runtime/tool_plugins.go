@@ -273,7 +273,7 @@ func loadAgentDirectory(rt *executor.Runtime, workspaceRoot string) []llmagents.
 	if rt != nil && rt.Agent != nil {
 		finder := rt.Agent.Finder()
 		if finder != nil {
-			agentIDs := discoverAgentIDs(workspaceRoot)
+			agentIDs := DiscoverAgentIDs(workspaceRoot)
 			for _, id := range agentIDs {
 				ag, err := finder.Find(context.Background(), id)
 				if err != nil || ag == nil {
@@ -286,7 +286,7 @@ func loadAgentDirectory(rt *executor.Runtime, workspaceRoot string) []llmagents.
 			}
 		}
 	}
-	for _, spec := range loadExternalA2ASpecs(workspaceRoot) {
+	for _, spec := range LoadExternalA2ASpecs(workspaceRoot) {
 		items = append(items, externalA2AToListItem(spec))
 	}
 	sort.SliceStable(items, func(i, j int) bool {
@@ -301,7 +301,9 @@ func loadAgentDirectory(rt *executor.Runtime, workspaceRoot string) []llmagents.
 	return items
 }
 
-func discoverAgentIDs(workspaceRoot string) []string {
+// DiscoverAgentIDs returns the ids of agents defined under
+// <workspaceRoot>/agents, either as <id>/ folders or <id>.yaml files.
+func DiscoverAgentIDs(workspaceRoot string) []string {
 	root := filepath.Join(strings.TrimSpace(workspaceRoot), "agents")
 	entries, err := os.ReadDir(root)
 	if err != nil {
@@ -395,7 +397,7 @@ func externalA2AToListItem(spec *svca2a.ExternalSpec) llmagents.ListItem {
 
 func externalA2ARunner(workspaceRoot string) func(context.Context, string, string, map[string]interface{}) (string, string, string, string, bool, []string, error) {
 	return func(ctx context.Context, agentID, objective string, payload map[string]interface{}) (string, string, string, string, bool, []string, error) {
-		specs := loadExternalA2ASpecs(workspaceRoot)
+		specs := LoadExternalA2ASpecs(workspaceRoot)
 		spec, ok := specs[strings.TrimSpace(agentID)]
 		if !ok || spec == nil {
 			return "", "", "", "", false, nil, nil
@@ -475,7 +477,9 @@ func payloadString(payload map[string]interface{}, key string) string {
 	return ""
 }
 
-func loadExternalA2ASpecs(workspaceRoot string) map[string]*svca2a.ExternalSpec {
+// LoadExternalA2ASpecs returns the enabled external A2A agents declared in
+// <workspaceRoot>/a2a/*.yaml keyed by id.
+func LoadExternalA2ASpecs(workspaceRoot string) map[string]*svca2a.ExternalSpec {
 	root := filepath.Join(strings.TrimSpace(workspaceRoot), "a2a")
 	entries, err := os.ReadDir(root)
 	if err != nil {
@@ -0,0 +1,221 @@
+package runtime
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Coarse tool policy modes accepted by `agently serve --policy`.
+const (
+	ToolPolicyAuto = "auto"
+	ToolPolicyAsk  = "ask"
+	ToolPolicyDeny = "deny"
+)
+
+// ToolPolicyConfig is the optional `toolPolicy` section of workspace
+// config.yaml. Agent overrides are keyed by agent id; group overrides apply to
+// every listed user id.
+//
+//	toolPolicy:
+//	  agents:
+//	    coder: ask
+//	  groups:
+//	    - name: oncall
+//	      mode: auto
+//	      users: [alice, bob]
+type ToolPolicyConfig struct {
+	Agents map[string]string  `yaml:"agents,omitempty" json:"agents,omitempty"`
+	Groups []*ToolPolicyGroup `yaml:"groups,omitempty" json:"groups,omitempty"`
+}
+
+// ToolPolicyGroup assigns a policy mode to a named set of users.
+type ToolPolicyGroup struct {
+	Name  string   `yaml:"name" json:"name"`
+	Mode  string   `yaml:"mode" json:"mode"`
+	Users []string `yaml:"users,omitempty" json:"users,omitempty"`
+}
+
+// ToolPolicySnapshot is a point-in-time view of the live policy.
+type ToolPolicySnapshot struct {
+	Mode   string             `json:"mode"`
+	Agents map[string]string  `json:"agents,omitempty"`
+	Groups []*ToolPolicyGroup `json:"groups,omitempty"`
+}
+
+// ToolPolicy holds the live coarse tool policy. The global mode can be
+// switched at runtime; a global deny always wins over overrides so it acts as
+// a kill switch.
+type ToolPolicy struct {
+	mu     sync.RWMutex
+	mode   string
+	agents map[string]string
+	groups []*ToolPolicyGroup
+	users  map[string]string
+}
+
+// NormalizeToolPolicyMode validates mode, treating empty as auto.
+func NormalizeToolPolicyMode(mode string) (string, error) {
+	switch value := strings.ToLower(strings.TrimSpace(mode)); value {
+	case "":
+		return ToolPolicyAuto, nil
+	case ToolPolicyAuto, ToolPolicyAsk, ToolPolicyDeny:
+		return value, nil
+	default:
+		return "", fmt.Errorf("invalid tool policy %q (expected auto, ask or deny)", mode)
+	}
+}
+
+// NewToolPolicy builds a policy from the global mode and optional overrides.
+func NewToolPolicy(mode string, config *ToolPolicyConfig) (*ToolPolicy, error) {
+	normalized, err := NormalizeToolPolicyMode(mode)
+	if err != nil {
+		return nil, err
+	}
+	policy := &ToolPolicy{
+		mode:   normalized,
+		agents: map[string]string{},
+		users:  map[string]string{},
+	}
+	if config == nil {
+		return policy, nil
+	}
+	for agentID, agentMode := range config.Agents {
+		agentID = strings.TrimSpace(agentID)
+		if agentID == "" {
+			continue
+		}
+		value, err := NormalizeToolPolicyMode(agentMode)
+		if err != nil {
+			return nil, fmt.Errorf("toolPolicy.agents.%s: %w", agentID, err)
+		}
+		policy.agents[agentID] = value
+	}
+	for i, group := range config.Groups {
+		if group == nil {
+			continue
+		}
+		value, err := NormalizeToolPolicyMode(group.Mode)
+		if err != nil {
+			return nil, fmt.Errorf("toolPolicy.groups[%d] %q: %w", i, group.Name, err)
+		}
+		normalizedGroup := &ToolPolicyGroup{Name: strings.TrimSpace(group.Name), Mode: value}
+		for _, user := range group.Users {
+			user = strings.TrimSpace(user)
+			if user == "" {
+				continue
+			}
+			normalizedGroup.Users = append(normalizedGroup.Users, user)
+			// A user in several groups gets the most restrictive mode.
+			if existing, ok := policy.users[user]; !ok || toolPolicyRank(value) > toolPolicyRank(existing) {
+				policy.users[user] = value
+			}
+		}
+		policy.groups = append(policy.groups, normalizedGroup)
+	}
+	return policy, nil
+}
+
+// LoadToolPolicyConfig reads the `toolPolicy` section from the workspace
+// config.yaml. A missing file or section yields nil without error.
+func LoadToolPolicyConfig(workspaceRoot string) (*ToolPolicyConfig, error) {
+	path := filepath.Join(strings.TrimSpace(workspaceRoot), "config.yaml")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var root struct {
+		ToolPolicy *ToolPolicyConfig `yaml:"toolPolicy"`
+	}
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return root.ToolPolicy, nil
+}
+
+// Mode returns the current global mode.
+func (p *ToolPolicy) Mode() string {
+	if p == nil {
+		return ToolPolicyAuto
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.mode
+}
+
+// SetMode switches the global mode without restarting the server.
+func (p *ToolPolicy) SetMode(mode string) error {
+	if p == nil {
+		return fmt.Errorf("tool policy is not configured")
+	}
+	normalized, err := NormalizeToolPolicyMode(mode)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.mode = normalized
+	p.mu.Unlock()
+	return nil
+}
+
+// Resolve returns the effective mode for a tool call made on behalf of
+// agentID and userID. Global deny short-circuits; otherwise a user group
+// override beats an agent override, which beats the global mode.
+func (p *ToolPolicy) Resolve(agentID, userID string) string {
+	if p == nil {
+		return ToolPolicyAuto
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.mode == ToolPolicyDeny {
+		return ToolPolicyDeny
+	}
+	if mode, ok := p.users[strings.TrimSpace(userID)]; ok {
+		return mode
+	}
+	if mode, ok := p.agents[strings.TrimSpace(agentID)]; ok {
+		return mode
+	}
+	return p.mode
+}
+
+// Snapshot returns a copy of the live policy state.
+func (p *ToolPolicy) Snapshot() ToolPolicySnapshot {
+	if p == nil {
+		return ToolPolicySnapshot{Mode: ToolPolicyAuto}
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	out := ToolPolicySnapshot{Mode: p.mode}
+	if len(p.agents) > 0 {
+		out.Agents = make(map[string]string, len(p.agents))
+		for key, value := range p.agents {
+			out.Agents[key] = value
+		}
+	}
+	for _, group := range p.groups {
+		users := append([]string(nil), group.Users...)
+		sort.Strings(users)
+		out.Groups = append(out.Groups, &ToolPolicyGroup{Name: group.Name, Mode: group.Mode, Users: users})
+	}
+	return out
+}
+
+func toolPolicyRank(mode string) int {
+	switch mode {
+	case ToolPolicyDeny:
+		return 2
+	case ToolPolicyAsk:
+		return 1
+	default:
+		return 0
+	}
+}
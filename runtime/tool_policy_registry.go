@@ -0,0 +1,199 @@
+package runtime
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/viant/agently-core/app/executor"
+	coreplan "github.com/viant/agently-core/protocol/agent/execution"
+	"github.com/viant/agently-core/protocol/tool"
+	runtimerequestctx "github.com/viant/agently-core/runtime/requestctx"
+	svcauth "github.com/viant/agently-core/service/auth"
+)
+
+// policyRegistry enforces the coarse tool policy in front of the runtime
+// registry. Everything except Execute is delegated unchanged.
+type policyRegistry struct {
+	tool.Registry
+	policy  *ToolPolicy
+	agentOf func(ctx context.Context) string
+	// approve asks the user about calls resolved to "ask". When nil the
+	// decision is left to the core through the context policy.
+	approve ToolApprover
+}
+
+// ToolApprover reports whether the user allowed a tool call.
+type ToolApprover func(ctx context.Context, name string, args map[string]interface{}) (bool, error)
+
+// ApplyToolPolicy wraps rt.Registry so every tool execution is checked against
+// policy. It must run from RuntimeOptions.ConfigureRuntime so the agent
+// service picks up the wrapped registry.
+func ApplyToolPolicy(rt *executor.Runtime, policy *ToolPolicy) {
+	if rt == nil || rt.Registry == nil || policy == nil {
+		return
+	}
+	if _, ok := rt.Registry.(*policyRegistry); ok {
+		return
+	}
+	registry := &policyRegistry{
+		Registry: rt.Registry,
+		policy:   policy,
+		agentOf:  conversationAgentResolver(rt),
+	}
+	if elicitor, ok := interface{}(rt.Elicitation).(approvalElicitor); ok && rt.Elicitation != nil {
+		registry.approve = elicitationApprover(elicitor)
+	} else {
+		log.Printf("agently-app: tool policy ask mode relies on the core approval flow (no elicitation service)")
+	}
+	rt.Registry = registry
+	log.Printf("agently-app: tool policy %q enforced on registry", policy.Mode())
+}
+
+// UnwrapRegistry returns the registry underneath the policy wrapper so callers
+// that register internal services reach the concrete registry.
+func UnwrapRegistry(registry tool.Registry) tool.Registry {
+	if wrapped, ok := registry.(*policyRegistry); ok {
+		return wrapped.Registry
+	}
+	return registry
+}
+
+func (r *policyRegistry) Execute(ctx context.Context, name string, args map[string]interface{}) (string, error) {
+	agentID := ""
+	if r.agentOf != nil {
+		agentID = r.agentOf(ctx)
+	}
+	userID := strings.TrimSpace(svcauth.EffectiveUserID(ctx))
+	switch mode := r.policy.Resolve(agentID, userID); mode {
+	case ToolPolicyDeny:
+		log.Printf("[tool-policy] denied tool=%q agent=%q user=%q", name, agentID, userID)
+		return "", fmt.Errorf("tool %q denied by tool policy", name)
+	case ToolPolicyAsk:
+		if r.approve == nil {
+			ctx = tool.WithPolicy(ctx, &tool.Policy{Mode: tool.ModeAsk})
+			break
+		}
+		allowed, err := r.approve(ctx, name, args)
+		if err != nil {
+			return "", fmt.Errorf("tool %q approval: %w", name, err)
+		}
+		if !allowed {
+			log.Printf("[tool-policy] declined tool=%q agent=%q user=%q", name, agentID, userID)
+			return "", fmt.Errorf("tool %q was not approved", name)
+		}
+	}
+	return r.Registry.Execute(ctx, name, args)
+}
+
+// approvalElicitor is the part of the runtime elicitation service used to
+// ask for tool approval in the conversation.
+type approvalElicitor interface {
+	Elicit(ctx context.Context, conversationID string, req *coreplan.Elicitation) (*coreplan.ElicitResult, error)
+}
+
+// elicitationApprover asks for approval with an accept/decline elicitation
+// in the calling conversation, so "ask" prompts no matter where the core
+// decides its own approvals.
+func elicitationApprover(elicitor approvalElicitor) ToolApprover {
+	return func(ctx context.Context, name string, args map[string]interface{}) (bool, error) {
+		conversationID := strings.TrimSpace(runtimerequestctx.ConversationIDFromContext(ctx))
+		if conversationID == "" {
+			return false, fmt.Errorf("no conversation to ask in")
+		}
+		req := &coreplan.Elicitation{}
+		req.Message = fmt.Sprintf("Allow tool %s to run?", name)
+		req.RequestedSchema.Type = "object"
+		req.RequestedSchema.Properties = map[string]interface{}{
+			"tool":      map[string]interface{}{"type": "string", "const": name},
+			"arguments": map[string]interface{}{"type": "object", "default": args},
+		}
+		result, err := elicitor.Elicit(ctx, conversationID, req)
+		if err != nil {
+			return false, err
+		}
+		return result != nil && result.Action == coreplan.ElicitResultActionAccept, nil
+	}
+}
+
+// agentCacheSize and agentCacheTTL bound the conversation to agent cache;
+// the agent of a conversation can change, and serve runs indefinitely.
+const (
+	agentCacheSize = 1024
+	agentCacheTTL  = 5 * time.Minute
+)
+
+func conversationAgentResolver(rt *executor.Runtime) func(ctx context.Context) string {
+	cache := newAgentCache(agentCacheSize, agentCacheTTL)
+	return func(ctx context.Context) string {
+		conversationID := strings.TrimSpace(runtimerequestctx.ConversationIDFromContext(ctx))
+		if conversationID == "" || rt == nil || rt.Conversation == nil {
+			return ""
+		}
+		if agentID, ok := cache.get(conversationID); ok {
+			return agentID
+		}
+		conversation, err := rt.Conversation.GetConversation(ctx, conversationID)
+		if err != nil || conversation == nil || conversation.AgentId == nil {
+			return ""
+		}
+		agentID := strings.TrimSpace(*conversation.AgentId)
+		cache.put(conversationID, agentID)
+		return agentID
+	}
+}
+
+// agentCache is a small LRU whose entries also expire after ttl.
+type agentCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	now     func() time.Time
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type agentCacheEntry struct {
+	key     string
+	agentID string
+	expires time.Time
+}
+
+func newAgentCache(size int, ttl time.Duration) *agentCache {
+	return &agentCache{size: size, ttl: ttl, now: time.Now, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+func (c *agentCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	entry := element.Value.(*agentCacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return "", false
+	}
+	c.order.MoveToFront(element)
+	return entry.agentID, true
+}
+
+func (c *agentCache) put(key, agentID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		c.order.Remove(element)
+	}
+	c.entries[key] = c.order.PushFront(&agentCacheEntry{key: key, agentID: agentID, expires: c.now().Add(c.ttl)})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*agentCacheEntry).key)
+	}
+}
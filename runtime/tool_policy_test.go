@@ -0,0 +1,213 @@
+package runtime
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	coreplan "github.com/viant/agently-core/protocol/agent/execution"
+	"github.com/viant/agently-core/protocol/tool"
+	runtimerequestctx "github.com/viant/agently-core/runtime/requestctx"
+)
+
+func TestToolPolicyResolve(t *testing.T) {
+	policy, err := NewToolPolicy("ask", &ToolPolicyConfig{
+		Agents: map[string]string{"coder": "deny", "chatter": "auto"},
+		Groups: []*ToolPolicyGroup{
+			{Name: "oncall", Mode: "auto", Users: []string{"alice", "bob"}},
+			{Name: "contractors", Mode: "deny", Users: []string{"bob"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewToolPolicy() error = %v", err)
+	}
+
+	testCases := []struct {
+		name    string
+		agentID string
+		userID  string
+		expect  string
+	}{
+		{name: "global default", agentID: "other", userID: "carol", expect: ToolPolicyAsk},
+		{name: "agent override", agentID: "coder", userID: "carol", expect: ToolPolicyDeny},
+		{name: "agent relaxes", agentID: "chatter", userID: "carol", expect: ToolPolicyAuto},
+		{name: "group beats agent", agentID: "coder", userID: "alice", expect: ToolPolicyAuto},
+		{name: "most restrictive group", agentID: "chatter", userID: "bob", expect: ToolPolicyDeny},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := policy.Resolve(tc.agentID, tc.userID); got != tc.expect {
+				t.Fatalf("Resolve(%q, %q) = %q, want %q", tc.agentID, tc.userID, got, tc.expect)
+			}
+		})
+	}
+}
+
+func TestToolPolicyGlobalDenyIsKillSwitch(t *testing.T) {
+	policy, err := NewToolPolicy("auto", &ToolPolicyConfig{
+		Agents: map[string]string{"coder": "auto"},
+		Groups: []*ToolPolicyGroup{{Name: "admins", Mode: "auto", Users: []string{"alice"}}},
+	})
+	if err != nil {
+		t.Fatalf("NewToolPolicy() error = %v", err)
+	}
+	if err := policy.SetMode("DENY"); err != nil {
+		t.Fatalf("SetMode() error = %v", err)
+	}
+	if got := policy.Resolve("coder", "alice"); got != ToolPolicyDeny {
+		t.Fatalf("Resolve() = %q, want %q", got, ToolPolicyDeny)
+	}
+	if err := policy.SetMode("off"); err == nil {
+		t.Fatalf("expected invalid mode error")
+	}
+	if got := policy.Mode(); got != ToolPolicyDeny {
+		t.Fatalf("Mode() = %q after rejected switch, want %q", got, ToolPolicyDeny)
+	}
+}
+
+func TestNewToolPolicyRejectsInvalidOverride(t *testing.T) {
+	if _, err := NewToolPolicy("auto", &ToolPolicyConfig{Agents: map[string]string{"coder": "maybe"}}); err == nil {
+		t.Fatalf("expected invalid agent override error")
+	}
+	if _, err := NewToolPolicy("sometimes", nil); err == nil {
+		t.Fatalf("expected invalid global mode error")
+	}
+}
+
+func TestLoadToolPolicyConfig(t *testing.T) {
+	root := t.TempDir()
+	if cfg, err := LoadToolPolicyConfig(root); err != nil || cfg != nil {
+		t.Fatalf("LoadToolPolicyConfig() on empty workspace = %v, %v", cfg, err)
+	}
+	content := "default:\n  agent: chatter\ntoolPolicy:\n  agents:\n    coder: ask\n  groups:\n    - name: oncall\n      mode: auto\n      users: [alice]\n"
+	if err := os.WriteFile(filepath.Join(root, "config.yaml"), []byte(content), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err := LoadToolPolicyConfig(root)
+	if err != nil {
+		t.Fatalf("LoadToolPolicyConfig() error = %v", err)
+	}
+	if cfg == nil || cfg.Agents["coder"] != "ask" || len(cfg.Groups) != 1 || cfg.Groups[0].Users[0] != "alice" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+type recordingRegistry struct {
+	tool.Registry
+	executed []string
+}
+
+func (r *recordingRegistry) Execute(_ context.Context, name string, _ map[string]interface{}) (string, error) {
+	r.executed = append(r.executed, name)
+	return "ok", nil
+}
+
+type stubApprovalElicitor struct {
+	action   string
+	requests []*coreplan.Elicitation
+	convIDs  []string
+}
+
+func (s *stubApprovalElicitor) Elicit(_ context.Context, conversationID string, req *coreplan.Elicitation) (*coreplan.ElicitResult, error) {
+	s.convIDs = append(s.convIDs, conversationID)
+	s.requests = append(s.requests, req)
+	return &coreplan.ElicitResult{Action: s.action}, nil
+}
+
+func TestPolicyRegistryAskPromptsForApproval(t *testing.T) {
+	policy, err := NewToolPolicy("ask", nil)
+	if err != nil {
+		t.Fatalf("NewToolPolicy() error = %v", err)
+	}
+	ctx := runtimerequestctx.WithConversationID(context.Background(), "conv-1")
+
+	testCases := []struct {
+		name       string
+		action     string
+		expectErr  string
+		expectRuns int
+	}{
+		{name: "accepted", action: coreplan.ElicitResultActionAccept, expectRuns: 1},
+		{name: "declined", action: coreplan.ElicitResultActionDecline, expectErr: `tool "system/exec:execute" was not approved`},
+		{name: "canceled", action: "cancel", expectErr: `tool "system/exec:execute" was not approved`},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			inner := &recordingRegistry{}
+			elicitor := &stubApprovalElicitor{action: testCase.action}
+			registry := &policyRegistry{Registry: inner, policy: policy, approve: elicitationApprover(elicitor)}
+
+			_, err := registry.Execute(ctx, "system/exec:execute", map[string]interface{}{"commands": []string{"ls"}})
+			if testCase.expectErr == "" && err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if testCase.expectErr != "" && (err == nil || err.Error() != testCase.expectErr) {
+				t.Fatalf("Execute() error = %v, want %q", err, testCase.expectErr)
+			}
+			if len(elicitor.requests) != 1 {
+				t.Fatalf("approval prompts = %d, want 1", len(elicitor.requests))
+			}
+			if elicitor.convIDs[0] != "conv-1" {
+				t.Fatalf("approval asked in %q, want conv-1", elicitor.convIDs[0])
+			}
+			if !strings.Contains(elicitor.requests[0].Message, "system/exec:execute") {
+				t.Fatalf("approval message %q does not name the tool", elicitor.requests[0].Message)
+			}
+			if len(inner.executed) != testCase.expectRuns {
+				t.Fatalf("tool executions = %d, want %d", len(inner.executed), testCase.expectRuns)
+			}
+		})
+	}
+}
+
+func TestPolicyRegistryAutoAndDenySkipApproval(t *testing.T) {
+	for _, mode := range []string{ToolPolicyAuto, ToolPolicyDeny} {
+		policy, err := NewToolPolicy(mode, nil)
+		if err != nil {
+			t.Fatalf("NewToolPolicy(%s) error = %v", mode, err)
+		}
+		inner := &recordingRegistry{}
+		elicitor := &stubApprovalElicitor{action: coreplan.ElicitResultActionAccept}
+		registry := &policyRegistry{Registry: inner, policy: policy, approve: elicitationApprover(elicitor)}
+		_, err = registry.Execute(runtimerequestctx.WithConversationID(context.Background(), "conv-1"), "system/exec:execute", nil)
+		if len(elicitor.requests) != 0 {
+			t.Fatalf("%s: unexpected approval prompt", mode)
+		}
+		if mode == ToolPolicyDeny && (err == nil || len(inner.executed) != 0) {
+			t.Fatalf("deny: error = %v, executions = %d", err, len(inner.executed))
+		}
+		if mode == ToolPolicyAuto && (err != nil || len(inner.executed) != 1) {
+			t.Fatalf("auto: error = %v, executions = %d", err, len(inner.executed))
+		}
+	}
+}
+
+func TestAgentCacheIsBounded(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := newAgentCache(2, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	cache.put("conv-1", "coder")
+	cache.put("conv-2", "chatter")
+	if _, ok := cache.get("conv-1"); !ok {
+		t.Fatalf("conv-1 evicted too early")
+	}
+	cache.put("conv-3", "planner")
+	if _, ok := cache.get("conv-2"); ok {
+		t.Fatalf("least recently used entry was not evicted")
+	}
+	if agentID, ok := cache.get("conv-1"); !ok || agentID != "coder" {
+		t.Fatalf("get(conv-1) = %q, %v", agentID, ok)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := cache.get("conv-3"); ok {
+		t.Fatalf("expired entry was returned")
+	}
+	if len(cache.entries) != 1 || cache.order.Len() != 1 {
+		t.Fatalf("expired entry not removed: %d entries", len(cache.entries))
+	}
+}
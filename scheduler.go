@@ -27,6 +27,16 @@ type SchedulerRunOptions struct {
 	Interval          time.Duration
 	Once              bool
 	ScratchpadRootURI string
+	// DryRun evaluates one cycle without starting runs and reports which
+	// schedules are due and why. It implies Once.
+	DryRun bool
+	// Report selects the cycle report printed to stdout: "text" or "json".
+	// Empty prints nothing for a real run and text for a dry run.
+	Report string
+	// WaitTimeout bounds how long Once waits for the started runs; runs still
+	// in progress then are reported as failed. Zero derives it from the run
+	// timeouts of the due schedules.
+	WaitTimeout time.Duration
 }
 
 func RunScheduler(options SchedulerRunOptions) error {
@@ -98,23 +108,85 @@ func RunScheduler(options SchedulerRunOptions) error {
 	if err != nil {
 		return fmt.Errorf("failed to initialize scheduler store: %w", err)
 	}
-	schedulerSvc := svcscheduler.New(
-		scheduleStore,
-		rt.Agent,
+	schedulerSvcOpts := []svcscheduler.Option{
 		svcscheduler.WithConversationClient(rt.Conversation),
 		svcscheduler.WithAuthConfig(authCfg),
 		svcscheduler.WithTokenProvider(tokenProvider),
 		svcscheduler.WithUserService(svcauth.NewDatlyUserService(rt.DAO)),
 		svcscheduler.WithUserCredAuthConfig(userCredAuthCfg),
 		svcscheduler.WithInterval(interval),
-	)
+	}
+	if cap := agentlyrt.SchedulerMaxConcurrentRunsFromEnv(); cap > 0 {
+		schedulerSvcOpts = append(schedulerSvcOpts, svcscheduler.WithMaxConcurrentRuns(cap))
+	}
+	schedulerSvc := svcscheduler.New(scheduleStore, rt.Agent, schedulerSvcOpts...)
 
-	if options.Once {
-		_, err = schedulerSvc.RunDue(ctx)
-		return err
+	if options.Once || options.DryRun {
+		return runScheduleCycle(ctx, schedulerSvc, scheduleStore, options)
 	}
 
 	log.Printf("agently scheduler run started (workspace=%s interval=%s)", workspace.Root(), interval)
 	schedulerSvc.StartWatchdog(ctx)
 	return nil
 }
+
+// runScheduleCycle runs (or, with DryRun, plans) one RunDue cycle. The
+// service decides which schedules are due; the store is only read to explain
+// the others and to follow the started runs until they finish, since RunDue
+// dispatches them asynchronously.
+func runScheduleCycle(ctx context.Context, schedulerSvc *svcscheduler.Service, scheduleStore svcscheduler.Store, options SchedulerRunOptions) error {
+	startedAt := time.Now()
+	all, err := listScheduleStates(ctx, scheduleStore.List)
+	if err != nil {
+		return err
+	}
+	dueRows, err := schedulerSvc.DueSchedules(ctx, startedAt)
+	if err != nil {
+		return fmt.Errorf("failed to list due schedules: %w", err)
+	}
+	due, err := decodeScheduleStates(dueRows)
+	if err != nil {
+		return err
+	}
+	report := planScheduleCycle(all, due, startedAt)
+	report.MaxConcurrentRuns = agentlyrt.SchedulerMaxConcurrentRunsFromEnv()
+	if options.DryRun {
+		report.DryRun = true
+		return writeScheduleCycleReport(os.Stdout, report, options.Report)
+	}
+	started, err := schedulerSvc.RunDue(ctx)
+	if err != nil {
+		return err
+	}
+	waitTimeout := options.WaitTimeout
+	if waitTimeout <= 0 {
+		waitTimeout = scheduleWaitTimeout(due, report.MaxConcurrentRuns)
+	}
+	if err := waitForScheduleRuns(ctx, report, started, func(ctx context.Context) ([]*scheduleState, error) {
+		return listScheduleStates(ctx, scheduleStore.List)
+	}, time.Second, waitTimeout); err != nil {
+		return err
+	}
+	if options.Report != "" {
+		if err := writeScheduleCycleReport(os.Stdout, report, options.Report); err != nil {
+			return err
+		}
+	}
+	log.Printf("scheduler cycle: %d due, %d started, %d failed", report.Due, report.Started, report.Failed)
+	if report.TimedOut {
+		return fmt.Errorf("scheduler run: runs still unfinished after %s; %d of %d started run(s) failed", waitTimeout, report.Failed, report.Started)
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("scheduler run: %d of %d started run(s) failed", report.Failed, report.Started)
+	}
+	return nil
+}
+
+// listScheduleStates reads all schedules through the store's List method.
+func listScheduleStates[T any](ctx context.Context, list func(context.Context) (T, error)) ([]*scheduleState, error) {
+	rows, err := list(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+	return decodeScheduleStates(rows)
+}
@@ -0,0 +1,349 @@
+package agently
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// ScheduleCycleReport summarizes one `scheduler run --once` cycle, or the
+// plan of a dry run.
+type ScheduleCycleReport struct {
+	StartedAt  time.Time `json:"startedAt"`
+	DurationMs int64     `json:"durationMs"`
+	DryRun     bool      `json:"dryRun,omitempty"`
+	// MaxConcurrentRuns is the scheduler's cap on in-flight runs; 0 means
+	// unbounded.
+	MaxConcurrentRuns int `json:"maxConcurrentRuns"`
+	// Running counts the schedules whose last run was in progress when the
+	// store was last read.
+	Running   int                   `json:"running"`
+	Due       int                   `json:"due"`
+	Started   int                   `json:"started"`
+	Failed    int                   `json:"failed"`
+	TimedOut  bool                  `json:"timedOut,omitempty"`
+	Schedules []*ScheduleCycleEntry `json:"schedules"`
+}
+
+// ScheduleCycleEntry is the state of one schedule in a cycle.
+type ScheduleCycleEntry struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name,omitempty"`
+	Enabled    bool       `json:"enabled"`
+	Due        bool       `json:"due"`
+	Reason     string     `json:"reason"`
+	NextRunAt  *time.Time `json:"nextRunAt,omitempty"`
+	LeaseOwner string     `json:"leaseOwner,omitempty"`
+	LeaseUntil *time.Time `json:"leaseUntil,omitempty"`
+	Status     string     `json:"status,omitempty"`
+	LastRunAt  *time.Time `json:"lastRunAt,omitempty"`
+	Error      string     `json:"error,omitempty"`
+
+	previousRunAt *time.Time
+}
+
+// scheduleState is the subset of a stored schedule the cycle report needs.
+// Store rows are decoded through JSON so only the wire field names matter.
+type scheduleState struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Enabled      bool       `json:"enabled"`
+	ScheduleType string     `json:"scheduleType"`
+	StartAt      *time.Time `json:"startAt"`
+	EndAt        *time.Time `json:"endAt"`
+	NextRunAt    *time.Time `json:"nextRunAt"`
+	LastRunAt    *time.Time `json:"lastRunAt"`
+	LastStatus   string     `json:"lastStatus"`
+	LastError    string     `json:"lastError"`
+	LeaseOwner   string     `json:"leaseOwner"`
+	LeaseUntil   *time.Time `json:"leaseUntil"`
+	// TimeoutSeconds bounds one run of the schedule; 0 leaves it unset.
+	TimeoutSeconds int `json:"timeoutSeconds"`
+}
+
+func decodeScheduleStates(rows interface{}) ([]*scheduleState, error) {
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return nil, err
+	}
+	var states []*scheduleState
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("decode schedules: %w", err)
+	}
+	out := states[:0]
+	for _, state := range states {
+		if state != nil && strings.TrimSpace(state.ID) != "" {
+			out = append(out, state)
+		}
+	}
+	return out, nil
+}
+
+// planScheduleCycle builds the report for a cycle at now. due is the set the
+// scheduler service returned; the other schedules only get a reason that
+// explains why they are not due.
+func planScheduleCycle(states, due []*scheduleState, now time.Time) *ScheduleCycleReport {
+	report := &ScheduleCycleReport{StartedAt: now}
+	dueIDs := make(map[string]bool, len(due))
+	for _, state := range due {
+		dueIDs[state.ID] = true
+	}
+	for _, state := range states {
+		if isActiveRunStatus(normalizeRunStatus(state.LastStatus)) {
+			report.Running++
+		}
+		entry := &ScheduleCycleEntry{
+			ID:         state.ID,
+			Name:       state.Name,
+			Enabled:    state.Enabled,
+			Due:        dueIDs[state.ID],
+			NextRunAt:  state.NextRunAt,
+			LeaseOwner: state.LeaseOwner,
+			LeaseUntil: state.LeaseUntil,
+			LastRunAt:  state.LastRunAt,
+		}
+		entry.previousRunAt = state.LastRunAt
+		if entry.Due {
+			report.Due++
+			entry.Reason = "due: next fire time not computed yet"
+			if state.NextRunAt != nil {
+				entry.Reason = "due since " + state.NextRunAt.UTC().Format(time.RFC3339)
+			}
+		} else {
+			entry.Reason = scheduleSkipReason(state, now)
+		}
+		report.Schedules = append(report.Schedules, entry)
+	}
+	sort.SliceStable(report.Schedules, func(i, j int) bool {
+		left, right := report.Schedules[i], report.Schedules[j]
+		if left.Due != right.Due {
+			return left.Due
+		}
+		return scheduleTimeOrZero(left.NextRunAt).Before(scheduleTimeOrZero(right.NextRunAt))
+	})
+	return report
+}
+
+// scheduleSkipReason describes why the service left a schedule out of the
+// due set.
+func scheduleSkipReason(state *scheduleState, now time.Time) string {
+	switch {
+	case !state.Enabled:
+		return "disabled"
+	case state.StartAt != nil && now.Before(*state.StartAt):
+		return "starts at " + state.StartAt.UTC().Format(time.RFC3339)
+	case state.EndAt != nil && !now.Before(*state.EndAt):
+		return "ended at " + state.EndAt.UTC().Format(time.RFC3339)
+	case state.LeaseUntil != nil && now.Before(*state.LeaseUntil):
+		owner := state.LeaseOwner
+		if owner == "" {
+			owner = "another runner"
+		}
+		return fmt.Sprintf("leased by %s until %s", owner, state.LeaseUntil.UTC().Format(time.RFC3339))
+	case state.NextRunAt == nil && strings.EqualFold(state.ScheduleType, "adhoc"):
+		return "adhoc: runs only when triggered"
+	case state.NextRunAt != nil && state.NextRunAt.After(now):
+		return "next fire at " + state.NextRunAt.UTC().Format(time.RFC3339)
+	}
+	return "not due"
+}
+
+// defaultScheduleRunTimeout stands in for the timeout of a schedule that does
+// not set one when the wait deadline is derived.
+const defaultScheduleRunTimeout = 30 * time.Minute
+
+// scheduleWaitTimeout derives how long a cycle waits for its runs: the longest
+// run timeout of the due schedules, once per wave of runs the concurrency cap
+// allows, plus a minute for dispatch and bookkeeping.
+func scheduleWaitTimeout(due []*scheduleState, maxConcurrentRuns int) time.Duration {
+	longest := time.Duration(0)
+	for _, state := range due {
+		timeout := defaultScheduleRunTimeout
+		if state.TimeoutSeconds > 0 {
+			timeout = time.Duration(state.TimeoutSeconds) * time.Second
+		}
+		if timeout > longest {
+			longest = timeout
+		}
+	}
+	waves := 1
+	if maxConcurrentRuns > 0 && len(due) > maxConcurrentRuns {
+		waves = (len(due) + maxConcurrentRuns - 1) / maxConcurrentRuns
+	}
+	return time.Duration(waves)*longest + time.Minute
+}
+
+// waitForScheduleRuns polls the store until the runs of a cycle finish or
+// timeout passes. started is the count RunDue returned; runs are dispatched
+// asynchronously, so their outcome only shows up in the store later, and the
+// report counts them from the store rather than trusting started. At the
+// deadline the runs still in progress, and those RunDue counted but the store
+// never showed, are reported as failed.
+func waitForScheduleRuns(ctx context.Context, report *ScheduleCycleReport, started int, list func(context.Context) ([]*scheduleState, error), poll, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		after, err := list(ctx)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if !completeScheduleCycle(report, after, now) && report.Started >= started {
+			return nil
+		}
+		if !now.Before(deadline) {
+			expireScheduleRuns(report, started, timeout)
+			return nil
+		}
+		wait := poll
+		if remaining := deadline.Sub(now); remaining < wait {
+			wait = remaining
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for %d scheduled run(s): %w", started, ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+}
+
+// expireScheduleRuns fails the runs of a completed report that were still in
+// progress when the wait timed out.
+func expireScheduleRuns(report *ScheduleCycleReport, started int, timeout time.Duration) {
+	report.TimedOut = true
+	for _, entry := range report.Schedules {
+		if entry.Status == "" || isActiveRunStatus(entry.Status) {
+			if entry.Status == "" {
+				entry.Status = "pending"
+			}
+			entry.Error = fmt.Sprintf("still %s after waiting %s", entry.Status, timeout)
+			entry.Status = "timeout"
+			report.Failed++
+		}
+	}
+	if missing := started - report.Started; missing > 0 {
+		report.Failed += missing
+		report.Started = started
+	}
+}
+
+// completeScheduleCycle fills the planned report with the outcome read back
+// from the store and reports whether any started run is still in progress. A
+// due schedule whose last run did not advance was not started by this process
+// (e.g. another runner claimed it); a schedule that was not due but whose last
+// run advanced became due after planning and is counted as started.
+func completeScheduleCycle(report *ScheduleCycleReport, after []*scheduleState, finishedAt time.Time) bool {
+	report.DurationMs = finishedAt.Sub(report.StartedAt).Milliseconds()
+	report.Started, report.Failed, report.Running = 0, 0, 0
+	byID := make(map[string]*scheduleState, len(after))
+	for _, state := range after {
+		byID[state.ID] = state
+		if isActiveRunStatus(normalizeRunStatus(state.LastStatus)) {
+			report.Running++
+		}
+	}
+	running := false
+	for _, entry := range report.Schedules {
+		state := byID[entry.ID]
+		advanced := state != nil && state.LastRunAt != nil && (entry.previousRunAt == nil || state.LastRunAt.After(*entry.previousRunAt))
+		if !advanced {
+			entry.Status = "not-started"
+			if !entry.Due {
+				entry.Status = "skipped"
+			}
+			continue
+		}
+		if !entry.Due {
+			entry.Reason = "became due after planning"
+		}
+		report.Started++
+		entry.LastRunAt = state.LastRunAt
+		entry.NextRunAt = state.NextRunAt
+		entry.Status = normalizeRunStatus(state.LastStatus)
+		entry.Error = ""
+		switch {
+		case entry.Status == "" || isActiveRunStatus(entry.Status):
+			running = true
+		case isFailedRunStatus(entry.Status):
+			report.Failed++
+			entry.Error = strings.TrimSpace(state.LastError)
+		}
+	}
+	return running
+}
+
+func normalizeRunStatus(status string) string {
+	return strings.ToLower(strings.TrimSpace(status))
+}
+
+func isActiveRunStatus(status string) bool {
+	switch status {
+	case "pending", "queued", "started", "running":
+		return true
+	}
+	return false
+}
+
+func isFailedRunStatus(status string) bool {
+	switch normalizeRunStatus(status) {
+	case "failed", "error", "timeout", "timed_out", "canceled", "cancelled":
+		return true
+	}
+	return false
+}
+
+func writeScheduleCycleReport(out io.Writer, report *ScheduleCycleReport, format string) error {
+	if strings.EqualFold(strings.TrimSpace(format), "json") {
+		if report.Schedules == nil {
+			report.Schedules = []*ScheduleCycleEntry{}
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	w := tabwriter.NewWriter(out, 0, 2, 2, ' ', 0)
+	if report.DryRun {
+		fmt.Fprintln(w, "ID\tNAME\tDUE\tREASON")
+		for _, entry := range report.Schedules {
+			fmt.Fprintf(w, "%s\t%s\t%t\t%s\n", entry.ID, entry.Name, entry.Due, entry.Reason)
+		}
+	} else {
+		fmt.Fprintln(w, "ID\tNAME\tSTATUS\tREASON\tERROR")
+		for _, entry := range report.Schedules {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", entry.ID, entry.Name, entry.Status, entry.Reason, entry.Error)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	var err error
+	if report.DryRun {
+		_, err = fmt.Fprintf(out, "%d of %d schedule(s) due\n", report.Due, len(report.Schedules))
+	} else {
+		timedOut := ""
+		if report.TimedOut {
+			timedOut = " (timed out)"
+		}
+		_, err = fmt.Fprintf(out, "%d due, %d started, %d failed in %dms%s\n", report.Due, report.Started, report.Failed, report.DurationMs, timedOut)
+	}
+	if err != nil {
+		return err
+	}
+	limit := "no concurrency cap"
+	if report.MaxConcurrentRuns > 0 {
+		limit = fmt.Sprintf("at most %d concurrent run(s)", report.MaxConcurrentRuns)
+	}
+	_, err = fmt.Fprintf(out, "%d running, %s\n", report.Running, limit)
+	return err
+}
+
+func scheduleTimeOrZero(value *time.Time) time.Time {
+	if value == nil {
+		return time.Time{}
+	}
+	return *value
+}
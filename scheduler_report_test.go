@@ -0,0 +1,220 @@
+package agently
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlanScheduleCycle(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		value := now.Add(d)
+		return &value
+	}
+	states, err := decodeScheduleStates([]map[string]interface{}{
+		{"id": "overdue", "name": "Overdue", "enabled": true, "scheduleType": "cron", "nextRunAt": now.Add(-time.Hour)},
+		{"id": "recent", "enabled": true, "scheduleType": "interval", "nextRunAt": now.Add(-time.Minute)},
+		{"id": "fresh", "enabled": true, "scheduleType": "cron"},
+		{"id": "later", "enabled": true, "scheduleType": "cron", "nextRunAt": now.Add(time.Hour)},
+		{"id": "off", "enabled": false, "nextRunAt": now.Add(-time.Hour)},
+		{"id": "leased", "enabled": true, "nextRunAt": now.Add(-time.Hour), "leaseOwner": "pod-a", "leaseUntil": now.Add(time.Minute)},
+		{"id": "manual", "enabled": true, "scheduleType": "adhoc"},
+		{"id": "expired", "enabled": true, "nextRunAt": now.Add(-time.Hour), "endAt": now.Add(-time.Second)},
+		{"name": "no id"},
+	})
+	if err != nil {
+		t.Fatalf("decode states: %v", err)
+	}
+	due, _ := decodeScheduleStates([]map[string]interface{}{{"id": "overdue"}, {"id": "fresh"}})
+	report := planScheduleCycle(states, due, now)
+
+	reasons := map[string]string{}
+	isDue := map[string]bool{}
+	for _, entry := range report.Schedules {
+		reasons[entry.ID] = entry.Reason
+		isDue[entry.ID] = entry.Due
+	}
+	if len(report.Schedules) != 8 || report.Due != 2 {
+		t.Fatalf("expected 8 schedules with 2 due, got %d with %d due", len(report.Schedules), report.Due)
+	}
+	if !isDue["fresh"] || !isDue["overdue"] || isDue["recent"] {
+		t.Fatalf("unexpected due set: %v", isDue)
+	}
+	if report.Schedules[0].ID != "fresh" || report.Schedules[1].ID != "overdue" {
+		t.Fatalf("expected due schedules first, got %s, %s", report.Schedules[0].ID, report.Schedules[1].ID)
+	}
+	expect := map[string]string{
+		"overdue": "due since " + at(-time.Hour).Format(time.RFC3339),
+		"recent":  "not due",
+		"later":   "next fire at " + at(time.Hour).Format(time.RFC3339),
+		"off":     "disabled",
+		"leased":  "leased by pod-a until " + at(time.Minute).Format(time.RFC3339),
+		"manual":  "adhoc: runs only when triggered",
+		"expired": "ended at " + at(-time.Second).Format(time.RFC3339),
+	}
+	for id, want := range expect {
+		if reasons[id] != want {
+			t.Fatalf("%s reason = %q, want %q", id, reasons[id], want)
+		}
+	}
+}
+
+func TestCompleteScheduleCycle(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	before, _ := decodeScheduleStates([]map[string]interface{}{
+		{"id": "ok", "enabled": true, "nextRunAt": now.Add(-time.Minute), "lastRunAt": now.Add(-24 * time.Hour)},
+		{"id": "broken", "enabled": true, "nextRunAt": now.Add(-time.Minute)},
+		{"id": "stolen", "enabled": true, "nextRunAt": now.Add(-time.Minute)},
+		{"id": "idle", "enabled": false},
+	})
+	after, _ := decodeScheduleStates([]map[string]interface{}{
+		{"id": "ok", "lastRunAt": now, "lastStatus": "succeeded", "nextRunAt": now.Add(24 * time.Hour)},
+		{"id": "broken", "lastRunAt": now, "lastStatus": "failed", "lastError": "agent not found"},
+		{"id": "stolen"},
+		{"id": "idle"},
+	})
+	report := planScheduleCycle(before, before[:3], now)
+	if completeScheduleCycle(report, after, now.Add(1500*time.Millisecond)) {
+		t.Fatalf("expected no run in progress")
+	}
+	if report.Started != 2 || report.Failed != 1 || report.DurationMs != 1500 {
+		t.Fatalf("unexpected totals: started=%d failed=%d duration=%d", report.Started, report.Failed, report.DurationMs)
+	}
+	status := map[string]*ScheduleCycleEntry{}
+	for _, entry := range report.Schedules {
+		status[entry.ID] = entry
+	}
+	if status["ok"].Status != "succeeded" || status["broken"].Error != "agent not found" || status["stolen"].Status != "not-started" || status["idle"].Status != "skipped" {
+		t.Fatalf("unexpected statuses: ok=%s broken=%s/%s stolen=%s idle=%s", status["ok"].Status, status["broken"].Status, status["broken"].Error, status["stolen"].Status, status["idle"].Status)
+	}
+
+	var buf bytes.Buffer
+	if err := writeScheduleCycleReport(&buf, report, "json"); err != nil {
+		t.Fatalf("write json report: %v", err)
+	}
+	var decoded ScheduleCycleReport
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("decode json report: %v", err)
+	}
+	if decoded.Failed != 1 || len(decoded.Schedules) != 4 {
+		t.Fatalf("unexpected json report: %s", buf.String())
+	}
+	buf.Reset()
+	if err := writeScheduleCycleReport(&buf, report, "text"); err != nil {
+		t.Fatalf("write text report: %v", err)
+	}
+	if !strings.Contains(buf.String(), "3 due, 2 started, 1 failed in 1500ms") {
+		t.Fatalf("unexpected text report:\n%s", buf.String())
+	}
+}
+
+func TestWaitForScheduleRuns(t *testing.T) {
+	now := time.Now()
+	before, _ := decodeScheduleStates([]map[string]interface{}{
+		{"id": "nightly", "enabled": true, "nextRunAt": now.Add(-time.Minute)},
+	})
+	polls := [][]map[string]interface{}{
+		{{"id": "nightly"}},
+		{{"id": "nightly", "lastRunAt": now, "lastStatus": "running"}},
+		{{"id": "nightly", "lastRunAt": now, "lastStatus": "failed", "lastError": "model unavailable"}},
+	}
+	calls := 0
+	list := func(context.Context) ([]*scheduleState, error) {
+		rows := polls[calls]
+		calls++
+		return decodeScheduleStates(rows)
+	}
+	report := planScheduleCycle(before, before, now)
+	if err := waitForScheduleRuns(context.Background(), report, 1, list, time.Millisecond, time.Minute); err != nil {
+		t.Fatalf("wait for runs: %v", err)
+	}
+	if calls != 3 || report.Started != 1 || report.Failed != 1 || report.Schedules[0].Error != "model unavailable" {
+		t.Fatalf("unexpected outcome after %d polls: started=%d failed=%d error=%q", calls, report.Started, report.Failed, report.Schedules[0].Error)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	stuck := func(context.Context) ([]*scheduleState, error) {
+		return decodeScheduleStates([]map[string]interface{}{{"id": "nightly", "lastRunAt": now, "lastStatus": "running"}})
+	}
+	if err := waitForScheduleRuns(ctx, planScheduleCycle(before, before, now), 1, stuck, time.Millisecond, time.Minute); err == nil {
+		t.Fatalf("expected a canceled wait to fail")
+	}
+
+	report = planScheduleCycle(before, before, now)
+	if err := waitForScheduleRuns(context.Background(), report, 2, stuck, time.Millisecond, 20*time.Millisecond); err != nil {
+		t.Fatalf("wait for stuck runs: %v", err)
+	}
+	entry := report.Schedules[0]
+	if !report.TimedOut || report.Started != 2 || report.Failed != 2 || entry.Status != "timeout" || !strings.Contains(entry.Error, "still running") {
+		t.Fatalf("unexpected timed out report: started=%d failed=%d status=%s error=%q", report.Started, report.Failed, entry.Status, entry.Error)
+	}
+}
+
+func TestWaitForScheduleRuns_CountsUnplannedRuns(t *testing.T) {
+	now := time.Now()
+	before, _ := decodeScheduleStates([]map[string]interface{}{
+		{"id": "nightly", "enabled": true, "nextRunAt": now.Add(-time.Minute)},
+		{"id": "hourly", "enabled": true, "nextRunAt": now.Add(time.Second)},
+	})
+	list := func(context.Context) ([]*scheduleState, error) {
+		return decodeScheduleStates([]map[string]interface{}{
+			{"id": "nightly", "lastRunAt": now, "lastStatus": "succeeded"},
+			{"id": "hourly", "lastRunAt": now, "lastStatus": "succeeded"},
+		})
+	}
+	report := planScheduleCycle(before, before[:1], now)
+	if err := waitForScheduleRuns(context.Background(), report, 2, list, time.Millisecond, time.Minute); err != nil {
+		t.Fatalf("wait for runs: %v", err)
+	}
+	if report.TimedOut || report.Started != 2 || report.Failed != 0 || report.Schedules[1].Reason != "became due after planning" {
+		t.Fatalf("unexpected report: timedOut=%v started=%d failed=%d reason=%q", report.TimedOut, report.Started, report.Failed, report.Schedules[1].Reason)
+	}
+}
+
+func TestScheduleWaitTimeout(t *testing.T) {
+	due, _ := decodeScheduleStates([]map[string]interface{}{
+		{"id": "a", "timeoutSeconds": 600},
+		{"id": "b", "timeoutSeconds": 120},
+		{"id": "c"},
+	})
+	if got, want := scheduleWaitTimeout(due[:2], 0), 11*time.Minute; got != want {
+		t.Fatalf("uncapped wait = %s, want %s", got, want)
+	}
+	if got, want := scheduleWaitTimeout(due[:2], 1), 21*time.Minute; got != want {
+		t.Fatalf("one run at a time wait = %s, want %s", got, want)
+	}
+	if got, want := scheduleWaitTimeout(due, 0), defaultScheduleRunTimeout+time.Minute; got != want {
+		t.Fatalf("wait without a run timeout = %s, want %s", got, want)
+	}
+}
+
+func TestWriteScheduleCycleReport_Concurrency(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	states, _ := decodeScheduleStates([]map[string]interface{}{
+		{"id": "nightly", "enabled": true, "nextRunAt": now.Add(-time.Minute)},
+		{"id": "busy", "enabled": true, "nextRunAt": now.Add(time.Hour), "lastStatus": "running"},
+	})
+	report := planScheduleCycle(states, states[:1], now)
+	report.DryRun = true
+	report.MaxConcurrentRuns = 4
+	var buf bytes.Buffer
+	if err := writeScheduleCycleReport(&buf, report, "text"); err != nil {
+		t.Fatalf("write text report: %v", err)
+	}
+	if !strings.Contains(buf.String(), "1 of 2 schedule(s) due\n1 running, at most 4 concurrent run(s)") {
+		t.Fatalf("unexpected text report:\n%s", buf.String())
+	}
+	buf.Reset()
+	if err := writeScheduleCycleReport(&buf, report, "json"); err != nil {
+		t.Fatalf("write json report: %v", err)
+	}
+	var decoded ScheduleCycleReport
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || decoded.MaxConcurrentRuns != 4 || decoded.Running != 1 {
+		t.Fatalf("unexpected json report: %s", buf.String())
+	}
+}
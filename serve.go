@@ -6,6 +6,7 @@ import (
 	"fmt"
 	iofs "io/fs"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -15,11 +16,9 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/viant/afs"
 	_ "github.com/viant/afs/file"
 	"github.com/viant/agently-core/adapter/http/ui"
 	"github.com/viant/agently-core/app/executor"
-	execconfig "github.com/viant/agently-core/app/executor/config"
 	appserver "github.com/viant/agently-core/app/server"
 	mcpexpose "github.com/viant/agently-core/protocol/mcp/expose"
 	"github.com/viant/agently-core/protocol/tool"
@@ -31,12 +30,8 @@ import (
 	uiview "github.com/viant/agently-core/protocol/tool/service/ui/view"
 	uiwindow "github.com/viant/agently-core/protocol/tool/service/ui/window"
 	agentsvc "github.com/viant/agently-core/service/agent"
-	svcauthctx "github.com/viant/agently-core/service/auth"
-	svcscheduler "github.com/viant/agently-core/service/scheduler"
 	"github.com/viant/agently-core/workspace"
-	wscfg "github.com/viant/agently-core/workspace/config"
 	forgewindowrepo "github.com/viant/agently-core/workspace/repository/forgewindow"
-	"github.com/viant/agently/bootstrap"
 	deployui "github.com/viant/agently/deployment/ui"
 	coremeta "github.com/viant/agently/metadata"
 	agentlyrt "github.com/viant/agently/runtime"
@@ -62,6 +57,7 @@ type ServeOptions struct {
 	Debug             bool
 	Policy            string // tool policy: auto|ask|deny
 	ExposeMCP         bool   // expose tools over MCP HTTP server
+	Name              string // instance registry name; defaults to <workspace>-<port>
 }
 
 const (
@@ -103,117 +99,29 @@ func Serve(options ServeOptions) error {
 		enableDebugLogging()
 	}
 
-	workspace.SetRoot(workspacePath)
 	log.Printf("workspace: %s", workspacePath)
-	bootstrap.SetBootstrapHook()
-	workspace.EnsureDefault(afs.New())
+	wsConfig, err := loadWorkspace(workspacePath)
 	defer workspace.SetBootstrapHook(nil)
-
-	wsConfig, err := wscfg.Load(workspace.Root())
 	if err != nil {
-		return fmt.Errorf("failed to load workspace config: %w", err)
+		return err
 	}
 	reportingRuntime, err := configureWorkspaceReporting(ctx, workspace.Root(), wsConfig, debugEnabled)
 	if err != nil {
 		return err
 	}
 	defer reportingRuntime.Close()
-	defaults := (&wscfg.Root{}).DefaultsWithFallback(&execconfig.Defaults{
-		Model:    "openai_gpt-5.2",
-		Embedder: "openai_text",
-		Agent:    "chatter",
-	})
-	if wsConfig != nil {
-		defaults = wsConfig.DefaultsWithFallback(defaults)
-	}
-	wscfg.ApplyPathDefaults(defaults)
-	if err := defaults.Reporting.ValidateOrchestrationPrerequisites(); err != nil {
-		return fmt.Errorf("invalid reporting orchestration configuration: %w", err)
-	}
-	orchestrationEnabled := defaults.Reporting.OrchestrationEnabled()
 
-	rt, client, agentFndr, err := appserver.BuildWorkspaceRuntime(ctx, appserver.RuntimeOptions{
-		WorkspaceRoot: workspace.Root(),
-		Defaults:      defaults,
-		ConfigureRuntime: func(ctx context.Context, rt *executor.Runtime, workspaceRoot string) {
-			agentlyrt.ConfigureRegistry(ctx, rt, workspaceRoot)
+	wr, err := buildWorkspaceRuntime(ctx, wsConfig, workspaceRuntimeOptions{
+		Policy: options.Policy,
+		Configure: func(ctx context.Context, rt *executor.Runtime) error {
+			return registerUIServices(ctx, rt, reportingRuntime)
 		},
 	})
 	if err != nil {
-		return fmt.Errorf("failed to initialize runtime: %w", err)
-	}
-	if orchestrationEnabled {
-		switch {
-		case rt.Registry == nil:
-			return fmt.Errorf("reporting orchestration requires a tool registry")
-		case rt.Reporting == nil:
-			return fmt.Errorf("reporting orchestration requires the reporting service")
-		case rt.ReportRuns == nil:
-			return fmt.Errorf("reporting orchestration requires the durable report-run service")
-		}
-	}
-
-	authRuntime, err := svcauthctx.NewRuntime(ctx, workspace.Root(), rt.DAO)
-	if err != nil {
-		return fmt.Errorf("failed to initialize auth runtime: %w", err)
+		return err
 	}
+	rt, authRuntime, toolPolicy := wr.Runtime, wr.AuthRuntime, wr.ToolPolicy
 	speechHandler := server.NewSpeechHandler()
-
-	scheduleStore, err := svcscheduler.NewDatlyStore(ctx, rt.DAO, rt.Data)
-	if err != nil {
-		return fmt.Errorf("failed to initialize scheduler store: %w", err)
-	}
-	schedulerSvcOpts := []svcscheduler.Option{
-		svcscheduler.WithConversationClient(rt.Conversation),
-		svcscheduler.WithAuthConfig(rt.AuthConfig),
-		svcscheduler.WithTokenProvider(rt.TokenProvider),
-		svcscheduler.WithUserService(svcauthctx.NewDatlyUserService(rt.DAO)),
-	}
-	if cap := agentlyrt.SchedulerMaxConcurrentRunsFromEnv(); cap > 0 {
-		schedulerSvcOpts = append(schedulerSvcOpts, svcscheduler.WithMaxConcurrentRuns(cap))
-		log.Printf("scheduler: max concurrent runs capped at %d", cap)
-	}
-	schedulerSvc := svcscheduler.New(scheduleStore, rt.Agent, schedulerSvcOpts...)
-	uiBridge := rt.UIBridge
-	if uiBridge == nil {
-		return fmt.Errorf("runtime Forge UI bridge is not configured")
-	}
-	forgeWindowRepo := forgewindowrepo.NewWithStore(rt.Store)
-	logLoadedForgeWindows(ctx, forgeWindowRepo)
-	if rt.Registry != nil {
-		if err := tool.AddInternalService(rt.Registry, uiview.New(forgeWindowRepo, uiBridge, uiview.WithListItemEnricher(reportingRuntime.EnrichView))); err != nil {
-			log.Printf("agently-app: failed to register internal UI view service: %v", err)
-		}
-		if err := tool.AddInternalService(rt.Registry, uiwindow.New(uiBridge)); err != nil {
-			log.Printf("agently-app: failed to register internal UI window service: %v", err)
-		}
-		if err := tool.AddInternalService(rt.Registry, uicontrol.New(uiBridge)); err != nil {
-			log.Printf("agently-app: failed to register internal UI control service: %v", err)
-		}
-		if err := tool.AddInternalService(rt.Registry, uidatasource.New(uiBridge)); err != nil {
-			log.Printf("agently-app: failed to register internal UI datasource service: %v", err)
-		}
-		if err := tool.AddInternalService(rt.Registry, uicontext.New(uiBridge)); err != nil {
-			log.Printf("agently-app: failed to register internal UI context service: %v", err)
-		}
-		uiEventsService := uievents.New(uiBridge)
-		if rt.Defaults != nil && rt.Defaults.Reporting.BrowserRunPersistenceEnabled() && rt.ReportRuns != nil {
-			uiEventsService = uievents.New(uiBridge, uievents.WithDurableReportRuns(rt.ReportRuns))
-		}
-		if err := tool.AddInternalService(rt.Registry, uiEventsService); err != nil {
-			log.Printf("agently-app: failed to register internal UI events service: %v", err)
-		}
-		uiReportService := uireport.New(uiBridge)
-		if orchestrationEnabled {
-			uiReportService = uireport.New(uiBridge, uireport.WithOrchestration(rt.ReportRuns))
-		}
-		if err := tool.AddInternalService(rt.Registry, uiReportService); err != nil {
-			if orchestrationEnabled {
-				return fmt.Errorf("register orchestration-enabled UI report service: %w", err)
-			}
-			log.Printf("agently-app: failed to register internal UI report service: %v", err)
-		}
-	}
 	agentWatchdog := agentsvc.NewWatchdog(rt.Data, rt.Agent, agentsvc.WithWatchdogTokenProvider(rt.TokenProvider))
 	go agentWatchdog.Start(ctx)
 	go func() {
@@ -221,26 +129,11 @@ func Serve(options ServeOptions) error {
 			log.Printf("conversation status reconcile error: %v", err)
 		}
 	}()
-	schedulerOpts := agentlyrt.SchedulerOptionsFromEnv()
-	apiHandler, err := appserver.NewAPIHandler(ctx, appserver.APIOptions{
-		Version:          firstNonEmpty(strings.TrimSpace(Version), "agently-v1"),
-		Runtime:          rt,
-		Client:           client,
-		AgentFinder:      agentFndr,
-		AgentIDs:         appserver.DiscoverWorkspaceAgentIDs(workspace.Root()),
-		AuthRuntime:      authRuntime,
-		SchedulerService: schedulerSvc,
-		SchedulerOptions: schedulerOpts,
-		UIBridgeHandler:  http.HandlerFunc(uiBridge.Hub().ServeHTTPRPC),
-	})
-	if err != nil {
-		return fmt.Errorf("failed to create api handler: %w", err)
-	}
 	metaRoot := "embed://localhost/"
 	metaHandler := ui.NewEmbeddedHandler(metaRoot, &coremeta.FS)
 	uiBundle := servedUIBundle{Name: "v1", FS: deployui.FS, Index: deployui.Index}
 
-	h := newRouter(apiHandler, metaHandler, speechHandler, uiDist, uiBundle)
+	h := newRouter(wr.Handler, metaHandler, speechHandler, uiDist, uiBundle)
 	// Bound header-read and idle keep-alive so half-open / slow-loris
 	// connections cannot accumulate goroutines+threads. Body read/write
 	// timeouts are intentionally left zero because SSE handlers are
@@ -307,11 +200,97 @@ func Serve(options ServeOptions) error {
 		wg.Wait()
 	}()
 
-	log.Printf("agently serve listening on %s (workspace=%s ui=%s)", addr, workspace.Root(), uiBundle.Name)
-	serveErr := srv.ListenAndServe()
+	log.Printf("agently serve listening on %s (workspace=%s ui=%s policy=%s)", addr, workspace.Root(), uiBundle.Name, toolPolicy.Mode())
+	listener, serveErr := net.Listen("tcp", addr)
+	if serveErr == nil {
+		unregister := registerServeInstance(options.Name, listener.Addr())
+		defer unregister()
+		serveErr = srv.Serve(listener)
+	}
 	return finalizeServeResult(cancel, &shutdownWG, serveErr, mcpSrv)
 }
 
+// registerUIServices adds the Forge UI internal tool services that need the
+// runtime UI bridge; only the served UI can drive them.
+func registerUIServices(ctx context.Context, rt *executor.Runtime, reportingRuntime *workspaceReportingRuntime) error {
+	uiBridge := rt.UIBridge
+	if uiBridge == nil {
+		return fmt.Errorf("runtime Forge UI bridge is not configured")
+	}
+	forgeWindowRepo := forgewindowrepo.NewWithStore(rt.Store)
+	logLoadedForgeWindows(ctx, forgeWindowRepo)
+	if rt.Registry == nil {
+		return nil
+	}
+	orchestrationEnabled := rt.Defaults != nil && rt.Defaults.Reporting.OrchestrationEnabled()
+	registry := agentlyrt.UnwrapRegistry(rt.Registry)
+	if err := tool.AddInternalService(registry, uiview.New(forgeWindowRepo, uiBridge, uiview.WithListItemEnricher(reportingRuntime.EnrichView))); err != nil {
+		log.Printf("agently-app: failed to register internal UI view service: %v", err)
+	}
+	if err := tool.AddInternalService(registry, uiwindow.New(uiBridge)); err != nil {
+		log.Printf("agently-app: failed to register internal UI window service: %v", err)
+	}
+	if err := tool.AddInternalService(registry, uicontrol.New(uiBridge)); err != nil {
+		log.Printf("agently-app: failed to register internal UI control service: %v", err)
+	}
+	if err := tool.AddInternalService(registry, uidatasource.New(uiBridge)); err != nil {
+		log.Printf("agently-app: failed to register internal UI datasource service: %v", err)
+	}
+	if err := tool.AddInternalService(registry, uicontext.New(uiBridge)); err != nil {
+		log.Printf("agently-app: failed to register internal UI context service: %v", err)
+	}
+	uiEventsService := uievents.New(uiBridge)
+	if rt.Defaults != nil && rt.Defaults.Reporting.BrowserRunPersistenceEnabled() && rt.ReportRuns != nil {
+		uiEventsService = uievents.New(uiBridge, uievents.WithDurableReportRuns(rt.ReportRuns))
+	}
+	if err := tool.AddInternalService(registry, uiEventsService); err != nil {
+		log.Printf("agently-app: failed to register internal UI events service: %v", err)
+	}
+	uiReportService := uireport.New(uiBridge)
+	if orchestrationEnabled {
+		uiReportService = uireport.New(uiBridge, uireport.WithOrchestration(rt.ReportRuns))
+	}
+	if err := tool.AddInternalService(registry, uiReportService); err != nil {
+		if orchestrationEnabled {
+			return fmt.Errorf("register orchestration-enabled UI report service: %w", err)
+		}
+		log.Printf("agently-app: failed to register internal UI report service: %v", err)
+	}
+	return nil
+}
+
+// registerServeInstance records the server in the local instance registry so
+// CLI commands can find it without scanning processes. Registration failures
+// are logged only: the server stays usable through --api.
+func registerServeInstance(name string, addr net.Addr) func() {
+	port := 0
+	if tcpAddr, ok := addr.(*net.TCPAddr); ok {
+		port = tcpAddr.Port
+	}
+	if name = strings.TrimSpace(name); name == "" {
+		name = agentlyrt.InstanceName(workspace.Root(), port)
+	}
+	dir := agentlyrt.InstanceRegistryDir()
+	remove, err := agentlyrt.RegisterInstance(dir, agentlyrt.InstanceEntry{
+		Name:      name,
+		PID:       os.Getpid(),
+		Addr:      addr.String(),
+		BaseURL:   agentlyrt.InstanceBaseURL(addr),
+		Workspace: workspace.Root(),
+		Version:   strings.TrimSpace(Version),
+	})
+	if err != nil {
+		log.Printf("instance registry: %v", err)
+		return func() {}
+	}
+	log.Printf("registered instance %q in %s", name, dir)
+	return func() {
+		if err := remove(); err != nil {
+			log.Printf("instance registry: %v", err)
+		}
+	}
+}
+
 func applyScratchpadRootURI(value string) {
 	if value = strings.TrimSpace(value); value != "" {
 		_ = os.Setenv("AGENTLY_SCRATCHPAD_URI", value)
@@ -412,7 +391,7 @@ func newRouter(api http.Handler, meta http.Handler, speech http.Handler, uiDist
 			speech.ServeHTTP(w, r)
 			return
 		}
-		if path == "/healthz" || path == "/health" || path == "/upload" || (strings.HasPrefix(path, "/v1/") && !strings.HasPrefix(path, "/v1/conversation/")) {
+		if path == "/healthz" || path == "/health" || path == "/upload" || path == server.JWKSPath || (strings.HasPrefix(path, "/v1/") && !strings.HasPrefix(path, "/v1/conversation/")) {
 			api.ServeHTTP(w, r)
 			return
 		}
@@ -106,3 +106,30 @@ func TestNewRouter_ForwardsUploadToAPI(t *testing.T) {
 		t.Fatalf("want 202, got %d", w.Code)
 	}
 }
+
+func TestNewRouter_ForwardsJWKSToAPI(t *testing.T) {
+	apiCalled := false
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiCalled = true
+		w.WriteHeader(http.StatusOK)
+	})
+	meta := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("meta should not handle %s", r.URL.Path)
+	})
+	speech := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("speech should not handle %s", r.URL.Path)
+	})
+	bundle := servedUIBundle{
+		Name:  "test",
+		FS:    fstest.MapFS{"index.html": &fstest.MapFile{Data: []byte("<html></html>")}},
+		Index: []byte("<html></html>"),
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	w := httptest.NewRecorder()
+	newRouter(api, meta, speech, "", bundle).ServeHTTP(w, req)
+
+	if !apiCalled {
+		t.Fatalf("expected /.well-known/jwks.json to reach API handler")
+	}
+}
@@ -0,0 +1,213 @@
+package server
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// JWKSPath publishes the public keys Agently-issued tokens are verified with.
+const JWKSPath = "/.well-known/jwks.json"
+
+// JWK is a public JSON Web Key (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet is a JWKS document.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWKSHandler serves the key set returned by keys. The document is public
+// by design, so unlike the admin endpoints it needs no token.
+func NewJWKSHandler(keys func() (*JWKSet, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		set, err := keys()
+		if err != nil {
+			log.Printf("[jwks] %v", err)
+			writeAdminError(w, http.StatusInternalServerError, "unable to load signing keys")
+			return
+		}
+		if set == nil || set.Keys == nil {
+			set = &JWKSet{Keys: []JWK{}}
+		}
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		writeAdminJSON(w, http.StatusOK, set)
+	}
+}
+
+// LoadWorkspaceJWKS builds the key set from the public keys listed under
+// auth.jwt.rsa in the workspace config.yaml. Keys whose rotation grace period
+// ended before now are left out. Files are read on every call so a rotation
+// is published without a restart.
+func LoadWorkspaceJWKS(workspaceRoot string, now time.Time) (*JWKSet, error) {
+	cfg, err := loadWorkspaceJWTConfig(workspaceRoot)
+	if err != nil {
+		return nil, err
+	}
+	set := &JWKSet{Keys: []JWK{}}
+	keyrings := map[string]*jwtKeyring{}
+	for _, location := range cfg.PublicKeys {
+		path := resolveWorkspacePath(workspaceRoot, location)
+		dir := filepath.Dir(path)
+		ring, ok := keyrings[dir]
+		if !ok {
+			if ring, err = loadJWTKeyring(dir); err != nil {
+				return nil, err
+			}
+			keyrings[dir] = ring
+		}
+		if record := ring.lookup(path); record != nil && record.retired(now) {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read public key %s: %w", location, err)
+		}
+		pub, err := parsePublicKeyPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse public key %s: %w", location, err)
+		}
+		jwk, err := PublicJWK(pub)
+		if err != nil {
+			return nil, fmt.Errorf("public key %s: %w", location, err)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set, nil
+}
+
+// workspaceJWTConfig mirrors the auth.jwt keys of the workspace config.
+type workspaceJWTConfig struct {
+	PublicKeys []string `yaml:"rsa"`
+	PrivateKey string   `yaml:"rsaPrivateKey"`
+}
+
+func loadWorkspaceJWTConfig(workspaceRoot string) (*workspaceJWTConfig, error) {
+	path := filepath.Join(strings.TrimSpace(workspaceRoot), "config.yaml")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &workspaceJWTConfig{}, nil
+		}
+		return nil, err
+	}
+	var root struct {
+		Auth struct {
+			JWT workspaceJWTConfig `yaml:"jwt"`
+		} `yaml:"auth"`
+	}
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &root.Auth.JWT, nil
+}
+
+// resolveWorkspacePath resolves relative key paths against the workspace.
+func resolveWorkspacePath(workspaceRoot, location string) string {
+	location = strings.TrimPrefix(strings.TrimSpace(location), "file://")
+	if location == "" || filepath.IsAbs(location) {
+		return location
+	}
+	return filepath.Join(workspaceRoot, location)
+}
+
+// PublicJWK converts an RSA, EC or Ed25519 public key. The kid is the
+// RFC 7638 SHA-256 thumbprint, so it is stable across restarts and hosts.
+func PublicJWK(pub crypto.PublicKey) (JWK, error) {
+	var jwk JWK
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		jwk = JWK{Kty: "RSA", Alg: "RS256", N: b64(k.N.Bytes()), E: b64(big.NewInt(int64(k.E)).Bytes())}
+	case *ecdsa.PublicKey:
+		params := k.Curve.Params()
+		size := (params.BitSize + 7) / 8
+		jwk = JWK{Kty: "EC", Crv: params.Name, X: b64(k.X.FillBytes(make([]byte, size))), Y: b64(k.Y.FillBytes(make([]byte, size)))}
+		switch params.Name {
+		case "P-256":
+			jwk.Alg = "ES256"
+		case "P-384":
+			jwk.Alg = "ES384"
+		case "P-521":
+			jwk.Alg = "ES512"
+		default:
+			return JWK{}, fmt.Errorf("unsupported ec curve %s", params.Name)
+		}
+	case ed25519.PublicKey:
+		jwk = JWK{Kty: "OKP", Crv: "Ed25519", Alg: "EdDSA", X: b64(k)}
+	default:
+		return JWK{}, fmt.Errorf("unsupported public key type %T", pub)
+	}
+	jwk.Use = "sig"
+	jwk.Kid = jwkThumbprint(jwk)
+	return jwk, nil
+}
+
+// jwkThumbprint hashes the required members in lexicographic order.
+func jwkThumbprint(jwk JWK) string {
+	var members map[string]string
+	switch jwk.Kty {
+	case "RSA":
+		members = map[string]string{"e": jwk.E, "kty": jwk.Kty, "n": jwk.N}
+	case "EC":
+		members = map[string]string{"crv": jwk.Crv, "kty": jwk.Kty, "x": jwk.X, "y": jwk.Y}
+	default:
+		members = map[string]string{"crv": jwk.Crv, "kty": jwk.Kty, "x": jwk.X}
+	}
+	// encoding/json sorts map keys, which is the canonical form.
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return b64(sum[:])
+}
+
+func parsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	default:
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	}
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
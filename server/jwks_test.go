@@ -0,0 +1,381 @@
+package server
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestGenerateJWTKeyPair(t *testing.T) {
+	cases := []struct {
+		algorithm string
+		curve     string
+		pemType   string
+		kty       string
+		alg       string
+	}{
+		{algorithm: JWTKeyRSA, pemType: "RSA PRIVATE KEY", kty: "RSA", alg: "RS256"},
+		{algorithm: JWTKeyEC, pemType: "EC PRIVATE KEY", kty: "EC", alg: "ES256"},
+		{algorithm: JWTKeyEC, curve: "P-384", pemType: "EC PRIVATE KEY", kty: "EC", alg: "ES384"},
+		{algorithm: JWTKeyEd25519, pemType: "PRIVATE KEY", kty: "OKP", alg: "EdDSA"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.algorithm+tc.curve, func(t *testing.T) {
+			dir := t.TempDir()
+			options := &JWTKeygenOptions{
+				PrivatePath: filepath.Join(dir, "keys", "private.pem"),
+				PublicPath:  filepath.Join(dir, "keys", "public.pem"),
+				Algorithm:   tc.algorithm,
+				Curve:       tc.curve,
+			}
+			pair, err := GenerateJWTKeyPair(options)
+			if err != nil {
+				t.Fatalf("GenerateJWTKeyPair() error = %v", err)
+			}
+			info, err := os.Stat(pair.PrivatePath)
+			if err != nil {
+				t.Fatalf("stat private key: %v", err)
+			}
+			if info.Mode().Perm() != 0o600 {
+				t.Fatalf("private key mode = %v, want 0600", info.Mode().Perm())
+			}
+			data, _ := os.ReadFile(pair.PrivatePath)
+			if block, _ := pem.Decode(data); block == nil || block.Type != tc.pemType {
+				t.Fatalf("private key PEM type = %v, want %q", block, tc.pemType)
+			}
+			data, _ = os.ReadFile(pair.PublicPath)
+			pub, err := parsePublicKeyPEM(data)
+			if err != nil {
+				t.Fatalf("parse public key: %v", err)
+			}
+			jwk, err := PublicJWK(pub)
+			if err != nil {
+				t.Fatalf("PublicJWK() error = %v", err)
+			}
+			if jwk.Kty != tc.kty || jwk.Alg != tc.alg || jwk.Kid != pair.KeyID {
+				t.Fatalf("jwk = %+v, want kty %s alg %s kid %s", jwk, tc.kty, tc.alg, pair.KeyID)
+			}
+
+			if _, err := GenerateJWTKeyPair(options); err == nil || !strings.Contains(err.Error(), "already exists") {
+				t.Fatalf("second GenerateJWTKeyPair() error = %v, want already exists", err)
+			}
+			options.Overwrite = true
+			if _, err := GenerateJWTKeyPair(options); err != nil {
+				t.Fatalf("GenerateJWTKeyPair(overwrite) error = %v", err)
+			}
+		})
+	}
+}
+
+func TestGenerateJWTKeyPair_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	for _, options := range []*JWTKeygenOptions{
+		{Algorithm: "dsa"},
+		{Algorithm: JWTKeyEC, Curve: "secp256k1"},
+		{Algorithm: JWTKeyRSA, Bits: 1024},
+	} {
+		options.PrivatePath = filepath.Join(dir, "private.pem")
+		options.PublicPath = filepath.Join(dir, "public.pem")
+		if _, err := GenerateJWTKeyPair(options); err == nil {
+			t.Fatalf("GenerateJWTKeyPair(%+v) expected error", options)
+		}
+	}
+}
+
+// TestPublicJWK_Thumbprint checks the RFC 7638 section 3.1 example.
+func TestPublicJWK_Thumbprint(t *testing.T) {
+	n := "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw"
+	raw, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		t.Fatalf("decode n: %v", err)
+	}
+	jwk, err := PublicJWK(&rsa.PublicKey{N: new(big.Int).SetBytes(raw), E: 65537})
+	if err != nil {
+		t.Fatalf("PublicJWK() error = %v", err)
+	}
+	if jwk.N != n || jwk.E != "AQAB" {
+		t.Fatalf("jwk n/e = %q/%q", jwk.N, jwk.E)
+	}
+	if want := "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"; jwk.Kid != want {
+		t.Fatalf("kid = %q, want %q", jwk.Kid, want)
+	}
+}
+
+func TestPublicJWK_ECPadsCoordinates(t *testing.T) {
+	key, err := generateJWTKey(JWTKeyEC, 0, "P-521")
+	if err != nil {
+		t.Fatalf("generateJWTKey() error = %v", err)
+	}
+	jwk, err := PublicJWK(key.Public())
+	if err != nil {
+		t.Fatalf("PublicJWK() error = %v", err)
+	}
+	x, _ := base64.RawURLEncoding.DecodeString(jwk.X)
+	y, _ := base64.RawURLEncoding.DecodeString(jwk.Y)
+	if len(x) != 66 || len(y) != 66 || jwk.Crv != "P-521" {
+		t.Fatalf("P-521 coordinates = %d/%d bytes, crv %q", len(x), len(y), jwk.Crv)
+	}
+	if _, ok := key.Public().(*ecdsa.PublicKey); !ok {
+		t.Fatalf("unexpected key type %T", key.Public())
+	}
+	edKey, _ := generateJWTKey(JWTKeyEd25519, 0, "")
+	edJWK, _ := PublicJWK(edKey.Public())
+	if x, _ := base64.RawURLEncoding.DecodeString(edJWK.X); len(x) != ed25519.PublicKeySize {
+		t.Fatalf("ed25519 x = %d bytes", len(x))
+	}
+}
+
+func writeJWTWorkspace(t *testing.T, config string) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "config.yaml"), []byte(config), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return root
+}
+
+func TestRotateJWTKey(t *testing.T) {
+	root := writeJWTWorkspace(t, "# workspace\ndefault:\n  agent: chat\nauth:\n  enabled: true\n  jwt:\n    enabled: true\n    rsa:\n      - keys/old.pub.pem\n    rsaPrivateKey: keys/old.pem\n")
+	old, err := GenerateJWTKeyPair(&JWTKeygenOptions{
+		PrivatePath: filepath.Join(root, "keys", "old.pem"),
+		PublicPath:  filepath.Join(root, "keys", "old.pub.pem"),
+	})
+	if err != nil {
+		t.Fatalf("GenerateJWTKeyPair() error = %v", err)
+	}
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	first, err := RotateJWTKey(&JWTRotateOptions{WorkspaceRoot: root, Grace: time.Hour, Now: start})
+	if err != nil {
+		t.Fatalf("RotateJWTKey() error = %v", err)
+	}
+	if len(first.Retiring) != 1 || first.Retiring[0].KeyID != old.KeyID || len(first.Removed) != 0 {
+		t.Fatalf("first rotation retiring=%v removed=%v", first.Retiring, first.Removed)
+	}
+	if filepath.Dir(first.Key.PrivatePath) != filepath.Join(root, "keys") {
+		t.Fatalf("new key written to %s, want the current key directory", first.Key.PrivatePath)
+	}
+	cfg, err := loadWorkspaceJWTConfig(root)
+	if err != nil {
+		t.Fatalf("loadWorkspaceJWTConfig() error = %v", err)
+	}
+	if cfg.PrivateKey != first.Key.PrivatePath || len(cfg.PublicKeys) != 2 || cfg.PublicKeys[0] != first.Key.PublicPath {
+		t.Fatalf("config after first rotation = %+v", cfg)
+	}
+	data, _ := os.ReadFile(filepath.Join(root, "config.yaml"))
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil || raw["default"] == nil || !strings.Contains(string(data), "# workspace") {
+		t.Fatalf("rotation lost unrelated config: %s", data)
+	}
+
+	set, err := LoadWorkspaceJWKS(root, start.Add(30*time.Minute))
+	if err != nil || len(set.Keys) != 2 {
+		t.Fatalf("JWKS during grace = %+v, %v", set, err)
+	}
+	set, err = LoadWorkspaceJWKS(root, start.Add(2*time.Hour))
+	if err != nil || len(set.Keys) != 1 || set.Keys[0].Kid != first.Key.KeyID {
+		t.Fatalf("JWKS after grace = %+v, %v", set, err)
+	}
+
+	second, err := RotateJWTKey(&JWTRotateOptions{WorkspaceRoot: root, Grace: time.Hour, Now: start.Add(2 * time.Hour)})
+	if err != nil {
+		t.Fatalf("second RotateJWTKey() error = %v", err)
+	}
+	if len(second.Removed) != 1 || second.Removed[0].KeyID != old.KeyID {
+		t.Fatalf("second rotation removed = %v, want the original key", second.Removed)
+	}
+	cfg, _ = loadWorkspaceJWTConfig(root)
+	if len(cfg.PublicKeys) != 2 || cfg.PublicKeys[0] != second.Key.PublicPath || cfg.PublicKeys[1] != first.Key.PublicPath {
+		t.Fatalf("config after second rotation = %+v", cfg)
+	}
+}
+
+func TestPruneRetiredJWTKeys(t *testing.T) {
+	root := writeJWTWorkspace(t, "auth:\n  jwt:\n    rsa:\n      - keys/old.pub.pem\n    rsaPrivateKey: keys/old.pem\n")
+	if _, err := GenerateJWTKeyPair(&JWTKeygenOptions{
+		PrivatePath: filepath.Join(root, "keys", "old.pem"),
+		PublicPath:  filepath.Join(root, "keys", "old.pub.pem"),
+	}); err != nil {
+		t.Fatalf("GenerateJWTKeyPair() error = %v", err)
+	}
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	rotated, err := RotateJWTKey(&JWTRotateOptions{WorkspaceRoot: root, Grace: time.Hour, Now: start})
+	if err != nil {
+		t.Fatalf("RotateJWTKey() error = %v", err)
+	}
+
+	removed, err := PruneRetiredJWTKeys(root, start.Add(30*time.Minute))
+	if err != nil || len(removed) != 0 {
+		t.Fatalf("prune during grace = %v, %v", removed, err)
+	}
+	removed, err = PruneRetiredJWTKeys(root, start.Add(2*time.Hour))
+	if err != nil || len(removed) != 1 || removed[0].PublicKey != filepath.Join(root, "keys", "old.pub.pem") {
+		t.Fatalf("prune after grace = %v, %v", removed, err)
+	}
+	cfg, _ := loadWorkspaceJWTConfig(root)
+	if len(cfg.PublicKeys) != 1 || cfg.PublicKeys[0] != rotated.Key.PublicPath || cfg.PrivateKey != rotated.Key.PrivatePath {
+		t.Fatalf("config after prune = %+v", cfg)
+	}
+	ring, err := loadJWTKeyring(filepath.Join(root, "keys"))
+	if err != nil || len(ring.Keys) != 1 || ring.Keys[0].KeyID != rotated.Key.KeyID {
+		t.Fatalf("keyring after prune = %+v, %v", ring, err)
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(root, "*.tmp")); len(leftovers) != 0 {
+		t.Fatalf("temporary files left behind: %v", leftovers)
+	}
+}
+
+func TestRetiredJWTKeyGuard(t *testing.T) {
+	root := writeJWTWorkspace(t, "auth:\n  jwt:\n    rsa:\n      - keys/old.pub.pem\n    rsaPrivateKey: keys/old.pem\n")
+	old, err := GenerateJWTKeyPair(&JWTKeygenOptions{
+		PrivatePath: filepath.Join(root, "keys", "old.pem"),
+		PublicPath:  filepath.Join(root, "keys", "old.pub.pem"),
+	})
+	if err != nil {
+		t.Fatalf("GenerateJWTKeyPair() error = %v", err)
+	}
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	guard, err := newRetiredJWTKeyGuard(root, api, 0)
+	if err != nil {
+		t.Fatalf("newRetiredJWTKeyGuard() error = %v", err)
+	}
+	status := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/v1/api/auth/me", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		guard.ServeHTTP(w, req)
+		return w.Code
+	}
+	oldToken := signTestJWT(t, old.PrivatePath)
+	if got := status(oldToken); got != http.StatusNoContent {
+		t.Fatalf("current key status = %d", got)
+	}
+
+	// The old key's grace period ended an hour ago.
+	rotated, err := RotateJWTKey(&JWTRotateOptions{WorkspaceRoot: root, Grace: time.Hour, Now: time.Now().Add(-2 * time.Hour)})
+	if err != nil {
+		t.Fatalf("RotateJWTKey() error = %v", err)
+	}
+	if got := status(oldToken); got != http.StatusUnauthorized {
+		t.Fatalf("retired key status = %d, want %d", got, http.StatusUnauthorized)
+	}
+	if got := status(signTestJWT(t, rotated.Key.PrivatePath)); got != http.StatusNoContent {
+		t.Fatalf("new key status = %d", got)
+	}
+	if got := status(""); got != http.StatusNoContent {
+		t.Fatalf("anonymous status = %d", got)
+	}
+}
+
+func signTestJWT(t *testing.T, privatePath string) string {
+	t.Helper()
+	data, err := os.ReadFile(privatePath)
+	if err != nil {
+		t.Fatalf("read private key: %v", err)
+	}
+	block, _ := pem.Decode(data)
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		t.Fatalf("parse private key: %v", err)
+	}
+	input := b64([]byte(`{"alg":"RS256","typ":"JWT"}`)) + "." + b64([]byte(`{"sub":"dev"}`))
+	sum := sha256.Sum256([]byte(input))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return input + "." + b64(signature)
+}
+
+func TestRotateJWTKey_CreatesJWTSection(t *testing.T) {
+	root := writeJWTWorkspace(t, "auth:\n  enabled: true\n")
+	result, err := RotateJWTKey(&JWTRotateOptions{WorkspaceRoot: root})
+	if err != nil {
+		t.Fatalf("RotateJWTKey() error = %v", err)
+	}
+	if filepath.Dir(result.Key.PrivatePath) != filepath.Join(root, "keys", "jwt") {
+		t.Fatalf("key dir = %s", filepath.Dir(result.Key.PrivatePath))
+	}
+	cfg, _ := loadWorkspaceJWTConfig(root)
+	if cfg.PrivateKey != result.Key.PrivatePath || len(cfg.PublicKeys) != 1 {
+		t.Fatalf("config = %+v", cfg)
+	}
+	if _, err := RotateJWTKey(&JWTRotateOptions{WorkspaceRoot: root, Grace: -time.Second}); err == nil {
+		t.Fatalf("negative grace expected error")
+	}
+}
+
+func TestJWKSHandler(t *testing.T) {
+	root := writeJWTWorkspace(t, "auth:\n  jwt:\n    rsa:\n      - keys/ec.pub.pem\n")
+	pair, err := GenerateJWTKeyPair(&JWTKeygenOptions{
+		PrivatePath: filepath.Join(root, "keys", "ec.pem"),
+		PublicPath:  filepath.Join(root, "keys", "ec.pub.pem"),
+		Algorithm:   JWTKeyEC,
+	})
+	if err != nil {
+		t.Fatalf("GenerateJWTKeyPair() error = %v", err)
+	}
+	handler := NewJWKSHandler(func() (*JWKSet, error) { return LoadWorkspaceJWKS(root, time.Now()) })
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, JWKSPath, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Cache-Control"); got != "public, max-age=300" {
+		t.Fatalf("Cache-Control = %q", got)
+	}
+	var set JWKSet
+	if err := json.Unmarshal(w.Body.Bytes(), &set); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(set.Keys) != 1 || set.Keys[0].Kid != pair.KeyID || set.Keys[0].Use != "sig" {
+		t.Fatalf("keys = %+v", set.Keys)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, JWKSPath, nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST status = %d", w.Code)
+	}
+
+	empty := NewJWKSHandler(func() (*JWKSet, error) { return LoadWorkspaceJWKS(t.TempDir(), time.Now()) })
+	w = httptest.NewRecorder()
+	empty.ServeHTTP(w, httptest.NewRequest(http.MethodGet, JWKSPath, nil))
+	if strings.TrimSpace(w.Body.String()) != `{"keys":[]}` {
+		t.Fatalf("empty workspace body = %s", w.Body.String())
+	}
+}
+
+func TestParsePublicKeyPEM_PKCS1(t *testing.T) {
+	key, err := generateJWTKey(JWTKeyRSA, 2048, "")
+	if err != nil {
+		t.Fatalf("generateJWTKey() error = %v", err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(key.Public().(*rsa.PublicKey))})
+	if _, err := parsePublicKeyPEM(data); err != nil {
+		t.Fatalf("parsePublicKeyPEM() error = %v", err)
+	}
+	if _, err := parsePublicKeyPEM([]byte("not pem")); err == nil {
+		t.Fatalf("expected error for non-PEM input")
+	}
+}
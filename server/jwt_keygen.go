@@ -1,65 +1,157 @@
 package server
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
-	"flag"
-	"log"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
-func jwtKeygen(args []string) {
-	fs := flag.NewFlagSet("jwt-keygen", flag.ExitOnError)
-	privatePath := fs.String("private", "", "private key output path (required)")
-	publicPath := fs.String("public", "", "public key output path (required)")
-	bits := fs.Int("bits", 2048, "rsa key size")
-	overwrite := fs.Bool("overwrite", false, "overwrite existing files")
-	_ = fs.Parse(args)
+// JWT signing key algorithms accepted by GenerateJWTKeyPair.
+const (
+	JWTKeyRSA     = "rsa"
+	JWTKeyEC      = "ec"
+	JWTKeyEd25519 = "ed25519"
+)
+
+// JWTKeygenOptions describes a signing key pair to generate.
+type JWTKeygenOptions struct {
+	PrivatePath string
+	PublicPath  string
+	// Algorithm is rsa (default), ec or ed25519.
+	Algorithm string
+	// Bits is the RSA key size; 2048 when zero.
+	Bits int
+	// Curve is the EC curve: P-256 (default), P-384 or P-521.
+	Curve     string
+	Overwrite bool
+}
 
-	priv := strings.TrimSpace(*privatePath)
-	pub := strings.TrimSpace(*publicPath)
+// JWTKeyPair describes generated key files. KeyID is the RFC 7638 thumbprint
+// published as "kid" in the JWKS document.
+type JWTKeyPair struct {
+	PrivatePath string
+	PublicPath  string
+	Algorithm   string
+	KeyID       string
+}
+
+// GenerateJWTKeyPair writes a PEM private key (mode 0600) and a PKIX public
+// key. RSA private keys stay PKCS#1 for compatibility with existing signers;
+// EC keys use SEC 1 and Ed25519 keys PKCS#8.
+func GenerateJWTKeyPair(options *JWTKeygenOptions) (*JWTKeyPair, error) {
+	priv := strings.TrimSpace(options.PrivatePath)
+	pub := strings.TrimSpace(options.PublicPath)
 	if priv == "" || pub == "" {
-		log.Fatal("both --private and --public are required")
+		return nil, fmt.Errorf("both private and public key paths are required")
 	}
-	if !*overwrite {
+	if !options.Overwrite {
 		if _, err := os.Stat(priv); err == nil {
-			log.Fatalf("private key file already exists: %s", priv)
+			return nil, fmt.Errorf("private key file already exists: %s", priv)
 		}
 		if _, err := os.Stat(pub); err == nil {
-			log.Fatalf("public key file already exists: %s", pub)
+			return nil, fmt.Errorf("public key file already exists: %s", pub)
 		}
 	}
-	key, err := rsa.GenerateKey(rand.Reader, *bits)
+	algorithm := strings.ToLower(strings.TrimSpace(options.Algorithm))
+	if algorithm == "" {
+		algorithm = JWTKeyRSA
+	}
+	key, err := generateJWTKey(algorithm, options.Bits, options.Curve)
 	if err != nil {
-		log.Fatalf("unable to generate rsa key: %v", err)
+		return nil, err
 	}
-	privPEM := pem.EncodeToMemory(&pem.Block{
-		Type:  "RSA PRIVATE KEY",
-		Bytes: x509.MarshalPKCS1PrivateKey(key),
-	})
-	pubDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	privBlock, err := privateKeyPEMBlock(key)
 	if err != nil {
-		log.Fatalf("unable to encode public key: %v", err)
+		return nil, err
 	}
-	pubPEM := pem.EncodeToMemory(&pem.Block{
-		Type:  "PUBLIC KEY",
-		Bytes: pubDER,
-	})
-	if err := os.MkdirAll(filepath.Dir(priv), 0o755); err != nil {
-		log.Fatalf("unable to create private key directory: %v", err)
+	pubDER, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode public key: %w", err)
+	}
+	jwk, err := PublicJWK(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(priv), 0o700); err != nil {
+		return nil, fmt.Errorf("unable to create private key directory: %w", err)
 	}
 	if err := os.MkdirAll(filepath.Dir(pub), 0o755); err != nil {
-		log.Fatalf("unable to create public key directory: %v", err)
+		return nil, fmt.Errorf("unable to create public key directory: %w", err)
+	}
+	if err := os.WriteFile(priv, pem.EncodeToMemory(privBlock), 0o600); err != nil {
+		return nil, fmt.Errorf("unable to write private key: %w", err)
+	}
+	if err := os.WriteFile(pub, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0o644); err != nil {
+		return nil, fmt.Errorf("unable to write public key: %w", err)
 	}
-	if err := os.WriteFile(priv, privPEM, 0o600); err != nil {
-		log.Fatalf("unable to write private key: %v", err)
+	return &JWTKeyPair{PrivatePath: priv, PublicPath: pub, Algorithm: algorithm, KeyID: jwk.Kid}, nil
+}
+
+func generateJWTKey(algorithm string, bits int, curve string) (crypto.Signer, error) {
+	switch algorithm {
+	case JWTKeyRSA:
+		if bits == 0 {
+			bits = 2048
+		}
+		if bits < 2048 {
+			return nil, fmt.Errorf("rsa key size %d is too small (minimum 2048)", bits)
+		}
+		key, err := rsa.GenerateKey(rand.Reader, bits)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate rsa key: %w", err)
+		}
+		return key, nil
+	case JWTKeyEC:
+		var c elliptic.Curve
+		switch strings.ToUpper(strings.TrimSpace(curve)) {
+		case "", "P-256", "P256":
+			c = elliptic.P256()
+		case "P-384", "P384":
+			c = elliptic.P384()
+		case "P-521", "P521":
+			c = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported ec curve %q (expected P-256, P-384 or P-521)", curve)
+		}
+		key, err := ecdsa.GenerateKey(c, rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate ec key: %w", err)
+		}
+		return key, nil
+	case JWTKeyEd25519:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate ed25519 key: %w", err)
+		}
+		return key, nil
 	}
-	if err := os.WriteFile(pub, pubPEM, 0o644); err != nil {
-		log.Fatalf("unable to write public key: %v", err)
+	return nil, fmt.Errorf("unsupported key algorithm %q (expected rsa, ec or ed25519)", algorithm)
+}
+
+func privateKeyPEMBlock(key crypto.Signer) (*pem.Block, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(k)}, nil
+	case *ecdsa.PrivateKey:
+		der, err := x509.MarshalECPrivateKey(k)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode ec private key: %w", err)
+		}
+		return &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}, nil
+	default:
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode private key: %w", err)
+		}
+		return &pem.Block{Type: "PRIVATE KEY", Bytes: der}, nil
 	}
-	log.Printf("generated jwt keypair (private=%s public=%s bits=%d)", priv, pub, *bits)
 }
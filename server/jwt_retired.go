@@ -0,0 +1,166 @@
+package server
+
+import (
+	"crypto"
+	"crypto/rsa"
+	_ "crypto/sha512" // RS384 and RS512
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// retiredKeyRefresh bounds how long the guard trusts its view of which keys
+// are still published.
+const retiredKeyRefresh = time.Minute
+
+// retiredJWTKeyGuard rejects bearer tokens signed by a key whose rotation
+// grace period ended while the server was running. The API verifies tokens
+// with the auth.jwt.rsa keys it loaded at startup and accepts them until
+// restart; the guard compares those keys with the ones LoadWorkspaceJWKS
+// still publishes.
+type retiredJWTKeyGuard struct {
+	next          http.Handler
+	workspaceRoot string
+	loaded        map[string]*rsa.PublicKey // by kid
+	refresh       time.Duration
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	retired   map[string]*rsa.PublicKey
+}
+
+// NewRetiredJWTKeyGuard wraps next, the API handler built from the current
+// workspace config, so that tokens signed by keys retired since then get 401.
+func NewRetiredJWTKeyGuard(workspaceRoot string, next http.Handler) (http.Handler, error) {
+	return newRetiredJWTKeyGuard(workspaceRoot, next, retiredKeyRefresh)
+}
+
+func newRetiredJWTKeyGuard(workspaceRoot string, next http.Handler, refresh time.Duration) (http.Handler, error) {
+	cfg, err := loadWorkspaceJWTConfig(workspaceRoot)
+	if err != nil {
+		return nil, err
+	}
+	loaded := map[string]*rsa.PublicKey{}
+	for _, location := range cfg.PublicKeys {
+		data, err := os.ReadFile(resolveWorkspacePath(workspaceRoot, location))
+		if err != nil {
+			return nil, fmt.Errorf("read public key %s: %w", location, err)
+		}
+		pub, err := parsePublicKeyPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse public key %s: %w", location, err)
+		}
+		key, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			continue
+		}
+		jwk, err := PublicJWK(key)
+		if err != nil {
+			return nil, fmt.Errorf("public key %s: %w", location, err)
+		}
+		loaded[jwk.Kid] = key
+	}
+	if len(loaded) == 0 {
+		return next, nil
+	}
+	return &retiredJWTKeyGuard{next: next, workspaceRoot: workspaceRoot, loaded: loaded, refresh: refresh}, nil
+}
+
+func (g *retiredJWTKeyGuard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if token := bearerToken(r); token != "" {
+		if retired := g.retiredKeys(time.Now()); len(retired) > 0 && signedByAny(token, retired) {
+			writeAdminError(w, http.StatusUnauthorized, "token signed by a retired key")
+			return
+		}
+	}
+	g.next.ServeHTTP(w, r)
+}
+
+// retiredKeys returns the loaded keys LoadWorkspaceJWKS no longer publishes.
+// When the workspace cannot be read the previous answer stands.
+func (g *retiredJWTKeyGuard) retiredKeys(now time.Time) map[string]*rsa.PublicKey {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.checkedAt.IsZero() && now.Sub(g.checkedAt) < g.refresh {
+		return g.retired
+	}
+	g.checkedAt = now
+	set, err := LoadWorkspaceJWKS(g.workspaceRoot, now)
+	if err != nil {
+		log.Printf("[jwt] check retired keys: %v", err)
+		return g.retired
+	}
+	published := map[string]bool{}
+	for _, key := range set.Keys {
+		published[key.Kid] = true
+	}
+	retired := map[string]*rsa.PublicKey{}
+	for kid, key := range g.loaded {
+		if !published[kid] {
+			retired[kid] = key
+		}
+	}
+	g.retired = retired
+	return retired
+}
+
+func bearerToken(r *http.Request) string {
+	header := strings.TrimSpace(r.Header.Get("Authorization"))
+	if len(header) < 7 || !strings.EqualFold(header[:7], "bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(header[7:])
+}
+
+// signedByAny reports whether token is an RS256/384/512 JWT whose signature
+// verifies with one of keys. The kid header, when present, narrows the check.
+func signedByAny(token string, keys map[string]*rsa.PublicKey) bool {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return false
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return false
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if json.Unmarshal(data, &header) != nil {
+		return false
+	}
+	var hash crypto.Hash
+	switch header.Alg {
+	case "RS256":
+		hash = crypto.SHA256
+	case "RS384":
+		hash = crypto.SHA384
+	case "RS512":
+		hash = crypto.SHA512
+	default:
+		return false
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	digest := hash.New()
+	digest.Write([]byte(parts[0] + "." + parts[1]))
+	sum := digest.Sum(nil)
+	if key, ok := keys[header.Kid]; ok {
+		return rsa.VerifyPKCS1v15(key, hash, sum, signature) == nil
+	}
+	for _, key := range keys {
+		if rsa.VerifyPKCS1v15(key, hash, sum, signature) == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,360 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// jwtKeyringFile records the keys `agently jwt rotate` manages, next to the
+// key files.
+const jwtKeyringFile = "keyring.json"
+
+// JWTRotateOptions configures a key rotation.
+type JWTRotateOptions struct {
+	WorkspaceRoot string
+	// Dir holds the key files; defaults to the directory of the current
+	// private key, else <workspace>/keys/jwt.
+	Dir string
+	// Bits is the RSA key size; 2048 when zero.
+	Bits int
+	// Grace keeps the previous keys valid for verification this long.
+	Grace time.Duration
+	// Now is the rotation time; time.Now when zero.
+	Now time.Time
+}
+
+// JWTKeyRecord is one managed key. RetiresAt is set once a newer key took
+// over signing; the key stays published and accepted until then.
+type JWTKeyRecord struct {
+	KeyID      string     `json:"kid,omitempty"`
+	PrivateKey string     `json:"privateKey,omitempty"`
+	PublicKey  string     `json:"publicKey"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	RetiresAt  *time.Time `json:"retiresAt,omitempty"`
+}
+
+func (r *JWTKeyRecord) retired(now time.Time) bool {
+	return r.RetiresAt != nil && !r.RetiresAt.After(now)
+}
+
+// JWTRotateResult reports what a rotation changed.
+type JWTRotateResult struct {
+	Key         *JWTKeyPair
+	Retiring    []*JWTKeyRecord
+	Removed     []*JWTKeyRecord
+	ConfigPath  string
+	KeyringPath string
+}
+
+type jwtKeyring struct {
+	Keys []*JWTKeyRecord `json:"keys"`
+}
+
+func loadJWTKeyring(dir string) (*jwtKeyring, error) {
+	ring := &jwtKeyring{}
+	data, err := os.ReadFile(filepath.Join(dir, jwtKeyringFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ring, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, ring); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", filepath.Join(dir, jwtKeyringFile), err)
+	}
+	return ring, nil
+}
+
+func (k *jwtKeyring) lookup(publicPath string) *JWTKeyRecord {
+	for _, record := range k.Keys {
+		if filepath.Clean(record.PublicKey) == filepath.Clean(publicPath) {
+			return record
+		}
+	}
+	return nil
+}
+
+// RotateJWTKey generates a new RSA signing key and makes it current in the
+// workspace config.yaml. Previous keys stay listed under auth.jwt.rsa, and
+// so remain valid for verification, until their grace period ends; the
+// rotation or PruneRetiredJWTKeys that finds them expired drops them from the
+// config. RSA is used because auth.jwt takes RSA key paths.
+func RotateJWTKey(options *JWTRotateOptions) (*JWTRotateResult, error) {
+	if options.Grace < 0 {
+		return nil, fmt.Errorf("grace period must not be negative")
+	}
+	now := options.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	now = now.UTC()
+	root := strings.TrimSpace(options.WorkspaceRoot)
+	configPath := filepath.Join(root, "config.yaml")
+	document, err := readWorkspaceConfigNode(configPath)
+	if err != nil {
+		return nil, err
+	}
+	jwtNode := mappingChild(mappingChild(document.Content[0], "auth"), "jwt")
+	cfg, err := loadWorkspaceJWTConfig(root)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := strings.TrimSpace(options.Dir)
+	if dir == "" {
+		if cfg.PrivateKey != "" {
+			dir = filepath.Dir(resolveWorkspacePath(root, cfg.PrivateKey))
+		} else {
+			dir = filepath.Join(root, "keys", "jwt")
+		}
+	}
+	if dir, err = filepath.Abs(dir); err != nil {
+		return nil, err
+	}
+	ring, err := loadJWTKeyring(dir)
+	if err != nil {
+		return nil, err
+	}
+	// Keys configured by hand are adopted so they retire like managed ones.
+	for _, location := range cfg.PublicKeys {
+		path := resolveWorkspacePath(root, location)
+		if ring.lookup(path) != nil {
+			continue
+		}
+		record := &JWTKeyRecord{PublicKey: path, CreatedAt: now}
+		if data, err := os.ReadFile(path); err == nil {
+			if pub, err := parsePublicKeyPEM(data); err == nil {
+				if jwk, err := PublicJWK(pub); err == nil {
+					record.KeyID = jwk.Kid
+				}
+			}
+		}
+		ring.Keys = append(ring.Keys, record)
+	}
+
+	result := &JWTRotateResult{ConfigPath: configPath, KeyringPath: filepath.Join(dir, jwtKeyringFile)}
+	retiresAt := now.Add(options.Grace)
+	for _, record := range ring.Keys {
+		if record.RetiresAt == nil {
+			record.RetiresAt = &retiresAt
+			result.Retiring = append(result.Retiring, record)
+		}
+	}
+
+	stamp := now.Format("20060102T150405Z")
+	name := "jwt-" + stamp
+	for n := 2; fileExists(filepath.Join(dir, name+".pem")); n++ {
+		name = fmt.Sprintf("jwt-%s-%d", stamp, n)
+	}
+	key, err := GenerateJWTKeyPair(&JWTKeygenOptions{
+		PrivatePath: filepath.Join(dir, name+".pem"),
+		PublicPath:  filepath.Join(dir, name+".pub.pem"),
+		Algorithm:   JWTKeyRSA,
+		Bits:        options.Bits,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Key = key
+
+	kept := []*JWTKeyRecord{{KeyID: key.KeyID, PrivateKey: key.PrivatePath, PublicKey: key.PublicPath, CreatedAt: now}}
+	for _, record := range ring.Keys {
+		if record.retired(now) {
+			result.Removed = append(result.Removed, record)
+			continue
+		}
+		kept = append(kept, record)
+	}
+	ring.Keys = kept
+
+	publicKeys := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, record := range ring.Keys {
+		publicKeys.Content = append(publicKeys.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: record.PublicKey})
+	}
+	setMappingChild(jwtNode, "rsa", publicKeys)
+	setMappingChild(jwtNode, "rsaPrivateKey", &yaml.Node{Kind: yaml.ScalarNode, Value: key.PrivatePath})
+
+	if err := ring.save(dir); err != nil {
+		return nil, err
+	}
+	if err := writeWorkspaceConfigNode(configPath, document); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PruneRetiredJWTKeys drops keys whose grace period ended before now from
+// auth.jwt.rsa in the workspace config.yaml and from their keyrings, so a
+// server started afterwards no longer accepts tokens they signed. Keys not
+// managed by `agently jwt rotate` are left alone.
+func PruneRetiredJWTKeys(workspaceRoot string, now time.Time) ([]*JWTKeyRecord, error) {
+	root := strings.TrimSpace(workspaceRoot)
+	cfg, err := loadWorkspaceJWTConfig(root)
+	if err != nil || len(cfg.PublicKeys) == 0 {
+		return nil, err
+	}
+	var removed []*JWTKeyRecord
+	keyrings := map[string]*jwtKeyring{}
+	retiredPaths := map[string]bool{}
+	for _, location := range cfg.PublicKeys {
+		path := resolveWorkspacePath(root, location)
+		dir := filepath.Dir(path)
+		ring, ok := keyrings[dir]
+		if !ok {
+			if ring, err = loadJWTKeyring(dir); err != nil {
+				return nil, err
+			}
+			keyrings[dir] = ring
+		}
+		if record := ring.lookup(path); record != nil && record.retired(now) {
+			removed = append(removed, record)
+			retiredPaths[filepath.Clean(path)] = true
+		}
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	for dir, ring := range keyrings {
+		kept := ring.Keys[:0]
+		for _, record := range ring.Keys {
+			if !retiredPaths[filepath.Clean(record.PublicKey)] {
+				kept = append(kept, record)
+			}
+		}
+		if len(kept) == len(ring.Keys) {
+			continue
+		}
+		ring.Keys = kept
+		if err := ring.save(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	configPath := filepath.Join(root, "config.yaml")
+	document, err := readWorkspaceConfigNode(configPath)
+	if err != nil {
+		return nil, err
+	}
+	jwtNode := mappingChild(mappingChild(document.Content[0], "auth"), "jwt")
+	publicKeys := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, location := range cfg.PublicKeys {
+		if !retiredPaths[filepath.Clean(resolveWorkspacePath(root, location))] {
+			publicKeys.Content = append(publicKeys.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: location})
+		}
+	}
+	setMappingChild(jwtNode, "rsa", publicKeys)
+	if err := writeWorkspaceConfigNode(configPath, document); err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+func (k *jwtKeyring) save(dir string) error {
+	data, err := json.MarshalIndent(k, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(dir, jwtKeyringFile), data, 0o600); err != nil {
+		return fmt.Errorf("write keyring: %w", err)
+	}
+	return nil
+}
+
+// readWorkspaceConfigNode parses config.yaml keeping comments and key order;
+// an empty file yields an empty mapping.
+func readWorkspaceConfigNode(configPath string) (*yaml.Node, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("read workspace config: %w", err)
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", configPath, err)
+	}
+	if len(document.Content) == 0 {
+		document = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	return &document, nil
+}
+
+// writeWorkspaceConfigNode replaces config.yaml atomically, keeping its mode,
+// so a server starting concurrently never reads a partial config.
+func writeWorkspaceConfigNode(configPath string, document *yaml.Node) error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+	_ = encoder.Close()
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(configPath); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := writeFileAtomic(configPath, buf.Bytes(), mode); err != nil {
+		return fmt.Errorf("write workspace config: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place.
+func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(mode)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// mappingChild returns the mapping stored under key, creating it when absent.
+func mappingChild(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			child := node.Content[i+1]
+			if child.Kind != yaml.MappingNode {
+				child.Kind, child.Tag, child.Value, child.Content = yaml.MappingNode, "", "", nil
+			}
+			return child
+		}
+	}
+	child := &yaml.Node{Kind: yaml.MappingNode}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, child)
+	return child
+}
+
+func setMappingChild(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/viant/agently-core/sdk"
+	agentlyrt "github.com/viant/agently/runtime"
+)
+
+// ToolOpenAPIPath serves the live tool catalog as an OpenAPI document.
+const ToolOpenAPIPath = "/v1/api/tools/openapi.json"
+
+// NewToolOpenAPIHandler renders the tool catalog of api as an OpenAPI 3.1
+// document on GET. The catalog is read in process through the API's own tool
+// definitions route with the caller's credentials, so the endpoint is guarded
+// by the regular API auth and lists the same tools as `mcp list`.
+func NewToolOpenAPIHandler(api http.Handler, version string) http.HandlerFunc {
+	return newToolOpenAPIHandler(func(r *http.Request) ([]sdk.ToolDefinitionInfo, int, error) {
+		return apiToolDefinitions(api, r)
+	}, version)
+}
+
+func newToolOpenAPIHandler(definitions func(r *http.Request) ([]sdk.ToolDefinitionInfo, int, error), version string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		defs, status, err := definitions(r)
+		if err != nil {
+			writeAdminError(w, status, err.Error())
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, agentlyrt.ToolOpenAPIDocument(defs, agentlyrt.ToolOpenAPIInfo{
+			Title:     "Agently tools",
+			Version:   version,
+			ServerURL: requestBaseURL(r),
+		}))
+	}
+}
+
+// apiToolDefinitions lists tools through api as the SDK does over HTTP. When
+// the API rejects the caller, its status is returned with the error.
+func apiToolDefinitions(api http.Handler, r *http.Request) ([]sdk.ToolDefinitionInfo, int, error) {
+	transport := &inProcessTransport{handler: api, source: r}
+	client, err := sdk.NewHTTP("http://"+r.Host, sdk.WithHTTPClient(&http.Client{Transport: transport}))
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	defs, err := client.ListToolDefinitions(r.Context())
+	if err != nil {
+		if transport.status == http.StatusUnauthorized || transport.status == http.StatusForbidden {
+			return nil, transport.status, errors.New(http.StatusText(transport.status))
+		}
+		return nil, http.StatusInternalServerError, fmt.Errorf("list tools: %w", err)
+	}
+	return defs, http.StatusOK, nil
+}
+
+// inProcessTransport serves SDK requests with handler, carrying over the
+// credentials of the source request. status is the last response status.
+type inProcessTransport struct {
+	handler http.Handler
+	source  *http.Request
+	status  int
+}
+
+func (t *inProcessTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for _, name := range []string{"Authorization", "Cookie"} {
+		if value := t.source.Header.Get(name); value != "" && req.Header.Get(name) == "" {
+			req.Header.Set(name, value)
+		}
+	}
+	req.RemoteAddr = t.source.RemoteAddr
+	recorder := &bufferedResponse{header: http.Header{}, status: http.StatusOK}
+	t.handler.ServeHTTP(recorder, req)
+	t.status = recorder.status
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorder.status, http.StatusText(recorder.status)),
+		StatusCode:    recorder.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorder.header,
+		Body:          io.NopCloser(bytes.NewReader(recorder.body.Bytes())),
+		ContentLength: int64(recorder.body.Len()),
+		Request:       req,
+	}, nil
+}
+
+// bufferedResponse collects a handler response in memory.
+type bufferedResponse struct {
+	header      http.Header
+	body        bytes.Buffer
+	status      int
+	wroteHeader bool
+}
+
+func (b *bufferedResponse) Header() http.Header { return b.header }
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.wroteHeader {
+		return
+	}
+	b.status, b.wroteHeader = status, true
+}
+
+func (b *bufferedResponse) Write(data []byte) (int, error) {
+	b.wroteHeader = true
+	return b.body.Write(data)
+}
+
+// requestBaseURL reconstructs the externally visible base URL, honoring the
+// usual reverse proxy headers.
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if forwarded := strings.TrimSpace(strings.Split(r.Header.Get("X-Forwarded-Proto"), ",")[0]); forwarded != "" {
+		scheme = forwarded
+	}
+	host := r.Host
+	if forwarded := strings.TrimSpace(strings.Split(r.Header.Get("X-Forwarded-Host"), ",")[0]); forwarded != "" {
+		host = forwarded
+	}
+	if host == "" {
+		return ""
+	}
+	return scheme + "://" + host
+}
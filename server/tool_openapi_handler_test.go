@@ -0,0 +1,84 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/viant/agently-core/sdk"
+)
+
+func TestToolOpenAPIHandler(t *testing.T) {
+	handler := newToolOpenAPIHandler(func(*http.Request) ([]sdk.ToolDefinitionInfo, int, error) {
+		return []sdk.ToolDefinitionInfo{{Name: "system/os:getEnv", Description: "Read environment variables."}}, http.StatusOK, nil
+	}, "test")
+
+	t.Run("serves document", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, ToolOpenAPIPath, nil)
+		req.Host = "internal:8080"
+		req.Header.Set("X-Forwarded-Proto", "https")
+		req.Header.Set("X-Forwarded-Host", "agently.example.com")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+		}
+		var doc struct {
+			Info struct {
+				Version string `json:"version"`
+			} `json:"info"`
+			Servers []struct {
+				URL string `json:"url"`
+			} `json:"servers"`
+			Paths map[string]interface{} `json:"paths"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		if doc.Info.Version != "test" {
+			t.Fatalf("version = %q, want test", doc.Info.Version)
+		}
+		if len(doc.Servers) != 1 || doc.Servers[0].URL != "https://agently.example.com" {
+			t.Fatalf("servers = %#v", doc.Servers)
+		}
+		if _, ok := doc.Paths["/v1/tools/system%2Fos:getEnv/execute"]; !ok {
+			t.Fatalf("paths = %v", doc.Paths)
+		}
+	})
+
+	t.Run("rejects writes", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, ToolOpenAPIPath, nil))
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func TestToolOpenAPIHandlerUsesAPIAuth(t *testing.T) {
+	var seen http.Header
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r.Header.Clone()
+		if r.Header.Get("Authorization") != "Bearer user-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	handler := NewToolOpenAPIHandler(api, "test")
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, ToolOpenAPIPath, nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, ToolOpenAPIPath, nil)
+	req.Header.Set("Authorization", "Bearer user-token")
+	req.Header.Set("Cookie", "agently_session=abc")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if seen.Get("Authorization") != "Bearer user-token" || seen.Get("Cookie") != "agently_session=abc" {
+		t.Fatalf("credentials not forwarded to the API: %v", seen)
+	}
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	agentlyrt "github.com/viant/agently/runtime"
+)
+
+// ToolPolicyPath is the admin endpoint used to inspect and switch the live
+// coarse tool policy.
+const ToolPolicyPath = "/v1/api/admin/tool-policy"
+
+const maxToolPolicyBodyBytes = 4 << 10
+
+type toolPolicyRequest struct {
+	Mode string `json:"mode"`
+}
+
+// NewToolPolicyHandler exposes the live tool policy. GET returns the current
+// state; PUT or POST with {"mode":"auto|ask|deny"} switches the global mode.
+// Requests must carry `Authorization: Bearer $AGENTLY_ADMIN_TOKEN`; when the
+// variable is unset the endpoint is disabled.
+func NewToolPolicyHandler(policy *agentlyrt.ToolPolicy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !authorizeAdmin(w, r) {
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeAdminJSON(w, http.StatusOK, policy.Snapshot())
+		case http.MethodPut, http.MethodPost:
+			var request toolPolicyRequest
+			r.Body = http.MaxBytesReader(w, r.Body, maxToolPolicyBodyBytes)
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				writeAdminError(w, http.StatusBadRequest, "invalid JSON payload")
+				return
+			}
+			previous := policy.Mode()
+			if err := policy.SetMode(request.Mode); err != nil {
+				writeAdminError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			log.Printf("[tool-policy] global mode switched %q -> %q by admin API (remote=%s)", previous, policy.Mode(), r.RemoteAddr)
+			writeAdminJSON(w, http.StatusOK, policy.Snapshot())
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+func adminToken() string {
+	return strings.TrimSpace(os.Getenv("AGENTLY_ADMIN_TOKEN"))
+}
+
+func authorizeAdmin(w http.ResponseWriter, r *http.Request) bool {
+	expected := adminToken()
+	if expected == "" {
+		writeAdminError(w, http.StatusForbidden, "admin API is disabled (set AGENTLY_ADMIN_TOKEN)")
+		return false
+	}
+	header := strings.TrimSpace(r.Header.Get("Authorization"))
+	if len(header) < len("Bearer ") || !strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+		writeAdminError(w, http.StatusUnauthorized, "missing bearer token")
+		return false
+	}
+	provided := strings.TrimSpace(header[len("Bearer "):])
+	if subtle.ConstantTimeCompare([]byte(provided), []byte(expected)) != 1 {
+		writeAdminError(w, http.StatusUnauthorized, "invalid admin token")
+		return false
+	}
+	return true
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Printf("[admin] failed to encode response: %v", err)
+	}
+}
+
+func writeAdminError(w http.ResponseWriter, status int, message string) {
+	writeAdminJSON(w, status, map[string]string{"error": message})
+}
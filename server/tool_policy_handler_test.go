@@ -0,0 +1,66 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	agentlyrt "github.com/viant/agently/runtime"
+)
+
+func TestToolPolicyHandler(t *testing.T) {
+	policy, err := agentlyrt.NewToolPolicy("auto", nil)
+	if err != nil {
+		t.Fatalf("NewToolPolicy() error = %v", err)
+	}
+	handler := NewToolPolicyHandler(policy)
+
+	t.Run("disabled without admin token", func(t *testing.T) {
+		t.Setenv("AGENTLY_ADMIN_TOKEN", "")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, ToolPolicyPath, nil))
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusForbidden)
+		}
+	})
+
+	t.Run("rejects wrong token", func(t *testing.T) {
+		t.Setenv("AGENTLY_ADMIN_TOKEN", "secret")
+		req := httptest.NewRequest(http.MethodPut, ToolPolicyPath, strings.NewReader(`{"mode":"deny"}`))
+		req.Header.Set("Authorization", "Bearer nope")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+		}
+		if policy.Mode() != agentlyrt.ToolPolicyAuto {
+			t.Fatalf("mode changed without authorization: %q", policy.Mode())
+		}
+	})
+
+	t.Run("switches mode", func(t *testing.T) {
+		t.Setenv("AGENTLY_ADMIN_TOKEN", "secret")
+		req := httptest.NewRequest(http.MethodPut, ToolPolicyPath, strings.NewReader(`{"mode":"deny"}`))
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+		}
+		if policy.Mode() != agentlyrt.ToolPolicyDeny {
+			t.Fatalf("mode = %q, want %q", policy.Mode(), agentlyrt.ToolPolicyDeny)
+		}
+	})
+
+	t.Run("rejects invalid mode", func(t *testing.T) {
+		t.Setenv("AGENTLY_ADMIN_TOKEN", "secret")
+		req := httptest.NewRequest(http.MethodPost, ToolPolicyPath, strings.NewReader(`{"mode":"maybe"}`))
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusBadRequest)
+		}
+	})
+}
@@ -0,0 +1,178 @@
+package agently
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/viant/afs"
+	_ "github.com/viant/afs/file"
+	"github.com/viant/agently-core/app/executor"
+	execconfig "github.com/viant/agently-core/app/executor/config"
+	appserver "github.com/viant/agently-core/app/server"
+	svcauthctx "github.com/viant/agently-core/service/auth"
+	svcscheduler "github.com/viant/agently-core/service/scheduler"
+	"github.com/viant/agently-core/workspace"
+	wscfg "github.com/viant/agently-core/workspace/config"
+	"github.com/viant/agently/bootstrap"
+	agentlyrt "github.com/viant/agently/runtime"
+	"github.com/viant/agently/server"
+)
+
+// workspaceRuntimeOptions configures buildWorkspaceRuntime.
+type workspaceRuntimeOptions struct {
+	Policy            string // tool policy: auto|ask|deny
+	SchedulerHeadless bool
+	// Configure runs once the runtime and scheduler exist, before the API
+	// handler is created, so callers can register extra internal services.
+	Configure func(ctx context.Context, rt *executor.Runtime) error
+}
+
+// workspaceRuntime is the runtime and API surface shared by `agently serve`
+// and the in-process server behind `query --local`.
+type workspaceRuntime struct {
+	Runtime     *executor.Runtime
+	Defaults    *execconfig.Defaults
+	ToolPolicy  *agentlyrt.ToolPolicy
+	AuthRuntime *svcauthctx.Runtime
+	// Handler serves the API together with the tool policy, tool OpenAPI and
+	// JWKS routes. The tool OpenAPI route reads the catalog through the API.
+	// Bearer tokens signed by JWT keys retired since startup are rejected.
+	Handler http.Handler
+}
+
+// loadWorkspace points the process at workspacePath, seeds missing defaults
+// and loads the workspace config. Callers clear the bootstrap hook.
+func loadWorkspace(workspacePath string) (*wscfg.Root, error) {
+	workspace.SetRoot(workspacePath)
+	bootstrap.SetBootstrapHook()
+	workspace.EnsureDefault(afs.New())
+	// Keys past their rotation grace period must not reach the verifier.
+	retired, err := server.PruneRetiredJWTKeys(workspace.Root(), time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to prune retired jwt keys: %w", err)
+	}
+	for _, record := range retired {
+		log.Printf("jwt: removed retired key %s from auth.jwt.rsa", record.PublicKey)
+	}
+	wsConfig, err := wscfg.Load(workspace.Root())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load workspace config: %w", err)
+	}
+	return wsConfig, nil
+}
+
+// buildWorkspaceRuntime resolves defaults and tool policy for the loaded
+// workspace, builds the executor runtime with its auth and scheduler services,
+// and assembles the API handler.
+func buildWorkspaceRuntime(ctx context.Context, wsConfig *wscfg.Root, options workspaceRuntimeOptions) (*workspaceRuntime, error) {
+	defaults := (&wscfg.Root{}).DefaultsWithFallback(&execconfig.Defaults{
+		Model:    "openai_gpt-5.2",
+		Embedder: "openai_text",
+		Agent:    "chatter",
+	})
+	if wsConfig != nil {
+		defaults = wsConfig.DefaultsWithFallback(defaults)
+	}
+	wscfg.ApplyPathDefaults(defaults)
+	if err := defaults.Reporting.ValidateOrchestrationPrerequisites(); err != nil {
+		return nil, fmt.Errorf("invalid reporting orchestration configuration: %w", err)
+	}
+	toolPolicyConfig, err := agentlyrt.LoadToolPolicyConfig(workspace.Root())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tool policy config: %w", err)
+	}
+	toolPolicy, err := agentlyrt.NewToolPolicy(options.Policy, toolPolicyConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	rt, client, agentFndr, err := appserver.BuildWorkspaceRuntime(ctx, appserver.RuntimeOptions{
+		WorkspaceRoot:     workspace.Root(),
+		Defaults:          defaults,
+		SchedulerHeadless: options.SchedulerHeadless,
+		ConfigureRuntime: func(ctx context.Context, rt *executor.Runtime, workspaceRoot string) {
+			agentlyrt.ConfigureRegistry(ctx, rt, workspaceRoot)
+			agentlyrt.ApplyToolPolicy(rt, toolPolicy)
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize runtime: %w", err)
+	}
+	if defaults.Reporting.OrchestrationEnabled() {
+		switch {
+		case rt.Registry == nil:
+			return nil, fmt.Errorf("reporting orchestration requires a tool registry")
+		case rt.Reporting == nil:
+			return nil, fmt.Errorf("reporting orchestration requires the reporting service")
+		case rt.ReportRuns == nil:
+			return nil, fmt.Errorf("reporting orchestration requires the durable report-run service")
+		}
+	}
+
+	authRuntime, err := svcauthctx.NewRuntime(ctx, workspace.Root(), rt.DAO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize auth runtime: %w", err)
+	}
+	scheduleStore, err := svcscheduler.NewDatlyStore(ctx, rt.DAO, rt.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize scheduler store: %w", err)
+	}
+	schedulerSvcOpts := []svcscheduler.Option{
+		svcscheduler.WithConversationClient(rt.Conversation),
+		svcscheduler.WithAuthConfig(rt.AuthConfig),
+		svcscheduler.WithTokenProvider(rt.TokenProvider),
+		svcscheduler.WithUserService(svcauthctx.NewDatlyUserService(rt.DAO)),
+	}
+	if cap := agentlyrt.SchedulerMaxConcurrentRunsFromEnv(); cap > 0 {
+		schedulerSvcOpts = append(schedulerSvcOpts, svcscheduler.WithMaxConcurrentRuns(cap))
+		log.Printf("scheduler: max concurrent runs capped at %d", cap)
+	}
+	schedulerSvc := svcscheduler.New(scheduleStore, rt.Agent, schedulerSvcOpts...)
+	if options.Configure != nil {
+		if err := options.Configure(ctx, rt); err != nil {
+			return nil, err
+		}
+	}
+
+	version := firstNonEmpty(strings.TrimSpace(Version), "agently-v1")
+	apiOptions := appserver.APIOptions{
+		Version:          version,
+		Runtime:          rt,
+		Client:           client,
+		AgentFinder:      agentFndr,
+		AgentIDs:         appserver.DiscoverWorkspaceAgentIDs(workspace.Root()),
+		AuthRuntime:      authRuntime,
+		SchedulerService: schedulerSvc,
+		SchedulerOptions: agentlyrt.SchedulerOptionsFromEnv(),
+	}
+	if rt.UIBridge != nil {
+		apiOptions.UIBridgeHandler = http.HandlerFunc(rt.UIBridge.Hub().ServeHTTPRPC)
+	}
+	apiHandler, err := appserver.NewAPIHandler(ctx, apiOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create api handler: %w", err)
+	}
+	apiHandler, err = server.NewRetiredJWTKeyGuard(workspace.Root(), apiHandler)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load jwt keys: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(server.ToolPolicyPath, server.NewToolPolicyHandler(toolPolicy))
+	mux.Handle(server.ToolOpenAPIPath, server.NewToolOpenAPIHandler(apiHandler, version))
+	mux.Handle(server.JWKSPath, server.NewJWKSHandler(func() (*server.JWKSet, error) {
+		return server.LoadWorkspaceJWKS(workspace.Root(), time.Now())
+	}))
+	mux.Handle("/", apiHandler)
+
+	return &workspaceRuntime{
+		Runtime:     rt,
+		Defaults:    defaults,
+		ToolPolicy:  toolPolicy,
+		AuthRuntime: authRuntime,
+		Handler:     mux,
+	}, nil
+}